@@ -2074,7 +2074,7 @@ func (p *PublicHeaderStoreAPI) CurrentHeaderNumber(chainID uint64) (uint64, erro
 	if err != nil {
 		return 0, err
 	}
-	hs, err := interfaces.HeaderStoreFactory(group)
+	hs, err := interfaces.HeaderStoreFactory(group, chains.ChainType(chainID))
 	if err != nil {
 		return 0, err
 	}
@@ -2096,7 +2096,7 @@ func (p *PublicHeaderStoreAPI) GetHashByNumber(chainID uint64, number uint64) (c
 	if err != nil {
 		return common.Hash{}, err
 	}
-	hs, err := interfaces.HeaderStoreFactory(group)
+	hs, err := interfaces.HeaderStoreFactory(group, chains.ChainType(chainID))
 	if err != nil {
 		return common.Hash{}, err
 	}
@@ -2113,7 +2113,7 @@ func (p *PublicHeaderStoreAPI) CurrentNumberAndHash(chainID uint64) (map[string]
 	if err != nil {
 		return nil, err
 	}
-	hs, err := interfaces.HeaderStoreFactory(group)
+	hs, err := interfaces.HeaderStoreFactory(group, chains.ChainType(chainID))
 	if err != nil {
 		return nil, err
 	}
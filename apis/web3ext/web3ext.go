@@ -18,22 +18,56 @@
 package web3ext
 
 var Modules = map[string]string{
-	"admin":    AdminJs,
-	"clique":   CliqueJs,
-	"ethash":   EthashJs,
-	"debug":    DebugJs,
-	"eth":      EthJs,
-	"istanbul": Istanbul_JS,
-	"relayer":  Relayer_JS,
-	"miner":    MinerJs,
-	"net":      NetJs,
-	"personal": PersonalJs,
-	"rpc":      RpcJs,
-	"txpool":   TxpoolJs,
-	"les":      LESJs,
-	"vflux":    VfluxJs,
+	"admin":       AdminJs,
+	"atlas":       AtlasJs,
+	"clique":      CliqueJs,
+	"ethash":      EthashJs,
+	"headerstore": HeaderStoreJs,
+	"debug":       DebugJs,
+	"eth":         EthJs,
+	"istanbul":    Istanbul_JS,
+	"relayer":     Relayer_JS,
+	"miner":       MinerJs,
+	"net":         NetJs,
+	"personal":    PersonalJs,
+	"rpc":         RpcJs,
+	"txpool":      TxpoolJs,
+	"les":         LESJs,
+	"vflux":       VfluxJs,
 }
 
+const AtlasJs = `
+web3._extend({
+	property: 'atlas',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getMMRProof',
+			call: 'atlas_getMMRProof',
+			params: 2,
+			inputFormatter: [web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
+		}),
+	],
+	properties: []
+});
+`
+
+const HeaderStoreJs = `
+web3._extend({
+	property: 'headerstore',
+	methods:
+	[
+		new web3._extend.Method({
+			name: 'getRelayers',
+			call: 'headerstore_getRelayers',
+			params: 2,
+			inputFormatter: [web3._extend.utils.fromDecimal, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+	],
+	properties: []
+});
+`
+
 const Relayer_JS = `
 web3._extend({
 	property: 'relayer',
@@ -103,6 +137,32 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'getEpochTransitionProof',
+			call: 'istanbul_getEpochTransitionProof',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getSignerHistory',
+			call: 'istanbul_getSignerHistory',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getEpochUptime',
+			call: 'istanbul_getEpochUptime',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'simulateEpochRewards',
+			call: 'istanbul_simulateEpochRewards',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getEquivocationEvidences',
+			call: 'istanbul_getEquivocationEvidences',
+			params: 2
+		}),
 		new web3._extend.Method({
 			name: 'getLookbackWindow',
 			call: 'istanbul_getLookbackWindow',
@@ -36,10 +36,13 @@ import (
 	"github.com/ethereum/go-ethereum/trie"
 
 	"github.com/mapprotocol/atlas/apis/atlasapi"
+	"github.com/mapprotocol/atlas/chains"
 	"github.com/mapprotocol/atlas/core/chain"
+	mmr "github.com/mapprotocol/atlas/core/mmr"
 	"github.com/mapprotocol/atlas/core/rawdb"
 	"github.com/mapprotocol/atlas/core/state"
 	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/core/vm"
 )
 
 // PublicEthereumAPI provides an API to access Ethereum full node-related
@@ -84,6 +87,72 @@ func (api *PublicMinerAPI) Mining() bool {
 	return api.e.IsMining()
 }
 
+// PublicMMRAPI serves proofs of the MMR of the canonical block hashes, they let
+// FlyClient style light clients of Atlas on other chains sync with few headers.
+type PublicMMRAPI struct {
+	e *Ethereum
+}
+
+// NewPublicMMRAPI creates a new PublicMMRAPI instance.
+func NewPublicMMRAPI(e *Ethereum) *PublicMMRAPI {
+	return &PublicMMRAPI{e}
+}
+
+// GetMMRProof proves the block blockNumber against the MMR of the blocks up to and
+// including tipNumber.
+func (api *PublicMMRAPI) GetMMRProof(blockNumber, tipNumber hexutil.Uint64) (map[string]interface{}, error) {
+	proof, err := api.e.BlockChain().HeaderMMR().GenerateProof(uint64(blockNumber), uint64(tipNumber))
+	if err != nil {
+		return nil, err
+	}
+	enc, err := mmr.ProofInfoToBytes(proof)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"blockHash":      api.e.BlockChain().GetCanonicalHash(uint64(blockNumber)),
+		"tipHash":        api.e.BlockChain().GetCanonicalHash(uint64(tipNumber)),
+		"root":           proof.RootHash,
+		"rootDifficulty": (*hexutil.Big)(proof.RootDifficulty),
+		"leafNumber":     hexutil.Uint64(proof.LeafNumber),
+		"proof":          hexutil.Bytes(enc),
+	}, nil
+}
+
+// PublicHeaderStoreAPI serves the relayers authorized to sync the headers of the
+// chains kept by the header store contract.
+type PublicHeaderStoreAPI struct {
+	e *Ethereum
+}
+
+// NewPublicHeaderStoreAPI creates a new PublicHeaderStoreAPI instance.
+func NewPublicHeaderStoreAPI(e *Ethereum) *PublicHeaderStoreAPI {
+	return &PublicHeaderStoreAPI{e}
+}
+
+// RPCRelayer is a relayer of a chain with the headers it got accepted.
+type RPCRelayer struct {
+	Address     common.Address `json:"address"`
+	Submissions hexutil.Uint64 `json:"submissions"`
+}
+
+// GetRelayers returns the relayers of chainID at the given block.
+func (api *PublicHeaderStoreAPI) GetRelayers(ctx context.Context, chainID hexutil.Uint64, blockNrOrHash rpc.BlockNumberOrHash) ([]RPCRelayer, error) {
+	stateDb, _, err := api.e.APIBackend.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if stateDb == nil || err != nil {
+		return nil, err
+	}
+	relayers, err := vm.ChainRelayers(stateDb, chains.ChainType(chainID))
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]RPCRelayer, 0, len(relayers))
+	for _, r := range relayers {
+		ret = append(ret, RPCRelayer{Address: r.Address, Submissions: hexutil.Uint64(r.Submissions)})
+	}
+	return ret, nil
+}
+
 // PrivateMinerAPI provides private RPC methods to control the miner.
 // These methods can be abused by external users and must be considered insecure for use by untrusted users.
 type PrivateMinerAPI struct {
@@ -246,6 +246,7 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 				stateRoot := eth.blockchain.GetHeaderByHash(hash).Root
 				return eth.blockchain.StateAt(stateRoot)
 			})
+		istanbul.SetTxPool(eth.txPool)
 	}
 
 	eth.miner = miner.New(eth, &config.Miner, chainConfig, eth.EventMux(), eth.engine, eth.isLocalBlock, chainDb)
@@ -340,6 +341,16 @@ func (s *Ethereum) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   NewPublicMinerAPI(s),
 			Public:    true,
+		}, {
+			Namespace: "atlas",
+			Version:   "1.0",
+			Service:   NewPublicMMRAPI(s),
+			Public:    true,
+		}, {
+			Namespace: "headerstore",
+			Version:   "1.0",
+			Service:   NewPublicHeaderStoreAPI(s),
+			Public:    true,
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
@@ -691,6 +702,13 @@ func CreateConsensusEngine(stack *node.Node, chainConfig *params.ChainConfig, co
 		if err := istanbul.ApplyParamsChainConfigToConfig(chainConfig, &config.Istanbul); err != nil {
 			log.Crit("Invalid Configuration for Istanbul Engine", "err", err)
 		}
+		// Keep the round states and evidences on disk, so that they survive restarts and can be dumped
+		if config.Istanbul.RoundStateDBPath == "" {
+			config.Istanbul.RoundStateDBPath = stack.ResolvePath(istanbul.RoundStateDBName)
+		}
+		if config.Istanbul.EvidenceDBPath == "" {
+			config.Istanbul.EvidenceDBPath = stack.ResolvePath(istanbul.EvidenceDBName)
+		}
 
 		return istanbulBackend.New(&config.Istanbul, db)
 	}
@@ -0,0 +1,104 @@
+package bsc
+
+import (
+	"math/big"
+
+	"github.com/mapprotocol/atlas/chains"
+)
+
+const (
+	defaultEpochLength = uint64(200)  // blocks between validator set checkpoints before Lorentz
+	lorentzEpochLength = uint64(500)  // blocks between validator set checkpoints since Lorentz
+	maxwellEpochLength = uint64(1000) // blocks between validator set checkpoints since Maxwell
+
+	defaultTurnLength = uint8(1) // consecutive blocks an in-turn validator signs before Bohr
+
+	gasLimitBoundDivisorBeforeLorentz = uint64(256)
+	gasLimitBoundDivisor              = uint64(1024)
+)
+
+// Config holds the Parlia forks of a BSC network that change how its headers are
+// verified. A nil fork is never activated.
+type Config struct {
+	LubanBlock  *big.Int // validators carry BLS vote keys and blocks a vote attestation
+	BohrTime    *uint64  // validators sign turnLength consecutive blocks, announced in epoch blocks
+	LorentzTime *uint64  // epochs last 500 blocks and timestamps carry milliseconds
+	MaxwellTime *uint64  // epochs last 1000 blocks
+}
+
+func newUint64(v uint64) *uint64 { return &v }
+
+var (
+	// MainnetConfig is the Parlia config of BSC mainnet.
+	MainnetConfig = &Config{
+		LubanBlock:  big.NewInt(29_020_050),
+		BohrTime:    newUint64(1727317200),
+		LorentzTime: newUint64(1745903100),
+		MaxwellTime: newUint64(1751250600),
+	}
+
+	// ChapelConfig is the Parlia config of the BSC testnet.
+	ChapelConfig = &Config{
+		LubanBlock:  big.NewInt(29_295_050),
+		BohrTime:    newUint64(1724116996),
+		LorentzTime: newUint64(1744097580),
+		MaxwellTime: newUint64(1748243100),
+	}
+
+	// AllForksConfig activates every fork from genesis, it is used by the chains
+	// without a known config.
+	AllForksConfig = &Config{
+		LubanBlock:  big.NewInt(0),
+		BohrTime:    newUint64(0),
+		LorentzTime: newUint64(0),
+		MaxwellTime: newUint64(0),
+	}
+)
+
+var configs = map[chains.ChainType]*Config{
+	chains.ChainTypeBSC:     MainnetConfig,
+	chains.ChainTypeBSCTest: ChapelConfig,
+}
+
+// ConfigOf returns the Parlia config of the chain.
+func ConfigOf(chain chains.ChainType) *Config {
+	if c, ok := configs[chain]; ok {
+		return c
+	}
+	return AllForksConfig
+}
+
+func (c *Config) IsLuban(num *big.Int) bool {
+	return c.LubanBlock != nil && num != nil && c.LubanBlock.Cmp(num) <= 0
+}
+
+func (c *Config) IsBohr(time uint64) bool {
+	return c.BohrTime != nil && *c.BohrTime <= time
+}
+
+func (c *Config) IsLorentz(time uint64) bool {
+	return c.LorentzTime != nil && *c.LorentzTime <= time
+}
+
+func (c *Config) IsMaxwell(time uint64) bool {
+	return c.MaxwellTime != nil && *c.MaxwellTime <= time
+}
+
+// epochLength returns the epoch length of the forks active at the given time.
+func (c *Config) epochLength(time uint64) uint64 {
+	switch {
+	case c.IsMaxwell(time):
+		return maxwellEpochLength
+	case c.IsLorentz(time):
+		return lorentzEpochLength
+	default:
+		return defaultEpochLength
+	}
+}
+
+func (c *Config) gasLimitBoundDivisor(time uint64) uint64 {
+	if c.IsLorentz(time) {
+		return gasLimitBoundDivisor
+	}
+	return gasLimitBoundDivisorBeforeLorentz
+}
@@ -0,0 +1,159 @@
+package bsc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains"
+)
+
+// Genesis headers of BSC mainnet and Chapel, they hash to the genesis hashes of the
+// networks.
+var (
+	mainnetGenesisHash = common.HexToHash("0x0d21840abff46b96c84b2ac9e10e4f5cdaeb5693cb665db62a2f3b02d2d57b5b")
+	chapelGenesisHash  = common.HexToHash("0x6d3c66c5357ec91d5c43af47e234a939b22557cbb552dc45bebbceeed90fbe34")
+
+	mainnetGenesis = recordedGenesis(
+		"0x919fcc7ad870b53db0aa76eb588da06bacb6d230195100699fc928511003b422",
+		"0x00000000000000000000000000000000000000000000000000000000000000002a7cdd959bfe8d9487b2a43b33565295a698f7e26488aa4d1955ee33403f8ccb1d4de5fb97c7ade29ef9f4360c606c7ab4db26b016007d3ad0ab86a0ee01c3b1283aa067c58eab4709f85e99d46de5fe685b1ded8013785d6623cc18d214320b6bb6475978f3adfc719c99674c072166708589033e2d9afec2be4ec20253b8642161bc3f444f53679c1f3d472f7be8361c80a4c1e7e9aaf001d0877f1cfde218ce2fd7544e0b2cc94692d4a704debef7bcb61328b8f7166496996a7da21cf1f1b04d9b3e26a3d0772d4c407bbe49438ed859fe965b140dcf1aab71a96bbad7cf34b5fa511d8e963dbba288b1960e75d64430b3230294d12c6ab2aac5c2cd68e80b16b581ea0a6e3c511bbd10f4519ece37dc24887e11b55d7ae2f5b9e386cd1b50a4550696d957cb4900f03a82012708dafc9e1b880fd083b32182b869be8e0922b81f8e175ffde54d797fe11eb03f9e3bf75f1d68bf0b8b6fb4e317a0f9d6f03eaf8ce6675bc60d8c4d90829ce8f72d0163c1d5cf348a862d55063035e7a025f4da968de7e4d7e4004197917f4070f1d6caa02bbebaebb5d7e581e4b66559e635f805ff0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	)
+	chapelGenesis = recordedGenesis(
+		"0x62cbef2935ac1ada32fc9795d4b59e982febd8cd4a36d299066a877b225a9dde",
+		"0x00000000000000000000000000000000000000000000000000000000000000001284214b9b9c85549ab3d2b972df0deef66ac2c9b71b214cb885500844365e95cd9942c7276e7fd8a2959d3f95eae5dc7d70144ce1b73b403b7eb6e0980a75ecd1309ea12fa2ed87a8744fbfc9b863d535552c16704d214347f29fa77f77da6d75d7c752f474cf03cceff28abc65c9cbae594f725c80e12d0000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000",
+	)
+)
+
+func recordedGenesis(root, extra string) *Header {
+	return &Header{
+		UncleHash:   ethtypes.EmptyUncleHash,
+		Coinbase:    common.HexToAddress("0xfffffffffffffffffffffffffffffffffffffffe"),
+		Root:        common.HexToHash(root),
+		TxHash:      ethtypes.EmptyRootHash,
+		ReceiptHash: ethtypes.EmptyRootHash,
+		Difficulty:  big.NewInt(1),
+		Number:      big.NewInt(0),
+		GasLimit:    40_000_000,
+		Time:        0x5e9da7ce,
+		Extra:       hexutil.MustDecode(extra),
+	}
+}
+
+// Extra-data recorded from BSC headers since Luban. Between vanity and seal they carry
+// the announced validators with their vote addresses and the vote attestation of the
+// parent.
+var (
+	recordedSealOnly              = "0xd983010209846765746889676f312e31392e3131856c696e75780000a6bf97c1e99f701bb14cb7dfb68b90bd3e6d1ca656964630de71beffc7f33f7f08ec99d336ec51ad9fad0ac84ae77ca2e8ad9512acc56e0d7c93f3c2ce7de1b69149a5a400"
+	recordedLubanEpoch            = "0xd983010209846765746889676f312e31392e3131856c696e75780000a6bf97c1152465176c461afb316ebc773c61faee85a6515daa8a923564c6ffd37fb2fe9f118ef88092e8762c7addb526ab7eb1e772baef85181f892c731be0c1891a50e6b06262c816295e26495cef6f69dfa69911d9d8e4f3bbadb89b977cf58294f7239d515e15b24cfeb82494056cf691eaf729b165f32c9757c429dba5051155903067e56ebe3698678e912d4c407bbe49438ed859fe965b140dcf1aab71a993c1f7f6929d1fe2a17b4e14614ef9fc5bdc713d6631d675403fbeefac55611bf612700b1b65f4744861b80b0f7d6ab03f349bbafec1551819b8be1efea2fc46ca749aa184248a459464eec1a21e7fc7b71a053d9644e9bb8da4853b8f872cd7c1d6b324bf1922829830646ceadfb658d3de009a61dd481a114a2e761c554b641742c973867899d300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000069c77a677c40c7fbea129d4b171a39b7a8ddabfab2317f59d86abfaf690850223d90e9e7593d91a29331dfc2f84d5adecc75fc39ecab4632c1b4400a3dd1e1298835bcca70f657164e5b75689b64b7fd1fa275f334f28e1896a26afa1295da81418593bd12814463d9f6e45c36a0e47eb4cd3e5b6af29c41e2a3a5636430155a466e216585af3ba772b61c6014342d914470ec7ac2975be345796c2b81db0422a5fd08e40db1fc2368d2245e4b18b1d0b85c921aaaafd2e341760e29fc613edd39f71254614e2055c3287a517ae2f5b9e386cd1b50a4550696d957cb4900f03ab84f83ff2df44193496793b847f64e9d6db1b3953682bb95edd096eb1e69bbd357c200992ca78050d0cbe180cfaa018e8b6c8fd93d6f4cea42bbb345dbc6f0dfdb5bec73a8a257074e82b881cfa06ef3eb4efeca060c2531359abd0eab8af1e3edfa2025fca464ac9c3fd123f6c24a0d78869485a6f79b60359f141df90a0c745125b131caaffd12000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000b218c5d6af1f979ac42bc68d98a5a0d796c6ab01000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000b4dd66d7c2c7e57f628210187192fb89d4b99dd4000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000be807dddb074639cd9fa61b47676c064fc50d62cb1f2c71577def3144fabeb75a8a1c8cb5b51d1d1b4a05eec67988b8685008baa17459ec425dbaebc852f496dc92196cdcc8e6d00c17eb431350c6c50d8b8f05176b90b11b3a3d4feb825ae9702711566df5dbf38e82add4dd1b573b95d2466fa6501ccb81e9d26a352b96150ccbf7b697fd0a419d1d6bf74282782b0b3eb1413c901d6ecf02e8e28000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000e2d3a739effcd3a99387d015e260eefac72ebea1956c470ddff48cb49300200b5f83497f3a3ccb3aeb83c5edd9818569038e61d197184f4aa6939ea5e9911e3e98ac6d21e9ae3261a475a27bb1028f140bc2a7c843318afd000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000ea0a6e3c511bbd10f4519ece37dc24887e11b55db2d4c6283c44a1c7bd503aaba7666e9f0c830e0ff016c1c750a5e48757a713d0836b1cabfd5c281b1de3b77d1c192183ee226379db83cffc681495730c11fdde79ba4c0c000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000ef0274e31810c9df02f98fafde0f841f4e66a1cd000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000e99f701bb14cb7dfb68b90bd3e6d1ca656964630de71beffc7f33f7f08ec99d336ec51ad9fad0ac84ae77ca2e8ad9512acc56e0d7c93f3c2ce7de1b69149a5a400"
+	recordedAttestation           = "0xd883010205846765746888676f312e32302e35856c696e75780000002995c52af8b5830563efb86089cf168dcf4c5d3cb057926628ad1bf0f03ea67eef1458485578a4f8489afa8a853ecc7af45e2d145c21b70641c4b29f0febd2dd2c61fa1ba174be3fd47f1f5fa2ab9b5c318563d8b70ca58d0d51e79ee32b2fb721649e2cb9d36538361fba11f84c8401d14bb7a0fa67ddb3ba654d6006bf788710032247aa4d1be0707273e696b422b3ff72e9798401d14bbaa01225f505f5a0e1aefadcd2913b7aac9009fe4fb3d1bf57399e0b9dce5947f94280fe6d3647276c4127f437af59eb7c7985b2ae1ebe432619860695cb6106b80cc66c735bc1709afd11f233a2c97409d38ebaf7178aa53e895aea2fe0a229f71ec601"
+	recordedLubanEpochAttestation = "0xd883010209846765746888676f312e31392e38856c696e7578000000dc55905c071284214b9b9c85549ab3d2b972df0deef66ac2c98e82934ca974fdcd97f3309de967d3c9c43fa711a8d673af5d75465844bf8969c8d1948d903748ac7b8b1720fa64e50c35552c16704d214347f29fa77f77da6d75d7c752b742ad4855bae330426b823e742da31f816cc83bc16d69a9134be0cfb4a1d17ec34f1b5b32d5c20440b8536b1e88f0f247788386d0ed6c748e03a53160b4b30ed3748cc5000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000980a75ecd1309ea12fa2ed87a8744fbfc9b863d589037a9ace3b590165ea1c0c5ac72bf600b7c88c1e435f41932c1132aae1bfa0bb68e46b96ccb12c3415e4d82af717d8a2959d3f95eae5dc7d70144ce1b73b403b7eb6e0b973c2d38487e58fd6e145491b110080fb14ac915a0411fc78f19e09a399ddee0d20c63a75d8f930f1694544ad2dc01bb71b214cb885500844365e95cd9942c7276e7fd8a2750ec6dded3dcdc2f351782310b0eadc077db59abca0f0cd26776e2e7acb9f3bce40b1fa5221fd1561226c6263cc5ff474cf03cceff28abc65c9cbae594f725c80e12d96c9b86c3400e529bfe184056e257c07940bb664636f689e8d2027c834681f8f878b73445261034e946bb2d901b4b878f8b27bb8608c11016739b3f8a19e54ab8c7abacd936cfeba200f3645a98b65adb0dd3692b69ce0b3ae10e7176b9a4b0d83f04065b1042b4bcb646a34b75c550f92fc34b8b2b1db0fa0d3172db23ba92727c80bcd306320d0ff411bf858525fde13bc8e0370f84c8401e9c2e6a0820dc11d63176a0eb1b828bc5376867b275579112b7013358da40317e7bab6e98401e9c2e7a00edc71ce80105a3220a87bea2792fa340d66c59002f02b0a09349ed1ed28407080048b972fac2b9077a4dcb6fc37093799a652858016c99142b227500c844fa97ec22e3f9d3b1e982f14bcd999a7453e89ce5ef5c55f1c7f8f74ba904186cd67828200"
+)
+
+// withTurnLength makes the synthetic extra-data of a Bohr epoch block from the recorded
+// extra-data of a Luban epoch block, splicing the turn length in after its validators.
+// No header recorded across the Bohr boundary is checked in: Bohr is only covered by
+// these spliced extras and the synthetic chains of make_chain_test.go.
+func withTurnLength(extra string, validators int, turnLength byte) string {
+	data := hexutil.MustDecode(extra)
+	offset := extraVanity + validatorNumberSize + validators*validatorBytesLengthLuban
+	spliced := append(append(append([]byte{}, data[:offset]...), turnLength), data[offset:]...)
+	return hexutil.Encode(spliced)
+}
+
+func TestRecorded_Genesis(t *testing.T) {
+	tests := []struct {
+		chain      chains.ChainType
+		genesis    *Header
+		hash       common.Hash
+		validators int
+		first      common.Address
+	}{
+		{chains.ChainTypeBSC, mainnetGenesis, mainnetGenesisHash, 21, common.HexToAddress("0x2a7cdd959bfe8d9487b2a43b33565295a698f7e2")},
+		{chains.ChainTypeBSCTest, chapelGenesis, chapelGenesisHash, 6, common.HexToAddress("0x1284214b9b9c85549ab3d2b972df0deef66ac2c9")},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.hash, tt.genesis.Hash())
+
+		extra, err := parseExtra(tt.genesis, ConfigOf(tt.chain), true)
+		assert.Nil(t, err)
+		assert.Equal(t, tt.validators, len(extra.Validators))
+		assert.Equal(t, tt.first, extra.Validators[0])
+
+		// the genesis validators sign from the genesis on, it is its own previous epoch
+		db := newTestDB()
+		hs := NewHeaderStore(tt.chain)
+		assert.Nil(t, hs.ResetHeaderStore(db, encodeHeaders([]*Header{tt.genesis, tt.genesis}), big.NewInt(1)))
+		number, hash, err := hs.GetCurrentNumberAndHash(db)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), number)
+		assert.Equal(t, tt.hash, hash)
+	}
+}
+
+func TestRecorded_Extra(t *testing.T) {
+	var (
+		luban = &Config{LubanBlock: big.NewInt(0)}
+		bohr  = AllForksConfig
+	)
+	tests := []struct {
+		name       string
+		extra      string
+		config     *Config
+		epoch      bool
+		validators int
+		turnLength uint8
+		target     uint64
+	}{
+		{name: "seal only", extra: recordedSealOnly, config: luban},
+		{name: "luban epoch", extra: recordedLubanEpoch, config: luban, epoch: true, validators: 21},
+		{name: "synthetic bohr epoch", extra: withTurnLength(recordedLubanEpoch, 21, 4), config: bohr, epoch: true, validators: 21, turnLength: 4},
+		{name: "attestation", extra: recordedAttestation, config: luban, target: 30493626},
+		{name: "luban epoch with attestation", extra: recordedLubanEpochAttestation, config: luban, epoch: true, validators: 7, target: 32096999},
+		{name: "synthetic bohr epoch with attestation", extra: withTurnLength(recordedLubanEpochAttestation, 7, 4), config: bohr, epoch: true, validators: 7, turnLength: 4, target: 32096999},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := &Header{Number: big.NewInt(1), Extra: hexutil.MustDecode(tt.extra)}
+			extra, err := parseExtra(header, tt.config, tt.epoch)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.validators, len(extra.Validators))
+			assert.Equal(t, tt.turnLength, extra.TurnLength)
+			if tt.target == 0 {
+				assert.Nil(t, extra.Attestation)
+				return
+			}
+			assert.Equal(t, tt.target, extra.Attestation.Data.TargetNumber)
+		})
+	}
+
+	// the vote addresses stay with their validators when the set is sorted
+	extra, err := parseExtra(&Header{Number: big.NewInt(1), Extra: hexutil.MustDecode(recordedLubanEpoch)}, luban, true)
+	assert.Nil(t, err)
+	snap := new(Snapshot)
+	snap.setValidators(extra)
+	assert.Equal(t, common.HexToAddress("0xcc8e6d00c17eb431350c6c50d8b8f05176b90b11"), snap.Validators[14])
+	assert.Equal(t, hexutil.MustDecode("0xb2d4c6283c44a1c7bd503aaba7666e9f0c830e0ff016c1c750a5e48757a713d0836b1cabfd5c281b1de3b77d1c192183"), snap.VoteAddresses[18][:])
+}
+
+func TestRecorded_VoteSignature(t *testing.T) {
+	// the epoch block carries the attestation of its parent, voted by the validators it
+	// announces again
+	header := &Header{Number: big.NewInt(1), Extra: hexutil.MustDecode(recordedLubanEpochAttestation)}
+	extra, err := parseExtra(header, &Config{LubanBlock: big.NewInt(0)}, true)
+	assert.Nil(t, err)
+	signers := new(Snapshot)
+	signers.setValidators(extra)
+	attestation := extra.Attestation
+	assert.Equal(t, common.HexToHash("0x0edc71ce80105a3220a87bea2792fa340d66c59002f02b0a09349ed1ed284070"), attestation.Data.TargetHash)
+	assert.Nil(t, verifyVoteSignature(attestation, signers))
+
+	// the signature covers the vote data
+	data := *attestation.Data
+	data.TargetNumber++
+	forged := &VoteAttestation{VoteAddressSet: attestation.VoteAddressSet, AggSignature: attestation.AggSignature, Data: &data}
+	assert.ErrorIs(t, verifyVoteSignature(forged, signers), errInvalidAttestation)
+}
@@ -0,0 +1,515 @@
+package bsc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// MaxHeaderLimit is the number of canonical headers kept per chain, older ones are
+// overwritten in a ring buffer.
+const MaxHeaderLimit = 100000
+
+// snapshotInterval is the distance between stored snapshots besides the epoch blocks,
+// at most snapshotInterval-1 headers are replayed to rebuild a snapshot.
+const snapshotInterval = 100
+
+// HeaderStore keeps the canonical header chain of a BSC network. The Parlia snapshot is
+// stored at every epoch block, every snapshotInterval blocks and at the head, snapshots
+// at other headers are rebuilt from the stored snapshot before them when a reorg needs
+// them.
+type HeaderStore struct {
+	ChainType chains.ChainType
+	CurNumber uint64
+	CurHash   common.Hash
+}
+
+// entry is a canonical header with the total difficulty of the chain up to it.
+type entry struct {
+	Header *Header
+	TD     *big.Int
+}
+
+// snapshotPair holds the snapshot after a header and the one of its parent, whose
+// validators sealed the header and sign the attestations of it.
+type snapshotPair struct {
+	Signers *Snapshot
+	Head    *Snapshot
+}
+
+func NewHeaderStore(chain chains.ChainType) *HeaderStore {
+	return &HeaderStore{ChainType: chain}
+}
+
+func storeDbKey(chain chains.ChainType) common.Hash {
+	return common.BytesToHash([]byte(fmt.Sprintf("bsc-%d-store", chain)))
+}
+
+func entryDbKey(chain chains.ChainType, number uint64) common.Hash {
+	str := fmt.Sprintf("bsc-%d-%d", chain, number%MaxHeaderLimit)
+	key := common.BytesToHash([]byte(str))
+	log.Debug("bsc entryDbKey", "number", number, "str", str, "key", key.String())
+	return key
+}
+
+func snapshotDbKey(chain chains.ChainType, number uint64) common.Hash {
+	return common.BytesToHash([]byte(fmt.Sprintf("bsc-%d-snap-%d", chain, number%MaxHeaderLimit)))
+}
+
+func headSnapshotDbKey(chain chains.ChainType) common.Hash {
+	return common.BytesToHash([]byte(fmt.Sprintf("bsc-%d-snap-head", chain)))
+}
+
+func (hs *HeaderStore) chainID() *big.Int {
+	return new(big.Int).SetUint64(uint64(hs.ChainType))
+}
+
+func (hs *HeaderStore) config() *Config {
+	return ConfigOf(hs.ChainType)
+}
+
+// ResetHeaderStore starts the store from a trusted epoch header. The validators of an
+// epoch block only take effect minerHistoryCheckLen blocks after it, so the store takes
+// the rlp list of the epoch header announcing the set signing at the checkpoint and the
+// checkpoint epoch header itself.
+func (hs *HeaderStore) ResetHeaderStore(db types.StateDB, header []byte, td *big.Int) error {
+	var headers []*Header
+	if err := rlp.DecodeBytes(header, &headers); err != nil {
+		log.Error("rlp decode bsc headers failed.", "err", err)
+		return chains.ErrRLPDecode
+	}
+	if len(headers) != 2 || headers[0].Number == nil || headers[1].Number == nil {
+		return errors.New("reset needs the previous epoch header and the checkpoint header")
+	}
+	prev, h := headers[0], headers[1]
+
+	config := hs.config()
+	epochLength := config.epochLength(h.Time)
+	if h.Number.Uint64()%epochLength != 0 {
+		return fmt.Errorf("reset header must be an epoch block, number: %v", h.Number)
+	}
+	if err := checkPreviousEpoch(prev, h, config); err != nil {
+		return err
+	}
+	prevExtra, err := parseExtra(prev, config, true)
+	if err != nil {
+		return err
+	}
+	extra, err := parseExtra(h, config, true)
+	if err != nil {
+		return err
+	}
+	if len(prevExtra.Validators) == 0 || len(extra.Validators) == 0 {
+		return errCheckpointWithoutSigner
+	}
+	if td == nil {
+		td = new(big.Int)
+	}
+
+	number, hash := h.Number.Uint64(), h.Hash()
+	snap := newSnapshot(number, hash, epochLength, prev, prevExtra)
+	snap.updateAttestation(h, extra.Attestation)
+	if snap.minerHistoryCheckLen() == 0 {
+		snap.setValidators(extra)
+		snap.Checkpoint = number
+	} else if prev.Hash() == hash && number != 0 {
+		return fmt.Errorf("%w: the validators of %d do not sign at it", errInvalidPreviousEpoch, number)
+	}
+	// the recent signers before the checkpoint are unknown, the first validators after
+	// it are not checked against them
+	pair := &snapshotPair{Signers: snap, Head: snap}
+	if err := hs.storeEntry(db, &entry{Header: h, TD: new(big.Int).Set(td)}); err != nil {
+		return err
+	}
+	if err := hs.storeSnapshot(db, snapshotDbKey(hs.ChainType, number), pair); err != nil {
+		return err
+	}
+	if err := hs.storeSnapshot(db, headSnapshotDbKey(hs.ChainType), pair); err != nil {
+		return err
+	}
+	hs.CurNumber, hs.CurHash = number, hash
+	return hs.Store(db)
+}
+
+// checkPreviousEpoch checks that prev is the epoch header right before the checkpoint h,
+// or h itself when the validators of h sign at it already.
+func checkPreviousEpoch(prev, h *Header, config *Config) error {
+	p, n := prev.Number.Uint64(), h.Number.Uint64()
+	if p == n {
+		if prev.Hash() != h.Hash() {
+			return fmt.Errorf("%w: not the checkpoint header %d", errInvalidPreviousEpoch, n)
+		}
+		return nil
+	}
+	// an epoch length only grows at a fork, so the next epoch block after prev is at most
+	// one of its epochs later
+	length := config.epochLength(prev.Time)
+	if p > n || p%length != 0 || p+length < n {
+		return fmt.Errorf("%w: number %d, checkpoint %d", errInvalidPreviousEpoch, p, n)
+	}
+	if prev.Time > h.Time {
+		return fmt.Errorf("%w: timestamp %d after the checkpoint %d", errInvalidPreviousEpoch, prev.Time, h.Time)
+	}
+	return nil
+}
+
+func (hs *HeaderStore) Store(db types.StateDB) error {
+	data, err := rlp.EncodeToBytes(hs)
+	if err != nil {
+		log.Error("Failed to RLP encode bsc HeaderStore", "err", err)
+		return err
+	}
+	db.SetPOWState(chains.BscHeaderStoreAddress, storeDbKey(hs.ChainType), data)
+	return nil
+}
+
+func (hs *HeaderStore) Load(db types.StateDB) error {
+	data := db.GetPOWState(chains.BscHeaderStoreAddress, storeDbKey(hs.ChainType))
+	if len(data) == 0 {
+		return errStoreNotInitialized
+	}
+
+	var h HeaderStore
+	if err := rlp.DecodeBytes(data, &h); err != nil {
+		log.Error("bsc HeaderStore RLP decode failed", "err", err)
+		return fmt.Errorf("bsc HeaderStore RLP decode failed, error: %s", err.Error())
+	}
+	*hs = h
+	return nil
+}
+
+func (hs *HeaderStore) storeEntry(db types.StateDB, e *entry) error {
+	data, err := rlp.EncodeToBytes(e)
+	if err != nil {
+		log.Error("Failed to RLP encode bsc header", "err", err)
+		return err
+	}
+	db.SetPOWState(chains.BscHeaderStoreAddress, entryDbKey(hs.ChainType, e.Header.Number.Uint64()), data)
+	return nil
+}
+
+func (hs *HeaderStore) deleteEntry(db types.StateDB, number uint64) {
+	db.SetPOWState(chains.BscHeaderStoreAddress, entryDbKey(hs.ChainType, number), nil)
+	db.SetPOWState(chains.BscHeaderStoreAddress, snapshotDbKey(hs.ChainType, number), nil)
+}
+
+// loadEntry returns the canonical entry with the given number, or nil if it is unknown
+// or has been overwritten.
+func (hs *HeaderStore) loadEntry(db types.StateDB, number uint64) *entry {
+	data := db.GetPOWState(chains.BscHeaderStoreAddress, entryDbKey(hs.ChainType, number))
+	if len(data) == 0 {
+		return nil
+	}
+	e := new(entry)
+	if err := rlp.DecodeBytes(data, e); err != nil {
+		log.Error("Invalid bsc header RLP", "number", number, "err", err)
+		return nil
+	}
+	if e.Header.Number.Uint64() != number {
+		return nil
+	}
+	return e
+}
+
+func (hs *HeaderStore) storeSnapshot(db types.StateDB, key common.Hash, pair *snapshotPair) error {
+	data, err := rlp.EncodeToBytes(pair)
+	if err != nil {
+		log.Error("Failed to RLP encode bsc snapshot", "err", err)
+		return err
+	}
+	db.SetPOWState(chains.BscHeaderStoreAddress, key, data)
+	return nil
+}
+
+// loadSnapshot returns the snapshots stored under the key if they are the ones after
+// the canonical header with the given number.
+func (hs *HeaderStore) loadSnapshot(db types.StateDB, key common.Hash, number uint64) *snapshotPair {
+	data := db.GetPOWState(chains.BscHeaderStoreAddress, key)
+	if len(data) == 0 {
+		return nil
+	}
+	pair := new(snapshotPair)
+	if err := rlp.DecodeBytes(data, pair); err != nil {
+		log.Error("Invalid bsc snapshot RLP", "number", number, "err", err)
+		return nil
+	}
+	e := hs.loadEntry(db, number)
+	if pair.Head.Number != number || e == nil || e.Header.Hash() != pair.Head.Hash {
+		return nil
+	}
+	return pair
+}
+
+// snapshots returns the snapshots after the canonical header with the given number and
+// after its parent. They are read from the head or the stored snapshots, or rebuilt from
+// the latest stored snapshot before the header, replaying less than snapshotInterval
+// headers.
+func (hs *HeaderStore) snapshots(db types.StateDB, number uint64) (*snapshotPair, error) {
+	if number == hs.CurNumber {
+		if pair := hs.loadSnapshot(db, headSnapshotDbKey(hs.ChainType), number); pair != nil {
+			return pair, nil
+		}
+	}
+
+	var (
+		headers []*Header
+		base    *snapshotPair
+	)
+	for n := number; base == nil; n-- {
+		if base = hs.loadSnapshot(db, snapshotDbKey(hs.ChainType, n), n); base != nil {
+			break
+		}
+		e := hs.loadEntry(db, n)
+		if e == nil || n == 0 {
+			return nil, errUnknownAncestor
+		}
+		if len(headers) >= snapshotInterval {
+			return nil, errSnapshotReplay
+		}
+		headers = append(headers, e.Header)
+	}
+
+	// the headers have been verified when they were stored, their coinbase is the signer
+	config := hs.config()
+	checkpoint := func(n uint64) *Header { return hs.GetHeaderByNumber(db, n) }
+	pair := base
+	for i := len(headers) - 1; i >= 0; i-- {
+		snap, err := pair.Head.apply(headers[i], headers[i].Coinbase, config, checkpoint)
+		if err != nil {
+			return nil, err
+		}
+		pair = &snapshotPair{Signers: pair.Head, Head: snap}
+	}
+	return pair, nil
+}
+
+// GetHeaderByNumber returns the canonical header with the given number.
+func (hs *HeaderStore) GetHeaderByNumber(db types.StateDB, number uint64) *Header {
+	e := hs.loadEntry(db, number)
+	if e == nil {
+		return nil
+	}
+	return e.Header
+}
+
+// verifyHeaders checks the headers against the stored chain and returns the entries
+// they would be written as together with the snapshots after them. The first header
+// must be a child of a canonical header.
+func (hs *HeaderStore) verifyHeaders(db types.StateDB, headers []*Header) ([]*entry, []*snapshotPair, int, error) {
+	first := headers[0].Number.Uint64()
+	if first == 0 || first > hs.CurNumber+1 {
+		return nil, nil, 0, fmt.Errorf("non contiguous insert, current number: %d, first number: %d", hs.CurNumber, first)
+	}
+	if hs.CurNumber >= MaxHeaderLimit && first <= hs.CurNumber-MaxHeaderLimit+1 {
+		return nil, nil, 0, fmt.Errorf("obsolete block, current number: %d, first number: %d", hs.CurNumber, first)
+	}
+	parent := hs.loadEntry(db, first-1)
+	if parent == nil || parent.Header.Hash() != headers[0].ParentHash {
+		return nil, nil, 0, errUnknownAncestor
+	}
+	pair, err := hs.snapshots(db, first-1)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	var (
+		entries = make([]*entry, 0, len(headers))
+		pairs   = make([]*snapshotPair, 0, len(headers))
+		chainID = hs.chainID()
+		config  = hs.config()
+	)
+	checkpoint := func(number uint64) *Header {
+		if number >= first {
+			return entries[number-first].Header
+		}
+		return hs.GetHeaderByNumber(db, number)
+	}
+	for i, header := range headers {
+		if err := verifyHeader(header, parent.Header, config); err != nil {
+			return nil, nil, i, err
+		}
+		signer, err := verifySeal(header, chainID)
+		if err != nil {
+			return nil, nil, i, err
+		}
+		snap, err := pair.Head.apply(header, signer, config, checkpoint)
+		if err != nil {
+			return nil, nil, i, err
+		}
+		extra, err := parseExtra(header, config, header.Number.Uint64()%snap.EpochLength == 0)
+		if err != nil {
+			return nil, nil, i, err
+		}
+		if extra.Attestation != nil {
+			if err := verifyAttestation(extra.Attestation, parent.Header, pair.Head, pair.Signers); err != nil {
+				return nil, nil, i, err
+			}
+		}
+
+		e := &entry{
+			Header: header,
+			TD:     new(big.Int).Add(parent.TD, header.Difficulty),
+		}
+		entries = append(entries, e)
+		pair = &snapshotPair{Signers: pair.Head, Head: snap}
+		pairs = append(pairs, pair)
+		parent = e
+	}
+	return entries, pairs, 0, nil
+}
+
+func (hs *HeaderStore) InsertHeaders(db types.StateDB, headers []byte) ([]*params.NumberHash, *params.Reorg, error) {
+	start := time.Now()
+	var chain []*Header
+	if err := rlp.DecodeBytes(headers, &chain); err != nil {
+		log.Error("rlp decode bsc headers failed.", "err", err)
+		return nil, nil, chains.ErrRLPDecode
+	}
+	if err := checkContiguous(chain); err != nil {
+		return nil, nil, err
+	}
+	if err := hs.Load(db); err != nil {
+		return nil, nil, err
+	}
+
+	entries, pairs, _, err := hs.verifyHeaders(db, chain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Parlia chooses the chain with the highest total difficulty
+	last := entries[len(entries)-1]
+	current := hs.loadEntry(db, hs.CurNumber)
+	if current != nil && last.TD.Cmp(current.TD) <= 0 {
+		log.Info("ignored bsc side chain", "number", last.Header.Number, "td", last.TD, "localTD", current.TD)
+		return nil, nil, nil
+	}
+
+	// the parent of the first header is canonical, skip the headers that already are
+	ancestor := params.NumberHash{Number: chain[0].Number.Uint64() - 1, Hash: chain[0].ParentHash}
+	inserted := make([]*params.NumberHash, 0, len(entries))
+	for i, e := range entries {
+		number, hash := e.Header.Number.Uint64(), e.Header.Hash()
+		if len(inserted) == 0 && number <= hs.CurNumber {
+			if stored := hs.loadEntry(db, number); stored != nil && stored.Header.Hash() == hash {
+				ancestor = params.NumberHash{Number: number, Hash: hash}
+				continue
+			}
+		}
+		if err := hs.storeEntry(db, e); err != nil {
+			return nil, nil, err
+		}
+		if number%snapshotInterval == 0 || number%pairs[i].Head.EpochLength == 0 {
+			if err := hs.storeSnapshot(db, snapshotDbKey(hs.ChainType, number), pairs[i]); err != nil {
+				return nil, nil, err
+			}
+		}
+		inserted = append(inserted, &params.NumberHash{Number: number, Hash: hash})
+	}
+	lastNumber, lastHash := last.Header.Number.Uint64(), last.Header.Hash()
+	for n := lastNumber + 1; n <= hs.CurNumber; n++ {
+		hs.deleteEntry(db, n)
+	}
+	if err := hs.storeSnapshot(db, headSnapshotDbKey(hs.ChainType), pairs[len(pairs)-1]); err != nil {
+		return nil, nil, err
+	}
+
+	var reorg *params.Reorg
+	if ancestor.Number < hs.CurNumber {
+		reorg = &params.Reorg{
+			OldHead:  params.NumberHash{Number: hs.CurNumber, Hash: hs.CurHash},
+			NewHead:  params.NumberHash{Number: lastNumber, Hash: lastHash},
+			Ancestor: ancestor,
+			Depth:    hs.CurNumber - ancestor.Number,
+		}
+	}
+	hs.CurNumber, hs.CurHash = lastNumber, lastHash
+	if err := hs.Store(db); err != nil {
+		return nil, nil, err
+	}
+
+	context := []interface{}{
+		"count", len(inserted), "number", hs.CurNumber, "hash", hs.CurHash,
+		"elapsed", common.PrettyDuration(time.Since(start)),
+	}
+	if reorg != nil {
+		context = append(context, "reorgDepth", reorg.Depth, "ancestor", reorg.Ancestor.Number)
+	}
+	log.Info("stored new bsc block headers", context...)
+	return inserted, reorg, nil
+}
+
+func (hs *HeaderStore) GetCurrentNumberAndHash(db types.StateDB) (uint64, common.Hash, error) {
+	if err := hs.Load(db); err != nil {
+		return 0, common.Hash{}, err
+	}
+	return hs.CurNumber, hs.CurHash, nil
+}
+
+func (hs *HeaderStore) GetHashByNumber(db types.StateDB, number uint64) (common.Hash, error) {
+	if err := hs.Load(db); err != nil {
+		return common.Hash{}, err
+	}
+	if number > hs.CurNumber {
+		return common.Hash{}, nil
+	}
+	header := hs.GetHeaderByNumber(db, number)
+	if header == nil {
+		return common.Hash{}, nil
+	}
+	return header.Hash(), nil
+}
+
+// Checkpoint returns the latest stored epoch header at or below number. Its ancestor is
+// the epoch header announcing the validators signing at it, which ResetHeaderStore
+// needs as well.
+func (hs *HeaderStore) Checkpoint(db types.StateDB, number uint64) (*chains.Checkpoint, error) {
+	if err := hs.Load(db); err != nil {
+		return nil, err
+	}
+	if number > hs.CurNumber {
+		number = hs.CurNumber
+	}
+	config := hs.config()
+	for n := number; ; n-- {
+		e := hs.loadEntry(db, n)
+		if e == nil {
+			return nil, errCheckpointPruned
+		}
+		pair := hs.loadSnapshot(db, snapshotDbKey(hs.ChainType, n), n)
+		if pair != nil && n%config.epochLength(e.Header.Time) == 0 {
+			prev := hs.GetHeaderByNumber(db, pair.Head.Checkpoint)
+			if prev == nil {
+				return nil, errCheckpointPruned
+			}
+			header, err := rlp.EncodeToBytes(e.Header)
+			if err != nil {
+				return nil, err
+			}
+			ancestors, err := rlp.EncodeToBytes([]*Header{prev})
+			if err != nil {
+				return nil, err
+			}
+			return &chains.Checkpoint{
+				ChainType: hs.ChainType,
+				Number:    n,
+				Hash:      e.Header.Hash(),
+				TD:        e.TD,
+				Header:    header,
+				Ancestors: ancestors,
+			}, nil
+		}
+		if n == 0 {
+			return nil, errCheckpointPruned
+		}
+	}
+}
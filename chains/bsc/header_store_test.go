@@ -0,0 +1,517 @@
+package bsc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/params"
+)
+
+func newTestDB() *state.StateDB {
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	return db
+}
+
+// newTestStore resets a store of the chain to an epoch header announcing next, while
+// prev signs at the checkpoint.
+func newTestStore(t *testing.T, chain chains.ChainType, prev, next *testValidators) (*state.StateDB, *Header) {
+	db := newTestDB()
+	checkpoint := newChainMaker(chain).checkpoint(prev, next)
+	assert.Nil(t, NewHeaderStore(chain).ResetHeaderStore(db, encodeHeaders(checkpoint), big.NewInt(0)))
+	return db, checkpoint[1]
+}
+
+func TestHeaderStore_ResetHeaderStore(t *testing.T) {
+	v3, v4 := newTestValidators(3), newTestValidators(4)
+	db, checkpoint := newTestStore(t, testChain, v3, v4)
+	hs := NewHeaderStore(testChain)
+
+	number, hash, err := hs.GetCurrentNumberAndHash(db)
+	assert.Nil(t, err)
+	assert.Equal(t, maxwellEpochLength, number)
+	assert.Equal(t, checkpoint.Hash(), hash)
+
+	// the announced validators do not sign at the checkpoint yet
+	pair, err := hs.snapshots(db, number)
+	assert.Nil(t, err)
+	assert.Equal(t, v3.addrs, pair.Head.Validators)
+	assert.Equal(t, uint64(0), pair.Head.Checkpoint)
+	assert.Equal(t, maxwellEpochLength, pair.Head.EpochLength)
+	assert.Equal(t, testVoteKeys[v3.addrs[0]].PublicKey().Marshal(), pair.Head.VoteAddresses[0][:])
+
+	m := newChainMaker(testChain)
+	headers := []*Header{m.checkpoint(v3, v3)[0], m.inturnChain(checkpoint, 1, v3, v3)[0]}
+	assert.NotNil(t, hs.ResetHeaderStore(db, encodeHeaders(headers), big.NewInt(0)), "non epoch header")
+	single, _ := rlp.EncodeToBytes(checkpoint)
+	assert.NotNil(t, hs.ResetHeaderStore(db, single, big.NewInt(0)), "missing previous epoch header")
+
+	// the previous epoch header must be the one right before the checkpoint
+	first := m.checkpoint(v3, v3)[0]
+	parent := &Header{Number: big.NewInt(int64(2*maxwellEpochLength - 1)), GasLimit: checkpoint.GasLimit, Time: checkpoint.Time + 3*maxwellEpochLength}
+	later := m.header(parent, v3.inturn(2*maxwellEpochLength), 2, v4, nil)
+	err = hs.ResetHeaderStore(db, encodeHeaders([]*Header{first, later}), big.NewInt(0))
+	assert.ErrorIs(t, err, errInvalidPreviousEpoch, "previous epoch header two epochs before")
+	err = hs.ResetHeaderStore(db, encodeHeaders([]*Header{later, checkpoint}), big.NewInt(0))
+	assert.ErrorIs(t, err, errInvalidPreviousEpoch, "previous epoch header after the checkpoint")
+	err = hs.ResetHeaderStore(db, encodeHeaders([]*Header{checkpoint, checkpoint}), big.NewInt(0))
+	assert.ErrorIs(t, err, errInvalidPreviousEpoch, "validators signing at their own epoch block")
+
+	// stores of other chains are not initialized
+	_, _, err = NewHeaderStore(testChain + 2).GetCurrentNumberAndHash(db)
+	assert.Equal(t, errStoreNotInitialized, err)
+}
+
+func TestHeaderStore_InsertHeaders(t *testing.T) {
+	v := newTestValidators(3)
+	db, checkpoint := newTestStore(t, testChain, v, v)
+	m := newChainMaker(testChain)
+	headers := m.inturnChain(checkpoint, 10, v, v)
+
+	assert.Nil(t, validateHeaders(db, testChain, headers))
+	nums, reorg, err := NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders(headers))
+	assert.Nil(t, err)
+	assert.Equal(t, len(headers), len(nums))
+	assert.Nil(t, reorg)
+
+	last := headers[len(headers)-1]
+	number, hash, err := NewHeaderStore(testChain).GetCurrentNumberAndHash(db)
+	assert.Nil(t, err)
+	assert.Equal(t, last.Number.Uint64(), number)
+	assert.Equal(t, last.Hash(), hash)
+
+	hash, err = NewHeaderStore(testChain).GetHashByNumber(db, headers[4].Number.Uint64())
+	assert.Nil(t, err)
+	assert.Equal(t, headers[4].Hash(), hash)
+
+	// headers must extend a stored header
+	unknown := &Header{Number: last.Number, GasLimit: last.GasLimit, Time: last.Time, Extra: []byte("unknown")}
+	_, _, err = NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders(m.inturnChain(unknown, 1, v, v)))
+	assert.Equal(t, errUnknownAncestor, err)
+}
+
+func TestHeaderStore_EpochSnapshots(t *testing.T) {
+	v := newTestValidators(3)
+	db, checkpoint := newTestStore(t, testChain, v, v)
+	headers := newChainMaker(testChain).inturnChain(checkpoint, 10, v, v)
+	_, _, err := NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders(headers))
+	assert.Nil(t, err)
+
+	// only the epoch block and the head keep their snapshot
+	hs := NewHeaderStore(testChain)
+	assert.Nil(t, hs.Load(db))
+	number := headers[4].Number.Uint64()
+	assert.Nil(t, hs.loadSnapshot(db, snapshotDbKey(testChain, number), number))
+	assert.NotNil(t, hs.loadSnapshot(db, snapshotDbKey(testChain, maxwellEpochLength), maxwellEpochLength))
+
+	// other snapshots are rebuilt from the epoch snapshot
+	pair, err := hs.snapshots(db, number)
+	assert.Nil(t, err)
+	assert.Equal(t, headers[4].Hash(), pair.Head.Hash)
+	assert.Equal(t, headers[3].Hash(), pair.Signers.Hash)
+	assert.Equal(t, []Recent{{Number: number, Signer: headers[4].Coinbase}}, pair.Head.Recents)
+}
+
+func TestHeaderStore_SnapshotReplay(t *testing.T) {
+	v := newTestValidators(3)
+	db, checkpoint := newTestStore(t, testChain, v, v)
+	headers := newChainMaker(testChain).inturnChain(checkpoint, snapshotInterval+10, v, v)
+	_, _, err := NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders(headers))
+	assert.Nil(t, err)
+
+	// a snapshot is stored every snapshotInterval blocks besides the epoch blocks
+	hs := NewHeaderStore(testChain)
+	assert.Nil(t, hs.Load(db))
+	number := maxwellEpochLength + snapshotInterval
+	assert.NotNil(t, hs.loadSnapshot(db, snapshotDbKey(testChain, number), number))
+	pair, err := hs.snapshots(db, number+5)
+	assert.Nil(t, err)
+	assert.Equal(t, headers[snapshotInterval+4].Hash(), pair.Head.Hash)
+
+	// and no more headers than the interval are replayed to rebuild one
+	db.SetPOWState(chains.BscHeaderStoreAddress, snapshotDbKey(testChain, number), nil)
+	_, err = hs.snapshots(db, number+5)
+	assert.Equal(t, errSnapshotReplay, err)
+}
+
+func TestHeaderStore_ValidatorSetChange(t *testing.T) {
+	v3, v4 := newTestValidators(3), newTestValidators(4)
+	db, checkpoint := newTestStore(t, testChain, v3, v4)
+	m := newChainMaker(testChain)
+	stranger := v4.addrs[0]
+	for _, addr := range v4.addrs {
+		if v3.inturn(maxwellEpochLength+1) != addr && !contains(v3.addrs, addr) {
+			stranger = addr
+		}
+	}
+
+	// the new validators sign only minerHistoryCheckLen blocks after the epoch block
+	early := m.header(checkpoint, stranger, 1, nil, nil)
+	assert.Equal(t, errUnauthorizedValidator, validateHeaders(db, testChain, []*Header{early}))
+
+	checkLen := uint64(len(v3.addrs)/2+1)*uint64(v3.turnLength) - 1
+	headers := m.inturnChain(checkpoint, int(checkLen), v3, v4)
+	_, _, err := NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders(headers))
+	assert.Nil(t, err)
+
+	last := headers[len(headers)-1]
+	pair, err := NewHeaderStore(testChain).snapshots(db, last.Number.Uint64())
+	assert.Nil(t, err)
+	assert.Equal(t, v4.addrs, pair.Head.Validators)
+	assert.Equal(t, v3.addrs, pair.Signers.Validators)
+	assert.Equal(t, maxwellEpochLength, pair.Head.Checkpoint)
+	// the miner history is cleared on the switch
+	assert.Empty(t, pair.Head.Recents)
+
+	next := m.inturnChain(last, 5, v4, v4)
+	assert.Nil(t, validateHeaders(db, testChain, next))
+	_, _, err = NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders(next))
+	assert.Nil(t, err)
+}
+
+func TestHeaderStore_TurnLength(t *testing.T) {
+	v, turns := newTestValidators(3), newTestValidators(3)
+	turns.turnLength = 2
+	db, checkpoint := newTestStore(t, testChain, v, turns)
+	m := newChainMaker(testChain)
+
+	checkLen := uint64(len(v.addrs)/2+1)*uint64(v.turnLength) - 1
+	headers := m.inturnChain(checkpoint, int(checkLen), v, turns)
+	headers = append(headers, m.inturnChain(headers[len(headers)-1], 6, turns, turns)...)
+	assert.Nil(t, validateHeaders(db, testChain, headers))
+	_, _, err := NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders(headers))
+	assert.Nil(t, err)
+
+	// a validator signs turnLength blocks in a row, but not more
+	last := headers[len(headers)-1]
+	if last.Number.Uint64()%2 == 0 {
+		last = headers[len(headers)-2]
+	}
+	assert.Equal(t, headers[len(headers)-3].Coinbase, headers[len(headers)-4].Coinbase)
+	third := m.header(last, last.Coinbase, 1, nil, nil)
+	assert.Equal(t, errRecentlySigned, validateHeaders(db, testChain, []*Header{third}))
+}
+
+func TestHeaderStore_Reorg(t *testing.T) {
+	v := newTestValidators(3)
+	db, checkpoint := newTestStore(t, testChain, v, v)
+	m := newChainMaker(testChain)
+
+	// an out-of-turn chain has a lower total difficulty than the in-turn one
+	var outTurn []*Header
+	parent := checkpoint
+	for i := 0; i < 3; i++ {
+		signer := v.addrs[(parent.Number.Uint64()+2)%3]
+		parent = m.header(parent, signer, 1, nil, nil)
+		outTurn = append(outTurn, parent)
+	}
+	_, _, err := NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders(outTurn))
+	assert.Nil(t, err)
+
+	inTurn := m.inturnChain(checkpoint, 2, v, v)
+	nums, reorg, err := NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders(inTurn))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(nums))
+	assert.Equal(t, &params.Reorg{
+		OldHead:  params.NumberHash{Number: outTurn[2].Number.Uint64(), Hash: outTurn[2].Hash()},
+		NewHead:  params.NumberHash{Number: inTurn[1].Number.Uint64(), Hash: inTurn[1].Hash()},
+		Ancestor: params.NumberHash{Number: checkpoint.Number.Uint64(), Hash: checkpoint.Hash()},
+		Depth:    3,
+	}, reorg)
+
+	number, hash, err := NewHeaderStore(testChain).GetCurrentNumberAndHash(db)
+	assert.Nil(t, err)
+	assert.Equal(t, inTurn[1].Number.Uint64(), number)
+	assert.Equal(t, inTurn[1].Hash(), hash)
+	hash, err = NewHeaderStore(testChain).GetHashByNumber(db, outTurn[2].Number.Uint64())
+	assert.Nil(t, err)
+	assert.Equal(t, common.Hash{}, hash)
+
+	// the lighter chain is ignored
+	nums, _, err = NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders(outTurn[:1]))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(nums))
+}
+
+func TestHeaderStore_Checkpoint(t *testing.T) {
+	v := newTestValidators(3)
+	db, checkpoint := newTestStore(t, legacyChain, v, v)
+	headers := newChainMaker(legacyChain).inturnChain(checkpoint, int(defaultEpochLength)+10, v, v)
+	_, _, err := NewHeaderStore(legacyChain).InsertHeaders(db, encodeHeaders(headers))
+	assert.Nil(t, err)
+
+	// the validators signing at the reset header are announced before it
+	_, err = NewHeaderStore(legacyChain).Checkpoint(db, defaultEpochLength+5)
+	assert.Equal(t, errCheckpointPruned, err)
+
+	// the checkpoint is rounded down to the last epoch header
+	c, err := NewHeaderStore(legacyChain).Checkpoint(db, 2*defaultEpochLength+5)
+	assert.Nil(t, err)
+	assert.Equal(t, 2*defaultEpochLength, c.Number)
+	assert.Equal(t, headers[defaultEpochLength-1].Hash(), c.Hash)
+	assert.Equal(t, c.Hash, crypto.Keccak256Hash(c.Header))
+
+	// and can reset a new store that follows the chain
+	input, err := c.ResetInput()
+	assert.Nil(t, err)
+	fresh := newTestDB()
+	assert.Nil(t, NewHeaderStore(legacyChain).ResetHeaderStore(fresh, input, c.TD))
+	_, hash, err := NewHeaderStore(legacyChain).GetCurrentNumberAndHash(fresh)
+	assert.Nil(t, err)
+	assert.Equal(t, c.Hash, hash)
+	_, _, err = NewHeaderStore(legacyChain).InsertHeaders(fresh, encodeHeaders(headers[defaultEpochLength:]))
+	assert.Nil(t, err)
+}
+
+func TestHeaderStore_Attestation(t *testing.T) {
+	v := newTestValidators(3)
+	m := newChainMaker(testChain)
+
+	tests := []struct {
+		name        string
+		attestation func(checkpoint, parent *Header) *VoteAttestation
+		wantErr     error
+	}{
+		{
+			name: "justified by 2/3",
+			attestation: func(checkpoint, parent *Header) *VoteAttestation {
+				return v.attest(checkpoint, parent, 0, 2)
+			},
+		},
+		{
+			name: "less than 2/3 voted",
+			attestation: func(checkpoint, parent *Header) *VoteAttestation {
+				return v.attest(checkpoint, parent, 1)
+			},
+			wantErr: errAttestationQuorum,
+		},
+		{
+			name: "not the parent",
+			attestation: func(checkpoint, parent *Header) *VoteAttestation {
+				return v.attest(checkpoint, checkpoint, 0, 1, 2)
+			},
+			wantErr: errAttestationTarget,
+		},
+		{
+			name: "not from the justified block",
+			attestation: func(checkpoint, parent *Header) *VoteAttestation {
+				return v.attest(parent, parent, 0, 1, 2)
+			},
+			wantErr: errAttestationSource,
+		},
+		{
+			name: "bad signature",
+			attestation: func(checkpoint, parent *Header) *VoteAttestation {
+				attestation := v.attest(checkpoint, parent, 0, 1)
+				attestation.VoteAddressSet = 1<<0 | 1<<2
+				return attestation
+			},
+			wantErr: errInvalidAttestation,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, checkpoint := newTestStore(t, testChain, v, v)
+			// the first attestation after the reset justifies the checkpoint
+			second := m.header(checkpoint, v.inturn(checkpoint.Number.Uint64()+1), 2, nil, v.attest(checkpoint, checkpoint, 0, 1, 2))
+			_, _, err := NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders([]*Header{second}))
+			assert.Nil(t, err)
+
+			header := m.header(second, v.inturn(second.Number.Uint64()+1), 2, nil, tt.attestation(checkpoint, second))
+			err = validateHeaders(db, testChain, []*Header{header})
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+			_, _, err = NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders([]*Header{header}))
+			assert.Nil(t, err)
+			pair, err := NewHeaderStore(testChain).snapshots(db, header.Number.Uint64())
+			assert.Nil(t, err)
+			assert.Equal(t, &VoteData{
+				SourceNumber: checkpoint.Number.Uint64(),
+				SourceHash:   checkpoint.Hash(),
+				TargetNumber: second.Number.Uint64(),
+				TargetHash:   second.Hash(),
+			}, pair.Head.Attestation)
+		})
+	}
+}
+
+func TestValidate_ValidateHeaderChain(t *testing.T) {
+	v := newTestValidators(3)
+	outsider := newTestValidators(4)
+	stranger := outsider.addrs[0]
+	for _, addr := range outsider.addrs {
+		if !contains(v.addrs, addr) {
+			stranger = addr
+		}
+	}
+
+	tests := []struct {
+		name    string
+		chain   chains.ChainType
+		headers func(m *chainMaker, parent *Header) []*Header
+		wantErr error
+	}{
+		{
+			name: "out-of-turn with in-turn difficulty",
+			headers: func(m *chainMaker, parent *Header) []*Header {
+				signer := v.addrs[(parent.Number.Uint64()+2)%3]
+				return []*Header{m.header(parent, signer, 2, nil, nil)}
+			},
+			wantErr: errWrongDifficulty,
+		},
+		{
+			name: "recently signed",
+			headers: func(m *chainMaker, parent *Header) []*Header {
+				// the history is cleared when the validator set switches after the first block
+				chain := m.inturnChain(parent, 2, v, v)
+				return append(chain, m.header(chain[1], chain[1].Coinbase, 1, nil, nil))
+			},
+			wantErr: errRecentlySigned,
+		},
+		{
+			name: "unauthorized validator",
+			headers: func(m *chainMaker, parent *Header) []*Header {
+				return []*Header{m.header(parent, stranger, 1, nil, nil)}
+			},
+			wantErr: errUnauthorizedValidator,
+		},
+		{
+			name: "coinbase mismatch",
+			headers: func(m *chainMaker, parent *Header) []*Header {
+				header := m.inturnChain(parent, 1, v, v)[0]
+				header.Coinbase = v.addrs[(parent.Number.Uint64()+2)%3]
+				return []*Header{header}
+			},
+			wantErr: errCoinbaseMismatch,
+		},
+		{
+			name:  "validators in non epoch block",
+			chain: legacyChain,
+			headers: func(m *chainMaker, parent *Header) []*Header {
+				header := m.inturnChain(parent, 1, v, v)[0]
+				header.Extra = append(append(header.Extra[:extraVanity:extraVanity], v.addrs[0].Bytes()...), make([]byte, extraSeal)...)
+				m.seal(header)
+				return []*Header{header}
+			},
+			wantErr: errExtraValidators,
+		},
+		{
+			name: "garbage instead of an attestation",
+			headers: func(m *chainMaker, parent *Header) []*Header {
+				header := m.inturnChain(parent, 1, v, v)[0]
+				header.Extra = append(append(header.Extra[:extraVanity:extraVanity], v.addrs[0].Bytes()...), make([]byte, extraSeal)...)
+				m.seal(header)
+				return []*Header{header}
+			},
+			wantErr: errInvalidAttestation,
+		},
+		{
+			name:  "mix digest before Lorentz",
+			chain: legacyChain,
+			headers: func(m *chainMaker, parent *Header) []*Header {
+				header := m.inturnChain(parent, 1, v, v)[0]
+				header.MixDigest = common.BigToHash(big.NewInt(500))
+				m.seal(header)
+				return []*Header{header}
+			},
+			wantErr: errInvalidMixDigest,
+		},
+		{
+			name: "milliseconds out of range",
+			headers: func(m *chainMaker, parent *Header) []*Header {
+				header := m.inturnChain(parent, 1, v, v)[0]
+				header.MixDigest = common.BigToHash(big.NewInt(1000))
+				m.seal(header)
+				return []*Header{header}
+			},
+			wantErr: errInvalidMixDigest,
+		},
+		{
+			name: "invalid difficulty",
+			headers: func(m *chainMaker, parent *Header) []*Header {
+				signer := v.inturn(parent.Number.Uint64() + 1)
+				return []*Header{m.header(parent, signer, 3, nil, nil)}
+			},
+			wantErr: errInvalidDifficulty,
+		},
+		{
+			name: "invalid seal",
+			headers: func(m *chainMaker, parent *Header) []*Header {
+				header := m.inturnChain(parent, 1, v, v)[0]
+				header.Time++
+				return []*Header{header}
+			},
+			wantErr: errCoinbaseMismatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := tt.chain
+			if chain == 0 {
+				chain = testChain
+			}
+			db, checkpoint := newTestStore(t, chain, v, v)
+			assert.ErrorIs(t, validateHeaders(db, chain, tt.headers(newChainMaker(chain), checkpoint)), tt.wantErr)
+		})
+	}
+}
+
+func TestSealHash(t *testing.T) {
+	v := newTestValidators(1)
+	checkpoint := newChainMaker(legacyChain).checkpoint(v, v)[1]
+	chainID := big.NewInt(int64(legacyChain))
+	legacy := SealHash(checkpoint, chainID)
+
+	// the fork fields are sealed once the parent beacon root is set
+	header := *checkpoint
+	header.BaseFee = new(big.Int)
+	assert.Equal(t, legacy, SealHash(&header, chainID))
+	header.ParentBeaconRoot = &common.Hash{}
+	bohr := SealHash(&header, chainID)
+	assert.NotEqual(t, legacy, bohr)
+	header.RequestsHash = &common.Hash{}
+	assert.NotEqual(t, bohr, SealHash(&header, chainID))
+
+	// the header with the fork fields round trips
+	var zero uint64
+	header.WithdrawalsHash, header.BlobGasUsed, header.ExcessBlobGas = &common.Hash{}, &zero, &zero
+	data, err := rlp.EncodeToBytes(&header)
+	assert.Nil(t, err)
+	var decoded Header
+	assert.Nil(t, rlp.DecodeBytes(data, &decoded))
+	assert.Equal(t, header.Hash(), decoded.Hash())
+}
+
+func TestSnapshot_EpochLength(t *testing.T) {
+	lorentz := uint64(1_000)
+	config := &Config{LorentzTime: &lorentz}
+	snap := &Snapshot{EpochLength: defaultEpochLength}
+
+	// the new epoch length starts at its first multiple after the fork
+	assert.Equal(t, defaultEpochLength, snap.epochLengthAt(&Header{Number: big.NewInt(400), Time: lorentz - 1}, config))
+	assert.Equal(t, defaultEpochLength, snap.epochLengthAt(&Header{Number: big.NewInt(600), Time: lorentz}, config))
+	assert.Equal(t, lorentzEpochLength, snap.epochLengthAt(&Header{Number: big.NewInt(1000), Time: lorentz}, config))
+}
+
+func validateHeaders(db *state.StateDB, chain chains.ChainType, headers []*Header) error {
+	_, err := new(Validate).ValidateHeaderChain(db, encodeHeaders(headers), chain)
+	return err
+}
+
+func contains(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package bsc
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	bls "github.com/mapprotocol/atlas/chains/eth2/bls12381"
+	blscommon "github.com/mapprotocol/atlas/chains/eth2/bls12381/common"
+)
+
+const (
+	testChain   = chains.ChainType(714) // every Parlia fork is active
+	legacyChain = chains.ChainType(715) // no Parlia fork is active
+)
+
+func init() {
+	configs[legacyChain] = &Config{}
+}
+
+// Validator keys the header fixtures are signed with.
+var testKeys = []string{
+	"b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291",
+	"8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a",
+	"49a7b37aa6f6645917e7b807e9d1c00d4fa71f18343b0d4122a4d2df64dd6fee",
+	"289c2857d4598e37fb9647507e47a309d6133539bf21a8b9cb6df88fd5232032",
+}
+
+var (
+	testSigners  = make(map[common.Address]*ecdsa.PrivateKey)
+	testVoteKeys = make(map[common.Address]bls.SecretKey)
+)
+
+func init() {
+	for _, hex := range testKeys {
+		key, _ := crypto.HexToECDSA(hex)
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		testSigners[addr] = key
+		testVoteKeys[addr], _ = bls.RandKey()
+	}
+}
+
+type testValidators struct {
+	addrs      []common.Address // sorted like the Parlia validator set
+	turnLength uint8
+}
+
+func newTestValidators(n int) *testValidators {
+	v := &testValidators{turnLength: 1}
+	for _, hex := range testKeys[:n] {
+		key, _ := crypto.HexToECDSA(hex)
+		v.addrs = append(v.addrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	sort.Sort(validatorsAscending{v.addrs, make([]VoteAddress, n)})
+	return v
+}
+
+// inturn returns the validator expected to sign the block with the given number.
+func (v *testValidators) inturn(number uint64) common.Address {
+	return v.addrs[number/uint64(v.turnLength)%uint64(len(v.addrs))]
+}
+
+// attest returns the attestation of target voted by the validators with the given
+// indexes, justified from source.
+func (v *testValidators) attest(source, target *Header, voters ...int) *VoteAttestation {
+	attestation := &VoteAttestation{Data: &VoteData{
+		SourceNumber: source.Number.Uint64(),
+		SourceHash:   source.Hash(),
+		TargetNumber: target.Number.Uint64(),
+		TargetHash:   target.Hash(),
+	}}
+	hash := attestation.Data.Hash()
+	sigs := make([]blscommon.Signature, 0, len(voters))
+	for _, i := range voters {
+		attestation.VoteAddressSet |= 1 << uint(i)
+		sigs = append(sigs, testVoteKeys[v.addrs[i]].Sign(hash[:]))
+	}
+	copy(attestation.AggSignature[:], bls.AggregateSignatures(sigs).Marshal())
+	return attestation
+}
+
+// chainMaker creates the headers of a test chain following its Parlia config.
+type chainMaker struct {
+	chain  chains.ChainType
+	config *Config
+}
+
+func newChainMaker(chain chains.ChainType) *chainMaker {
+	return &chainMaker{chain: chain, config: ConfigOf(chain)}
+}
+
+// header creates a child of parent sealed by signer. Epoch blocks announce the validators
+// of next in their extra-data, the attestation of the parent is added when given.
+func (m *chainMaker) header(parent *Header, signer common.Address, difficulty int64, next *testValidators, attestation *VoteAttestation) *Header {
+	number := new(big.Int).Add(parent.Number, common.Big1)
+	header := &Header{
+		ParentHash:  parent.Hash(),
+		UncleHash:   ethtypes.EmptyUncleHash,
+		Coinbase:    signer,
+		ReceiptHash: ethtypes.EmptyRootHash,
+		Difficulty:  big.NewInt(difficulty),
+		Number:      number,
+		GasLimit:    parent.GasLimit,
+		Time:        parent.Time + 3,
+	}
+	if m.config.IsBohr(header.Time) {
+		var zero uint64
+		header.BaseFee = new(big.Int)
+		header.WithdrawalsHash = &ethtypes.EmptyRootHash
+		header.BlobGasUsed, header.ExcessBlobGas = &zero, &zero
+		header.ParentBeaconRoot = &common.Hash{}
+		header.RequestsHash = &ethtypes.EmptyRootHash
+	}
+	header.Extra = m.extra(header, next, attestation)
+	m.seal(header)
+	return header
+}
+
+func (m *chainMaker) extra(header *Header, next *testValidators, attestation *VoteAttestation) []byte {
+	extra := make([]byte, extraVanity)
+	if header.Number.Uint64()%m.config.epochLength(header.Time) == 0 && next != nil {
+		if m.config.IsLuban(header.Number) {
+			extra = append(extra, byte(len(next.addrs)))
+		}
+		for _, addr := range next.addrs {
+			extra = append(extra, addr.Bytes()...)
+			if m.config.IsLuban(header.Number) {
+				extra = append(extra, testVoteKeys[addr].PublicKey().Marshal()...)
+			}
+		}
+		if m.config.IsBohr(header.Time) {
+			extra = append(extra, next.turnLength)
+		}
+	}
+	if attestation != nil {
+		data, _ := rlp.EncodeToBytes(attestation)
+		extra = append(extra, data...)
+	}
+	return append(extra, make([]byte, extraSeal)...)
+}
+
+func (m *chainMaker) seal(header *Header) {
+	sig, _ := crypto.Sign(SealHash(header, big.NewInt(int64(m.chain))).Bytes(), testSigners[header.Coinbase])
+	copy(header.Extra[len(header.Extra)-extraSeal:], sig)
+}
+
+// checkpoint creates the headers the store is reset to: the epoch header announcing
+// prev and the next epoch header announcing next.
+func (m *chainMaker) checkpoint(prev, next *testValidators) []*Header {
+	epoch := m.config.epochLength(0)
+	first := &Header{Number: big.NewInt(0), GasLimit: 30_000_000, Time: 1_600_000_000, Coinbase: prev.addrs[0]}
+	first.Extra = m.extra(first, prev, nil)
+	m.seal(first)
+
+	parent := &Header{Number: big.NewInt(int64(epoch - 1)), GasLimit: 30_000_000, Time: first.Time + 3*(epoch-1)}
+	signer := prev.inturn(epoch)
+	return []*Header{first, m.header(parent, signer, 2, next, nil)}
+}
+
+// inturnChain creates n headers on top of parent, each signed in turn by v. Epoch
+// blocks announce next as the new validator set.
+func (m *chainMaker) inturnChain(parent *Header, n int, v *testValidators, next *testValidators) []*Header {
+	headers := make([]*Header, 0, n)
+	for i := 0; i < n; i++ {
+		signer := v.inturn(parent.Number.Uint64() + 1)
+		parent = m.header(parent, signer, 2, next, nil)
+		headers = append(headers, parent)
+	}
+	return headers
+}
+
+func encodeHeaders(headers []*Header) []byte {
+	data, _ := rlp.EncodeToBytes(headers)
+	return data
+}
@@ -0,0 +1,325 @@
+package bsc
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	extraVanity          = 32 // fixed number of extra-data prefix bytes reserved for signer vanity
+	extraSeal            = 65 // fixed number of extra-data suffix bytes reserved for signer seal
+	validatorBytesLength = common.AddressLength
+
+	// Since Luban every validator of an epoch block is announced with its BLS vote address
+	// after a one byte validator count.
+	validatorNumberSize       = 1
+	validatorBytesLengthLuban = common.AddressLength + voteAddressLength
+	turnLengthSize            = 1
+)
+
+var (
+	diffInTurn = big.NewInt(2) // block difficulty for in-turn signatures
+	diffNoTurn = big.NewInt(1) // block difficulty for out-of-turn signatures
+)
+
+var (
+	errMissingVanity           = errors.New("extra-data 32 byte vanity prefix missing")
+	errMissingSignature        = errors.New("extra-data 65 byte signature suffix missing")
+	errExtraValidators         = errors.New("non-sprint-end block contains extra validator list")
+	errInvalidSpanValidators   = errors.New("invalid validator list on sprint end block")
+	errInvalidTurnLength       = errors.New("invalid turn length")
+	errInvalidMixDigest        = errors.New("invalid mix digest")
+	errInvalidUncleHash        = errors.New("non empty uncle hash")
+	errInvalidDifficulty       = errors.New("invalid difficulty")
+	errWrongDifficulty         = errors.New("wrong difficulty")
+	errUnauthorizedValidator   = errors.New("unauthorized validator")
+	errRecentlySigned          = errors.New("recently signed")
+	errCoinbaseMismatch        = errors.New("coinbase does not match the signer")
+	errUnknownAncestor         = errors.New("unknown ancestor")
+	errOlderBlockTime          = errors.New("timestamp older than parent")
+	errInvalidNumber           = errors.New("invalid block number")
+	errStoreNotInitialized     = errors.New("please initialize bsc header store")
+	errCheckpointWithoutSigner = errors.New("checkpoint header contains no validators")
+	errCheckpointPruned        = errors.New("checkpoint header has been overwritten")
+	errInvalidPreviousEpoch    = errors.New("invalid previous epoch header")
+	errSnapshotReplay          = errors.New("too many headers to replay since the last stored snapshot")
+)
+
+// Recent is a block recently signed by a validator.
+type Recent struct {
+	Number uint64
+	Signer common.Address
+}
+
+// Snapshot is the state of the Parlia validator set at a given block.
+type Snapshot struct {
+	Number        uint64           // block number where the snapshot was created
+	Hash          common.Hash      // block hash where the snapshot was created
+	EpochLength   uint64           // number of blocks between validator set checkpoints
+	TurnLength    uint8            // number of consecutive blocks an in-turn validator signs
+	Validators    []common.Address // validator set at this moment, sorted ascending
+	VoteAddresses []VoteAddress    // BLS vote addresses of the validators, zero before Luban
+	Checkpoint    uint64           // epoch block the validator set was announced in
+	Recents       []Recent         // recent signers for spam protections, oldest first
+	Attestation   *VoteData        `rlp:"nil"` // latest justified block, nil until the first attestation
+}
+
+func newSnapshot(number uint64, hash common.Hash, epochLength uint64, checkpoint *Header, extra *extraData) *Snapshot {
+	snap := &Snapshot{
+		Number:      number,
+		Hash:        hash,
+		EpochLength: epochLength,
+		TurnLength:  defaultTurnLength,
+		Checkpoint:  checkpoint.Number.Uint64(),
+	}
+	snap.setValidators(extra)
+	return snap
+}
+
+// setValidators installs the validator set announced in an epoch block.
+func (s *Snapshot) setValidators(extra *extraData) {
+	s.Validators = make([]common.Address, len(extra.Validators))
+	s.VoteAddresses = make([]VoteAddress, len(extra.Validators))
+	copy(s.Validators, extra.Validators)
+	copy(s.VoteAddresses, extra.VoteAddresses)
+	sort.Sort(validatorsAscending{s.Validators, s.VoteAddresses})
+	if extra.TurnLength != 0 {
+		s.TurnLength = extra.TurnLength
+	}
+}
+
+func (s *Snapshot) copy() *Snapshot {
+	cpy := *s
+	cpy.Validators = make([]common.Address, len(s.Validators))
+	cpy.VoteAddresses = make([]VoteAddress, len(s.VoteAddresses))
+	cpy.Recents = make([]Recent, len(s.Recents))
+	copy(cpy.Validators, s.Validators)
+	copy(cpy.VoteAddresses, s.VoteAddresses)
+	copy(cpy.Recents, s.Recents)
+	if s.Attestation != nil {
+		attestation := *s.Attestation
+		cpy.Attestation = &attestation
+	}
+	return &cpy
+}
+
+// minerHistoryCheckLen is the number of recent blocks in which a validator may sign at
+// most TurnLength blocks. The validator set of an epoch block takes effect that many
+// blocks after it.
+func (s *Snapshot) minerHistoryCheckLen() uint64 {
+	return uint64(len(s.Validators)/2+1)*uint64(s.TurnLength) - 1
+}
+
+// signRecently returns whether the validator has already signed its share of the blocks
+// in the recent history, so it may not sign the next block.
+func (s *Snapshot) signRecently(validator common.Address) bool {
+	var bound uint64 // excluded
+	if checkLen := s.minerHistoryCheckLen(); s.Number > checkLen {
+		bound = s.Number - checkLen
+	}
+	times := 0
+	for _, recent := range s.Recents {
+		if recent.Number > bound && recent.Signer == validator {
+			times++
+		}
+	}
+	return times >= int(s.TurnLength)
+}
+
+func (s *Snapshot) isValidator(addr common.Address) bool {
+	for _, v := range s.Validators {
+		if v == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// inturn returns whether the validator is the one expected to sign the given block.
+func (s *Snapshot) inturn(number uint64, validator common.Address) bool {
+	return s.Validators[number/uint64(s.TurnLength)%uint64(len(s.Validators))] == validator
+}
+
+// epochLengthAt returns the epoch length the header is verified with. After a fork
+// changes it, the new length is used from its first multiple on.
+func (s *Snapshot) epochLengthAt(header *Header, config *Config) uint64 {
+	if length := config.epochLength(header.Time); length != s.EpochLength && header.Number.Uint64()%length == 0 {
+		return length
+	}
+	return s.EpochLength
+}
+
+// apply moves the snapshot forward by the header sealed by signer. checkpoint returns
+// the header with the given number, it is used to read the validator set that takes
+// effect minerHistoryCheckLen blocks after an epoch block.
+func (s *Snapshot) apply(header *Header, signer common.Address, config *Config, checkpoint func(number uint64) *Header) (*Snapshot, error) {
+	number := header.Number.Uint64()
+	if number != s.Number+1 || header.ParentHash != s.Hash {
+		return nil, errUnknownAncestor
+	}
+	if !s.isValidator(signer) {
+		return nil, errUnauthorizedValidator
+	}
+	if s.signRecently(signer) {
+		return nil, errRecentlySigned
+	}
+	inturn := s.inturn(number, signer)
+	if inturn && header.Difficulty.Cmp(diffInTurn) != 0 {
+		return nil, errWrongDifficulty
+	}
+	if !inturn && header.Difficulty.Cmp(diffNoTurn) != 0 {
+		return nil, errWrongDifficulty
+	}
+
+	snap := s.copy()
+	snap.Number, snap.Hash = number, header.Hash()
+	snap.EpochLength = s.epochLengthAt(header, config)
+	extra, err := parseExtra(header, config, number%snap.EpochLength == 0)
+	if err != nil {
+		return nil, err
+	}
+	snap.Recents = append(snap.Recents, Recent{Number: number, Signer: signer})
+	snap.updateAttestation(header, extra.Attestation)
+
+	// the validator set of an epoch block is used once enough of the old set has signed
+	if checkLen := s.minerHistoryCheckLen(); number > 0 && number%snap.EpochLength == checkLen {
+		cp := checkpoint(number - checkLen)
+		if cp == nil {
+			return nil, errUnknownAncestor
+		}
+		cpExtra, err := parseExtra(cp, config, true)
+		if err != nil {
+			return nil, err
+		}
+		snap.setValidators(cpExtra)
+		snap.Checkpoint = cp.Number.Uint64()
+		// BEP-404: the miner history is cleared when the validator set switches
+		if config.IsBohr(header.Time) {
+			snap.Recents = nil
+		}
+	}
+
+	// drop the signers that are out of the history
+	if checkLen := snap.minerHistoryCheckLen(); number > checkLen {
+		recents := snap.Recents[:0]
+		for _, recent := range snap.Recents {
+			if recent.Number > number-checkLen {
+				recents = append(recents, recent)
+			}
+		}
+		snap.Recents = recents
+	}
+	return snap, nil
+}
+
+// updateAttestation moves the latest justified block forward if the header carries an
+// attestation of its parent.
+func (s *Snapshot) updateAttestation(header *Header, attestation *VoteAttestation) {
+	if attestation == nil {
+		return
+	}
+	data := attestation.Data
+	if data.TargetNumber+1 != header.Number.Uint64() || data.TargetHash != header.ParentHash {
+		return
+	}
+	if s.Attestation != nil && data.SourceNumber+1 != data.TargetNumber {
+		s.Attestation.TargetNumber = data.TargetNumber
+		s.Attestation.TargetHash = data.TargetHash
+		return
+	}
+	attested := *data
+	s.Attestation = &attested
+}
+
+// extraData is the content of the extra-data of a header between vanity and seal.
+type extraData struct {
+	Validators    []common.Address
+	VoteAddresses []VoteAddress
+	TurnLength    uint8 // 0 unless announced
+	Attestation   *VoteAttestation
+}
+
+// parseExtra parses the extra-data of a header. Epoch blocks announce the validator set,
+// since Luban together with the vote addresses and since Bohr with the turn length.
+// Since Luban any block may carry the vote attestation of its parent.
+func parseExtra(header *Header, config *Config, epoch bool) (*extraData, error) {
+	if len(header.Extra) < extraVanity {
+		return nil, errMissingVanity
+	}
+	if len(header.Extra) < extraVanity+extraSeal {
+		return nil, errMissingSignature
+	}
+	data := header.Extra[extraVanity : len(header.Extra)-extraSeal]
+	extra := new(extraData)
+
+	if !config.IsLuban(header.Number) {
+		if !epoch {
+			if len(data) != 0 {
+				return nil, errExtraValidators
+			}
+			return extra, nil
+		}
+		if len(data) == 0 || len(data)%validatorBytesLength != 0 {
+			return nil, errInvalidSpanValidators
+		}
+		extra.Validators = make([]common.Address, len(data)/validatorBytesLength)
+		extra.VoteAddresses = make([]VoteAddress, len(extra.Validators))
+		for i := range extra.Validators {
+			copy(extra.Validators[i][:], data[i*validatorBytesLength:])
+		}
+		return extra, nil
+	}
+
+	if epoch {
+		if len(data) < validatorNumberSize || data[0] == 0 {
+			return nil, errInvalidSpanValidators
+		}
+		n := int(data[0])
+		end := validatorNumberSize + n*validatorBytesLengthLuban
+		if len(data) < end {
+			return nil, errInvalidSpanValidators
+		}
+		extra.Validators = make([]common.Address, n)
+		extra.VoteAddresses = make([]VoteAddress, n)
+		for i := 0; i < n; i++ {
+			offset := validatorNumberSize + i*validatorBytesLengthLuban
+			copy(extra.Validators[i][:], data[offset:])
+			copy(extra.VoteAddresses[i][:], data[offset+common.AddressLength:])
+		}
+		data = data[end:]
+
+		if config.IsBohr(header.Time) {
+			if len(data) < turnLengthSize || data[0] == 0 {
+				return nil, errInvalidTurnLength
+			}
+			extra.TurnLength, data = data[0], data[turnLengthSize:]
+		}
+	}
+	if len(data) > 0 {
+		attestation, err := decodeAttestation(data)
+		if err != nil {
+			return nil, err
+		}
+		extra.Attestation = attestation
+	}
+	return extra, nil
+}
+
+// validatorsAscending sorts validators together with their vote addresses.
+type validatorsAscending struct {
+	validators    []common.Address
+	voteAddresses []VoteAddress
+}
+
+func (v validatorsAscending) Len() int { return len(v.validators) }
+func (v validatorsAscending) Less(i, j int) bool {
+	return bytes.Compare(v.validators[i][:], v.validators[j][:]) < 0
+}
+func (v validatorsAscending) Swap(i, j int) {
+	v.validators[i], v.validators[j] = v.validators[j], v.validators[i]
+	v.voteAddresses[i], v.voteAddresses[j] = v.voteAddresses[j], v.voteAddresses[i]
+}
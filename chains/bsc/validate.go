@@ -0,0 +1,114 @@
+package bsc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+const (
+	// Gas limit bound of the BSC miner, the limit may only move by a fraction of the
+	// parent per block, see Config.gasLimitBoundDivisor.
+	minGasLimit = uint64(5000)
+)
+
+type Validate struct{}
+
+func (v *Validate) ValidateHeaderChain(db types.StateDB, headers []byte, chainType chains.ChainType) (int, error) {
+	var chain []*Header
+	if err := rlp.DecodeBytes(headers, &chain); err != nil {
+		log.Error("rlp decode bsc headers failed.", "err", err)
+		return 0, chains.ErrRLPDecode
+	}
+	if err := checkContiguous(chain); err != nil {
+		return 0, err
+	}
+
+	hs := NewHeaderStore(chainType)
+	if err := hs.Load(db); err != nil {
+		return 0, err
+	}
+	_, _, index, err := hs.verifyHeaders(db, chain)
+	return index, err
+}
+
+// checkContiguous does a sanity check that the provided chain is actually ordered and linked.
+func checkContiguous(chain []*Header) error {
+	if len(chain) == 0 {
+		return errors.New("headers cannot be empty")
+	}
+	for i, header := range chain {
+		if header.Number == nil || header.Difficulty == nil {
+			return errors.New("invalid header number or difficulty is nil")
+		}
+		if i == 0 {
+			continue
+		}
+		if header.Number.Uint64() != chain[i-1].Number.Uint64()+1 || header.ParentHash != chain[i-1].Hash() {
+			return fmt.Errorf("non contiguous insert: item %d is #%d, item %d is #%d", i-1, chain[i-1].Number, i, header.Number)
+		}
+	}
+	return nil
+}
+
+// verifyHeader checks the fields of a header that do not depend on the validator set,
+// the extra-data is parsed when the header is applied to the snapshot of its parent.
+func verifyHeader(header, parent *Header, config *Config) error {
+	number := header.Number.Uint64()
+	if parent.Number.Uint64()+1 != number {
+		return errInvalidNumber
+	}
+	if header.milliTimestamp() <= parent.milliTimestamp() {
+		return errOlderBlockTime
+	}
+	if len(header.Extra) < extraVanity {
+		return errMissingVanity
+	}
+	if len(header.Extra) < extraVanity+extraSeal {
+		return errMissingSignature
+	}
+
+	// Since Lorentz the mix digest carries the millisecond part of the timestamp
+	if !config.IsLorentz(header.Time) && header.MixDigest != (common.Hash{}) {
+		return errInvalidMixDigest
+	}
+	if config.IsLorentz(header.Time) && new(big.Int).SetBytes(header.MixDigest[:]).Cmp(big.NewInt(1000)) >= 0 {
+		return errInvalidMixDigest
+	}
+	if header.UncleHash != ethtypes.EmptyUncleHash {
+		return errInvalidUncleHash
+	}
+	if header.Difficulty.Cmp(diffInTurn) != 0 && header.Difficulty.Cmp(diffNoTurn) != 0 {
+		return errInvalidDifficulty
+	}
+
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
+	}
+	diff := new(big.Int).Sub(new(big.Int).SetUint64(parent.GasLimit), new(big.Int).SetUint64(header.GasLimit))
+	limit := parent.GasLimit / config.gasLimitBoundDivisor(header.Time)
+	if diff.CmpAbs(new(big.Int).SetUint64(limit)) >= 0 || header.GasLimit < minGasLimit {
+		return fmt.Errorf("invalid gas limit: have %d, want %d += %d", header.GasLimit, parent.GasLimit, limit)
+	}
+	return nil
+}
+
+// verifySeal returns the signer of the header, which must be its coinbase.
+func verifySeal(header *Header, chainID *big.Int) (common.Address, error) {
+	signer, err := ecrecover(header, chainID)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if signer != header.Coinbase {
+		return common.Address{}, errCoinbaseMismatch
+	}
+	return signer, nil
+}
@@ -0,0 +1,67 @@
+package bsc
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/ethereum"
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+// TxProve is a receipt proof, BSC shares the receipt tries of Ethereum.
+type TxProve = ethereum.TxProve
+
+type Verify struct {
+	ChainType     chains.ChainType
+	Confirmations uint64 // confirmations the block of the receipt needs, 0 to accept the head
+}
+
+func (v *Verify) Verify(db types.StateDB, routerContractAddr common.Address, txProveBytes []byte) (logs []byte, err error) {
+	txProve, err := ethereum.DecodeTxProve(txProveBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	hs := NewHeaderStore(v.ChainType)
+	if err := hs.Load(db); err != nil {
+		return nil, err
+	}
+	receiptsRoot, err := v.receiptsRoot(db, hs, txProve.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if err := ethereum.VerifyProof(receiptsRoot, txProve); err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(txProve.Receipt.Logs)
+}
+
+// VerifyBatch verifies many proofs, the receipts root of every block is looked up once
+// and the proofs are checked in parallel.
+func (v *Verify) VerifyBatch(db types.StateDB, routers []common.Address, txProves [][]byte) ([][]byte, []error) {
+	hs := NewHeaderStore(v.ChainType)
+	if err := hs.Load(db); err != nil {
+		errs := make([]error, len(txProves))
+		for i := range errs {
+			errs[i] = err
+		}
+		return make([][]byte, len(txProves)), errs
+	}
+	return ethereum.VerifyProofs(txProves, func(number uint64) (common.Hash, error) {
+		return v.receiptsRoot(db, hs, number)
+	})
+}
+
+func (v *Verify) receiptsRoot(db types.StateDB, hs *HeaderStore, blockNumber uint64) (common.Hash, error) {
+	if err := ethereum.CheckConfirmed(blockNumber, hs.CurNumber, v.Confirmations); err != nil {
+		return common.Hash{}, err
+	}
+	header := hs.GetHeaderByNumber(db, blockNumber)
+	if header == nil {
+		return common.Hash{}, fmt.Errorf("get header by number failed, number: %d", blockNumber)
+	}
+	return header.ReceiptHash, nil
+}
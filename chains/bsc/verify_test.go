@@ -0,0 +1,138 @@
+package bsc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains/ethereum"
+)
+
+func TestVerify_Verify(t *testing.T) {
+	var receipts []*ethtypes.Receipt
+	for i := 0; i < 3; i++ {
+		receipts = append(receipts, &ethtypes.Receipt{
+			Status:            ethtypes.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+			Logs: []*ethtypes.Log{{
+				Address: common.BigToAddress(big.NewInt(int64(i + 1))),
+				Topics:  []common.Hash{common.BigToHash(big.NewInt(int64(i)))},
+				Data:    []byte{byte(i)},
+			}},
+		})
+	}
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	assert.Nil(t, err)
+	for i, r := range receipts {
+		key, _ := rlp.EncodeToBytes(uint(i))
+		value, _ := rlp.EncodeToBytes(r)
+		tr.Update(key, value)
+	}
+
+	v := newTestValidators(3)
+	db, checkpoint := newTestStore(t, testChain, v, v)
+	m := newChainMaker(testChain)
+	headers := m.inturnChain(checkpoint, 2, v, v)
+	headers[0].ReceiptHash = tr.Hash()
+	m.seal(headers[0])
+	headers[1] = m.header(headers[0], headers[1].Coinbase, 2, nil, nil)
+	_, _, err = NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders(headers))
+	assert.Nil(t, err)
+
+	prove := func(txIndex uint, number uint64) []byte {
+		proof := light.NewNodeSet()
+		key, _ := rlp.EncodeToBytes(txIndex)
+		assert.Nil(t, tr.Prove(key, 0, proof))
+		input, err := rlp.EncodeToBytes(&TxProve{
+			Receipt:     ethereum.NewReceipt(receipts[1]),
+			Prove:       proof.NodeList(),
+			BlockNumber: number,
+			TxIndex:     txIndex,
+		})
+		assert.Nil(t, err)
+		return input
+	}
+	verify := &Verify{ChainType: testChain}
+
+	logs, err := verify.Verify(db, common.Address{}, prove(1, headers[0].Number.Uint64()))
+	assert.Nil(t, err)
+	want, _ := rlp.EncodeToBytes(receipts[1].Logs)
+	assert.Equal(t, want, logs)
+
+	_, err = verify.Verify(db, common.Address{}, prove(2, headers[0].Number.Uint64()))
+	assert.NotNil(t, err)
+	_, err = verify.Verify(db, common.Address{}, prove(1, headers[1].Number.Uint64()))
+	assert.NotNil(t, err)
+	_, err = verify.Verify(db, common.Address{}, prove(1, headers[1].Number.Uint64()+1))
+	assert.NotNil(t, err)
+
+	// the block of the receipt has two confirmations
+	verify.Confirmations = 2
+	_, err = verify.Verify(db, common.Address{}, prove(1, headers[0].Number.Uint64()))
+	assert.Nil(t, err)
+	verify.Confirmations = 3
+	_, err = verify.Verify(db, common.Address{}, prove(1, headers[0].Number.Uint64()))
+	assert.NotNil(t, err)
+}
+
+func TestVerify_VerifyBatch(t *testing.T) {
+	var receipts []*ethtypes.Receipt
+	for i := 0; i < 4; i++ {
+		receipts = append(receipts, &ethtypes.Receipt{
+			Status:            ethtypes.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+			Logs:              []*ethtypes.Log{{Address: common.BigToAddress(big.NewInt(int64(i + 1)))}},
+		})
+	}
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	assert.Nil(t, err)
+	for i, r := range receipts {
+		key, _ := rlp.EncodeToBytes(uint(i))
+		value, _ := rlp.EncodeToBytes(r)
+		tr.Update(key, value)
+	}
+
+	v := newTestValidators(3)
+	db, checkpoint := newTestStore(t, testChain, v, v)
+	m := newChainMaker(testChain)
+	header := m.inturnChain(checkpoint, 1, v, v)[0]
+	header.ReceiptHash = tr.Hash()
+	m.seal(header)
+	_, _, err = NewHeaderStore(testChain).InsertHeaders(db, encodeHeaders([]*Header{header}))
+	assert.Nil(t, err)
+
+	var txProves [][]byte
+	for i := range receipts {
+		proof := light.NewNodeSet()
+		key, _ := rlp.EncodeToBytes(uint(i))
+		if i == 2 {
+			// the receipt is not in the proven trie path
+			key, _ = rlp.EncodeToBytes(uint(1))
+		}
+		assert.Nil(t, tr.Prove(key, 0, proof))
+		input, _ := rlp.EncodeToBytes(&TxProve{
+			Receipt:     ethereum.NewReceipt(receipts[i]),
+			Prove:       proof.NodeList(),
+			BlockNumber: header.Number.Uint64(),
+			TxIndex:     uint(i),
+		})
+		txProves = append(txProves, input)
+	}
+	txProves[3] = []byte{0x01}
+
+	logs, errs := (&Verify{ChainType: testChain}).VerifyBatch(db, make([]common.Address, len(txProves)), txProves)
+	for i := 0; i < 2; i++ {
+		assert.Nil(t, errs[i])
+		want, _ := rlp.EncodeToBytes(receipts[i].Logs)
+		assert.Equal(t, want, logs[i])
+	}
+	assert.NotNil(t, errs[2])
+	assert.NotNil(t, errs[3])
+}
@@ -0,0 +1,110 @@
+package bsc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	bls "github.com/mapprotocol/atlas/chains/eth2/bls12381"
+)
+
+const (
+	voteAddressLength   = 48 // BLS public key a validator votes with
+	voteSignatureLength = 96
+)
+
+var (
+	errInvalidAttestation = errors.New("invalid vote attestation")
+	errAttestationTarget  = errors.New("vote attestation does not target the parent block")
+	errAttestationSource  = errors.New("vote attestation does not justify from the latest justified block")
+	errAttestationQuorum  = errors.New("vote attestation signed by less than 2/3 of the validators")
+)
+
+// VoteAddress is the BLS public key a validator signs fast finality votes with.
+type VoteAddress [voteAddressLength]byte
+
+// VoteData is the block range a fast finality vote justifies.
+type VoteData struct {
+	SourceNumber uint64 // latest justified block
+	SourceHash   common.Hash
+	TargetNumber uint64 // block the vote is for
+	TargetHash   common.Hash
+}
+
+func (d *VoteData) Hash() common.Hash {
+	data, _ := rlp.EncodeToBytes(d)
+	return crypto.Keccak256Hash(data)
+}
+
+// VoteAttestation is the aggregated vote of the validators a block carries for its parent.
+type VoteAttestation struct {
+	VoteAddressSet uint64 // bit set of the voters, indexed by the sorted validator set
+	AggSignature   [voteSignatureLength]byte
+	Data           *VoteData
+	Extra          []byte
+}
+
+// decodeAttestation decodes the attestation at the start of data. Like Parlia it ignores
+// the bytes after it.
+func decodeAttestation(data []byte) (*VoteAttestation, error) {
+	attestation := new(VoteAttestation)
+	if err := rlp.Decode(bytes.NewReader(data), attestation); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidAttestation, err)
+	}
+	if attestation.Data == nil {
+		return nil, errInvalidAttestation
+	}
+	return attestation, nil
+}
+
+// verifyAttestation checks that the attestation justifies parent, extending the latest
+// justified block of the parent snapshot, and is signed by 2/3 of the validators that
+// sealed parent.
+func verifyAttestation(attestation *VoteAttestation, parent *Header, parentSnap, signers *Snapshot) error {
+	data := attestation.Data
+	if data.TargetNumber != parent.Number.Uint64() || data.TargetHash != parent.Hash() {
+		return errAttestationTarget
+	}
+	// the justified block is unknown until the first attestation after a reset
+	if j := parentSnap.Attestation; j != nil && (data.SourceNumber != j.TargetNumber || data.SourceHash != j.TargetHash) {
+		return errAttestationSource
+	}
+
+	return verifyVoteSignature(attestation, signers)
+}
+
+// verifyVoteSignature checks that the attestation is signed by 2/3 of the validators of
+// the snapshot.
+func verifyVoteSignature(attestation *VoteAttestation, signers *Snapshot) error {
+	if bits.Len64(attestation.VoteAddressSet) > len(signers.Validators) {
+		return errInvalidAttestation
+	}
+	pubKeys := make([]bls.PublicKey, 0, bits.OnesCount64(attestation.VoteAddressSet))
+	for i := range signers.Validators {
+		if attestation.VoteAddressSet&(1<<uint(i)) == 0 {
+			continue
+		}
+		pubKey, err := bls.PublicKeyFromBytes(signers.VoteAddresses[i][:])
+		if err != nil {
+			return fmt.Errorf("%w: vote address of %s: %v", errInvalidAttestation, signers.Validators[i].Hex(), err)
+		}
+		pubKeys = append(pubKeys, pubKey)
+	}
+	if len(pubKeys) < (2*len(signers.Validators)+2)/3 {
+		return errAttestationQuorum
+	}
+
+	signature, err := bls.SignatureFromBytes(attestation.AggSignature[:])
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidAttestation, err)
+	}
+	if !signature.FastAggregateVerify(pubKeys, attestation.Data.Hash()) {
+		return fmt.Errorf("%w: bad aggregated signature", errInvalidAttestation)
+	}
+	return nil
+}
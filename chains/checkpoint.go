@@ -0,0 +1,168 @@
+package chains
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+var ErrCheckpointSigner = errors.New("checkpoint not signed by the expected signer")
+
+// Checkpoint is a header of a relayed chain read from the header store of an Atlas
+// state, together with the input ResetHeaderStore takes to start from it. It is signed
+// by whoever exported it, so that the header store of a new network or an emergency
+// reset can be started from it after checking the signer.
+type Checkpoint struct {
+	ChainType ChainType   `json:"chainType"`
+	Number    uint64      `json:"number"`
+	Hash      common.Hash `json:"hash"`
+	TD        *big.Int    `json:"td"`
+	Header    []byte      `json:"header"`              // rlp encoded header
+	Ancestors []byte      `json:"ancestors,omitempty"` // rlp list of the headers the store needs before Header
+	StateRoot common.Hash `json:"stateRoot"`           // Atlas state the header was read from
+	Signature []byte      `json:"signature"`
+}
+
+type checkpointMarshaling struct {
+	ChainType hexutil.Uint64 `json:"chainType"`
+	Number    hexutil.Uint64 `json:"number"`
+	Hash      common.Hash    `json:"hash"`
+	TD        *hexutil.Big   `json:"td"`
+	Header    hexutil.Bytes  `json:"header"`
+	Ancestors hexutil.Bytes  `json:"ancestors,omitempty"`
+	StateRoot common.Hash    `json:"stateRoot"`
+	Signature hexutil.Bytes  `json:"signature"`
+}
+
+func (c *Checkpoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&checkpointMarshaling{
+		ChainType: hexutil.Uint64(c.ChainType),
+		Number:    hexutil.Uint64(c.Number),
+		Hash:      c.Hash,
+		TD:        (*hexutil.Big)(c.TD),
+		Header:    c.Header,
+		Ancestors: c.Ancestors,
+		StateRoot: c.StateRoot,
+		Signature: c.Signature,
+	})
+}
+
+func (c *Checkpoint) UnmarshalJSON(input []byte) error {
+	var dec checkpointMarshaling
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	if dec.TD == nil || len(dec.Header) == 0 {
+		return errors.New("checkpoint misses header or td")
+	}
+	*c = Checkpoint{
+		ChainType: ChainType(dec.ChainType),
+		Number:    uint64(dec.Number),
+		Hash:      dec.Hash,
+		TD:        (*big.Int)(dec.TD),
+		Header:    dec.Header,
+		Ancestors: dec.Ancestors,
+		StateRoot: dec.StateRoot,
+		Signature: dec.Signature,
+	}
+	return nil
+}
+
+// ResetInput returns the header input of ResetHeaderStore. The stores that start from
+// more than one header take an rlp list of the ancestors followed by the header.
+func (c *Checkpoint) ResetInput() ([]byte, error) {
+	if len(c.Ancestors) == 0 {
+		return c.Header, nil
+	}
+	var headers []rlp.RawValue
+	if err := rlp.DecodeBytes(c.Ancestors, &headers); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint ancestors: %v", err)
+	}
+	return rlp.EncodeToBytes(append(headers, c.Header))
+}
+
+// SigHash returns the hash the signature of the checkpoint is made over.
+func (c *Checkpoint) SigHash() (common.Hash, error) {
+	data, err := rlp.EncodeToBytes([]interface{}{
+		uint64(c.ChainType),
+		c.Number,
+		c.Hash,
+		c.TD,
+		c.Header,
+		c.Ancestors,
+		c.StateRoot,
+	})
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(data), nil
+}
+
+// Sign signs the checkpoint with the given key.
+func (c *Checkpoint) Sign(key *ecdsa.PrivateKey) error {
+	hash, err := c.SigHash()
+	if err != nil {
+		return err
+	}
+	sig, err := crypto.Sign(hash.Bytes(), key)
+	if err != nil {
+		return err
+	}
+	c.Signature = sig
+	return nil
+}
+
+// Signer recovers the address that signed the checkpoint.
+func (c *Checkpoint) Signer() (common.Address, error) {
+	hash, err := c.SigHash()
+	if err != nil {
+		return common.Address{}, err
+	}
+	pub, err := crypto.SigToPub(hash.Bytes(), c.Signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// Verify checks that the checkpoint has been signed by signer.
+func (c *Checkpoint) Verify(signer common.Address) error {
+	addr, err := c.Signer()
+	if err != nil {
+		return err
+	}
+	if addr != signer {
+		return fmt.Errorf("%w: want %s, have %s", ErrCheckpointSigner, signer.Hex(), addr.Hex())
+	}
+	return nil
+}
+
+// WriteCheckpoint writes the checkpoint as JSON to the file.
+func WriteCheckpoint(file string, c *Checkpoint) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0644)
+}
+
+// ReadCheckpoint reads a checkpoint written by WriteCheckpoint.
+func ReadCheckpoint(file string) (*Checkpoint, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	c := new(Checkpoint)
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,61 @@
+package chains
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpointSignAndFile(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	c := &Checkpoint{
+		ChainType: ChainTypeETH,
+		Number:    100,
+		Hash:      common.HexToHash("0x01"),
+		TD:        big.NewInt(1000),
+		Header:    []byte{0xc0},
+		StateRoot: common.HexToHash("0x02"),
+	}
+	assert.Nil(t, c.Sign(key))
+	assert.Nil(t, c.Verify(signer))
+
+	file := filepath.Join(t.TempDir(), "checkpoint.json")
+	assert.Nil(t, WriteCheckpoint(file, c))
+	read, err := ReadCheckpoint(file)
+	assert.Nil(t, err)
+	assert.Equal(t, c, read)
+	assert.Nil(t, read.Verify(signer))
+
+	read.Number++
+	assert.ErrorIs(t, read.Verify(signer), ErrCheckpointSigner)
+}
+
+func TestCheckpointResetInput(t *testing.T) {
+	type header struct {
+		ParentHash common.Hash
+		Number     uint64
+	}
+	prev, last := &header{Number: 1}, &header{ParentHash: common.HexToHash("0x01"), Number: 2}
+	single, _ := rlp.EncodeToBytes(last)
+	ancestors, _ := rlp.EncodeToBytes([]*header{prev})
+	list, _ := rlp.EncodeToBytes([]*header{prev, last})
+
+	input, err := (&Checkpoint{Header: single}).ResetInput()
+	assert.Nil(t, err)
+	assert.Equal(t, single, input)
+
+	// the ancestors are followed by the header
+	input, err = (&Checkpoint{Header: single, Ancestors: ancestors}).ResetInput()
+	assert.Nil(t, err)
+	assert.Equal(t, list, input)
+
+	_, err = (&Checkpoint{Header: single, Ancestors: []byte{0x80}}).ResetInput()
+	assert.NotNil(t, err)
+}
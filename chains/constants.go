@@ -17,72 +17,65 @@ const (
 const (
 	ChainTypeETH     ChainType = 1
 	ChainTypeETHTest ChainType = 34434
+	ChainTypeBSC     ChainType = 56
+	ChainTypeBSCTest ChainType = 97
 )
 
 const (
 	ChainGroupMAP = 1000
 	ChainGroupETH = 1001
+	ChainGroupBSC = 1002
 )
 
-var ChainTypeList = []ChainType{
-	ChainTypeMAP,
-	ChainTypeMAPTest,
-	ChainTypeMAPDev,
-	ChainTypeETH,
-	ChainTypeETHTest,
-}
-
-var chainType2ChainGroup = map[ChainType]ChainGroup{
-	ChainTypeETH:     ChainGroupETH,
-	ChainTypeETHTest: ChainGroupETH,
-}
-
-var chainType2ChainID = map[ChainType]uint64{
-	ChainTypeETH:     params.MainNetChainID,
-	ChainTypeETHTest: params.TestNetChainID,
-}
-
-var chainType2LondonBlock = map[ChainType]*big.Int{
-	ChainTypeETH:     big.NewInt(12_965_000),
-	ChainTypeETHTest: big.NewInt(10_499_401),
-}
-
 var (
 	EthereumHeaderStoreAddress = common.BytesToAddress([]byte("EthereumHeaderStoreAddress"))
+	Eth2HeaderStoreAddress     = common.BytesToAddress([]byte("Eth2HeaderStoreAddress"))
+	BscHeaderStoreAddress      = common.BytesToAddress([]byte("BscHeaderStoreAddress"))
 )
 
 type ChainType uint64
 type ChainGroup uint64
 
-func IsSupportedChain(chain ChainType) bool {
-	for _, c := range ChainTypeList {
-		if c == chain {
-			return true
-		}
+func init() {
+	builtin := []*ChainInfo{
+		{Type: ChainTypeMAP},
+		{Type: ChainTypeMAPTest},
+		{Type: ChainTypeMAPDev},
+		{Type: ChainTypeETH, Group: ChainGroupETH, ChainID: params.MainNetChainID, LondonBlock: big.NewInt(12_965_000), ExecutionChainID: 1},
+		{Type: ChainTypeETHTest, Group: ChainGroupETH, ChainID: params.TestNetChainID, LondonBlock: big.NewInt(10_499_401)},
+	}
+	// BSC is not builtin, it is enabled at a fork block through the relay chains of the
+	// chain config of each network
+	for _, info := range builtin {
+		MustRegister(info)
 	}
-	return false
+}
+
+func IsSupportedChain(chain ChainType) bool {
+	_, err := Lookup(chain)
+	return err == nil
 }
 
 func ChainType2ChainGroup(chain ChainType) (ChainGroup, error) {
-	group, ok := chainType2ChainGroup[chain]
-	if !ok {
+	info, err := Lookup(chain)
+	if err != nil || info.Group == 0 {
 		return 0, ErrNotSupportChain
 	}
-	return group, nil
+	return info.Group, nil
 }
 
 func ChainType2ChainID(chain ChainType) (uint64, error) {
-	chainID, ok := chainType2ChainID[chain]
-	if !ok {
+	info, err := Lookup(chain)
+	if err != nil || info.ChainID == 0 {
 		return 0, ErrNotSupportChain
 	}
-	return chainID, nil
+	return info.ChainID, nil
 }
 
 func ChainType2LondonBlock(chain ChainType) (*big.Int, error) {
-	lb, ok := chainType2LondonBlock[chain]
-	if !ok {
+	info, err := Lookup(chain)
+	if err != nil || info.LondonBlock == nil {
 		return nil, ErrNotSupportChain
 	}
-	return lb, nil
+	return info.LondonBlock, nil
 }
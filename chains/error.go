@@ -5,4 +5,6 @@ import "errors"
 var (
 	ErrNotSupportChain = errors.New("not supported chain")
 	ErrRLPDecode       = errors.New("rlp decode error")
+
+	ErrRelayChainOverride = errors.New("relay chain overrides a registered chain with a later activation block")
 )
@@ -0,0 +1,289 @@
+package eth2
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+// MaxExecutionHeaderLimit is the number of finalized execution headers kept per chain,
+// older ones are overwritten in a ring buffer.
+const MaxExecutionHeaderLimit = 100000
+
+var (
+	errStoreNotInitialized = errors.New("please initialize eth2 header store")
+	errInvalidChainID      = errors.New("chain id of the store does not match")
+	errStaleUpdate         = errors.New("finalized header slot is not newer than the stored one")
+	errUnknownExecution    = errors.New("execution header is not in the store")
+
+	errNextSyncCommitteeUnknown = errors.New("next sync committee is unknown, submit an update of the finalized period first")
+)
+
+// HeaderStore is the on-chain state of an eth2 light client. It keeps the finalized
+// beacon header and the sync committees needed to verify the next light client update.
+type HeaderStore struct {
+	ChainID              uint64
+	FinalizedHeader      *BeaconBlockHeader
+	CurrentSyncCommittee *SyncCommittee
+	NextSyncCommittee    *SyncCommittee `rlp:"nil"` // nil until learnt from an update if the store was reset without it
+	CurNumber            uint64
+	CurHash              common.Hash
+}
+
+// ExecutionHeader is the part of a finalized execution payload that is needed to verify
+// proofs against it.
+type ExecutionHeader struct {
+	Number       uint64
+	BlockHash    common.Hash
+	ReceiptsRoot common.Hash
+	BeaconRoot   common.Hash // hash tree root of the finalized beacon block carrying the payload
+	Resets       uint64      // resets of the store before the header was stored, older entries are stale
+}
+
+func NewHeaderStore(chainID uint64) *HeaderStore {
+	return &HeaderStore{ChainID: chainID}
+}
+
+func storeDbKey(chainID uint64) common.Hash {
+	return common.BytesToHash([]byte(fmt.Sprintf("eth2-%d-store", chainID)))
+}
+
+func executionHeaderDbKey(chainID, number uint64) common.Hash {
+	return common.BytesToHash([]byte(fmt.Sprintf("eth2-%d-exe-%d", chainID, number%MaxExecutionHeaderLimit)))
+}
+
+func resetsDbKey(chainID uint64) common.Hash {
+	return common.BytesToHash([]byte(fmt.Sprintf("eth2-%d-resets", chainID)))
+}
+
+// resets returns how many times the store of the chain was reset. It is kept under its own
+// key, so that the execution headers can be checked without loading the store.
+func (hs *HeaderStore) resets(db types.StateDB) uint64 {
+	data := db.GetPOWState(chains.Eth2HeaderStoreAddress, resetsDbKey(hs.ChainID))
+	if len(data) == 0 {
+		return 0
+	}
+	var resets uint64
+	if err := rlp.DecodeBytes(data, &resets); err != nil {
+		log.Error("Invalid eth2 header store resets RLP", "err", err)
+		return 0
+	}
+	return resets
+}
+
+func (hs *HeaderStore) state() *LightClientState {
+	return &LightClientState{
+		finalizedHeader:      hs.FinalizedHeader,
+		currentSyncCommittee: hs.CurrentSyncCommittee,
+		nextSyncCommittee:    hs.NextSyncCommittee,
+		chainID:              hs.ChainID,
+	}
+}
+
+// ResetHeaderStore initializes the store of the chain in the given state. The input is the
+// ABI encoded (finalizedHeader, curSyncCommittee, nextSyncCommittee, chainId) tuple, the same
+// layout the stateless eth2VerifyLightClient precompile expects after the update.
+func (hs *HeaderStore) ResetHeaderStore(db types.StateDB, input []byte, exe *ExecutionHeader) error {
+	state, err := decodeLightClientState(input)
+	if err != nil {
+		return err
+	}
+	if _, err := newNetworkConfig(state.chainID); err != nil {
+		return err
+	}
+	if hs.ChainID != 0 && hs.ChainID != state.chainID {
+		return errInvalidChainID
+	}
+
+	hs.ChainID = state.chainID
+	// the execution headers of the ring buffer stored before the reset become stale
+	resets, err := rlp.EncodeToBytes(hs.resets(db) + 1)
+	if err != nil {
+		return err
+	}
+	db.SetPOWState(chains.Eth2HeaderStoreAddress, resetsDbKey(hs.ChainID), resets)
+
+	hs.FinalizedHeader = state.finalizedHeader
+	hs.CurrentSyncCommittee = state.currentSyncCommittee
+	hs.NextSyncCommittee = state.nextSyncCommittee
+	hs.CurNumber, hs.CurHash = 0, common.Hash{}
+	if exe != nil {
+		root, err := state.finalizedHeader.HashTreeRoot()
+		if err != nil {
+			return err
+		}
+		exe.BeaconRoot = root
+		if err := hs.StoreExecutionHeader(db, exe); err != nil {
+			return err
+		}
+		hs.CurNumber, hs.CurHash = exe.Number, exe.BlockHash
+	}
+	return hs.Store(db)
+}
+
+// UpdateLightClient verifies the ABI encoded light client update against the stored
+// state and, if it is valid, moves the finalized header forward.
+func (hs *HeaderStore) UpdateLightClient(db types.StateDB, input []byte) (*ExecutionHeader, error) {
+	update, err := decodeLightClientUpdate(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := hs.Load(db); err != nil {
+		return nil, err
+	}
+
+	if update.finalizedHeader.Slot <= hs.FinalizedHeader.Slot {
+		return nil, errStaleUpdate
+	}
+	finalizedPeriod := computeSyncCommitteePeriod(hs.FinalizedHeader.Slot)
+	updatePeriod := computeSyncCommitteePeriod(update.finalizedHeader.Slot)
+	if updatePeriod != finalizedPeriod && updatePeriod != finalizedPeriod+1 {
+		return nil, fmt.Errorf("update period should be %d or %d, but got %d", finalizedPeriod, finalizedPeriod+1, updatePeriod)
+	}
+
+	// The next sync committee signs the updates from the next period on, it must be known
+	// before the store can move to that period
+	signaturePeriod := computeSyncCommitteePeriod(update.signatureSlot)
+	if (updatePeriod == finalizedPeriod+1 || signaturePeriod == finalizedPeriod+1) && !hs.hasNextSyncCommittee() {
+		return nil, errNextSyncCommitteeUnknown
+	}
+
+	if err := verifyLightClientUpdate(&LightClientVerify{update: update, state: hs.state()}); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case updatePeriod == finalizedPeriod+1:
+		hs.CurrentSyncCommittee = hs.NextSyncCommittee
+		hs.NextSyncCommittee = update.nextSyncCommittee
+	case !hs.hasNextSyncCommittee() && hasSyncCommittee(update.nextSyncCommittee) &&
+		computeSyncCommitteePeriod(update.attestedHeader.Slot) == finalizedPeriod:
+		// A store reset without the next sync committee learns it from an update of the
+		// same period, the attested state of the period commits to it
+		if err := verifySyncCommitteeBranch(update); err != nil {
+			return nil, err
+		}
+		hs.NextSyncCommittee = update.nextSyncCommittee
+	}
+	hs.FinalizedHeader = update.finalizedHeader
+
+	root, err := update.finalizedHeader.HashTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+	exe := &ExecutionHeader{
+		Number:       update.finalizedExecution.BlockNumber.Uint64(),
+		BlockHash:    update.finalizedExecution.BlockHash,
+		ReceiptsRoot: update.finalizedExecution.ReceiptsRoot,
+		BeaconRoot:   root,
+	}
+	if exe.Number > hs.CurNumber {
+		if err := hs.StoreExecutionHeader(db, exe); err != nil {
+			return nil, err
+		}
+		hs.CurNumber, hs.CurHash = exe.Number, exe.BlockHash
+	}
+
+	if err := hs.Store(db); err != nil {
+		return nil, err
+	}
+	log.Info("eth2 light client updated", "chainID", hs.ChainID, "slot", hs.FinalizedHeader.Slot,
+		"number", hs.CurNumber, "hash", hs.CurHash)
+	return exe, nil
+}
+
+func (hs *HeaderStore) hasNextSyncCommittee() bool {
+	return hasSyncCommittee(hs.NextSyncCommittee)
+}
+
+func hasSyncCommittee(committee *SyncCommittee) bool {
+	return committee != nil && len(committee.Pubkeys) != 0
+}
+
+func (hs *HeaderStore) Store(db types.StateDB) error {
+	data, err := rlp.EncodeToBytes(hs)
+	if err != nil {
+		log.Error("Failed to RLP encode eth2 HeaderStore", "err", err)
+		return err
+	}
+	db.SetPOWState(chains.Eth2HeaderStoreAddress, storeDbKey(hs.ChainID), data)
+	return nil
+}
+
+func (hs *HeaderStore) Load(db types.StateDB) error {
+	data := db.GetPOWState(chains.Eth2HeaderStoreAddress, storeDbKey(hs.ChainID))
+	if len(data) == 0 {
+		return errStoreNotInitialized
+	}
+
+	var h HeaderStore
+	if err := rlp.DecodeBytes(data, &h); err != nil {
+		log.Error("eth2 HeaderStore RLP decode failed", "err", err)
+		return fmt.Errorf("eth2 HeaderStore RLP decode failed, error: %s", err.Error())
+	}
+	if h.ChainID != hs.ChainID {
+		return errInvalidChainID
+	}
+	*hs = h
+	return nil
+}
+
+func (hs *HeaderStore) StoreExecutionHeader(db types.StateDB, header *ExecutionHeader) error {
+	header.Resets = hs.resets(db)
+	data, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		log.Error("Failed to RLP encode eth2 ExecutionHeader", "err", err)
+		return err
+	}
+	db.SetPOWState(chains.Eth2HeaderStoreAddress, executionHeaderDbKey(hs.ChainID, header.Number), data)
+	return nil
+}
+
+// GetExecutionHeader returns the finalized execution header with the given number, or nil
+// if it was never finalized through this store, has been overwritten or was stored before
+// the last reset.
+func (hs *HeaderStore) GetExecutionHeader(db types.StateDB, number uint64) *ExecutionHeader {
+	data := db.GetPOWState(chains.Eth2HeaderStoreAddress, executionHeaderDbKey(hs.ChainID, number))
+	if len(data) == 0 {
+		return nil
+	}
+
+	header := new(ExecutionHeader)
+	if err := rlp.DecodeBytes(data, header); err != nil {
+		log.Error("Invalid eth2 execution header RLP", "number", number, "err", err)
+		return nil
+	}
+	if header.Number != number || header.Resets != hs.resets(db) {
+		return nil
+	}
+	return header
+}
+
+func (hs *HeaderStore) GetCurrentNumberAndHash(db types.StateDB) (uint64, common.Hash, error) {
+	if err := hs.Load(db); err != nil {
+		return 0, common.Hash{}, err
+	}
+	return hs.CurNumber, hs.CurHash, nil
+}
+
+func (hs *HeaderStore) GetHashByNumber(db types.StateDB, number uint64) (common.Hash, error) {
+	header := hs.GetExecutionHeader(db, number)
+	if header == nil {
+		return common.Hash{}, fmt.Errorf("%w, number: %d", errUnknownExecution, number)
+	}
+	return header.BlockHash, nil
+}
+
+func (hs *HeaderStore) GetReceiptsRootByNumber(db types.StateDB, number uint64) (common.Hash, error) {
+	header := hs.GetExecutionHeader(db, number)
+	if header == nil {
+		return common.Hash{}, fmt.Errorf("%w, number: %d", errUnknownExecution, number)
+	}
+	return header.ReceiptsRoot, nil
+}
@@ -0,0 +1,126 @@
+package eth2
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/core/rawdb"
+	atlasstate "github.com/mapprotocol/atlas/core/state"
+)
+
+// splitInput splits the stateless precompile input into the update and the state part.
+func splitInput(t *testing.T) ([]byte, []byte) {
+	data, err := hexutil.Decode(INPUT)
+	assert.Nil(t, err)
+
+	args, err := genAbiArgs()
+	assert.Nil(t, err)
+	ret, err := args.Unpack(data)
+	assert.Nil(t, err)
+
+	// the update tuple is self-contained, so it can be re-headed with its own offset
+	offset := new(big.Int).SetBytes(data[:32]).Uint64()
+	updateInput := append(common.LeftPadBytes([]byte{32}, 32), data[offset:]...)
+	stateInput, err := args[1:].Pack(ret[1:]...)
+	assert.Nil(t, err)
+	return updateInput, stateInput
+}
+
+func TestHeaderStoreUpdateLightClient(t *testing.T) {
+	db, _ := atlasstate.New(common.Hash{}, atlasstate.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	updateInput, stateInput := splitInput(t)
+
+	hs := new(HeaderStore)
+	assert.Nil(t, hs.ResetHeaderStore(db, stateInput, nil))
+	assert.Equal(t, state.chainID, hs.ChainID)
+
+	loaded := NewHeaderStore(hs.ChainID)
+	assert.Nil(t, loaded.Load(db))
+	assert.Equal(t, state.finalizedHeader, loaded.FinalizedHeader)
+	assert.Equal(t, state.currentSyncCommittee, loaded.CurrentSyncCommittee)
+
+	exe, err := NewHeaderStore(hs.ChainID).UpdateLightClient(db, updateInput)
+	assert.Nil(t, err)
+	assert.Equal(t, update.finalizedExecution.BlockNumber.Uint64(), exe.Number)
+
+	number, hash, err := NewHeaderStore(hs.ChainID).GetCurrentNumberAndHash(db)
+	assert.Nil(t, err)
+	assert.Equal(t, exe.Number, number)
+	assert.Equal(t, update.finalizedExecution.BlockHash, hash)
+
+	receiptsRoot, err := NewHeaderStore(hs.ChainID).GetReceiptsRootByNumber(db, number)
+	assert.Nil(t, err)
+	assert.Equal(t, update.finalizedExecution.ReceiptsRoot, receiptsRoot)
+
+	// headers that are not in the store are reported by both lookups
+	_, err = NewHeaderStore(hs.ChainID).GetHashByNumber(db, number+1)
+	assert.ErrorIs(t, err, errUnknownExecution)
+	_, err = NewHeaderStore(hs.ChainID).GetReceiptsRootByNumber(db, number+1)
+	assert.ErrorIs(t, err, errUnknownExecution)
+
+	// the same update can not be applied twice
+	_, err = NewHeaderStore(hs.ChainID).UpdateLightClient(db, updateInput)
+	assert.Equal(t, errStaleUpdate, err)
+}
+
+func TestHeaderStoreResetDropsExecutionHeaders(t *testing.T) {
+	db, _ := atlasstate.New(common.Hash{}, atlasstate.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	updateInput, stateInput := splitInput(t)
+
+	assert.Nil(t, new(HeaderStore).ResetHeaderStore(db, stateInput, nil))
+	exe, err := NewHeaderStore(state.chainID).UpdateLightClient(db, updateInput)
+	assert.Nil(t, err)
+	_, err = NewHeaderStore(state.chainID).GetHashByNumber(db, exe.Number)
+	assert.Nil(t, err)
+
+	// the headers finalized before a reset are not served from the ring buffer anymore
+	reset := &ExecutionHeader{Number: exe.Number - 1, BlockHash: common.HexToHash("0x01"), ReceiptsRoot: common.HexToHash("0x02")}
+	assert.Nil(t, new(HeaderStore).ResetHeaderStore(db, stateInput, reset))
+	_, err = NewHeaderStore(state.chainID).GetHashByNumber(db, exe.Number)
+	assert.ErrorIs(t, err, errUnknownExecution)
+	hash, err := NewHeaderStore(state.chainID).GetHashByNumber(db, reset.Number)
+	assert.Nil(t, err)
+	assert.Equal(t, reset.BlockHash, hash)
+}
+
+func TestHeaderStoreNotInitialized(t *testing.T) {
+	db, _ := atlasstate.New(common.Hash{}, atlasstate.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	updateInput, _ := splitInput(t)
+
+	_, err := NewHeaderStore(5).UpdateLightClient(db, updateInput)
+	assert.Equal(t, errStoreNotInitialized, err)
+}
+
+func TestHeaderStoreNextSyncCommittee(t *testing.T) {
+	db, _ := atlasstate.New(common.Hash{}, atlasstate.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	updateInput, stateInput := splitInput(t)
+
+	// The update moves the store to the next period, it can not be verified without the
+	// next sync committee
+	hs := new(HeaderStore)
+	assert.Nil(t, hs.ResetHeaderStore(db, stateInput, nil))
+	hs.NextSyncCommittee = nil
+	assert.Nil(t, hs.Store(db))
+	_, err := NewHeaderStore(hs.ChainID).UpdateLightClient(db, updateInput)
+	assert.Equal(t, errNextSyncCommitteeUnknown, err)
+
+	// A store reset in the period of the update learns the next sync committee from it
+	period := computeSyncCommitteePeriod(update.finalizedHeader.Slot)
+	finalized := *state.finalizedHeader
+	finalized.Slot = period * EpochsPerSyncCommitteePeriod * SlotsPerEpoch
+	assert.True(t, finalized.Slot < update.finalizedHeader.Slot)
+	hs.FinalizedHeader = &finalized
+	hs.CurrentSyncCommittee = state.nextSyncCommittee
+	assert.Nil(t, hs.Store(db))
+
+	_, err = NewHeaderStore(hs.ChainID).UpdateLightClient(db, updateInput)
+	assert.Nil(t, err)
+	loaded := NewHeaderStore(hs.ChainID)
+	assert.Nil(t, loaded.Load(db))
+	assert.Equal(t, state.nextSyncCommittee, loaded.CurrentSyncCommittee)
+	assert.Equal(t, update.nextSyncCommittee, loaded.NextSyncCommittee)
+}
@@ -2,6 +2,7 @@ package eth2
 
 import (
 	"fmt"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/mapprotocol/atlas/chains/eth2/bls12381"
 	ssz "github.com/prysmaticlabs/fastssz"
@@ -26,16 +27,15 @@ const ExecutionProofSize = L1BeaconBlockBodyProofSize + L2ExecutionPayloadProofS
 var DomainSyncCommittee = [4]byte{0x07, 0x00, 0x00, 0x00}
 
 func VerifyLightClientUpdate(input []byte) error {
-	verify, err := decodeLightClientVerifyV2(input)
+	verify, err := decodeLightClientVerify(input)
 	if err != nil {
-		log.Warn("decodeLightClientVerifyV2", "error", err)
-		verify, err = decodeLightClientVerifyV1(input)
-		if err != nil {
-			log.Warn("decodeLightClientVerifyV1", "error", err)
-			return err
-		}
+		return err
 	}
 
+	return verifyLightClientUpdate(verify)
+}
+
+func verifyLightClientUpdate(verify *LightClientVerify) error {
 	switch verify.update.(type) {
 	case *LightClientUpdateV1:
 		if err := verifyFinalityV1(verify.update.(*LightClientUpdateV1)); err != nil {
@@ -130,11 +130,21 @@ func verifyFinalityV1(update *LightClientUpdateV1) error {
 		return fmt.Errorf("invalid finality proof")
 	}
 
-	l1Proof := update.exeFinalityBranch[0:L1BeaconBlockBodyProofSize]
-	l2Proof := update.exeFinalityBranch[L1BeaconBlockBodyProofSize:ExecutionProofSize]
+	return verifyExecutionBranch(update.finalizedExeHeader.Hash(), update.finalizedHeader.BodyRoot, update.exeFinalityBranch)
+}
+
+// verifyExecutionBranch checks that the execution block hash is part of the execution payload
+// committed to by the beacon block body root. The branch is the concatenation of the payload
+// to body proof and the block hash to payload proof.
+func verifyExecutionBranch(blockHash common.Hash, bodyRoot []byte, branch [][]byte) error {
+	if uint64(len(branch)) != ExecutionProofSize {
+		return fmt.Errorf("invalid execution proof size, exp: %d, got: %d", ExecutionProofSize, len(branch))
+	}
+	l1Proof := branch[0:L1BeaconBlockBodyProofSize]
+	l2Proof := branch[L1BeaconBlockBodyProofSize:ExecutionProofSize]
 
 	executionPayloadHash, err := merkelRootFromBranch(
-		update.finalizedExeHeader.Hash(),
+		blockHash,
 		l2Proof,
 		L2ExecutionPayloadProofSize,
 		L2ExecutionPayloadTreeExecutionBlockIndex,
@@ -143,12 +153,12 @@ func verifyFinalityV1(update *LightClientUpdateV1) error {
 		return fmt.Errorf("compute execution payload merkel root failed: %v", err)
 	}
 
-	proof = ssz.Proof{
+	proof := ssz.Proof{
 		Index:  int(L1BeaconBlockBodyTreeExecutionPayloadIndex),
 		Leaf:   executionPayloadHash[:],
 		Hashes: l1Proof,
 	}
-	ret, err = ssz.VerifyProof(update.finalizedHeader.BodyRoot, &proof)
+	ret, err := ssz.VerifyProof(bodyRoot, &proof)
 	if err != nil {
 		return fmt.Errorf("VerifyProof return err: %v", err)
 	}
@@ -168,25 +178,32 @@ func verifyNextSyncCommittee(state *LightClientState, update ILightClientUpdate)
 	// Verify that the `next_sync_committee`, if present, actually is the next sync committee saved in the
 	// state of the `active_header`
 	if updatePeriod != finalizedPeriod {
-		leaf, err := SyncCommitteeRoot(update.GetNextSyncCommittee())
-		if err != nil {
-			return fmt.Errorf("failed to compute hash tree root of finalized header: %v", err)
-		}
-		proof := ssz.Proof{
-			Index:  int(NextSyncCommitteeIndex),
-			Leaf:   leaf[:],
-			Hashes: update.GetNextSyncCommitteeBranch(),
-		}
-		ret, err := ssz.VerifyProof(update.GetAttestedHeader().StateRoot, &proof)
-		if err != nil {
-			return fmt.Errorf("VerifyProof return err: %v", err)
-		}
+		return verifySyncCommitteeBranch(update)
+	}
 
-		if !ret {
-			return fmt.Errorf("invalid next sync committee proof")
-		}
+	return nil
+}
+
+// verifySyncCommitteeBranch checks that the next sync committee of the update is the one saved
+// in the state of its attested header.
+func verifySyncCommitteeBranch(update ILightClientUpdate) error {
+	leaf, err := SyncCommitteeRoot(update.GetNextSyncCommittee())
+	if err != nil {
+		return fmt.Errorf("failed to compute hash tree root of finalized header: %v", err)
+	}
+	proof := ssz.Proof{
+		Index:  int(NextSyncCommitteeIndex),
+		Leaf:   leaf[:],
+		Hashes: update.GetNextSyncCommitteeBranch(),
+	}
+	ret, err := ssz.VerifyProof(update.GetAttestedHeader().StateRoot, &proof)
+	if err != nil {
+		return fmt.Errorf("VerifyProof return err: %v", err)
 	}
 
+	if !ret {
+		return fmt.Errorf("invalid next sync committee proof")
+	}
 	return nil
 }
 
@@ -77,6 +77,50 @@ func decodeLightClientVerifyV2(input []byte) (*LightClientVerify, error) {
 	return ConvertToLightClientVerify(update, finalizedBeaconHeader, curSyncCommittee, nextSyncCommittee, *chainId), nil
 }
 
+func decodeLightClientUpdate(input []byte) (*LightClientUpdateV2, error) {
+	var updateArg abi.Argument
+	if err := updateArg.UnmarshalJSON([]byte(UpdateABIJSON)); err != nil {
+		return nil, fmt.Errorf("unmarshal update abi json failed: %v", err)
+	}
+
+	args := abi.Arguments{updateArg}
+	ret, err := args.Unpack(input)
+	if err != nil {
+		return nil, fmt.Errorf("unpack input failed: %v", err)
+	}
+
+	update := new(ILightNodeLightClientUpdateV2)
+	if err := args.Copy(&update, ret); err != nil {
+		return nil, fmt.Errorf("copy unpacked result failed: %v", err)
+	}
+
+	return update.toLightClientUpdateV2(), nil
+}
+
+func decodeLightClientState(input []byte) (*LightClientState, error) {
+	args, err := genAbiArgs()
+	if err != nil {
+		return nil, fmt.Errorf("gen abi args failed: %v", err)
+	}
+	// skip the update argument, the state is encoded as (finalizedHeader, curSyncCommittee, nextSyncCommittee, chainId)
+	args = args[1:]
+
+	ret, err := args.Unpack(input)
+	if err != nil {
+		return nil, fmt.Errorf("unpack input failed: %v", err)
+	}
+
+	finalizedBeaconHeader := new(ILightNodeBeaconBlockHeader)
+	curSyncCommittee := new(ILightNodeSyncCommittee)
+	nextSyncCommittee := new(ILightNodeSyncCommittee)
+	chainId := new(uint64)
+	if err := args.Copy(&[]interface{}{finalizedBeaconHeader, curSyncCommittee, nextSyncCommittee, chainId}, ret); err != nil {
+		return nil, fmt.Errorf("copy unpacked result failed: %v", err)
+	}
+
+	return ConvertToLightClientState(finalizedBeaconHeader, curSyncCommittee, nextSyncCommittee, *chainId), nil
+}
+
 func genAbiArgs() (abi.Arguments, error) {
 	var updateArg, beaconHeaderArg, syncCommitteeArg, chainIdArg abi.Argument
 	if err := updateArg.UnmarshalJSON([]byte(UpdateABIJSON)); err != nil {
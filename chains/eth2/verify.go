@@ -0,0 +1,209 @@
+package eth2
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains/ethereum"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/tools"
+)
+
+// TxProveType prefixes the RLP encoding of a beacon-finalized receipt proof, the way typed
+// transactions are enveloped. Untyped proofs are RLP lists, their first byte is at least 0xc0.
+const TxProveType byte = 0x02
+
+var (
+	// ErrNotBeaconProof is returned when the proof is not typed as a beacon-finalized proof
+	ErrNotBeaconProof = errors.New("not a beacon-finalized receipt proof")
+
+	errIncompleteProof = errors.New("incomplete beacon-finalized receipt proof")
+)
+
+// ExecutionBlockHeader is a post-merge execution layer block header. It differs from the
+// ethash header by the optional fields of the later forks: the withdrawals root of Capella,
+// the blob gas and parent beacon root of Dencun and the requests hash of Pectra.
+type ExecutionBlockHeader struct {
+	ParentHash       common.Hash
+	UncleHash        common.Hash
+	Coinbase         common.Address
+	Root             common.Hash
+	TxHash           common.Hash
+	ReceiptHash      common.Hash
+	Bloom            ethtypes.Bloom
+	Difficulty       *big.Int
+	Number           *big.Int
+	GasLimit         uint64
+	GasUsed          uint64
+	Time             uint64
+	Extra            []byte
+	MixDigest        common.Hash
+	Nonce            ethtypes.BlockNonce
+	BaseFee          *big.Int     `rlp:"optional"`
+	WithdrawalsHash  *common.Hash `rlp:"optional"`
+	BlobGasUsed      *uint64      `rlp:"optional"`
+	ExcessBlobGas    *uint64      `rlp:"optional"`
+	ParentBeaconRoot *common.Hash `rlp:"optional"`
+	RequestsHash     *common.Hash `rlp:"optional"`
+}
+
+func (h *ExecutionBlockHeader) Hash() common.Hash {
+	data, _ := rlp.EncodeToBytes(h)
+	return crypto.Keccak256Hash(data)
+}
+
+// TxProve proves a receipt of an execution block that is part of a beacon block
+// finalized through the eth2 light client store.
+type TxProve struct {
+	Receipt         *ethereum.Receipt
+	Prove           light.NodeList
+	TxIndex         uint
+	Header          *ExecutionBlockHeader
+	BeaconHeader    *BeaconBlockHeader
+	ExecutionBranch [][]byte
+}
+
+type Verify struct {
+	ChainID uint64
+}
+
+func (v *Verify) Verify(db types.StateDB, routerContractAddr common.Address, txProveBytes []byte) (logs []byte, err error) {
+	txProve, err := v.decode(txProveBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := v.verifyHeader(db, txProve); err != nil {
+		return nil, err
+	}
+
+	if err := v.verifyProof(txProve.Header.ReceiptHash, txProve); err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(txProve.Receipt.Logs)
+}
+
+// IsBeaconProof reports whether the proof is typed as a beacon-finalized receipt proof.
+func IsBeaconProof(txProveBytes []byte) bool {
+	return len(txProveBytes) > 0 && txProveBytes[0] == TxProveType
+}
+
+// EncodeTxProve returns the typed encoding of the proof expected by Verify.
+func EncodeTxProve(txProve *TxProve) ([]byte, error) {
+	data, err := rlp.EncodeToBytes(txProve)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{TxProveType}, data...), nil
+}
+
+func (v *Verify) decode(txProveBytes []byte) (*TxProve, error) {
+	if !IsBeaconProof(txProveBytes) {
+		return nil, ErrNotBeaconProof
+	}
+	var txProve TxProve
+	if err := rlp.DecodeBytes(txProveBytes[1:], &txProve); err != nil {
+		return nil, fmt.Errorf("invalid beacon-finalized receipt proof: %v", err)
+	}
+	if txProve.Receipt == nil || txProve.Header == nil || txProve.Header.Number == nil || txProve.BeaconHeader == nil {
+		return nil, errIncompleteProof
+	}
+	return &txProve, nil
+}
+
+// VerifyBatch verifies many proofs, the finalized header of every block is looked up once
+// and the proofs are checked in parallel. Proofs that are not typed as beacon-finalized
+// proofs fail with ErrNotBeaconProof.
+func (v *Verify) VerifyBatch(db types.StateDB, routers []common.Address, txProves [][]byte) ([][]byte, []error) {
+	var (
+		logs       = make([][]byte, len(txProves))
+		errs       = make([]error, len(txProves))
+		proves     = make([]*TxProve, len(txProves))
+		finalized  = make([]*ExecutionHeader, len(txProves))
+		hs         = NewHeaderStore(v.ChainID)
+		loadErr    error
+		storeReady bool
+	)
+	lookups := make(map[uint64]*ExecutionHeader)
+	for i, txProveBytes := range txProves {
+		if proves[i], errs[i] = v.decode(txProveBytes); errs[i] != nil {
+			continue
+		}
+		if !storeReady {
+			loadErr, storeReady = hs.Load(db), true
+		}
+		if loadErr != nil {
+			errs[i] = loadErr
+			continue
+		}
+		number := proves[i].Header.Number.Uint64()
+		header, ok := lookups[number]
+		if !ok {
+			header = hs.GetExecutionHeader(db, number)
+			lookups[number] = header
+		}
+		if finalized[i] = header; header == nil {
+			errs[i] = fmt.Errorf("execution header is not finalized, number: %d", number)
+		}
+	}
+
+	tools.ParallelFor(len(txProves), func(i int) {
+		if errs[i] != nil {
+			return
+		}
+		if errs[i] = v.checkHeader(finalized[i], proves[i]); errs[i] != nil {
+			return
+		}
+		if errs[i] = v.verifyProof(proves[i].Header.ReceiptHash, proves[i]); errs[i] != nil {
+			return
+		}
+		logs[i], errs[i] = rlp.EncodeToBytes(proves[i].Receipt.Logs)
+	})
+	return logs, errs
+}
+
+// verifyHeader checks that the execution header is the one carried by a beacon block
+// finalized in the store.
+func (v *Verify) verifyHeader(db types.StateDB, txProve *TxProve) error {
+	hs := NewHeaderStore(v.ChainID)
+	if err := hs.Load(db); err != nil {
+		return err
+	}
+
+	number := txProve.Header.Number.Uint64()
+	finalized := hs.GetExecutionHeader(db, number)
+	if finalized == nil {
+		return fmt.Errorf("execution header is not finalized, number: %d", number)
+	}
+	return v.checkHeader(finalized, txProve)
+}
+
+// checkHeader checks the execution header of the proof against the finalized one.
+func (v *Verify) checkHeader(finalized *ExecutionHeader, txProve *TxProve) error {
+	number := finalized.Number
+	beaconRoot, err := txProve.BeaconHeader.HashTreeRoot()
+	if err != nil {
+		return fmt.Errorf("failed to compute hash tree root of beacon header: %v", err)
+	}
+	if common.Hash(beaconRoot) != finalized.BeaconRoot {
+		return fmt.Errorf("beacon header mismatch, number: %d, exp: %s, got: %s", number, finalized.BeaconRoot, common.Hash(beaconRoot))
+	}
+
+	blockHash := txProve.Header.Hash()
+	if blockHash != finalized.BlockHash {
+		return fmt.Errorf("execution header mismatch, number: %d, exp: %s, got: %s", number, finalized.BlockHash, blockHash)
+	}
+
+	return verifyExecutionBranch(blockHash, txProve.BeaconHeader.BodyRoot, txProve.ExecutionBranch)
+}
+
+func (v *Verify) verifyProof(receiptsRoot common.Hash, txProve *TxProve) error {
+	return ethereum.VerifyReceipt(receiptsRoot, txProve.Receipt, txProve.TxIndex, txProve.Prove)
+}
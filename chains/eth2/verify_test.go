@@ -0,0 +1,260 @@
+package eth2
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains/ethereum"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	atlasstate "github.com/mapprotocol/atlas/core/state"
+)
+
+const testChainID = 5
+
+func newTestReceipts() []*ethtypes.Receipt {
+	var receipts []*ethtypes.Receipt
+	for i := 0; i < 3; i++ {
+		receipts = append(receipts, &ethtypes.Receipt{
+			Status:            ethtypes.ReceiptStatusSuccessful,
+			CumulativeGasUsed: uint64(21000 * (i + 1)),
+			Logs: []*ethtypes.Log{{
+				Address: common.BigToAddress(big.NewInt(int64(i + 1))),
+				Topics:  []common.Hash{common.BigToHash(big.NewInt(int64(i)))},
+				Data:    []byte{byte(i)},
+			}},
+		})
+	}
+	return receipts
+}
+
+// makeTxProve builds a proof of the receipt at txIndex together with a store in which the
+// beacon block carrying the execution header is finalized.
+func makeTxProve(t *testing.T, txIndex uint) (*atlasstate.StateDB, *TxProve) {
+	receipts := newTestReceipts()
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	assert.Nil(t, err)
+	for i, r := range receipts {
+		key, _ := rlp.EncodeToBytes(uint(i))
+		value, _ := rlp.EncodeToBytes(r)
+		tr.Update(key, value)
+	}
+	proof := light.NewNodeSet()
+	key, _ := rlp.EncodeToBytes(txIndex)
+	assert.Nil(t, tr.Prove(key, 0, proof))
+
+	withdrawalsHash := common.HexToHash("0x56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+	header := &ExecutionBlockHeader{
+		ReceiptHash:     tr.Hash(),
+		Difficulty:      big.NewInt(0),
+		Number:          big.NewInt(8_000_000),
+		GasLimit:        30_000_000,
+		BaseFee:         big.NewInt(7),
+		WithdrawalsHash: &withdrawalsHash,
+	}
+
+	var branch [][]byte
+	for i := 0; i < int(ExecutionProofSize); i++ {
+		branch = append(branch, common.BigToHash(big.NewInt(int64(i+1))).Bytes())
+	}
+	payloadRoot, err := merkelRootFromBranch(header.Hash(), branch[L1BeaconBlockBodyProofSize:],
+		L2ExecutionPayloadProofSize, L2ExecutionPayloadTreeExecutionBlockIndex)
+	assert.Nil(t, err)
+	bodyRoot, err := merkelRootFromBranch(payloadRoot, branch[:L1BeaconBlockBodyProofSize],
+		L1BeaconBlockBodyProofSize, L1BeaconBlockBodyTreeExecutionPayloadIndex)
+	assert.Nil(t, err)
+
+	beaconHeader := &BeaconBlockHeader{
+		Slot:       6_000_000,
+		ParentRoot: make([]byte, 32),
+		StateRoot:  make([]byte, 32),
+		BodyRoot:   bodyRoot.Bytes(),
+	}
+	beaconRoot, err := beaconHeader.HashTreeRoot()
+	assert.Nil(t, err)
+
+	db, _ := atlasstate.New(common.Hash{}, atlasstate.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	hs := NewHeaderStore(testChainID)
+	hs.FinalizedHeader = beaconHeader
+	hs.CurrentSyncCommittee = new(SyncCommittee)
+	hs.NextSyncCommittee = new(SyncCommittee)
+	assert.Nil(t, hs.Store(db))
+	assert.Nil(t, hs.StoreExecutionHeader(db, &ExecutionHeader{
+		Number:       header.Number.Uint64(),
+		BlockHash:    header.Hash(),
+		ReceiptsRoot: header.ReceiptHash,
+		BeaconRoot:   beaconRoot,
+	}))
+
+	return db, &TxProve{
+		Receipt:         ethereum.NewReceipt(receipts[txIndex]),
+		Prove:           proof.NodeList(),
+		TxIndex:         txIndex,
+		Header:          header,
+		BeaconHeader:    beaconHeader,
+		ExecutionBranch: branch,
+	}
+}
+
+func TestVerify_Verify(t *testing.T) {
+	db, txProve := makeTxProve(t, 1)
+	input, err := EncodeTxProve(txProve)
+	assert.Nil(t, err)
+
+	logs, err := (&Verify{ChainID: testChainID}).Verify(db, common.Address{}, input)
+	assert.Nil(t, err)
+	want, _ := rlp.EncodeToBytes(txProve.Receipt.Logs)
+	assert.Equal(t, want, logs)
+}
+
+func TestVerify_VerifyInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		chain  uint64
+		modify func(txProve *TxProve)
+	}{
+		{
+			name:   "unknown chain",
+			chain:  1,
+			modify: func(txProve *TxProve) {},
+		},
+		{
+			name:  "not finalized",
+			chain: testChainID,
+			modify: func(txProve *TxProve) {
+				txProve.Header.Number = big.NewInt(8_000_001)
+			},
+		},
+		{
+			name:  "beacon header mismatch",
+			chain: testChainID,
+			modify: func(txProve *TxProve) {
+				txProve.BeaconHeader.Slot++
+			},
+		},
+		{
+			name:  "invalid execution branch",
+			chain: testChainID,
+			modify: func(txProve *TxProve) {
+				txProve.ExecutionBranch[0] = common.Hash{}.Bytes()
+			},
+		},
+		{
+			name:  "receipt mismatch",
+			chain: testChainID,
+			modify: func(txProve *TxProve) {
+				txProve.Receipt = ethereum.NewReceipt(newTestReceipts()[0])
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, txProve := makeTxProve(t, 1)
+			tt.modify(txProve)
+			input, err := EncodeTxProve(txProve)
+			assert.Nil(t, err)
+
+			_, err = (&Verify{ChainID: tt.chain}).Verify(db, common.Address{}, input)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestVerify_NotBeaconProof(t *testing.T) {
+	powProve := struct {
+		Receipt     *ethtypes.Receipt
+		Prove       light.NodeList
+		BlockNumber uint64
+		TxIndex     uint
+	}{Receipt: newTestReceipts()[0]}
+	input, err := rlp.EncodeToBytes(powProve)
+	assert.Nil(t, err)
+
+	_, err = new(Verify).Verify(nil, common.Address{}, input)
+	assert.Equal(t, ErrNotBeaconProof, err)
+
+	// A malformed beacon proof is reported as such, it is not mistaken for a PoW proof
+	_, err = new(Verify).Verify(nil, common.Address{}, append([]byte{TxProveType}, input...))
+	assert.NotNil(t, err)
+	assert.NotEqual(t, ErrNotBeaconProof, err)
+}
+
+// TestExecutionBlockHeaderHash hashes the execution header recorded in the Goerli light client
+// update fixtures, the hash is committed to by the finalized beacon block.
+func TestExecutionBlockHeaderHash(t *testing.T) {
+	recorded := updateV1.finalizedExeHeader
+	header := &ExecutionBlockHeader{
+		ParentHash:  recorded.ParentHash,
+		UncleHash:   recorded.UncleHash,
+		Coinbase:    recorded.Coinbase,
+		Root:        recorded.Root,
+		TxHash:      recorded.TxHash,
+		ReceiptHash: recorded.ReceiptHash,
+		Bloom:       recorded.Bloom,
+		Difficulty:  recorded.Difficulty,
+		Number:      recorded.Number,
+		GasLimit:    recorded.GasLimit,
+		GasUsed:     recorded.GasUsed,
+		Time:        recorded.Time,
+		Extra:       recorded.Extra,
+		MixDigest:   recorded.MixDigest,
+		Nonce:       recorded.Nonce,
+		BaseFee:     recorded.BaseFee,
+	}
+	assert.Equal(t, update.finalizedExecution.BlockHash, header.Hash())
+	assert.Nil(t, verifyExecutionBranch(header.Hash(), updateV1.finalizedHeader.BodyRoot, updateV1.exeFinalityBranch))
+
+	// The fields of the later forks are part of the hash
+	var (
+		zero uint64
+		root = common.Hash{1}
+	)
+	for _, modify := range []func(h *ExecutionBlockHeader){
+		func(h *ExecutionBlockHeader) { h.WithdrawalsHash = &root },
+		func(h *ExecutionBlockHeader) { h.BlobGasUsed, h.ExcessBlobGas = &zero, &zero },
+		func(h *ExecutionBlockHeader) { h.ParentBeaconRoot = &root },
+		func(h *ExecutionBlockHeader) { h.RequestsHash = &root },
+	} {
+		prev := header.Hash()
+		modify(header)
+		assert.NotEqual(t, prev, header.Hash())
+	}
+	var decoded ExecutionBlockHeader
+	data, err := rlp.EncodeToBytes(header)
+	assert.Nil(t, err)
+	assert.Nil(t, rlp.DecodeBytes(data, &decoded))
+	assert.Equal(t, header.Hash(), decoded.Hash())
+}
+
+func TestVerify_VerifyBatch(t *testing.T) {
+	db, txProve := makeTxProve(t, 1)
+	_, otherProve := makeTxProve(t, 2)
+	var txProves [][]byte
+	for _, p := range []*TxProve{txProve, otherProve} {
+		input, err := EncodeTxProve(p)
+		assert.Nil(t, err)
+		txProves = append(txProves, input)
+	}
+	powProve, _ := rlp.EncodeToBytes(struct {
+		Receipt     *ethtypes.Receipt
+		Prove       light.NodeList
+		BlockNumber uint64
+		TxIndex     uint
+	}{Receipt: newTestReceipts()[0]})
+	txProves = append(txProves, powProve)
+
+	logs, errs := (&Verify{ChainID: testChainID}).VerifyBatch(db, make([]common.Address, len(txProves)), txProves)
+	for i, p := range []*TxProve{txProve, otherProve} {
+		assert.Nil(t, errs[i])
+		want, _ := rlp.EncodeToBytes(p.Receipt.Logs)
+		assert.Equal(t, want, logs[i])
+	}
+	assert.Equal(t, ErrNotBeaconProof, errs[2])
+}
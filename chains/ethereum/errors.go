@@ -8,4 +8,5 @@ var (
 	errFutureBlock     = errors.New("block in the future")
 	errInvalidNumber   = errors.New("invalid block number")
 	errNotSupportChain = errors.New("not supported chain")
+	errUnknownHeader   = errors.New("unknown header")
 )
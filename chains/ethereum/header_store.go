@@ -357,9 +357,10 @@ type headerWriteResult struct {
 	imported   []*params.NumberHash
 	lastHash   common.Hash
 	lastNumber uint64
+	reorg      *params.Reorg
 }
 
-func (hs *HeaderStore) InsertHeaders(db types.StateDB, ethHeaders []byte) ([]*params.NumberHash, error) {
+func (hs *HeaderStore) InsertHeaders(db types.StateDB, ethHeaders []byte) ([]*params.NumberHash, *params.Reorg, error) {
 	start := time.Now()
 	res, err := hs.WriteHeaders(db, ethHeaders)
 	// Report some public statistics so the user has a clue what's going on
@@ -377,8 +378,33 @@ func (hs *HeaderStore) InsertHeaders(db types.StateDB, ethHeaders []byte) ([]*pa
 	if res.ignored > 0 {
 		context = append(context, []interface{}{"ignored", res.ignored}...)
 	}
+	if res.reorg != nil {
+		context = append(context, "reorgDepth", res.reorg.Depth, "ancestor", res.reorg.Ancestor.Number)
+	}
 	log.Info("stored new ethereum block headers", context...)
-	return res.imported, err
+	return res.imported, res.reorg, err
+}
+
+// findAncestor returns the latest header of the given chain, or of its ancestors, that is
+// part of the current canonical chain. It must be called before the canonical hashes are
+// rewritten.
+func (hs *HeaderStore) findAncestor(db types.StateDB, headers []*Header) (*params.NumberHash, error) {
+	for i := len(headers) - 1; i >= 0; i-- {
+		hash, number := headers[i].Hash(), headers[i].Number.Uint64()
+		if number <= hs.CurNumber && hs.ReadCanonicalHash(number, db) == hash {
+			return &params.NumberHash{Number: number, Hash: hash}, nil
+		}
+	}
+
+	hash, number := headers[0].ParentHash, headers[0].Number.Uint64()-1
+	for hs.ReadCanonicalHash(number, db) != hash {
+		header := hs.GetHeader(hash, number, db)
+		if header == nil || number == 0 {
+			return nil, fmt.Errorf("not found header, number: %d, hash: %s", number, hash)
+		}
+		hash, number = header.ParentHash, number-1
+	}
+	return &params.NumberHash{Number: number, Hash: hash}, nil
 }
 
 func (hs *HeaderStore) WriteHeaders(db types.StateDB, ethHeaders []byte) (*headerWriteResult, error) {
@@ -455,8 +481,22 @@ func (hs *HeaderStore) WriteHeaders(db types.StateDB, ethHeaders []byte) (*heade
 	// we don't have to go backwards to delete canon blocks, but
 	// simply pile them onto the existing chain
 	chainAlreadyCanon := headers[0].ParentHash == hs.CurHash
+	var reorgInfo *params.Reorg
 	if reorg {
 		if !chainAlreadyCanon {
+			ancestor, err := hs.findAncestor(db, headers)
+			if err != nil {
+				return &headerWriteResult{}, err
+			}
+			if ancestor.Number < hs.CurNumber {
+				reorgInfo = &params.Reorg{
+					OldHead:  params.NumberHash{Number: hs.CurNumber, Hash: hs.CurHash},
+					NewHead:  params.NumberHash{Number: lastNumber, Hash: lastHash},
+					Ancestor: *ancestor,
+					Depth:    hs.CurNumber - ancestor.Number,
+				}
+			}
+
 			for i := lastNumber + 1; ; i++ {
 				if i <= hs.CurNumber-MaxHeaderLimit+1 {
 					log.Info("chainAlreadyCanon=false, obsolete block", "current", hs.CurNumber, "calNumber", i)
@@ -522,6 +562,7 @@ func (hs *HeaderStore) WriteHeaders(db types.StateDB, ethHeaders []byte) (*heade
 		imported:   inserted,
 		lastHash:   lastHash,
 		lastNumber: lastNumber,
+		reorg:      reorgInfo,
 	}, nil
 }
 
@@ -563,3 +604,26 @@ func (hs *HeaderStore) GetHashByNumber(db types.StateDB, number uint64) (common.
 	}
 	return hs.ReadCanonicalHash(number, db), nil
 }
+
+// Checkpoint returns the canonical header with the given number, or the current head if
+// number is above it.
+func (hs *HeaderStore) Checkpoint(db types.StateDB, number uint64) (*chains.Checkpoint, error) {
+	if err := hs.Load(db); err != nil {
+		return nil, err
+	}
+	if number > hs.CurNumber {
+		number = hs.CurNumber
+	}
+	hash := hs.ReadCanonicalHash(number, db)
+	header := hs.GetHeader(hash, number, db)
+	td := hs.GetTd(hash, number, db)
+	if header == nil || td == nil || header.Number.Uint64() != number {
+		return nil, errUnknownHeader
+	}
+	return &chains.Checkpoint{
+		Number: number,
+		Hash:   hash,
+		TD:     td,
+		Header: encodeHeader(header),
+	}, nil
+}
@@ -0,0 +1,127 @@
+package ethereum
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// Transaction types whose receipts are proven. The receipts of all typed transactions
+// share the same payload, geth 1.10 only knows the types up to the dynamic fee one.
+const (
+	BlobTxType    = 0x03 // EIP-4844, since Cancun
+	SetCodeTxType = 0x04 // EIP-7702, since Prague
+)
+
+var (
+	errEmptyTypedReceipt       = errors.New("empty typed receipt bytes")
+	errUnsupportedReceiptType  = errors.New("receipt type not supported")
+	receiptStatusSuccessfulRLP = []byte{0x01}
+)
+
+// Receipt is the consensus encoding of a receipt of a relayed chain, legacy or typed.
+type Receipt struct {
+	Type              uint8
+	PostStateOrStatus []byte
+	CumulativeGasUsed uint64
+	Bloom             ethtypes.Bloom
+	Logs              []*ethtypes.Log
+}
+
+type receiptRLP struct {
+	PostStateOrStatus []byte
+	CumulativeGasUsed uint64
+	Bloom             ethtypes.Bloom
+	Logs              []*ethtypes.Log
+}
+
+// NewReceipt returns the consensus fields of a geth receipt.
+func NewReceipt(r *ethtypes.Receipt) *Receipt {
+	status := r.PostState
+	if len(status) == 0 && r.Status == ethtypes.ReceiptStatusSuccessful {
+		status = receiptStatusSuccessfulRLP
+	}
+	return &Receipt{
+		Type:              r.Type,
+		PostStateOrStatus: status,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		Bloom:             r.Bloom,
+		Logs:              r.Logs,
+	}
+}
+
+func supportedReceiptType(typ uint8) bool {
+	return typ <= SetCodeTxType
+}
+
+// EncodeRLP implements rlp.Encoder, typed receipts are wrapped in a byte string.
+func (r *Receipt) EncodeRLP(w io.Writer) error {
+	data, err := r.ConsensusEncoding()
+	if err != nil {
+		return err
+	}
+	if r.Type == ethtypes.LegacyTxType {
+		_, err = w.Write(data)
+		return err
+	}
+	return rlp.Encode(w, data)
+}
+
+// DecodeRLP implements rlp.Decoder.
+func (r *Receipt) DecodeRLP(s *rlp.Stream) error {
+	kind, _, err := s.Kind()
+	if err != nil {
+		return err
+	}
+	var dec receiptRLP
+	if kind == rlp.List {
+		if err := s.Decode(&dec); err != nil {
+			return err
+		}
+		r.setFromRLP(ethtypes.LegacyTxType, &dec)
+		return nil
+	}
+
+	b, err := s.Bytes()
+	if err != nil {
+		return err
+	}
+	if len(b) == 0 {
+		return errEmptyTypedReceipt
+	}
+	if b[0] == ethtypes.LegacyTxType || !supportedReceiptType(b[0]) {
+		return fmt.Errorf("%w: %d", errUnsupportedReceiptType, b[0])
+	}
+	if err := rlp.DecodeBytes(b[1:], &dec); err != nil {
+		return err
+	}
+	r.setFromRLP(b[0], &dec)
+	return nil
+}
+
+func (r *Receipt) setFromRLP(typ uint8, dec *receiptRLP) {
+	r.Type = typ
+	r.PostStateOrStatus = dec.PostStateOrStatus
+	r.CumulativeGasUsed = dec.CumulativeGasUsed
+	r.Bloom = dec.Bloom
+	r.Logs = dec.Logs
+}
+
+// ConsensusEncoding returns the encoding of the receipt in the receipts trie.
+func (r *Receipt) ConsensusEncoding() ([]byte, error) {
+	if !supportedReceiptType(r.Type) {
+		return nil, fmt.Errorf("%w: %d", errUnsupportedReceiptType, r.Type)
+	}
+	var buf bytes.Buffer
+	if r.Type != ethtypes.LegacyTxType {
+		buf.WriteByte(r.Type)
+	}
+	if err := rlp.Encode(&buf, &receiptRLP{r.PostStateOrStatus, r.CumulativeGasUsed, r.Bloom, r.Logs}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,79 @@
+package ethereum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb/memorydb"
+	"github.com/ethereum/go-ethereum/light"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTypedReceipt(typ uint8) *Receipt {
+	return &Receipt{
+		Type:              typ,
+		PostStateOrStatus: receiptStatusSuccessfulRLP,
+		CumulativeGasUsed: 21000 * uint64(typ+1),
+		Logs: []*ethtypes.Log{{
+			Address: common.BigToAddress(big.NewInt(int64(typ + 1))),
+			Topics:  []common.Hash{common.BigToHash(big.NewInt(int64(typ)))},
+			Data:    []byte{typ},
+		}},
+	}
+}
+
+func TestReceipt_TypedProofs(t *testing.T) {
+	types := []uint8{ethtypes.LegacyTxType, ethtypes.AccessListTxType, ethtypes.DynamicFeeTxType, BlobTxType, SetCodeTxType}
+	tr, err := trie.New(common.Hash{}, trie.NewDatabase(memorydb.New()))
+	assert.Nil(t, err)
+	for i, typ := range types {
+		key, _ := rlp.EncodeToBytes(uint(i))
+		value, err := newTypedReceipt(typ).ConsensusEncoding()
+		assert.Nil(t, err)
+		tr.Update(key, value)
+	}
+
+	for i, typ := range types {
+		proof := light.NewNodeSet()
+		key, _ := rlp.EncodeToBytes(uint(i))
+		assert.Nil(t, tr.Prove(key, 0, proof))
+		input, err := rlp.EncodeToBytes(&TxProve{Receipt: newTypedReceipt(typ), Prove: proof.NodeList(), TxIndex: uint(i)})
+		assert.Nil(t, err)
+
+		txProve, err := DecodeTxProve(input)
+		assert.Nil(t, err)
+		assert.Equal(t, typ, txProve.Receipt.Type)
+		assert.Equal(t, newTypedReceipt(typ).Logs[0].Data, txProve.Receipt.Logs[0].Data)
+		assert.Nil(t, VerifyProof(tr.Hash(), txProve), "type %d", typ)
+	}
+}
+
+func TestReceipt_GethEncoding(t *testing.T) {
+	// the receipts geth knows are encoded the same way
+	for _, typ := range []uint8{ethtypes.LegacyTxType, ethtypes.AccessListTxType, ethtypes.DynamicFeeTxType} {
+		r := &ethtypes.Receipt{Type: typ, Status: ethtypes.ReceiptStatusSuccessful, CumulativeGasUsed: 21000, Logs: []*ethtypes.Log{}}
+		want, err := rlp.EncodeToBytes(r)
+		assert.Nil(t, err)
+		have, err := rlp.EncodeToBytes(NewReceipt(r))
+		assert.Nil(t, err)
+		assert.Equal(t, want, have)
+	}
+}
+
+func TestReceipt_UnsupportedType(t *testing.T) {
+	data, err := (&Receipt{Type: ethtypes.DynamicFeeTxType, Logs: []*ethtypes.Log{}}).ConsensusEncoding()
+	assert.Nil(t, err)
+	for _, typ := range []uint8{ethtypes.LegacyTxType, SetCodeTxType + 1, 0x7e} {
+		data[0] = typ
+		input, _ := rlp.EncodeToBytes(data)
+		err := rlp.DecodeBytes(input, new(Receipt))
+		assert.ErrorIs(t, err, errUnsupportedReceiptType, "type %d", typ)
+	}
+
+	_, err = (&Receipt{Type: SetCodeTxType + 1}).ConsensusEncoding()
+	assert.ErrorIs(t, err, errUnsupportedReceiptType)
+}
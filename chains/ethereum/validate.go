@@ -22,7 +22,9 @@ const (
 	allowedFutureBlockTimeSeconds = int64(15)
 )
 
-type Validate struct{}
+type Validate struct {
+	LondonBlock *big.Int // EIP-1559 fork block of the chain, looked up by chain type if nil
+}
 
 func (v *Validate) ValidateHeaderChain(db types.StateDB, headers []byte, chainType chains.ChainType) (int, error) {
 	var chain []*Header
@@ -188,7 +190,10 @@ func (v *Validate) verifyHeader(header, parent *Header, uncle bool, unixNow int6
 	}
 
 	// Verify the block's gas usage and (if applicable) verify the base fee.
-	lb, _ := chains.ChainType2LondonBlock(chainType)
+	lb := v.LondonBlock
+	if lb == nil {
+		lb, _ = chains.ChainType2LondonBlock(chainType)
+	}
 	cfg := &ethparams.ChainConfig{LondonBlock: lb}
 	if !cfg.IsLondon(header.Number) {
 		// Verify BaseFee not present before EIP-1559 fork.
@@ -5,12 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
-	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/light"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/trie"
 
 	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/tools"
 )
 
 var (
@@ -21,17 +21,18 @@ var (
 )
 
 type TxProve struct {
-	Receipt     *ethtypes.Receipt
+	Receipt     *Receipt
 	Prove       light.NodeList
 	BlockNumber uint64
 	TxIndex     uint
 }
 
 type Verify struct {
+	Confirmations uint64 // confirmations the block of the receipt needs, 0 to accept the head
 }
 
 func (v *Verify) Verify(db types.StateDB, routerContractAddr common.Address, txProveBytes []byte) (logs []byte, err error) {
-	txProve, err := v.decode(txProveBytes)
+	txProve, err := DecodeTxProve(txProveBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -45,17 +46,21 @@ func (v *Verify) Verify(db types.StateDB, routerContractAddr common.Address, txP
 		return nil, err
 	}
 
-	if err := v.verifyProof(receiptsRoot, txProve); err != nil {
+	if err := VerifyProof(receiptsRoot, txProve); err != nil {
 		return nil, err
 	}
 	return rlp.EncodeToBytes(txProve.Receipt.Logs)
 }
 
-func (v *Verify) decode(txProveBytes []byte) (*TxProve, error) {
+// DecodeTxProve decodes a receipt proof.
+func DecodeTxProve(txProveBytes []byte) (*TxProve, error) {
 	var txProve TxProve
 	if err := rlp.DecodeBytes(txProveBytes, &txProve); err != nil {
 		return nil, err
 	}
+	if txProve.Receipt == nil {
+		return nil, errors.New("receipt cannot be empty")
+	}
 	return &txProve, nil
 }
 
@@ -70,11 +75,76 @@ func (v *Verify) decode(txProveBytes []byte) (*TxProve, error) {
 //	return nil, fmt.Errorf("not found event log, router contract addr: %v, event hash: %v", routerContractAddr, EventHash)
 //}
 
+// VerifyBatch verifies many proofs, the receipts root of every block is looked up once
+// and the proofs are checked in parallel.
+func (v *Verify) VerifyBatch(db types.StateDB, routers []common.Address, txProves [][]byte) ([][]byte, []error) {
+	hs := NewHeaderStore()
+	if err := hs.Load(db); err != nil {
+		errs := make([]error, len(txProves))
+		for i := range errs {
+			errs[i] = err
+		}
+		return make([][]byte, len(txProves)), errs
+	}
+	return VerifyProofs(txProves, func(number uint64) (common.Hash, error) {
+		return v.receiptsRoot(db, hs, number)
+	})
+}
+
+// VerifyProofs verifies receipt proofs of any chain with Ethereum receipt tries and
+// returns the logs of every proven receipt. receiptsRoot is called once per block, the
+// proofs are then checked in parallel.
+func VerifyProofs(txProves [][]byte, receiptsRoot func(number uint64) (common.Hash, error)) ([][]byte, []error) {
+	var (
+		logs   = make([][]byte, len(txProves))
+		errs   = make([]error, len(txProves))
+		proves = make([]*TxProve, len(txProves))
+		roots  = make([]common.Hash, len(txProves))
+	)
+
+	type lookup struct {
+		root common.Hash
+		err  error
+	}
+	lookups := make(map[uint64]*lookup)
+	for i, txProveBytes := range txProves {
+		if proves[i], errs[i] = DecodeTxProve(txProveBytes); errs[i] != nil {
+			continue
+		}
+		number := proves[i].BlockNumber
+		l, ok := lookups[number]
+		if !ok {
+			l = new(lookup)
+			l.root, l.err = receiptsRoot(number)
+			lookups[number] = l
+		}
+		roots[i], errs[i] = l.root, l.err
+	}
+
+	tools.ParallelFor(len(txProves), func(i int) {
+		if errs[i] != nil {
+			return
+		}
+		if errs[i] = VerifyProof(roots[i], proves[i]); errs[i] != nil {
+			return
+		}
+		logs[i], errs[i] = rlp.EncodeToBytes(proves[i].Receipt.Logs)
+	})
+	return logs, errs
+}
+
 func (v *Verify) getReceiptsRoot(db types.StateDB, blockNumber uint64) (common.Hash, error) {
 	hs := NewHeaderStore()
 	if err := hs.Load(db); err != nil {
 		return common.Hash{}, err
 	}
+	return v.receiptsRoot(db, hs, blockNumber)
+}
+
+func (v *Verify) receiptsRoot(db types.StateDB, hs *HeaderStore, blockNumber uint64) (common.Hash, error) {
+	if err := CheckConfirmed(blockNumber, hs.CurNumber, v.Confirmations); err != nil {
+		return common.Hash{}, err
+	}
 	header := hs.GetHeaderByNumber(blockNumber, db)
 	if header == nil {
 		return common.Hash{}, fmt.Errorf("get header by number failed, number: %d", blockNumber)
@@ -83,16 +153,32 @@ func (v *Verify) getReceiptsRoot(db types.StateDB, blockNumber uint64) (common.H
 	return header.ReceiptHash, nil
 }
 
-func (v *Verify) verifyProof(receiptsRoot common.Hash, txProve *TxProve) error {
-	var buf bytes.Buffer
-	rs := ethtypes.Receipts{txProve.Receipt}
-	rs.EncodeIndex(0, &buf)
-	giveReceipt := buf.Bytes()
+// CheckConfirmed returns an error unless the block with the given number has at least
+// confirmations blocks on top of it, counting itself, in a chain whose head is current.
+func CheckConfirmed(number, current, confirmations uint64) error {
+	if number > current || current-number+1 < confirmations {
+		return fmt.Errorf("block is not confirmed, number: %d, current: %d, confirmations: %d", number, current, confirmations)
+	}
+	return nil
+}
+
+// VerifyProof checks that the receipt of the proof is in the receipts trie with the given root.
+func VerifyProof(receiptsRoot common.Hash, txProve *TxProve) error {
+	return VerifyReceipt(receiptsRoot, txProve.Receipt, txProve.TxIndex, txProve.Prove)
+}
+
+// VerifyReceipt checks that the receipt is the one at txIndex in the receipts trie with
+// the given root.
+func VerifyReceipt(receiptsRoot common.Hash, receipt *Receipt, txIndex uint, prove light.NodeList) error {
+	giveReceipt, err := receipt.ConsensusEncoding()
+	if err != nil {
+		return err
+	}
 
 	var key []byte
-	key = rlp.AppendUint64(key[:0], uint64(txProve.TxIndex))
+	key = rlp.AppendUint64(key[:0], uint64(txIndex))
 
-	getReceipt, err := trie.VerifyProof(receiptsRoot, key, txProve.Prove.NodeSet())
+	getReceipt, err := trie.VerifyProof(receiptsRoot, key, prove.NodeSet())
 	if err != nil {
 		return err
 	}
@@ -147,7 +147,7 @@ func getTxProve(blockNumber uint64, txIndex uint, receiptsJSON string) []byte {
 	}
 
 	txProve := TxProve{
-		Receipt:     receipts[txIndex],
+		Receipt:     NewReceipt(receipts[txIndex]),
 		Prove:       proof.NodeList(),
 		BlockNumber: blockNumber,
 		TxIndex:     txIndex,
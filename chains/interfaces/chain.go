@@ -1,16 +1,13 @@
 package interfaces
 
 import (
-	"errors"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/mapprotocol/atlas/chains"
-	"github.com/mapprotocol/atlas/chains/ethereum"
 	"github.com/mapprotocol/atlas/core/types"
 	"github.com/mapprotocol/atlas/params"
-
 )
 
 type IChain interface {
@@ -31,7 +28,7 @@ func (c *Chain) ResetHeaderStore(db types.StateDB, header []byte, td *big.Int) e
 	return c.HeaderStore.ResetHeaderStore(db, header, td)
 }
 
-func (c *Chain) InsertHeaders(db types.StateDB, headers []byte) ([]*params.NumberHash, error) {
+func (c *Chain) InsertHeaders(db types.StateDB, headers []byte) ([]*params.NumberHash, *params.Reorg, error) {
 	return c.HeaderStore.InsertHeaders(db, headers)
 }
 
@@ -43,14 +40,13 @@ func (c *Chain) GetHashByNumber(db types.StateDB, number uint64) (common.Hash, e
 	return c.HeaderStore.GetHashByNumber(db, number)
 }
 
-func ChainFactory(group chains.ChainGroup) (IChain, error) {
-	switch group {
-	case chains.ChainGroupETH:
-		return &Chain{
-			Validate:    new(ethereum.Validate),
-			HeaderStore: new(ethereum.HeaderStore),
-		}, nil
+func ChainFactory(info *chains.ChainInfo) (IChain, error) {
+	family, err := lookupFamily(info.Group)
+	if err != nil {
+		return nil, err
 	}
-
-	return nil, errors.New("not support chain")
+	return &Chain{
+		Validate:    family.NewValidate(info),
+		HeaderStore: family.NewHeaderStore(info.Type),
+	}, nil
 }
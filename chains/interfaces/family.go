@@ -0,0 +1,79 @@
+package interfaces
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/bsc"
+	"github.com/mapprotocol/atlas/chains/eth2"
+	"github.com/mapprotocol/atlas/chains/ethereum"
+)
+
+// Family holds the implementations shared by all chains of a chain group. Every chain
+// registered with the group in the chains registry is served by them.
+type Family struct {
+	NewValidate    func(info *chains.ChainInfo) IValidate
+	NewHeaderStore func(chain chains.ChainType) IHeaderStore
+	NewVerify      func(info *chains.ChainInfo) IVerify
+}
+
+var (
+	familyLock sync.RWMutex
+	families   = make(map[chains.ChainGroup]*Family)
+)
+
+func init() {
+	MustRegisterFamily(chains.ChainGroupETH, &Family{
+		NewValidate: func(info *chains.ChainInfo) IValidate {
+			return &ethereum.Validate{LondonBlock: info.LondonBlock}
+		},
+		NewHeaderStore: func(chain chains.ChainType) IHeaderStore { return new(ethereum.HeaderStore) },
+		NewVerify: func(info *chains.ChainInfo) IVerify {
+			v := &ethereumVerify{pow: &ethereum.Verify{Confirmations: info.Confirmations}}
+			if info.ExecutionChainID != 0 {
+				v.beacon = &eth2.Verify{ChainID: info.ExecutionChainID}
+			}
+			return v
+		},
+	})
+	MustRegisterFamily(chains.ChainGroupBSC, &Family{
+		NewValidate:    func(info *chains.ChainInfo) IValidate { return new(bsc.Validate) },
+		NewHeaderStore: func(chain chains.ChainType) IHeaderStore { return bsc.NewHeaderStore(chain) },
+		NewVerify: func(info *chains.ChainInfo) IVerify {
+			return &bsc.Verify{ChainType: info.Type, Confirmations: info.Confirmations}
+		},
+	})
+}
+
+// RegisterFamily adds the implementations of a chain group, a group can be registered only once.
+func RegisterFamily(group chains.ChainGroup, family *Family) error {
+	if family == nil || family.NewValidate == nil || family.NewHeaderStore == nil || family.NewVerify == nil {
+		return fmt.Errorf("incomplete chain family %d", group)
+	}
+
+	familyLock.Lock()
+	defer familyLock.Unlock()
+	if _, ok := families[group]; ok {
+		return fmt.Errorf("chain family %d already registered", group)
+	}
+	families[group] = family
+	return nil
+}
+
+// MustRegisterFamily is like RegisterFamily but panics if the family can not be registered.
+func MustRegisterFamily(group chains.ChainGroup, family *Family) {
+	if err := RegisterFamily(group, family); err != nil {
+		panic(err)
+	}
+}
+
+func lookupFamily(group chains.ChainGroup) (*Family, error) {
+	familyLock.RLock()
+	defer familyLock.RUnlock()
+	family, ok := families[group]
+	if !ok {
+		return nil, chains.ErrNotSupportChain
+	}
+	return family, nil
+}
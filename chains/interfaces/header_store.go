@@ -6,7 +6,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/mapprotocol/atlas/chains"
-	"github.com/mapprotocol/atlas/chains/ethereum"
 	"github.com/mapprotocol/atlas/core/types"
 	"github.com/mapprotocol/atlas/params"
 )
@@ -18,15 +17,41 @@ type StoreLoad interface {
 
 type IHeaderStore interface {
 	ResetHeaderStore(db types.StateDB, header []byte, td *big.Int) error
-	InsertHeaders(db types.StateDB, headers []byte) ([]*params.NumberHash, error)
+	InsertHeaders(db types.StateDB, headers []byte) ([]*params.NumberHash, *params.Reorg, error)
 	GetCurrentNumberAndHash(db types.StateDB) (uint64, common.Hash, error)
 	GetHashByNumber(db types.StateDB, number uint64) (common.Hash, error)
 }
 
-func HeaderStoreFactory(group chains.ChainGroup) (IHeaderStore, error) {
-	switch group {
-	case chains.ChainGroupETH:
-		return new(ethereum.HeaderStore), nil
+// ICheckpoint is implemented by the header stores a trusted checkpoint can be
+// exported from.
+type ICheckpoint interface {
+	// Checkpoint returns the unsigned checkpoint of the canonical header at or below
+	// number that ResetHeaderStore can start the store from.
+	Checkpoint(db types.StateDB, number uint64) (*chains.Checkpoint, error)
+}
+
+func HeaderStoreFactory(group chains.ChainGroup, chain chains.ChainType) (IHeaderStore, error) {
+	family, err := lookupFamily(group)
+	if err != nil {
+		return nil, err
+	}
+	return family.NewHeaderStore(chain), nil
+}
+
+// IsConfirmed returns whether the canonical header with the given number has been followed
+// by enough headers in the store to reach the given number of confirmations. The head
+// itself has one confirmation.
+func IsConfirmed(db types.StateDB, hs IHeaderStore, number, confirmations uint64) (bool, error) {
+	current, _, err := hs.GetCurrentNumberAndHash(db)
+	if err != nil {
+		return false, err
+	}
+	if number > current || current-number+1 < confirmations {
+		return false, nil
+	}
+	hash, err := hs.GetHashByNumber(db, number)
+	if err != nil {
+		return false, err
 	}
-	return nil, chains.ErrNotSupportChain
+	return hash != (common.Hash{}), nil
 }
@@ -1,10 +1,12 @@
 package interfaces
 
 import (
+	"errors"
+
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/mapprotocol/atlas/chains"
-	"github.com/mapprotocol/atlas/chains/ethereum"
+	"github.com/mapprotocol/atlas/chains/eth2"
 	"github.com/mapprotocol/atlas/core/types"
 )
 
@@ -12,10 +14,99 @@ type IVerify interface {
 	Verify(db types.StateDB, router common.Address, txProveBytes []byte) (logs []byte, err error)
 }
 
-func VerifyFactory(group chains.ChainGroup) (IVerify, error) {
-	switch group {
-	case chains.ChainGroupETH:
-		return new(ethereum.Verify), nil
+// IBatchVerify is implemented by verifiers that share header lookups between proofs and
+// check them in parallel.
+type IBatchVerify interface {
+	VerifyBatch(db types.StateDB, routers []common.Address, txProves [][]byte) (logs [][]byte, errs []error)
+}
+
+// VerifyBatch verifies the proofs with v, one by one if it can not verify batches.
+func VerifyBatch(db types.StateDB, v IVerify, routers []common.Address, txProves [][]byte) ([][]byte, []error) {
+	if bv, ok := v.(IBatchVerify); ok {
+		return bv.VerifyBatch(db, routers, txProves)
+	}
+	logs, errs := make([][]byte, len(txProves)), make([]error, len(txProves))
+	for i := range txProves {
+		logs[i], errs[i] = v.Verify(db, routers[i], txProves[i])
+	}
+	return logs, errs
+}
+
+func VerifyFactory(info *chains.ChainInfo) (IVerify, error) {
+	family, err := lookupFamily(info.Group)
+	if err != nil {
+		return nil, err
+	}
+	return family.NewVerify(info), nil
+}
+
+// errUnknownProofType is returned for proofs neither typed as beacon-finalized proofs nor
+// encoded as ethash receipt proofs
+var errUnknownProofType = errors.New("unknown receipt proof type")
+
+// errNoBeaconStore is returned for beacon-finalized proofs of a chain without an eth2
+// light client store
+var errNoBeaconStore = errors.New("chain has no eth2 light client store")
+
+// ethereumVerify verifies proofs of post-merge blocks against the eth2 light client store,
+// and untyped proofs of PoW blocks against the ethash header store.
+type ethereumVerify struct {
+	beacon IVerify
+	pow    IVerify
+}
+
+// isPoWProof reports whether the proof is an untyped ethash receipt proof, an RLP list.
+func isPoWProof(txProveBytes []byte) bool {
+	return len(txProveBytes) > 0 && txProveBytes[0] >= 0xc0
+}
+
+func (v *ethereumVerify) verifier(txProveBytes []byte) (IVerify, error) {
+	switch {
+	case eth2.IsBeaconProof(txProveBytes):
+		if v.beacon == nil {
+			return nil, errNoBeaconStore
+		}
+		return v.beacon, nil
+	case isPoWProof(txProveBytes):
+		return v.pow, nil
+	}
+	return nil, errUnknownProofType
+}
+
+func (v *ethereumVerify) Verify(db types.StateDB, router common.Address, txProveBytes []byte) ([]byte, error) {
+	verifier, err := v.verifier(txProveBytes)
+	if err != nil {
+		return nil, err
+	}
+	return verifier.Verify(db, router, txProveBytes)
+}
+
+func (v *ethereumVerify) VerifyBatch(db types.StateDB, routers []common.Address, txProves [][]byte) ([][]byte, []error) {
+	logs, errs := make([][]byte, len(txProves)), make([]error, len(txProves))
+
+	// The proofs of each type are verified in one batch
+	indexes := make(map[IVerify][]int)
+	for i, txProve := range txProves {
+		verifier, err := v.verifier(txProve)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		indexes[verifier] = append(indexes[verifier], i)
+	}
+	for _, verifier := range []IVerify{v.beacon, v.pow} {
+		idx := indexes[verifier]
+		if len(idx) == 0 {
+			continue
+		}
+		batchRouters, batchProves := make([]common.Address, len(idx)), make([][]byte, len(idx))
+		for j, i := range idx {
+			batchRouters[j], batchProves[j] = routers[i], txProves[i]
+		}
+		batchLogs, batchErrs := VerifyBatch(db, verifier, batchRouters, batchProves)
+		for j, i := range idx {
+			logs[i], errs[i] = batchLogs[j], batchErrs[j]
+		}
 	}
-	return nil, chains.ErrNotSupportChain
+	return logs, errs
 }
@@ -0,0 +1,28 @@
+package interfaces
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/eth2"
+)
+
+func TestVerifyFactory_BeaconStore(t *testing.T) {
+	// the eth2 store of Ethereum is kept under the chain id of the execution chain
+	info, err := chains.Lookup(chains.ChainTypeETH)
+	assert.Nil(t, err)
+	v, err := VerifyFactory(info)
+	assert.Nil(t, err)
+	assert.Equal(t, &eth2.Verify{ChainID: 1}, v.(*ethereumVerify).beacon)
+
+	// a chain without one rejects beacon-finalized proofs
+	info, err = chains.Lookup(chains.ChainTypeETHTest)
+	assert.Nil(t, err)
+	v, err = VerifyFactory(info)
+	assert.Nil(t, err)
+	_, err = v.Verify(nil, common.Address{}, []byte{eth2.TxProveType})
+	assert.Equal(t, errNoBeaconStore, err)
+}
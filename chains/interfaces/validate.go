@@ -2,7 +2,6 @@ package interfaces
 
 import (
 	"github.com/mapprotocol/atlas/chains"
-	"github.com/mapprotocol/atlas/chains/ethereum"
 	"github.com/mapprotocol/atlas/core/types"
 )
 
@@ -10,12 +9,12 @@ type IValidate interface {
 	ValidateHeaderChain(db types.StateDB, headers []byte, chainType chains.ChainType) (int, error)
 }
 
-func ValidateFactory(group chains.ChainGroup) (IValidate, error) {
-	switch group {
-	case chains.ChainGroupETH:
-		return new(ethereum.Validate), nil
+func ValidateFactory(info *chains.ChainInfo) (IValidate, error) {
+	family, err := lookupFamily(info.Group)
+	if err != nil {
+		return nil, err
 	}
-	return nil, chains.ErrNotSupportChain
+	return family.NewValidate(info), nil
 }
 
 
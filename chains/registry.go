@@ -0,0 +1,162 @@
+package chains
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/mapprotocol/atlas/params"
+)
+
+// ChainInfo holds the parameters of a chain whose headers can be relayed to Atlas.
+type ChainInfo struct {
+	Type        ChainType  // chain identifier used by the header store and tx verify contracts
+	Group       ChainGroup // chain family implementing validate, header store and verify, 0 for Atlas itself
+	ChainID     uint64     // Atlas chain id on which the header store of this chain can be reset
+	LondonBlock *big.Int   // EIP-1559 fork block of the relayed chain (nil = no fork)
+
+	// Chain id of the relayed network itself, its eth2 light client store is kept under
+	// it (0 = no beacon-finalized proofs)
+	ExecutionChainID uint64
+
+	// Number of confirmations a block needs on the relayed canonical chain before
+	// proofs against it are accepted (0 = accept the head)
+	Confirmations uint64
+
+	// Trusted checkpoint the header store of this chain starts from
+	GenesisHeader []byte
+	GenesisTD     *big.Int
+
+	// Atlas block from which the chain is supported (nil = always)
+	ActivationBlock *big.Int
+}
+
+// IsActive returns whether the chain is supported at the given Atlas block.
+func (c *ChainInfo) IsActive(number *big.Int) bool {
+	if c.ActivationBlock == nil || number == nil {
+		return true
+	}
+	return c.ActivationBlock.Cmp(number) <= 0
+}
+
+type registry struct {
+	lock   sync.RWMutex
+	chains map[ChainType]*ChainInfo
+}
+
+var defaultRegistry = &registry{
+	chains: make(map[ChainType]*ChainInfo),
+}
+
+// Register adds a chain to the registry, a chain type can be registered only once.
+func Register(info *ChainInfo) error {
+	if info == nil {
+		return fmt.Errorf("chain info cannot be nil")
+	}
+
+	defaultRegistry.lock.Lock()
+	defer defaultRegistry.lock.Unlock()
+	if _, ok := defaultRegistry.chains[info.Type]; ok {
+		return fmt.Errorf("chain %d already registered", info.Type)
+	}
+	defaultRegistry.chains[info.Type] = info
+	return nil
+}
+
+// MustRegister is like Register but panics if the chain can not be registered.
+func MustRegister(info *ChainInfo) {
+	if err := Register(info); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup returns the registered parameters of the chain.
+func Lookup(chain ChainType) (*ChainInfo, error) {
+	defaultRegistry.lock.RLock()
+	defer defaultRegistry.lock.RUnlock()
+	info, ok := defaultRegistry.chains[chain]
+	if !ok {
+		return nil, ErrNotSupportChain
+	}
+	return info, nil
+}
+
+// RegisteredChains returns the types of all registered chains.
+func RegisteredChains() []ChainType {
+	defaultRegistry.lock.RLock()
+	defer defaultRegistry.lock.RUnlock()
+	types := make([]ChainType, 0, len(defaultRegistry.chains))
+	for t := range defaultRegistry.chains {
+		types = append(types, t)
+	}
+	return types
+}
+
+// CheckRelayChains checks the relay chains of the Atlas chain config. A chain is listed
+// once, and a registered chain it overrides stays supported, so it must be active from
+// genesis.
+func CheckRelayChains(config *params.ChainConfig) error {
+	seen := make(map[uint64]bool)
+	for _, c := range config.RelayChains {
+		if seen[c.ChainType] {
+			return fmt.Errorf("relay chain %d listed twice", c.ChainType)
+		}
+		seen[c.ChainType] = true
+		if err := checkRelayChain(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkRelayChain(c *params.RelayChainConfig) error {
+	if _, err := Lookup(ChainType(c.ChainType)); err == nil && c.ActivationBlock != nil && c.ActivationBlock.Sign() > 0 {
+		return fmt.Errorf("%w: chain %d, activation block %v", ErrRelayChainOverride, c.ChainType, c.ActivationBlock)
+	}
+	return nil
+}
+
+// LookupAt returns the parameters of the chain at the given Atlas block. Chains listed in
+// the relay chains of the Atlas chain config take precedence over the registered ones and
+// are only supported once their activation block is reached.
+func LookupAt(config *params.ChainConfig, number *big.Int, chain ChainType) (*ChainInfo, error) {
+	if config != nil {
+		for _, c := range config.RelayChains {
+			if ChainType(c.ChainType) != chain {
+				continue
+			}
+			if err := checkRelayChain(c); err != nil {
+				return nil, err
+			}
+			info := ChainInfoFromConfig(c)
+			if !info.IsActive(number) {
+				return nil, ErrNotSupportChain
+			}
+			return info, nil
+		}
+	}
+
+	info, err := Lookup(chain)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsActive(number) {
+		return nil, ErrNotSupportChain
+	}
+	return info, nil
+}
+
+// ChainInfoFromConfig converts a relay chain of the Atlas chain config.
+func ChainInfoFromConfig(c *params.RelayChainConfig) *ChainInfo {
+	return &ChainInfo{
+		Type:             ChainType(c.ChainType),
+		Group:            ChainGroup(c.Group),
+		ChainID:          c.ChainID,
+		LondonBlock:      c.LondonBlock,
+		ExecutionChainID: c.ExecutionChainID,
+		Confirmations:    c.Confirmations,
+		GenesisHeader:    c.GenesisHeader,
+		GenesisTD:        c.GenesisTD,
+		ActivationBlock:  c.ActivationBlock,
+	}
+}
@@ -0,0 +1,75 @@
+package chains
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/params"
+)
+
+func TestRegisterBuiltin(t *testing.T) {
+	group, err := ChainType2ChainGroup(ChainTypeETH)
+	assert.Nil(t, err)
+	assert.Equal(t, ChainGroup(ChainGroupETH), group)
+
+	lb, err := ChainType2LondonBlock(ChainTypeETHTest)
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(10_499_401), lb)
+
+	assert.True(t, IsSupportedChain(ChainTypeMAP))
+	_, err = ChainType2ChainGroup(ChainTypeMAP)
+	assert.Equal(t, ErrNotSupportChain, err)
+
+	assert.NotNil(t, Register(&ChainInfo{Type: ChainTypeETH, Group: ChainGroupETH}))
+}
+
+func TestLookupAt(t *testing.T) {
+	const relayed = ChainType(250)
+	config := &params.ChainConfig{
+		RelayChains: []*params.RelayChainConfig{
+			{ChainType: uint64(relayed), Group: ChainGroupETH, ChainID: params.MainNetChainID, ActivationBlock: big.NewInt(100)},
+		},
+	}
+
+	_, err := LookupAt(config, big.NewInt(99), relayed)
+	assert.Equal(t, ErrNotSupportChain, err)
+	info, err := LookupAt(config, big.NewInt(100), relayed)
+	assert.Nil(t, err)
+	assert.Equal(t, ChainGroup(ChainGroupETH), info.Group)
+
+	// registered chains are still served by the config
+	info, err = LookupAt(config, big.NewInt(0), ChainTypeETH)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(params.MainNetChainID), info.ChainID)
+
+	_, err = LookupAt(nil, big.NewInt(100), relayed)
+	assert.Equal(t, ErrNotSupportChain, err)
+}
+
+func TestCheckRelayChains(t *testing.T) {
+	config := &params.ChainConfig{
+		RelayChains: []*params.RelayChainConfig{
+			{ChainType: uint64(ChainTypeBSC), Group: ChainGroupBSC, ChainID: params.MainNetChainID, ActivationBlock: big.NewInt(100)},
+			{ChainType: uint64(ChainTypeETH), Group: ChainGroupETH, ChainID: params.MainNetChainID},
+		},
+	}
+	assert.Nil(t, CheckRelayChains(config))
+
+	// BSC is only supported through the relay chains
+	assert.False(t, IsSupportedChain(ChainTypeBSC))
+	_, err := LookupAt(config, big.NewInt(99), ChainTypeBSC)
+	assert.Equal(t, ErrNotSupportChain, err)
+	_, err = LookupAt(config, big.NewInt(100), ChainTypeBSC)
+	assert.Nil(t, err)
+
+	// a registered chain can not be turned off until a later block
+	config.RelayChains[1].ActivationBlock = big.NewInt(100)
+	assert.ErrorIs(t, CheckRelayChains(config), ErrRelayChainOverride)
+	_, err = LookupAt(config, big.NewInt(100), ChainTypeETH)
+	assert.ErrorIs(t, err, ErrRelayChainOverride)
+
+	config.RelayChains[1] = config.RelayChains[0]
+	assert.NotNil(t, CheckRelayChains(config))
+}
@@ -221,6 +221,10 @@ func init() {
 		utils.ShowDeprecated,
 		// See snapshot.go
 		snapshotCommand,
+		// See headerstorecmd.go
+		headerStoreCommand,
+		// See consensuscmd.go
+		consensusCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
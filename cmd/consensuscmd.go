@@ -0,0 +1,214 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/log"
+	cli "gopkg.in/urfave/cli.v1"
+
+	"github.com/mapprotocol/atlas/cmd/node"
+	"github.com/mapprotocol/atlas/cmd/utils"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/consensus/istanbul/core"
+)
+
+var (
+	consensusCommand = cli.Command{
+		Name:        "consensus",
+		Usage:       "Inspect the consensus round states of validators",
+		Category:    "MISCELLANEOUS COMMANDS",
+		Description: "",
+		Subcommands: []cli.Command{
+			{
+				Name:      "dump-rounds",
+				Usage:     "Export the stored round states as JSON",
+				ArgsUsage: "[<file>]",
+				Action:    utils.MigrateFlags(dumpRounds),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.FromSeqFlag,
+					utils.ToSeqFlag,
+					utils.NodeLabelFlag,
+				},
+				Description: `
+atlas consensus dump-rounds --from-seq <n> --to-seq <m> [<file>]
+writes the round states the validator stored for the sequences n to m, with their
+prepared and round change certificates, to the file or to stdout. The node must
+be stopped, and only the latest sequences survive the round state garbage collection.
+`,
+			},
+			{
+				Name:      "merge-rounds",
+				Usage:     "Merge round state dumps of several validators on one timeline",
+				ArgsUsage: "<dump-file> [<dump-file>...]",
+				Action:    utils.MigrateFlags(mergeRounds),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Description: `
+atlas consensus merge-rounds <dump-file> [<dump-file>...]
+puts the rounds of the dumps on one timeline and prints it as JSON. Rounds that
+ended in a round change blame their proposer if no validator got the proposal,
+and otherwise the validators no one got a prepare nor a commit from.
+`,
+			},
+			{
+				Name:      "simulate-rewards",
+				Usage:     "Simulate the reward payouts of an epoch on a running node",
+				ArgsUsage: "<epoch> [<overrides-file>]",
+				Action:    utils.MigrateFlags(simulateRewards),
+				Category:  "MISCELLANEOUS COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+				},
+				Description: `
+atlas consensus simulate-rewards <epoch> [<overrides-file>]
+asks the node running in the data directory to run the reward path of the last
+block of the epoch without committing it, and prints the payouts of the
+validators, their voters, the community partner and the relayers as JSON.
+The overrides file replaces the uptimes, votes and target rewards read from the
+chain, e.g. {"uptimes": {"<signer>": 0.95}, "voters": ["<account>"],
+"votes": {"<validator>": {"<account>": "0x..."}}, "validatorReward": "0x..."}.
+`,
+			},
+		},
+	}
+)
+
+func dumpRounds(ctx *cli.Context) error {
+	if ctx.NArg() > 1 {
+		utils.Fatalf("This command accepts an optional output file.")
+	}
+	fromSeq, toSeq := ctx.Uint64(utils.FromSeqFlag.Name), ctx.Uint64(utils.ToSeqFlag.Name)
+	if toSeq == 0 {
+		toSeq = ^uint64(0)
+	}
+	if toSeq < fromSeq {
+		utils.Fatalf("--%s must not be lower than --%s.", utils.ToSeqFlag.Name, utils.FromSeqFlag.Name)
+	}
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	path := stack.ResolvePath(istanbul.RoundStateDBName)
+	if path == "" {
+		utils.Fatalf("A data directory is required to dump round states.")
+	}
+	records, err := core.DumpRoundStates(path, fromSeq, toSeq)
+	if err != nil {
+		log.Error("Failed to read round states", "path", path, "err", err)
+		return err
+	}
+	label := ctx.String(utils.NodeLabelFlag.Name)
+	if label == "" {
+		label = stack.DataDir()
+	}
+	dump := &core.RoundStateDump{Node: label, FromSeq: fromSeq, ToSeq: toSeq, Rounds: records}
+
+	out := io.Writer(os.Stdout)
+	if ctx.NArg() == 1 {
+		file, err := os.Create(ctx.Args().First())
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(dump); err != nil {
+		return err
+	}
+	log.Info("Dumped round states", "node", label, "rounds", len(records))
+	return nil
+}
+
+func mergeRounds(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		utils.Fatalf("This command requires at least one dump file.")
+	}
+	dumps := make([]*core.RoundStateDump, 0, ctx.NArg())
+	for _, file := range ctx.Args() {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		dump := new(core.RoundStateDump)
+		if err := json.Unmarshal(data, dump); err != nil {
+			return fmt.Errorf("invalid dump %s: %v", file, err)
+		}
+		if dump.Node == "" {
+			dump.Node = filepath.Base(file)
+		}
+		dumps = append(dumps, dump)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(core.MergeRoundStateDumps(dumps))
+}
+
+func simulateRewards(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		utils.Fatalf("This command requires an epoch and accepts an optional overrides file.")
+	}
+	epoch, err := strconv.ParseUint(ctx.Args().First(), 10, 64)
+	if err != nil {
+		utils.Fatalf("Invalid epoch %q: %v", ctx.Args().First(), err)
+	}
+	var overrides json.RawMessage
+	if ctx.NArg() == 2 {
+		data, err := ioutil.ReadFile(ctx.Args().Get(1))
+		if err != nil {
+			return err
+		}
+		if !json.Valid(data) {
+			return fmt.Errorf("invalid overrides %s", ctx.Args().Get(1))
+		}
+		overrides = data
+	}
+
+	path := node.DefaultDataDir()
+	if ctx.GlobalIsSet(utils.DataDirFlag.Name) {
+		path = ctx.GlobalString(utils.DataDirFlag.Name)
+	}
+	client, err := dialRPC(fmt.Sprintf("%s/atlas.ipc", path))
+	if err != nil {
+		utils.Fatalf("Unable to attach to atlas: %v", err)
+	}
+	defer client.Close()
+
+	var rewards json.RawMessage
+	if err := client.Call(&rewards, "istanbul_simulateEpochRewards", epoch, overrides); err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, rewards, "", "  "); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	_, err = out.WriteTo(os.Stdout)
+	return err
+}
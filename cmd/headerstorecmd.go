@@ -0,0 +1,209 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	cli "gopkg.in/urfave/cli.v1"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/interfaces"
+	"github.com/mapprotocol/atlas/cmd/utils"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/params"
+)
+
+var (
+	headerStoreCommand = cli.Command{
+		Name:        "headerstore",
+		Usage:       "Export and import checkpoints of the relayed chain header stores",
+		Category:    "BLOCKCHAIN COMMANDS",
+		Description: "",
+		Subcommands: []cli.Command{
+			{
+				Name:      "export",
+				Usage:     "Export a signed checkpoint of a relayed chain from the header store",
+				ArgsUsage: "<file> [<state-root>]",
+				Action:    utils.MigrateFlags(exportCheckpoint),
+				Category:  "BLOCKCHAIN COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.AncientFlag,
+					utils.TestnetFlag,
+					utils.HeaderStoreChainFlag,
+					utils.CheckpointNumberFlag,
+					utils.CheckpointKeyFlag,
+				},
+				Description: `
+atlas headerstore export --chain <chain-type> --key <keyfile> <file> [<state-root>]
+reads the header store of the relayed chain from the given Atlas state and writes
+its canonical header at --number, signed with the key, to the checkpoint file.
+The default state is the one of the HEAD block, the default header the latest
+stored one. BSC checkpoints are rounded down to the previous epoch header.
+`,
+			},
+			{
+				Name:      "import",
+				Usage:     "Verify a checkpoint file and print the inputs to reset a header store with",
+				ArgsUsage: "<file>",
+				Action:    utils.MigrateFlags(importCheckpoint),
+				Category:  "BLOCKCHAIN COMMANDS",
+				Flags: []cli.Flag{
+					utils.CheckpointSignerFlag,
+				},
+				Description: `
+atlas headerstore import --signer <address> <file>
+checks that the checkpoint file has been signed by the given address and prints
+the genesis header of the relay chain config and the input of the header store
+reset method that start the store of the chain from the checkpoint.
+`,
+			},
+		},
+	}
+)
+
+func exportCheckpoint(ctx *cli.Context) error {
+	if ctx.NArg() < 1 || ctx.NArg() > 2 {
+		utils.Fatalf("This command requires the checkpoint file and an optional state root.")
+	}
+	if !ctx.IsSet(utils.HeaderStoreChainFlag.Name) || !ctx.IsSet(utils.CheckpointKeyFlag.Name) {
+		utils.Fatalf("Both --%s and --%s must be given.", utils.HeaderStoreChainFlag.Name, utils.CheckpointKeyFlag.Name)
+	}
+	key, err := crypto.LoadECDSA(ctx.String(utils.CheckpointKeyFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to load the private key: %v", err)
+	}
+
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	chaindb := utils.MakeChainDatabase(ctx, stack, true)
+	headBlock := rawdb.ReadHeadBlock(chaindb)
+	if headBlock == nil {
+		log.Error("Failed to load head block")
+		return errors.New("no head block")
+	}
+	root := headBlock.Root()
+	if ctx.NArg() == 2 {
+		if root, err = parseRoot(ctx.Args()[1]); err != nil {
+			log.Error("Failed to resolve state root", "err", err)
+			return err
+		}
+	}
+	config := rawdb.ReadChainConfig(chaindb, rawdb.ReadCanonicalHash(chaindb, 0))
+
+	chain := chains.ChainType(ctx.Uint64(utils.HeaderStoreChainFlag.Name))
+	info, err := chains.LookupAt(config, headBlock.Number(), chain)
+	if err != nil {
+		return err
+	}
+	hs, err := interfaces.HeaderStoreFactory(info.Group, info.Type)
+	if err != nil {
+		return err
+	}
+	cs, ok := hs.(interfaces.ICheckpoint)
+	if !ok {
+		return fmt.Errorf("header store of chain %d can not export checkpoints", chain)
+	}
+
+	statedb, err := state.New(root, state.NewDatabase(chaindb), nil)
+	if err != nil {
+		log.Error("Failed to open state", "root", root, "err", err)
+		return err
+	}
+	number := ctx.Uint64(utils.CheckpointNumberFlag.Name)
+	if number == 0 {
+		number = ^uint64(0)
+	}
+	checkpoint, err := cs.Checkpoint(statedb, number)
+	if err != nil {
+		return err
+	}
+	checkpoint.ChainType, checkpoint.StateRoot = chain, root
+	if err := checkpoint.Sign(key); err != nil {
+		return err
+	}
+	if err := chains.WriteCheckpoint(ctx.Args().First(), checkpoint); err != nil {
+		return err
+	}
+	log.Info("Exported header store checkpoint", "chain", chain, "number", checkpoint.Number,
+		"hash", checkpoint.Hash, "root", root, "signer", crypto.PubkeyToAddress(key.PublicKey))
+	return nil
+}
+
+func importCheckpoint(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		utils.Fatalf("This command requires the checkpoint file.")
+	}
+	if !ctx.IsSet(utils.CheckpointSignerFlag.Name) {
+		utils.Fatalf("--%s must be given.", utils.CheckpointSignerFlag.Name)
+	}
+	signer := ctx.String(utils.CheckpointSignerFlag.Name)
+	if !common.IsHexAddress(signer) {
+		utils.Fatalf("Invalid signer address %q", signer)
+	}
+
+	checkpoint, err := chains.ReadCheckpoint(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	if err := checkpoint.Verify(common.HexToAddress(signer)); err != nil {
+		return err
+	}
+	if hash := crypto.Keccak256Hash(checkpoint.Header); hash != checkpoint.Hash {
+		return fmt.Errorf("checkpoint header hash %s does not match %s", hash.Hex(), checkpoint.Hash.Hex())
+	}
+
+	headerStore, err := abi.JSON(strings.NewReader(params.HeaderStoreABIJSON))
+	if err != nil {
+		return err
+	}
+	header, err := checkpoint.ResetInput()
+	if err != nil {
+		return err
+	}
+	input, err := headerStore.Pack("reset", new(big.Int).SetUint64(uint64(checkpoint.ChainType)), checkpoint.TD, header)
+	if err != nil {
+		return err
+	}
+	out := struct {
+		RelayChain *params.RelayChainConfig `json:"relayChain"`
+		ResetInput hexutil.Bytes            `json:"resetInput"`
+	}{
+		RelayChain: &params.RelayChainConfig{
+			ChainType:     uint64(checkpoint.ChainType),
+			GenesisHeader: header,
+			GenesisTD:     checkpoint.TD,
+		},
+		ResetInput: input,
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
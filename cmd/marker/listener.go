@@ -290,7 +290,7 @@ var getActiveVotesForValidatorCommand = cli.Command{
 
 var voterMonitorCommand = cli.Command{
 	Name:   "voterMonitor",
-	Usage:  "Monitor the revenue of voter to a validator",
+	Usage:  "Monitor the revenue of voter to a validator into a CSV file, superseded by the monitor command of new_marker",
 	Action: MigrateFlags(voterMonitor),
 	Flags:  Flags,
 }
@@ -32,18 +32,26 @@ func (a *Account) GetAccountMetadataURL(_ *cli.Context, cfg *define.Config) erro
 	var (
 		ret interface{}
 	)
-	a.handleType3Msg(cfg, &ret, a.to, nil, a.abi, "getMetadataURL", cfg.TargetAddress)
-	log.Info("get account metadata url", "address", cfg.TargetAddress, "url", ret)
-	return nil
+	if err := a.handleType3Msg(cfg, &ret, a.to, nil, a.abi, "getMetadataURL", cfg.TargetAddress); err != nil {
+		return err
+	}
+	result := AccountMetadataURLResult{Account: cfg.TargetAddress, URL: ret.(string)}
+	return writeResult(cfg, result, func() {
+		log.Info("get account metadata url", "address", cfg.TargetAddress, "url", result.URL)
+	})
 }
 
 func (a *Account) GetAccountName(_ *cli.Context, cfg *define.Config) error {
 	var (
 		ret interface{}
 	)
-	a.handleType3Msg(cfg, &ret, a.to, nil, a.abi, "getName", cfg.TargetAddress)
-	log.Info("get name", "address", cfg.TargetAddress, "name", ret)
-	return nil
+	if err := a.handleType3Msg(cfg, &ret, a.to, nil, a.abi, "getName", cfg.TargetAddress); err != nil {
+		return err
+	}
+	result := AccountNameResult{Account: cfg.TargetAddress, Name: ret.(string)}
+	return writeResult(cfg, result, func() {
+		log.Info("get name", "address", cfg.TargetAddress, "name", result.Name)
+	})
 }
 
 func (a *Account) GetAccountTotalLockedGold(_ *cli.Context, cfg *define.Config) error {
@@ -51,10 +59,13 @@ func (a *Account) GetAccountTotalLockedGold(_ *cli.Context, cfg *define.Config)
 		ret interface{}
 	)
 	log.Info("=== getAccountTotalLockedGold ===", "admin", cfg.From, "target", cfg.TargetAddress.String())
-	a.handleType3Msg(cfg, &ret, a.lockGoldTo, nil, a.lockedGoldAbi, "getAccountTotalLockedGold", cfg.TargetAddress)
+	if err := a.handleType3Msg(cfg, &ret, a.lockGoldTo, nil, a.lockedGoldAbi, "getAccountTotalLockedGold", cfg.TargetAddress); err != nil {
+		return err
+	}
 	result := ret.(*big.Int)
-	log.Info("result", "lockedGold", result)
-	return nil
+	return writeResult(cfg, LockedGoldResult{Account: cfg.TargetAddress, LockedGold: amount(result)}, func() {
+		log.Info("result", "lockedGold", result)
+	})
 }
 
 func (a *Account) GetAccountNonvotingLockedGold(_ *cli.Context, cfg *define.Config) error {
@@ -62,10 +73,13 @@ func (a *Account) GetAccountNonvotingLockedGold(_ *cli.Context, cfg *define.Conf
 		ret interface{}
 	)
 	log.Info("=== getAccountNonvotingLockedGold ===", "admin", cfg.From, "target", cfg.TargetAddress.String())
-	a.handleType3Msg(cfg, &ret, a.lockGoldTo, nil, a.lockedGoldAbi, "getAccountNonvotingLockedGold", cfg.TargetAddress)
+	if err := a.handleType3Msg(cfg, &ret, a.lockGoldTo, nil, a.lockedGoldAbi, "getAccountNonvotingLockedGold", cfg.TargetAddress); err != nil {
+		return err
+	}
 	result := ret.(*big.Int)
-	log.Info("result", "lockedGold", result)
-	return nil
+	return writeResult(cfg, LockedGoldResult{Account: cfg.TargetAddress, LockedGold: amount(result)}, func() {
+		log.Info("result", "lockedGold", result)
+	})
 }
 
 func (a *Account) GetPendingVotesForValidatorByAccount(_ *cli.Context, cfg *define.Config) error {
@@ -73,9 +87,13 @@ func (a *Account) GetPendingVotesForValidatorByAccount(_ *cli.Context, cfg *defi
 		ret interface{}
 	)
 	log.Info("=== getPendingVotesForValidatorByAccount ===", "admin", cfg.From)
-	a.handleType3Msg(cfg, &ret, a.electionTo, nil, a.electionAbi, "getPendingVotesForValidatorByAccount", cfg.TargetAddress, cfg.From)
-	log.Info("PendingVotes", "balance", ret.(*big.Int))
-	return nil
+	if err := a.handleType3Msg(cfg, &ret, a.electionTo, nil, a.electionAbi, "getPendingVotesForValidatorByAccount", cfg.TargetAddress, cfg.From); err != nil {
+		return err
+	}
+	result := VotesByAccountResult{Validator: cfg.TargetAddress, Account: cfg.From, Votes: amount(ret.(*big.Int))}
+	return writeResult(cfg, result, func() {
+		log.Info("PendingVotes", "balance", ret.(*big.Int))
+	})
 }
 
 func (a *Account) GetActiveVotesForValidatorByAccount(_ *cli.Context, cfg *define.Config) error {
@@ -83,10 +101,14 @@ func (a *Account) GetActiveVotesForValidatorByAccount(_ *cli.Context, cfg *defin
 		ret interface{}
 	)
 	log.Info("=== getActiveVotesForValidatorByAccount ===", "admin", cfg.From)
-	a.handleType3Msg(cfg, &ret, a.electionTo, nil, a.electionAbi, "getActiveVotesForValidatorByAccount",
-		cfg.TargetAddress, cfg.From)
-	log.Info("ActiveVotes", "balance", ret.(*big.Int))
-	return nil
+	if err := a.handleType3Msg(cfg, &ret, a.electionTo, nil, a.electionAbi, "getActiveVotesForValidatorByAccount",
+		cfg.TargetAddress, cfg.From); err != nil {
+		return err
+	}
+	result := VotesByAccountResult{Validator: cfg.TargetAddress, Account: cfg.From, Votes: amount(ret.(*big.Int))}
+	return writeResult(cfg, result, func() {
+		log.Info("ActiveVotes", "balance", ret.(*big.Int))
+	})
 }
 
 func (a *Account) GetValidatorsVotedForByAccount(_ *cli.Context, cfg *define.Config) error {
@@ -94,27 +116,31 @@ func (a *Account) GetValidatorsVotedForByAccount(_ *cli.Context, cfg *define.Con
 	var (
 		ret interface{}
 	)
-	a.handleType3Msg(cfg, &ret, a.electionTo, nil, a.electionAbi, "getValidatorsVotedForByAccount", cfg.TargetAddress)
-	result := ret.([]common.Address)
-	if len(result) == 0 {
-		log.Info("nil")
-	}
-	for i := 0; i < len(result); i++ {
-		log.Info("validator", "Address", result[i])
+	if err := a.handleType3Msg(cfg, &ret, a.electionTo, nil, a.electionAbi, "getValidatorsVotedForByAccount", cfg.TargetAddress); err != nil {
+		return err
 	}
-	return nil
+	result := append([]common.Address{}, ret.([]common.Address)...)
+	return writeResult(cfg, ValidatorsVotedForResult{Account: cfg.TargetAddress, Validators: result}, func() {
+		if len(result) == 0 {
+			log.Info("nil")
+		}
+		for i := 0; i < len(result); i++ {
+			log.Info("validator", "Address", result[i])
+		}
+	})
 }
 
 func (a *Account) SetAccountMetadataURL(_ *cli.Context, cfg *define.Config) error {
-	a.handleType1Msg(cfg, a.to, nil, a.abi, "setMetadataURL", cfg.MetadataURL)
+	if err := a.handleType1Msg(cfg, a.to, nil, a.abi, "setMetadataURL", cfg.MetadataURL); err != nil {
+		return err
+	}
 	log.Info("set account metadata url", "address", cfg.From, "url", cfg.MetadataURL)
 	return nil
 }
 
 func (a *Account) SetAccountName(_ *cli.Context, cfg *define.Config) error {
 	log.Info("set name", "address", cfg.From, "name", cfg.Name)
-	a.handleType1Msg(cfg, a.to, nil, a.abi, "setName", cfg.Name)
-	return nil
+	return a.handleType1Msg(cfg, a.to, nil, a.abi, "setName", cfg.Name)
 }
 
 func (a *Account) CreateAccount(_ *cli.Context, cfg *define.Config) error {
@@ -122,14 +148,17 @@ func (a *Account) CreateAccount(_ *cli.Context, cfg *define.Config) error {
 	logger.Info("Create account", "address", cfg.From, "name", cfg.Name)
 	log.Info("=== create Account ===")
 	// step 1
-	a.handleType1Msg(cfg, a.to, nil, a.abi, "createAccount")
+	if err := a.handleType1Msg(cfg, a.to, nil, a.abi, "createAccount"); err != nil {
+		return err
+	}
 	// step 2
 	log.Info("=== setName name ===")
-	a.handleType1Msg(cfg, a.to, nil, a.abi, "setName", cfg.Name)
+	if err := a.handleType1Msg(cfg, a.to, nil, a.abi, "setName", cfg.Name); err != nil {
+		return err
+	}
 	// step 3
 	log.Info("=== setAccountDataEncryptionKey ===")
-	a.handleType1Msg(cfg, a.to, nil, a.abi, "setAccountDataEncryptionKey", cfg.PublicKey)
-	return nil
+	return a.handleType1Msg(cfg, a.to, nil, a.abi, "setAccountDataEncryptionKey", cfg.PublicKey)
 }
 
 // SignerToAccount : Query the account of a target signer
@@ -137,7 +166,10 @@ func (a *Account) SignerToAccount(_ *cli.Context, cfg *define.Config) error {
 	//----------------------------- signerToAccount ---------------------------------
 	logger := log.New("func", "signerToAccount")
 	var ret common.Address
-	a.handleType3Msg(cfg, &ret, a.to, nil, a.abi, "signerToAccount", cfg.TargetAddress)
-	logger.Info("signerToAccount", "authorizingAccount", ret)
-	return nil
+	if err := a.handleType3Msg(cfg, &ret, a.to, nil, a.abi, "signerToAccount", cfg.TargetAddress); err != nil {
+		return err
+	}
+	return writeResult(cfg, SignerToAccountResult{Signer: cfg.TargetAddress, Account: ret}, func() {
+		logger.Info("signerToAccount", "authorizingAccount", ret)
+	})
 }
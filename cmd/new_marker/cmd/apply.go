@@ -0,0 +1,512 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/mapprotocol/atlas/accounts/abi"
+	"github.com/mapprotocol/atlas/cmd/new_marker/define"
+	"github.com/mapprotocol/atlas/cmd/new_marker/writer"
+	"gopkg.in/urfave/cli.v1"
+	"gopkg.in/yaml.v2"
+)
+
+// Plan operations
+const (
+	opCreateAccount   = "createAccount"   // Create the account of the sender and set its name
+	opLock            = "lock"            // Lock MAP
+	opAuthorizeSigner = "authorizeSigner" // Authorize a validator signer
+	opRegister        = "register"        // Register the sender as a validator
+	opVote            = "vote"            // Vote for a validator with locked MAP
+	opActivate        = "activate"        // Activate the pending votes for a validator
+	opTransfer        = "transfer"        // Transfer MAP
+	opWait            = "wait"            // Wait for all the earlier operations to complete
+)
+
+const (
+	defaultPlanConcurrency = 8
+	defaultReceiptTimeout  = 5 * time.Minute
+)
+
+// Plan is a declarative list of staking operations applied by marker apply. The operations of a
+// sender are applied in order, those of different senders concurrently, except that no
+// operation after a wait starts before all the operations before it completed.
+type Plan struct {
+	Concurrency int             `yaml:"concurrency,omitempty"` // Number of senders applying their operations at the same time
+	Operations  []PlanOperation `yaml:"operations"`
+}
+
+// PlanOperation is an operation of a plan. Amounts are in MAP.
+type PlanOperation struct {
+	Op         string  `yaml:"op"`
+	Keystore   string  `yaml:"keystore,omitempty"`   // Keystore of the sender
+	Name       string  `yaml:"name,omitempty"`       // Account name, for createAccount
+	Amount     string  `yaml:"amount,omitempty"`     // MAP to lock, vote or transfer
+	Validator  string  `yaml:"validator,omitempty"`  // Validator to vote for or to activate the votes for
+	To         string  `yaml:"to,omitempty"`         // Recipient of a transfer
+	Commission *uint64 `yaml:"commission,omitempty"` // Validator commission for register, 1000000 being 100%
+	SignerPriv string  `yaml:"signerPriv,omitempty"` // Signer private key, for authorizeSigner and register
+}
+
+// planCall is a transaction of a plan operation
+type planCall struct {
+	method string
+	to     common.Address
+	value  *big.Int
+	input  []byte
+}
+
+// parsePlan parses a YAML or JSON plan and checks its operations
+func parsePlan(data []byte) (*Plan, error) {
+	plan := new(Plan)
+	if err := yaml.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("invalid plan: %v", err)
+	}
+	if plan.Concurrency <= 0 {
+		plan.Concurrency = defaultPlanConcurrency
+	}
+	for i, op := range plan.Operations {
+		if err := op.validate(); err != nil {
+			return nil, fmt.Errorf("invalid plan operation %d (%s): %v", i, op.Op, err)
+		}
+	}
+	return plan, nil
+}
+
+func (op *PlanOperation) validate() error {
+	if op.Op == opWait {
+		return nil
+	}
+	if op.Keystore == "" {
+		return fmt.Errorf("keystore is required")
+	}
+	switch op.Op {
+	case opCreateAccount, opRegister:
+	case opLock, opVote, opTransfer:
+		if _, err := op.amount(); err != nil {
+			return err
+		}
+	case opActivate:
+	case opAuthorizeSigner:
+		if op.SignerPriv == "" {
+			return fmt.Errorf("signerPriv is required")
+		}
+	default:
+		return fmt.Errorf("unknown operation")
+	}
+	if (op.Op == opVote || op.Op == opActivate) && !common.IsHexAddress(op.Validator) {
+		return fmt.Errorf("invalid validator %q", op.Validator)
+	}
+	if op.Op == opTransfer && !common.IsHexAddress(op.To) {
+		return fmt.Errorf("invalid recipient %q", op.To)
+	}
+	if op.SignerPriv != "" {
+		if _, err := crypto.ToECDSA(common.FromHex(op.SignerPriv)); err != nil {
+			return fmt.Errorf("invalid signerPriv: %v", err)
+		}
+	}
+	return nil
+}
+
+// amount returns the amount of the operation in MAP
+func (op *PlanOperation) amount() (*big.Int, error) {
+	amount, ok := new(big.Int).SetString(op.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid amount %q, want a positive number of MAP", op.Amount)
+	}
+	return amount, nil
+}
+
+// stages splits the operations of the plan at its waits, and groups the operations of each stage
+// by keystore in plan order
+func (p *Plan) stages() [][][]int {
+	var (
+		stages [][][]int
+		stage  [][]int
+		index  = make(map[string]int)
+	)
+	for i, op := range p.Operations {
+		if op.Op == opWait {
+			if len(stage) > 0 {
+				stages = append(stages, stage)
+			}
+			stage, index = nil, make(map[string]int)
+			continue
+		}
+		j, ok := index[op.Keystore]
+		if !ok {
+			j = len(stage)
+			index[op.Keystore] = j
+			stage = append(stage, nil)
+		}
+		stage[j] = append(stage[j], i)
+	}
+	if len(stage) > 0 {
+		stages = append(stages, stage)
+	}
+	return stages
+}
+
+// planApplier applies the operations of a plan, journaling their transactions
+type planApplier struct {
+	validator      *Validator
+	voter          *Voter
+	plan           *Plan
+	journal        *writer.Journal
+	conn           *ethclient.Client
+	chainID        *big.Int
+	senders        map[string]*define.Config // Config of each keystore of the plan
+	receiptTimeout time.Duration             // Time to wait for the receipts of a batch
+	resolveMu      sync.Mutex                // Serializes the contract queries resolving the operations
+}
+
+// apply applies a plan file, resuming it from its journal if it was interrupted
+func (t *Tool) apply(ctx *cli.Context, cfg *define.Config) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("apply requires a plan file")
+	}
+	path := ctx.Args().First()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	plan, err := parsePlan(data)
+	if err != nil {
+		return err
+	}
+	journalPath := path + ".journal"
+	if cfg.Journal != "" {
+		journalPath = cfg.Journal
+	}
+	conn, err := t.newConn(cfg.RPCAddr)
+	if err != nil {
+		return err
+	}
+	chainID, err := conn.ChainID(context.Background())
+	if err != nil {
+		return err
+	}
+	a := &planApplier{
+		validator:      NewValidator(),
+		voter:          NewVoter(),
+		plan:           plan,
+		conn:           conn,
+		chainID:        chainID,
+		senders:        make(map[string]*define.Config),
+		receiptTimeout: defaultReceiptTimeout,
+	}
+	if cfg.ReceiptTimeout > 0 {
+		a.receiptTimeout = cfg.ReceiptTimeout
+	}
+	if err := a.loadSenders(cfg); err != nil {
+		return err
+	}
+	if a.journal, err = writer.OpenJournal(journalPath, crypto.Keccak256Hash(data)); err != nil {
+		return err
+	}
+	defer a.journal.Close()
+
+	for _, stage := range plan.stages() {
+		if err := a.applyStage(stage); err != nil {
+			return err
+		}
+	}
+	return writeResult(cfg, a.result(), func() {
+		log.Info("Plan applied", "plan", path, "operations", len(plan.Operations), "journal", journalPath)
+	})
+}
+
+// loadSenders decrypts the keystores of the plan, each once
+func (a *planApplier) loadSenders(cfg *define.Config) error {
+	for _, op := range a.plan.Operations {
+		if op.Op == opWait || a.senders[op.Keystore] != nil {
+			continue
+		}
+		password, err := define.ReadPassword(cfg.PasswordFile, fmt.Sprintf("Enter password for key %s:", op.Keystore))
+		if err != nil {
+			return err
+		}
+		_account, err := define.LoadAccount(op.Keystore, password)
+		if err != nil {
+			return err
+		}
+		sender := *cfg
+		if err := sender.SetAccount(_account); err != nil {
+			return err
+		}
+		a.senders[op.Keystore] = &sender
+	}
+	return nil
+}
+
+// applyStage applies the operations of each sender of the stage, the senders concurrently, and
+// returns the first error once all the senders stopped
+func (a *planApplier) applyStage(stage [][]int) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		sem      = make(chan struct{}, a.plan.Concurrency)
+	)
+	for _, ops := range stage {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(ops []int) {
+			defer func() { <-sem; wg.Done() }()
+			if err := a.applySender(ops); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(ops)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// applySender applies the operations of a sender in order. The transactions are sent with
+// consecutive nonces, managed locally from the pending nonce of the sender and the journaled
+// transactions, and their receipts are waited for as a batch: before an operation resolved
+// from the chain state, before resuming an interrupted operation, and at the end.
+func (a *planApplier) applySender(ops []int) error {
+	sender := a.senders[a.plan.Operations[ops[0]].Keystore]
+	nonce, err := a.conn.PendingNonceAt(context.Background(), sender.From)
+	if err != nil {
+		return err
+	}
+	fail := func(i int, err error) error {
+		op := &a.plan.Operations[i]
+		log.Error("Plan operation failed", "op", i, "operation", op.Op, "from", sender.From, "err", err)
+		return fmt.Errorf("operation %d (%s) of %s: %v", i, op.Op, sender.From.Hex(), err)
+	}
+	// batch holds the operations whose transactions are sent and not mined yet
+	var batch []int
+	wait := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), a.receiptTimeout)
+		defer cancel()
+		for _, i := range batch {
+			for _, tx := range a.journal.Sent(i) {
+				if err := writer.WaitJournaledTx(ctx, a.conn, tx); err != nil {
+					return fail(i, err)
+				}
+			}
+			if err := a.journal.Complete(i); err != nil {
+				return fail(i, err)
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for _, i := range ops {
+		op := &a.plan.Operations[i]
+		if a.journal.Done(i) {
+			log.Info("Skipping applied operation", "op", i, "operation", op.Op, "from", sender.From)
+			continue
+		}
+		sent := a.journal.Sent(i)
+		for _, tx := range sent {
+			if tx.Nonce() >= nonce {
+				nonce = tx.Nonce() + 1
+			}
+		}
+		if len(sent) > 0 || op.readsState() {
+			if err := wait(); err != nil {
+				return err
+			}
+		}
+		if len(sent) > 0 {
+			// The journaled transactions are sent again if needed, and mined before the
+			// remaining ones are resolved
+			ctx, cancel := context.WithTimeout(context.Background(), a.receiptTimeout)
+			for _, tx := range sent {
+				if err := writer.WaitJournaledTx(ctx, a.conn, tx); err != nil {
+					cancel()
+					return fail(i, err)
+				}
+			}
+			cancel()
+		}
+		a.resolveMu.Lock()
+		calls, err := a.resolve(op, sender)
+		a.resolveMu.Unlock()
+		if err != nil {
+			return fail(i, err)
+		}
+		for j := len(sent); j < len(calls); j++ {
+			tx, err := a.signCall(sender, calls[j], nonce, len(batch) == 0 && j == len(sent))
+			if err != nil {
+				return fail(i, err)
+			}
+			if err := a.journal.Insert(i, tx); err != nil {
+				return fail(i, err)
+			}
+			log.Info("Sending plan transaction", "op", i, "method", calls[j].method, "from", sender.From, "nonce", nonce, "txHash", tx.Hash())
+			if err := a.conn.SendTransaction(context.Background(), tx); err != nil {
+				return fail(i, err)
+			}
+			nonce++
+		}
+		batch = append(batch, i)
+	}
+	return wait()
+}
+
+// readsState reports whether the transactions of the operation are resolved from the chain
+// state, which must include the earlier transactions of the sender
+func (op *PlanOperation) readsState() bool {
+	return op.Op == opRegister || op.Op == opVote
+}
+
+// signCall builds and signs the transaction of a call with the nonce. The call is estimated only
+// if estimate is set, as it fails on a state missing the pending transactions of the sender.
+func (a *planApplier) signCall(sender *define.Config, call *planCall, nonce uint64, estimate bool) (*types.Transaction, error) {
+	gasPrice, err := a.conn.SuggestGasPrice(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if estimate {
+		msg := ethereum.CallMsg{From: sender.From, To: &call.to, GasPrice: gasPrice, Value: call.value, Data: call.input}
+		if _, err := a.conn.EstimateGas(context.Background(), msg); err != nil {
+			return nil, err
+		}
+	}
+	gasLimit := uint64(writer.DefaultGasLimit)
+	if sender.GasLimit != 0 {
+		gasLimit = uint64(sender.GasLimit)
+	}
+	tx := types.NewTransaction(nonce, call.to, call.value, gasLimit, gasPrice, call.input)
+	return types.SignTx(tx, types.LatestSignerForChainID(a.chainID), sender.PrivateKey)
+}
+
+// resolve returns the transactions of an operation of the sender, querying the contracts for the
+// arguments that depend on the chain state
+func (a *planApplier) resolve(op *PlanOperation, sender *define.Config) ([]*planCall, error) {
+	cfg := *sender
+	cfg.Name = op.Name
+	cfg.SignerPriv = op.SignerPriv
+	if op.Commission != nil {
+		cfg.Commission = *op.Commission
+	}
+	var amount *big.Int
+	if op.Amount != "" {
+		amount, _ = op.amount()
+	}
+	account := a.validator.account
+	pack := func(to common.Address, value *big.Int, abi *abi.ABI, method string, params ...interface{}) (*planCall, error) {
+		input, err := abi.Pack(method, params...)
+		if err != nil {
+			return nil, err
+		}
+		return &planCall{method: method, to: to, value: value, input: input}, nil
+	}
+
+	switch op.Op {
+	case opCreateAccount:
+		var calls []*planCall
+		for _, c := range []struct {
+			method string
+			params []interface{}
+		}{
+			{"createAccount", nil},
+			{"setName", []interface{}{cfg.Name}},
+			{"setAccountDataEncryptionKey", []interface{}{cfg.PublicKey}},
+		} {
+			call, err := pack(account.to, nil, account.abi, c.method, c.params...)
+			if err != nil {
+				return nil, err
+			}
+			calls = append(calls, call)
+		}
+		return calls, nil
+
+	case opLock:
+		call, err := pack(a.voter.lockGoldTo, new(big.Int).Mul(amount, big.NewInt(1e18)), a.voter.lockedGoldAbi, "lock")
+		return []*planCall{call}, err
+
+	case opAuthorizeSigner:
+		_params, err := a.validator.authorizeValidatorSignerParams(&cfg)
+		if err != nil {
+			return nil, err
+		}
+		call, err := pack(account.to, nil, account.abi, "authorizeValidatorSigner", _params...)
+		return []*planCall{call}, err
+
+	case opRegister:
+		if err := a.checkSigner(&cfg); err != nil {
+			return nil, err
+		}
+		_params, err := a.validator.registerValidatorParams(&cfg)
+		if err != nil {
+			return nil, err
+		}
+		call, err := pack(a.validator.to, nil, a.validator.abi, "registerValidator", _params...)
+		return []*planCall{call}, err
+
+	case opVote:
+		validator := common.HexToAddress(op.Validator)
+		cfg.VoteNum = amount
+		greater, lesser, err := a.voter.getGL(&cfg, validator)
+		if err != nil {
+			return nil, err
+		}
+		call, err := pack(a.voter.electionTo, nil, a.voter.electionAbi, "vote", validator, new(big.Int).Mul(amount, big.NewInt(1e18)), lesser, greater)
+		return []*planCall{call}, err
+
+	case opActivate:
+		call, err := pack(a.voter.electionTo, nil, a.voter.electionAbi, "activate", common.HexToAddress(op.Validator))
+		return []*planCall{call}, err
+
+	case opTransfer:
+		return []*planCall{{method: opTransfer, to: common.HexToAddress(op.To), value: new(big.Int).Mul(amount, big.NewInt(1e18))}}, nil
+	}
+	return nil, fmt.Errorf("unknown operation")
+}
+
+// checkSigner checks that the keys registered for the validator of the sender are those of its
+// authorized signer: the signerPriv of the operation, or the key of the sender if it has none
+func (a *planApplier) checkSigner(cfg *define.Config) error {
+	signer := cfg.From
+	if cfg.SignerPriv != "" {
+		priv, err := crypto.ToECDSA(common.FromHex(cfg.SignerPriv))
+		if err != nil {
+			return fmt.Errorf("invalid signerPriv: %v", err)
+		}
+		signer = crypto.PubkeyToAddress(priv.PublicKey)
+	}
+	authorized, err := a.validator.getValidatorSigner(cfg)
+	if err != nil {
+		return err
+	}
+	if signer != authorized {
+		return fmt.Errorf("signer %s is not the authorized validator signer %s", signer.Hex(), authorized.Hex())
+	}
+	return nil
+}
+
+// result returns the transactions of the applied operations
+func (a *planApplier) result() PlanResult {
+	result := PlanResult{Operations: []PlanOperationResult{}}
+	for i, op := range a.plan.Operations {
+		if op.Op == opWait {
+			continue
+		}
+		r := PlanOperationResult{Index: i, Op: op.Op, From: a.senders[op.Keystore].From, Transactions: []common.Hash{}}
+		for _, tx := range a.journal.Sent(i) {
+			r.Transactions = append(r.Transactions, tx.Hash())
+		}
+		result.Operations = append(result.Operations, r)
+	}
+	return result
+}
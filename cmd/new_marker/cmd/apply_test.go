@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePlan(t *testing.T) {
+	plan, err := parsePlan([]byte(`
+operations:
+  - {op: createAccount, keystore: v1.json, name: v1}
+  - {op: lock, keystore: v1.json, amount: 10000000}
+  - {op: transfer, keystore: funder.json, to: "0x2dC45799000ab08E60b7441c36fCC74060Ccbe11", amount: 1000}
+  - {op: register, keystore: v1.json, commission: 100000, signerPriv: "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"}
+  - op: wait
+  - {op: vote, keystore: voter.json, validator: "0x2dC45799000ab08E60b7441c36fCC74060Ccbe11", amount: 100}
+  - {op: activate, keystore: voter.json, validator: "0x2dC45799000ab08E60b7441c36fCC74060Ccbe11"}
+`))
+	if err != nil {
+		t.Fatalf("parsePlan: %v", err)
+	}
+	if plan.Concurrency != defaultPlanConcurrency {
+		t.Errorf("concurrency mismatch: have %d, want %d", plan.Concurrency, defaultPlanConcurrency)
+	}
+	if op := plan.Operations[1]; op.Amount != "10000000" {
+		t.Errorf("amount mismatch: have %q", op.Amount)
+	}
+	if op := plan.Operations[3]; op.Commission == nil || *op.Commission != 100000 {
+		t.Errorf("commission mismatch: have %v", op.Commission)
+	}
+	if op := plan.Operations[3]; op.SignerPriv == "" {
+		t.Error("signerPriv not parsed")
+	}
+	// The operations of each sender are grouped in order, and waits split the stages
+	want := [][][]int{{{0, 1, 3}, {2}}, {{5, 6}}}
+	if stages := plan.stages(); !reflect.DeepEqual(stages, want) {
+		t.Errorf("stages mismatch: have %v, want %v", stages, want)
+	}
+
+	for _, invalid := range []string{
+		`{"operations": [{"op": "lock", "keystore": "v1.json"}]}`,
+		`{"operations": [{"op": "lock", "keystore": "v1.json", "amount": "-1"}]}`,
+		`{"operations": [{"op": "vote", "keystore": "v1.json", "amount": "1", "validator": "v2"}]}`,
+		`{"operations": [{"op": "activate", "validator": "0x2dC45799000ab08E60b7441c36fCC74060Ccbe11"}]}`,
+		`{"operations": [{"op": "authorizeSigner", "keystore": "v1.json"}]}`,
+		`{"operations": [{"op": "slash", "keystore": "v1.json"}]}`,
+		`{"operations": [{"op": "register", "keystore": "v1.json", "signerPriv": "0x01"}]}`,
+	} {
+		if _, err := parsePlan([]byte(invalid)); err == nil {
+			t.Errorf("expected error for plan %s", invalid)
+		}
+	}
+}
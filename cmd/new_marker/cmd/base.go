@@ -12,50 +12,59 @@ import (
 )
 
 type base struct {
-	msgCh chan struct{} // wait for msg handles
+	msgCh chan error // wait for msg handles
 }
 
 func newBase() *base {
 	return &base{
-		msgCh: make(chan struct{}),
+		msgCh: make(chan error),
 	}
 }
 
-// waitUntilMsgHandled this function will block untill message is handled
-func (b *base) waitUntilMsgHandled(counter int) {
+// waitUntilMsgHandled this function will block untill message is handled, and returns the
+// first error of the messages
+func (b *base) waitUntilMsgHandled(counter int) error {
 	log.Debug("waitUntilMsgHandled", "counter", counter)
+	var err error
 	for counter > 0 {
-		<-b.msgCh
+		if e := <-b.msgCh; e != nil && err == nil {
+			err = e
+		}
 		counter -= 1
 	}
+	return err
 }
 
-func (b *base) newConn(addr string) *ethclient.Client {
+func (b *base) newConn(addr string) (*ethclient.Client, error) {
 	return connections.DialConn(addr)
 }
 
-func (b base) handleType1Msg(cfg *define.Config, to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) {
+func (b base) handleType1Msg(cfg *define.Config, to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) error {
 	m := writer.NewMessage(writer.SolveSendTranstion1, b.msgCh, cfg, to, value, abi, abiMethod, params...)
-	b.handleMessage(cfg.RPCAddr, m)
+	return b.handleMessage(cfg.RPCAddr, m)
 }
 
-func (b base) handleType2Msg(cfg *define.Config, to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) {
+func (b base) handleType2Msg(cfg *define.Config, to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) error {
 	m := writer.NewMessage(writer.SolveSendTranstion2, b.msgCh, cfg, to, value, abi, abiMethod, params...)
-	b.handleMessage(cfg.RPCAddr, m)
+	return b.handleMessage(cfg.RPCAddr, m)
 }
 
-func (b base) handleType3Msg(cfg *define.Config, ret interface{}, to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) {
+func (b base) handleType3Msg(cfg *define.Config, ret interface{}, to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) error {
 	m := writer.NewMessageRet1(writer.SolveQueryResult3, b.msgCh, cfg, &ret, to, value, abi, abiMethod, params...)
-	b.handleMessage(cfg.RPCAddr, m)
+	return b.handleMessage(cfg.RPCAddr, m)
 }
 
-func (b base) handleType4Msg(cfg *define.Config, solveResult func([]byte), to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) {
+func (b base) handleType4Msg(cfg *define.Config, solveResult func([]byte) error, to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) error {
 	m := writer.NewMessageRet2(writer.SolveQueryResult4, b.msgCh, cfg, solveResult, to, value, abi, abiMethod, params...)
-	b.handleMessage(cfg.RPCAddr, m)
+	return b.handleMessage(cfg.RPCAddr, m)
 }
 
-func (b *base) handleMessage(rpcAddr string, msg writer.Message) {
-	w := writer.New(b.newConn(rpcAddr))
+func (b *base) handleMessage(rpcAddr string, msg writer.Message) error {
+	conn, err := b.newConn(rpcAddr)
+	if err != nil {
+		return err
+	}
+	w := writer.New(conn)
 	go w.ResolveMessage(msg)
-	b.waitUntilMsgHandled(1)
+	return b.waitUntilMsgHandled(1)
 }
@@ -0,0 +1,504 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/mapprotocol/atlas/accounts/abi"
+	"github.com/mapprotocol/atlas/cmd/new_marker/define"
+	"github.com/mapprotocol/atlas/cmd/new_marker/mapprotocol"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/metrics"
+	"github.com/mapprotocol/atlas/metrics/prometheus"
+	"gopkg.in/urfave/cli.v1"
+	"gopkg.in/yaml.v2"
+)
+
+// Monitor alert types
+const (
+	alertDeregistration = "deregistration" // The validator deregistered or is pending deregistration
+	alertScoreDrop      = "scoreDrop"      // The validator score dropped by at least --scoreDrop
+	alertMissedReward   = "missedReward"   // The validator was elected but got no epoch payment
+)
+
+const (
+	monitorInterval = time.Second      // Interval between two polls of the latest block
+	webhookTimeout  = 10 * time.Second // Timeout of an alert post to the webhook
+)
+
+// fractionUnit is the fixed point unit of the fractions of the contracts
+var fractionUnit = new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(24), nil))
+
+// MonitorTargets are the validators and voters watched by marker monitor. The validators voted
+// for by the voters are watched too.
+type MonitorTargets struct {
+	Validators []string       `yaml:"validators"`
+	Voters     []MonitorVoter `yaml:"voters"`
+}
+
+// MonitorVoter is a voter watched for its votes for a validator
+type MonitorVoter struct {
+	Voter     string `yaml:"voter"`
+	Validator string `yaml:"validator"`
+}
+
+// MonitorAlert is an alert event, posted as JSON to the monitor webhook
+type MonitorAlert struct {
+	Type      string         `json:"type"`
+	Validator common.Address `json:"validator"`
+	Epoch     uint64         `json:"epoch"`
+	Block     uint64         `json:"block"`
+	Message   string         `json:"message"`
+}
+
+// validatorState is the state of a validator at a block. Amounts are in MAP.
+type validatorState struct {
+	registered          bool
+	pendingDeregister   bool
+	eligible            bool
+	activeVotes         float64
+	pendingVotes        float64
+	score               float64
+	commission          float64
+	nextCommission      float64
+	nextCommissionBlock uint64
+}
+
+// parseMonitorTargets parses a YAML or JSON targets file, returning the watched validators
+// without duplicates and the watched voters
+func parseMonitorTargets(data []byte) ([]common.Address, []define.VoterStruct, error) {
+	targets := new(MonitorTargets)
+	if err := yaml.Unmarshal(data, targets); err != nil {
+		return nil, nil, fmt.Errorf("invalid monitor targets: %v", err)
+	}
+	var (
+		validators []common.Address
+		voters     []define.VoterStruct
+		seen       = make(map[common.Address]bool)
+	)
+	watch := func(validator string) error {
+		if !common.IsHexAddress(validator) {
+			return fmt.Errorf("invalid validator %q", validator)
+		}
+		address := common.HexToAddress(validator)
+		if !seen[address] {
+			seen[address] = true
+			validators = append(validators, address)
+		}
+		return nil
+	}
+	for _, validator := range targets.Validators {
+		if err := watch(validator); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, voter := range targets.Voters {
+		if !common.IsHexAddress(voter.Voter) {
+			return nil, nil, fmt.Errorf("invalid voter %q", voter.Voter)
+		}
+		if err := watch(voter.Validator); err != nil {
+			return nil, nil, err
+		}
+		voters = append(voters, define.VoterStruct{
+			Voter:     common.HexToAddress(voter.Voter),
+			Validator: common.HexToAddress(voter.Validator),
+		})
+	}
+	if len(validators) == 0 {
+		return nil, nil, fmt.Errorf("no validator or voter to monitor")
+	}
+	return validators, voters, nil
+}
+
+// validatorAlerts returns the alerts raised by the change of state of a validator
+func validatorAlerts(validator common.Address, prev, cur *validatorState, scoreDrop float64) []MonitorAlert {
+	var alerts []MonitorAlert
+	if prev.registered && !cur.registered {
+		alerts = append(alerts, MonitorAlert{Type: alertDeregistration, Validator: validator, Message: "validator deregistered"})
+	} else if !prev.pendingDeregister && cur.pendingDeregister {
+		alerts = append(alerts, MonitorAlert{Type: alertDeregistration, Validator: validator, Message: "validator pending deregistration"})
+	}
+	if drop := prev.score - cur.score; cur.registered && drop > 0 && drop >= scoreDrop {
+		alerts = append(alerts, MonitorAlert{
+			Type:      alertScoreDrop,
+			Validator: validator,
+			Message:   fmt.Sprintf("validator score dropped from %g to %g", prev.score, cur.score),
+		})
+	}
+	return alerts
+}
+
+// monitor polls the state of validators and voters at each block, exports it as metrics and
+// raises alerts on its changes
+type monitor struct {
+	voter      *Voter
+	cfg        *define.Config
+	conn       *ethclient.Client
+	client     *rpc.Client
+	webhook    *http.Client
+	registry   metrics.Registry
+	validators []common.Address
+	voters     []define.VoterStruct
+	epochSize  uint64
+	states     map[common.Address]*validatorState
+	elected    map[common.Address]uint64 // Last epoch each validator was elected in
+}
+
+// monitor watches the validators and voters of a targets file, serving their metrics in the
+// Prometheus format and posting alerts to a webhook
+func (t *Tool) monitor(ctx *cli.Context, cfg *define.Config) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("monitor requires a targets file")
+	}
+	data, err := ioutil.ReadFile(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	validators, voters, err := parseMonitorTargets(data)
+	if err != nil {
+		return err
+	}
+	conn, err := t.newConn(cfg.RPCAddr)
+	if err != nil {
+		return err
+	}
+	client, err := rpc.Dial(cfg.RPCAddr)
+	if err != nil {
+		return err
+	}
+	// The metrics are disabled unless the --metrics flag of a node is given
+	metrics.Enabled = true
+	m := &monitor{
+		voter:      NewVoter(),
+		cfg:        cfg,
+		conn:       conn,
+		client:     client,
+		webhook:    &http.Client{Timeout: webhookTimeout},
+		registry:   metrics.NewRegistry(),
+		validators: validators,
+		voters:     voters,
+		states:     make(map[common.Address]*validatorState),
+		elected:    make(map[common.Address]uint64),
+	}
+	// The epoch size is read from the chain config of the node, through the Validators contract
+	epochSize, err := m.query(cfg, m.voter.validatorTo, m.voter.validatorAbi, "getEpochSize")
+	if err != nil {
+		return fmt.Errorf("can't query the epoch size: %v", err)
+	}
+	if m.epochSize = epochSize.(*big.Int).Uint64(); m.epochSize == 0 {
+		return fmt.Errorf("invalid epoch size 0")
+	}
+
+	listener, err := net.Listen("tcp", cfg.MetricsAddr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", prometheus.Handler(m.registry))
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			log.Error("Monitor metrics server failed", "err", err)
+		}
+	}()
+	log.Info("Monitor started", "validators", len(validators), "voters", len(voters), "epochSize", m.epochSize, "metrics", "http://"+listener.Addr().String()+"/metrics", "webhook", cfg.Webhook)
+	m.run()
+	return nil
+}
+
+// run polls the targets at each new block, forever
+func (m *monitor) run() {
+	var last uint64
+	for {
+		number, err := m.conn.BlockNumber(context.Background())
+		if err != nil || number == last {
+			if err != nil {
+				m.fail("BlockNumber", err)
+			}
+			time.Sleep(monitorInterval)
+			continue
+		}
+		m.poll(last, number)
+		last = number
+	}
+}
+
+// poll updates the metrics of the targets at the block, the last block being the previous polled one
+func (m *monitor) poll(last, number uint64) {
+	epoch := istanbul.GetEpochNumber(number, m.epochSize)
+	m.gauge("marker/monitor/block").Update(float64(number))
+	m.gauge("marker/monitor/epoch").Update(float64(epoch))
+
+	// The epoch payments are distributed in the last block of the epoch
+	if epoch > 1 && (last == 0 || istanbul.GetEpochNumber(last, m.epochSize) < epoch) {
+		m.pollRewards(epoch-1, last != 0, number)
+	}
+	m.pollUptimes()
+	for _, validator := range m.validators {
+		m.pollValidator(validator, epoch, number)
+	}
+	for _, key := range m.voters {
+		m.pollVoter(key)
+	}
+}
+
+// pollRewards updates the reward metrics of the validators for the epoch, and raises an alert
+// for those elected in the epoch that got no payment
+func (m *monitor) pollRewards(epoch uint64, alert bool, number uint64) {
+	block := new(big.Int).SetUint64(istanbul.GetEpochLastBlockNumber(epoch, m.epochSize))
+	payments, err := m.rewards(m.voter.validatorTo, mapprotocol.ValidatorEpochPaymentDistributed, block)
+	if err != nil {
+		m.fail("ValidatorEpochPaymentDistributed", err)
+		return
+	}
+	voterRewards, err := m.rewards(m.voter.electionTo, mapprotocol.EpochRewardsDistributedToVoters, block)
+	if err != nil {
+		m.fail("EpochRewardsDistributedToVoters", err)
+		return
+	}
+	for _, validator := range m.validators {
+		m.gauge(validatorMetric(validator, "reward/validator")).Update(toMap(payments[validator]))
+		m.gauge(validatorMetric(validator, "reward/voters")).Update(toMap(voterRewards[validator]))
+		if alert && m.elected[validator] == epoch && payments[validator] == nil {
+			m.alert(MonitorAlert{
+				Type:      alertMissedReward,
+				Validator: validator,
+				Epoch:     epoch,
+				Block:     number,
+				Message:   fmt.Sprintf("validator got no payment for epoch %d", epoch),
+			})
+		}
+	}
+	log.Info("Epoch rewards", "epoch", epoch, "payments", len(payments), "voterRewards", len(voterRewards))
+}
+
+// rewards returns the rewards of the event logged by the contract in the block, per validator
+func (m *monitor) rewards(contract common.Address, sig mapprotocol.EventSig, block *big.Int) (map[common.Address]*big.Int, error) {
+	logs, err := m.conn.FilterLogs(context.Background(), mapprotocol.BuildQuery(contract, sig, block, block))
+	if err != nil {
+		return nil, err
+	}
+	rewards, err := logRewards(logs)
+	if err != nil {
+		m.fail("rewards", err)
+	}
+	return rewards, nil
+}
+
+// logRewards returns the rewards of the logs per validator, indexed by the first topic after
+// the event and held by the first word of the data. Malformed logs are skipped and reported.
+func logRewards(logs []types.Log) (map[common.Address]*big.Int, error) {
+	var (
+		rewards = make(map[common.Address]*big.Int)
+		skipped []string
+	)
+	for _, l := range logs {
+		if len(l.Topics) < 2 || len(l.Data) < 32 {
+			skipped = append(skipped, fmt.Sprintf("%s#%d", l.TxHash.Hex(), l.Index))
+			continue
+		}
+		validator := common.BytesToAddress(l.Topics[1].Bytes())
+		rewards[validator] = big.NewInt(0).SetBytes(l.Data[:32])
+	}
+	if len(skipped) != 0 {
+		return rewards, fmt.Errorf("malformed reward logs skipped: %s", strings.Join(skipped, ", "))
+	}
+	return rewards, nil
+}
+
+// pollUptimes updates the uptime metrics of the validators from istanbul_activity
+func (m *monitor) pollUptimes() {
+	var activity struct {
+		Epoch   uint64 `json:"epoch"`
+		Uptimes map[common.Address]struct {
+			Uptime float64 `json:"uptime"`
+		} `json:"uptimes"`
+	}
+	if err := m.client.Call(&activity, "istanbul_activity"); err != nil {
+		m.fail("istanbul_activity", err)
+		return
+	}
+	for _, validator := range m.validators {
+		uptime, elected := activity.Uptimes[validator]
+		if !elected {
+			m.gauge(validatorMetric(validator, "elected")).Update(0)
+			continue
+		}
+		m.elected[validator] = activity.Epoch
+		m.gauge(validatorMetric(validator, "elected")).Update(1)
+		m.gauge(validatorMetric(validator, "uptime")).Update(uptime.Uptime)
+	}
+}
+
+// pollValidator updates the metrics of the validator and raises the alerts of its changes
+func (m *monitor) pollValidator(validator common.Address, epoch, number uint64) {
+	state, err := m.validatorState(validator)
+	if err != nil {
+		m.fail("validator "+validator.Hex(), err)
+		return
+	}
+	m.gauge(validatorMetric(validator, "registered")).Update(boolMetric(state.registered))
+	m.gauge(validatorMetric(validator, "pendingDeregister")).Update(boolMetric(state.pendingDeregister))
+	m.gauge(validatorMetric(validator, "eligible")).Update(boolMetric(state.eligible))
+	m.gauge(validatorMetric(validator, "votes/active")).Update(state.activeVotes)
+	m.gauge(validatorMetric(validator, "votes/pending")).Update(state.pendingVotes)
+	m.gauge(validatorMetric(validator, "score")).Update(state.score)
+	m.gauge(validatorMetric(validator, "commission")).Update(state.commission)
+	m.gauge(validatorMetric(validator, "nextCommission")).Update(state.nextCommission)
+	m.gauge(validatorMetric(validator, "nextCommissionBlock")).Update(float64(state.nextCommissionBlock))
+
+	prev := m.states[validator]
+	m.states[validator] = state
+	if prev == nil {
+		return
+	}
+	if prev.commission != state.commission || prev.nextCommission != state.nextCommission {
+		log.Info("Validator commission changed", "validator", validator, "commission", state.commission,
+			"nextCommission", state.nextCommission, "nextCommissionBlock", state.nextCommissionBlock)
+	}
+	for _, alert := range validatorAlerts(validator, prev, state, m.cfg.ScoreDrop) {
+		alert.Epoch, alert.Block = epoch, number
+		m.alert(alert)
+	}
+}
+
+// validatorState queries the state of the validator in the Validators and Election contracts
+func (m *monitor) validatorState(validator common.Address) (*validatorState, error) {
+	// isPendingDeRegisterValidator is about the sender of the query
+	cfg := *m.cfg
+	cfg.From = validator
+	v := m.voter
+
+	state := new(validatorState)
+	ret, err := m.query(&cfg, v.validatorTo, v.validatorAbi, "isValidator", validator)
+	if err != nil {
+		return nil, err
+	}
+	state.registered = ret.(bool)
+	if state.pendingDeregister, err = v.validator.isPendingDeRegisterValidator(&cfg); err != nil {
+		return nil, err
+	}
+	if ret, err = m.query(&cfg, v.electionTo, v.electionAbi, "getValidatorEligibility", validator); err != nil {
+		return nil, err
+	}
+	state.eligible = ret.(bool)
+	if ret, err = m.query(&cfg, v.electionTo, v.electionAbi, "getActiveVotesForValidator", validator); err != nil {
+		return nil, err
+	}
+	state.activeVotes = toMap(ret.(*big.Int))
+	if ret, err = m.query(&cfg, v.electionTo, v.electionAbi, "getPendingVotesForValidator", validator); err != nil {
+		return nil, err
+	}
+	state.pendingVotes = toMap(ret.(*big.Int))
+	// The fields of a validator are gone once it deregistered
+	if !state.registered {
+		return state, nil
+	}
+	fields, err := v.queryValidator(&cfg, validator)
+	if err != nil {
+		return nil, err
+	}
+	state.score = fraction(fields.Score)
+	state.commission = fraction(fields.Commission)
+	state.nextCommission = fraction(fields.NextCommission)
+	state.nextCommissionBlock = fields.NextCommissionBlock.Uint64()
+	return state, nil
+}
+
+// pollVoter updates the vote metrics of the voter for its validator
+func (m *monitor) pollVoter(key define.VoterStruct) {
+	v := m.voter
+	active, err := m.query(m.cfg, v.electionTo, v.electionAbi, "getActiveVotesForValidatorByAccount", key.Validator, key.Voter)
+	if err != nil {
+		m.fail("voter "+key.Voter.Hex(), err)
+		return
+	}
+	pending, err := m.query(m.cfg, v.electionTo, v.electionAbi, "getPendingVotesForValidatorByAccount", key.Validator, key.Voter)
+	if err != nil {
+		m.fail("voter "+key.Voter.Hex(), err)
+		return
+	}
+	m.gauge(voterMetric(key, "votes/active")).Update(toMap(active.(*big.Int)))
+	m.gauge(voterMetric(key, "votes/pending")).Update(toMap(pending.(*big.Int)))
+}
+
+func (m *monitor) query(cfg *define.Config, to common.Address, abi *abi.ABI, method string, params ...interface{}) (interface{}, error) {
+	var ret interface{}
+	err := m.voter.handleType3Msg(cfg, &ret, to, nil, abi, method, params...)
+	return ret, err
+}
+
+// alert counts and logs the alert, and posts it to the webhook if any
+func (m *monitor) alert(alert MonitorAlert) {
+	metrics.GetOrRegisterCounter("marker/monitor/alerts/"+alert.Type, m.registry).Inc(1)
+	log.Warn("Monitor alert", "type", alert.Type, "validator", alert.Validator, "epoch", alert.Epoch, "block", alert.Block, "message", alert.Message)
+	if m.cfg.Webhook == "" {
+		return
+	}
+	body, err := json.Marshal(alert)
+	if err != nil {
+		m.fail("alert", err)
+		return
+	}
+	resp, err := m.webhook.Post(m.cfg.Webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		m.fail("webhook", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		m.fail("webhook", fmt.Errorf("unexpected status %s", resp.Status))
+	}
+}
+
+// fail counts and logs a failed poll, retried at the next block
+func (m *monitor) fail(what string, err error) {
+	metrics.GetOrRegisterCounter("marker/monitor/errors", m.registry).Inc(1)
+	log.Error("Monitor poll failed", "query", what, "err", err)
+}
+
+func (m *monitor) gauge(name string) metrics.GaugeFloat64 {
+	return metrics.GetOrRegisterGaugeFloat64(name, m.registry)
+}
+
+func validatorMetric(validator common.Address, name string) string {
+	return "marker/validator/" + strings.ToLower(validator.Hex()) + "/" + name
+}
+
+func voterMetric(key define.VoterStruct, name string) string {
+	return "marker/voter/" + strings.ToLower(key.Voter.Hex()) + "/" + strings.ToLower(key.Validator.Hex()) + "/" + name
+}
+
+// toMap converts an amount in wei to MAP
+func toMap(v *big.Int) float64 {
+	if v == nil {
+		return 0
+	}
+	f, _ := ToMapI(v).Float64()
+	return f
+}
+
+// fraction converts a fixed point fraction of the contracts to a float
+func fraction(v *big.Int) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(v), fractionUnit).Float64()
+	return f
+}
+
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
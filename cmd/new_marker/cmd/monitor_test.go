@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestParseMonitorTargets(t *testing.T) {
+	validators, voters, err := parseMonitorTargets([]byte(`
+validators:
+  - "0x2dC45799000ab08E60b7441c36fCC74060Ccbe11"
+voters:
+  - {voter: "0x1111111111111111111111111111111111111111", validator: "0x2dC45799000ab08E60b7441c36fCC74060Ccbe11"}
+  - {voter: "0x1111111111111111111111111111111111111111", validator: "0x2222222222222222222222222222222222222222"}
+`))
+	if err != nil {
+		t.Fatalf("parseMonitorTargets: %v", err)
+	}
+	// The validators voted for are watched once
+	if len(validators) != 2 || validators[1] != common.HexToAddress("0x2222222222222222222222222222222222222222") {
+		t.Errorf("validators mismatch: %v", validators)
+	}
+	if len(voters) != 2 || voters[1].Voter != common.HexToAddress("0x1111111111111111111111111111111111111111") {
+		t.Errorf("voters mismatch: %v", voters)
+	}
+
+	for _, invalid := range []string{
+		`{}`,
+		`{"validators": ["v1"]}`,
+		`{"voters": [{"voter": "v1", "validator": "0x2dC45799000ab08E60b7441c36fCC74060Ccbe11"}]}`,
+	} {
+		if _, _, err := parseMonitorTargets([]byte(invalid)); err == nil {
+			t.Errorf("expected error for targets %s", invalid)
+		}
+	}
+}
+
+func TestValidatorAlerts(t *testing.T) {
+	validator := common.HexToAddress("0x2dC45799000ab08E60b7441c36fCC74060Ccbe11")
+	tests := []struct {
+		prev, cur validatorState
+		want      []string
+	}{
+		{validatorState{registered: true, score: 0.9}, validatorState{registered: true, score: 0.9}, nil},
+		{validatorState{registered: true, score: 0.9}, validatorState{registered: true, score: 0.895}, nil},
+		{validatorState{registered: true, score: 0.9}, validatorState{registered: true, score: 0.8}, []string{alertScoreDrop}},
+		{validatorState{registered: true, score: 0.9}, validatorState{registered: true, pendingDeregister: true, score: 0.9}, []string{alertDeregistration}},
+		{validatorState{registered: true, pendingDeregister: true}, validatorState{registered: true, pendingDeregister: true}, nil},
+		// The score of a deregistered validator is gone
+		{validatorState{registered: true, pendingDeregister: true, score: 0.9}, validatorState{}, []string{alertDeregistration}},
+	}
+	for i, tt := range tests {
+		alerts := validatorAlerts(validator, &tt.prev, &tt.cur, 0.01)
+		if len(alerts) != len(tt.want) {
+			t.Errorf("test %d: alerts mismatch: have %v, want %v", i, alerts, tt.want)
+			continue
+		}
+		for j, alert := range alerts {
+			if alert.Type != tt.want[j] || alert.Validator != validator {
+				t.Errorf("test %d: alert %d mismatch: have %v, want %s", i, j, alert, tt.want[j])
+			}
+		}
+	}
+}
+
+func TestLogRewards(t *testing.T) {
+	validator := common.HexToAddress("0x2dC45799000ab08E60b7441c36fCC74060Ccbe11")
+	topics := []common.Hash{{}, common.BytesToHash(validator.Bytes())}
+	logs := []types.Log{
+		{Topics: topics, Data: common.BigToHash(big.NewInt(42)).Bytes()},
+		{Topics: topics, Data: []byte{1}, Index: 1},
+		{Topics: topics[:1], Data: common.BigToHash(big.NewInt(7)).Bytes(), Index: 2},
+	}
+	rewards, err := logRewards(logs)
+	// The malformed logs are reported, the others still counted
+	if err == nil {
+		t.Error("expected the malformed logs to be reported")
+	}
+	if len(rewards) != 1 || rewards[validator].Cmp(big.NewInt(42)) != 0 {
+		t.Errorf("rewards mismatch: %v", rewards)
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/mapprotocol/atlas/cmd/new_marker/define"
+	"github.com/mapprotocol/atlas/cmd/new_marker/writer"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// offlineCommands are the write commands build-tx can build unsigned transactions for, the
+// ones only needing the key of the sender to sign
+var offlineCommands = []string{
+	"setAccountMetadataURL", "setAccountName",
+	"registerByProof", "revertRegister", "deregister",
+	"authorizeValidatorSigner", "authorizeValidatorSignerBySignature", "updateValidatorSigner",
+	"vote", "activate", "revokePending", "revokeActive",
+	"lockedMAP", "unlockMap", "relockMAP", "withdrawMap",
+	"setNextCommissionUpdate", "updateCommission",
+	"setValidatorLockedGoldRequirements", "setImplementation", "setContractOwner", "setProxyContractOwner",
+	"setValidatorEpochPayment", "setEpochMaintainerPaymentFraction", "setMgrMaintainerAddress",
+	"transfer",
+}
+
+// buildTxCommands returns the write commands of the sets as build-tx subcommands. They take the
+// sender address instead of its keystore, and write the transactions to the --txfile file
+// instead of sending them.
+func buildTxCommands(sets ...[]cli.Command) []cli.Command {
+	offline := make(map[string]bool, len(offlineCommands))
+	for _, name := range offlineCommands {
+		offline[name] = true
+	}
+
+	var commands []cli.Command
+	for _, set := range sets {
+		for _, command := range set {
+			if !offline[command.Name] {
+				continue
+			}
+			flags := []cli.Flag{define.KeystoreAddressFlag, define.TxFileFlag}
+			for _, flag := range command.Flags {
+				if name := flag.GetName(); name != define.KeyStoreFlag.Name && name != define.KeystoreAddressFlag.Name {
+					flags = append(flags, flag)
+				}
+			}
+			action := command.Action.(func(*cli.Context) error)
+			command.Action = func(ctx *cli.Context) error {
+				if !ctx.IsSet(define.KeystoreAddressFlag.Name) || !ctx.IsSet(define.TxFileFlag.Name) {
+					return fmt.Errorf("build-tx requires --%s and --%s", define.KeystoreAddressFlag.Name, define.TxFileFlag.Name)
+				}
+				return action(ctx)
+			}
+			command.Flags = flags
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+// signTx signs the transactions of an unsigned transaction file with the keystore, without
+// connecting to any node, and writes them to the signed transaction file
+func (t *Tool) signTx(ctx *cli.Context, cfg *define.Config) error {
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("sign-tx requires an unsigned and a signed transaction file")
+	}
+	if cfg.PrivateKey == nil {
+		return fmt.Errorf("sign-tx requires --%s", define.KeyStoreFlag.Name)
+	}
+	txs, err := writer.ReadOfflineTxs(ctx.Args().Get(0))
+	if err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		log.Info("Signing transaction", "method", tx.Method, "from", tx.From, "to", tx.To, "value", tx.Value.ToInt(),
+			"nonce", uint64(tx.Nonce), "gasLimit", uint64(tx.Gas), "gasPrice", tx.GasPrice.ToInt(), "chainID", tx.ChainID.ToInt())
+		if err := tx.Sign(cfg.PrivateKey); err != nil {
+			return err
+		}
+	}
+	if err := writer.WriteOfflineTxs(ctx.Args().Get(1), txs); err != nil {
+		return err
+	}
+	log.Info("Signed transactions written", "file", ctx.Args().Get(1), "count", len(txs))
+	return nil
+}
+
+// broadcastTx submits the transactions of a signed transaction file in order, and waits for
+// the receipt of each
+func (t *Tool) broadcastTx(ctx *cli.Context, cfg *define.Config) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("broadcast-tx requires a signed transaction file")
+	}
+	txs, err := writer.ReadOfflineTxs(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	conn, err := t.newConn(cfg.RPCAddr)
+	if err != nil {
+		return err
+	}
+	chainID, err := conn.ChainID(context.Background())
+	if err != nil {
+		return err
+	}
+	for _, tx := range txs {
+		signedTx, err := tx.SignedTransaction()
+		if err != nil {
+			return err
+		}
+		if tx.ChainID.ToInt().Cmp(chainID) != 0 {
+			return fmt.Errorf("transaction %d of %s is for chain %v, not %v", uint64(tx.Nonce), tx.From.Hex(), tx.ChainID.ToInt(), chainID)
+		}
+		if err := conn.SendTransaction(context.Background(), signedTx); err != nil {
+			log.Error("SendTransaction", "method", tx.Method, "nonce", uint64(tx.Nonce), "error", err)
+			return err
+		}
+		if err := writer.GetResult(conn, signedTx.Hash(), true); err != nil {
+			return err
+		}
+		log.Info("broadcast success", "method", tx.Method, "txHash", signedTx.Hash())
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/mapprotocol/atlas/cmd/new_marker/define"
+)
+
+// writeResult outputs the result of a query command. In the json output mode the result is
+// written to stdout as JSON, otherwise text logs it.
+func writeResult(cfg *define.Config, result interface{}, text func()) error {
+	if cfg.Output != define.OutputJSON {
+		text()
+		return nil
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// amount returns a token amount or vote count as a decimal string, so that it keeps its
+// precision in JSON
+func amount(v *big.Int) string {
+	if v == nil {
+		return "0"
+	}
+	return v.String()
+}
+
+// The JSON results of the query commands. Amounts are decimal strings in wei, and fractions
+// decimal strings between 0 and 1.
+
+type AccountMetadataURLResult struct {
+	Account common.Address `json:"account"`
+	URL     string         `json:"url"`
+}
+
+type AccountNameResult struct {
+	Account common.Address `json:"account"`
+	Name    string         `json:"name"`
+}
+
+type LockedGoldResult struct {
+	Account    common.Address `json:"account"`
+	LockedGold string         `json:"lockedGold"`
+}
+
+type VotesByAccountResult struct {
+	Validator common.Address `json:"validator"`
+	Account   common.Address `json:"account"`
+	Votes     string         `json:"votes"`
+}
+
+type ValidatorsVotedForResult struct {
+	Account    common.Address   `json:"account"`
+	Validators []common.Address `json:"validators"`
+}
+
+type SignerToAccountResult struct {
+	Signer  common.Address `json:"signer"`
+	Account common.Address `json:"account"`
+}
+
+type ValidatorVotesResult struct {
+	Validator common.Address `json:"validator"`
+	Votes     string         `json:"votes"`
+}
+
+type PendingVotersResult struct {
+	Validator common.Address   `json:"validator"`
+	Voters    []common.Address `json:"voters"`
+}
+
+type PendingInfoResult struct {
+	Validator common.Address `json:"validator"`
+	Account   common.Address `json:"account"`
+	Votes     string         `json:"votes"`
+	Epoch     string         `json:"epoch"` // Epoch in which the votes were cast
+}
+
+type EligibleValidatorsVotesResult struct {
+	Validators []ValidatorVotesResult `json:"validators"`
+}
+
+type ValidatorListResult struct {
+	Validators []common.Address `json:"validators"`
+}
+
+type ValidatorResult struct {
+	Validator           common.Address `json:"validator"`
+	EcdsaPublicKey      hexutil.Bytes  `json:"ecdsaPublicKey"`
+	BlsPublicKey        hexutil.Bytes  `json:"blsPublicKey"`
+	BlsG1PublicKey      hexutil.Bytes  `json:"blsG1PublicKey"`
+	Score               string         `json:"score"`
+	Signer              common.Address `json:"signer"`
+	Commission          string         `json:"commission"`
+	NextCommission      string         `json:"nextCommission"`
+	NextCommissionBlock string         `json:"nextCommissionBlock"`
+	SlashMultiplier     string         `json:"slashMultiplier"`
+	LastSlashed         string         `json:"lastSlashed"`
+}
+
+type RewardResult struct {
+	BlockNumber uint64         `json:"blockNumber"`
+	Validator   common.Address `json:"validator"`
+	Reward      string         `json:"reward"`
+}
+
+type RewardInfoResult struct {
+	Epoch     uint64         `json:"epoch"`
+	FromBlock uint64         `json:"fromBlock"`
+	ToBlock   uint64         `json:"toBlock"`
+	Rewards   []RewardResult `json:"rewards"`
+}
+
+type CountResult struct {
+	Count string `json:"count"`
+}
+
+type EligibilityResult struct {
+	Validator common.Address `json:"validator"`
+	Eligible  bool           `json:"eligible"`
+}
+
+type BalanceResult struct {
+	Account common.Address `json:"account"`
+	Balance string         `json:"balance"`
+}
+
+type TotalVotesResult struct {
+	TotalVotes string `json:"totalVotes"`
+}
+
+type PendingWithdrawal struct {
+	Index     int    `json:"index"`
+	Value     string `json:"value"`
+	Timestamp string `json:"timestamp"` // Unix time from which the withdrawal is possible
+}
+
+type PendingWithdrawalsResult struct {
+	Account     common.Address      `json:"account"`
+	Withdrawals []PendingWithdrawal `json:"withdrawals"`
+}
+
+type OwnerResult struct {
+	Contract common.Address `json:"contract"`
+	Owner    common.Address `json:"owner"`
+}
+
+type MaintainerResult struct {
+	Maintainer common.Address `json:"maintainer"`
+}
+
+type PlanOperationResult struct {
+	Index        int            `json:"index"` // Index of the operation in the plan
+	Op           string         `json:"op"`
+	From         common.Address `json:"from"`
+	Transactions []common.Hash  `json:"transactions"`
+}
+
+type PlanResult struct {
+	Operations []PlanOperationResult `json:"operations"`
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/mapprotocol/atlas/cmd/new_marker/define"
+	"github.com/mapprotocol/atlas/marker/cluster"
+	"github.com/mapprotocol/atlas/marker/env"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// runScenarios runs the fault injection scenarios of a scenario file on a local cluster of the
+// validators of a marker environment
+func (t *Tool) runScenarios(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("scenario requires a scenario file")
+	}
+	if !ctx.IsSet(define.EnvFlag.Name) {
+		return fmt.Errorf("missing --%s flag", define.EnvFlag.Name)
+	}
+	data, err := ioutil.ReadFile(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	scenarios, err := cluster.ParseScenarios(data)
+	if err != nil {
+		return err
+	}
+	environment, err := env.Load(ctx.String(define.EnvFlag.Name))
+	if err != nil {
+		return err
+	}
+	cl := cluster.New(environment, cluster.Config{
+		GethPath:  ctx.String(define.GethPathFlag.Name),
+		ProxyPort: ctx.Int64(define.ProxyPortFlag.Name),
+	})
+
+	// Interrupting stops the running scenario, and its nodes
+	runCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	go func() {
+		select {
+		case <-sigc:
+			cancel()
+		case <-runCtx.Done():
+		}
+	}()
+	return cl.RunScenarios(runCtx, scenarios)
+}
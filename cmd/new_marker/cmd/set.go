@@ -388,6 +388,17 @@ func init() {
 				},
 				define.TemplateFlags...),
 		},
+		{
+			Name:      "scenario",
+			Usage:     "Run fault injection scenarios on a local cluster of the validators of an environment",
+			Action:    tool.runScenarios,
+			ArgsUsage: "<scenario-file>",
+			Flags:     []cli.Flag{define.EnvFlag, define.GethPathFlag, define.ProxyPortFlag},
+			Description: "Runs each scenario of a YAML or JSON scenario file on a freshly initialized cluster of the validators " +
+				"of the --env environment: its steps start, stop and kill validators, partition them, and add latency and " +
+				"loss to their links. The chain must keep producing blocks (liveness) with no conflicting blocks between " +
+				"the validators (safety) at the end of each scenario.",
+		},
 		{
 			Name:   "transfer",
 			Usage:  "Transfer",
@@ -396,11 +407,54 @@ func init() {
 		},
 		{
 			Name:   "voterMonitor",
-			Usage:  "Monitor the revenue of voter to a validator",
+			Usage:  "Monitor the revenue of voter to a validator into a CSV file (deprecated, use monitor)",
 			Action: MigrateFlags(tool.voterMonitor),
 			Flags:  define.MustFlagCombination,
 		},
 	}...)
+	ToolSet = append(ToolSet, []cli.Command{
+		{
+			Name:        "build-tx",
+			Usage:       "Build an unsigned transaction file for a write command, to be signed offline",
+			Subcommands: buildTxCommands(AccountSet, ValidatorSet, VoterSet, ToolSet),
+			Description: "Builds the transactions of a write command for the --keystoreAddress sender, resolves their nonce, " +
+				"gas and chain ID on the rpc node and appends them unsigned to the --txfile file, for sign-tx to sign offline",
+		},
+		{
+			Name:      "sign-tx",
+			Usage:     "Sign an unsigned transaction file with a keystore, offline",
+			ArgsUsage: "<unsigned-file> <signed-file>",
+			Action:    MigrateFlags(tool.signTx),
+			Flags:     []cli.Flag{define.KeyStoreFlag},
+		},
+		{
+			Name:      "broadcast-tx",
+			Usage:     "Submit a signed transaction file and wait for the receipts",
+			ArgsUsage: "<signed-file>",
+			Action:    MigrateFlags(tool.broadcastTx),
+			Flags:     []cli.Flag{define.RPCAddrFlag},
+		},
+		{
+			Name:      "apply",
+			Usage:     "Apply a plan file of staking operations, resuming it from its journal if interrupted",
+			ArgsUsage: "<plan-file>",
+			Action:    MigrateFlags(tool.apply),
+			Flags:     []cli.Flag{define.RPCAddrFlag, define.GasLimitFlag, define.JournalFlag, define.ReceiptTimeoutFlag},
+			Description: "Applies the operations of a YAML or JSON plan: createAccount, lock, authorizeSigner, register, vote, " +
+				"activate and transfer, each with the keystore of its sender, and wait to wait for all the earlier operations. " +
+				"Amounts are in MAP. The transactions are journaled, so that applying the plan again resumes it.",
+		},
+		{
+			Name:      "monitor",
+			Usage:     "Monitor validators and voters, serving Prometheus metrics and posting alerts to a webhook",
+			ArgsUsage: "<targets-file>",
+			Action:    MigrateFlags(tool.monitor),
+			Flags:     []cli.Flag{define.RPCAddrFlag, define.MetricsAddrFlag, define.WebhookFlag, define.ScoreDropFlag},
+			Description: "Polls the validators and voters of a YAML or JSON targets file at each block, and serves their votes, " +
+				"rewards per epoch, score, commission, eligibility and uptime on /metrics. Deregistrations, score drops and " +
+				"missed epoch payments of the validators are posted as JSON alerts to the --webhook URL.",
+		},
+	}...)
 }
 
 func MigrateFlags(hdl func(ctx *cli.Context, cfg *define.Config) error) func(*cli.Context) error {
@@ -415,13 +469,11 @@ func MigrateFlags(hdl func(ctx *cli.Context, cfg *define.Config) error) func(*cl
 		}
 		_config, err := define.AssemblyConfig(ctx)
 		if err != nil {
-			cli.ShowAppHelpAndExit(ctx, 1)
-			panic(err)
+			return err
 		}
 		err = startLogger(ctx, _config)
 		if err != nil {
-			cli.ShowAppHelpAndExit(ctx, 1)
-			panic(err)
+			return err
 		}
 		return hdl(ctx, _config)
 	}
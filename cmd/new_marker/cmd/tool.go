@@ -85,19 +85,27 @@ func (t *Tool) transfer(_ *cli.Context, cfg *define.Config) error {
 	amount, ok := new(big.Int).SetString(cfg.Amount, 10)
 	if !ok {
 		log.Error("invalid amount", "amount ", cfg.Amount)
-		return nil
+		return fmt.Errorf("invalid amount %s", cfg.Amount)
+	}
+	conn, err := t.newConn(cfg.RPCAddr)
+	if err != nil {
+		return err
 	}
-	conn := t.newConn(cfg.RPCAddr)
 	if amount.Cmp(big.NewInt(0)) != 1 {
 		log.Error("transfer amount must be greater than 0", "amount", cfg.Amount)
-		return nil
+		return fmt.Errorf("transfer amount must be greater than 0, not %s", cfg.Amount)
+	}
+	if cfg.TxFile != "" {
+		return writer.AppendOfflineTx(conn, cfg.TxFile, "transfer", cfg.From, cfg.TargetAddress, amount, nil, 0)
 	}
 
 	txHash, err := writer.SendContractTransaction(conn, cfg.From, cfg.TargetAddress, amount, cfg.PrivateKey, nil, 0)
 	if err != nil {
 		return err
 	}
-	writer.GetResult(conn, txHash, false)
+	if err := writer.GetResult(conn, txHash, false); err != nil {
+		return err
+	}
 	log.Info("transfer success", "from ", cfg.From, "to", cfg.TargetAddress, "amount", cfg.Amount)
 	return nil
 }
@@ -163,7 +171,10 @@ func (t *Tool) pollBlocks(ctx *cli.Context, cfg *define.Config, key define.Voter
 		validatorList []common.Address
 		latestBlock   *big.Int
 	)
-	conn := t.newConn(cfg.RPCAddr)
+	conn, err := t.newConn(cfg.RPCAddr)
+	if err != nil {
+		return err
+	}
 	bnum, err := conn.BlockNumber(context.Background())
 	if err != nil {
 		log.Error("", "", err)
@@ -179,8 +190,14 @@ func (t *Tool) pollBlocks(ctx *cli.Context, cfg *define.Config, key define.Voter
 		latestBlock = big.NewInt(0).SetUint64(bnum)
 		log.Info(title, "Epoch", epochNum, "blockNumber", latestBlock, "YourAccount", From, "Validator", TargetAddress, "sign", key.Voter.String()+" to "+key.Validator.String())
 		// 查询当前投给validator情况
-		t.getActiveVotesForValidatorByAccount_(ctx, cfg, key, voterInfo)
-		t.getPendingInfo_(ctx, cfg, key, voterInfo)
+		if _, err := t.getActiveVotesForValidatorByAccount_(ctx, cfg, key, voterInfo); err != nil {
+			log.Error(title, "err", err, "sign", key.Voter.String()+" to "+key.Validator.String())
+			return false
+		}
+		if _, _, err := t.getPendingInfo_(ctx, cfg, key, voterInfo); err != nil {
+			log.Error(title, "err", err, "sign", key.Voter.String()+" to "+key.Validator.String())
+			return false
+		}
 		validatorList = t.getValidators(cfg)
 		exist := false
 		for _, e := range validatorList {
@@ -197,7 +214,10 @@ func (t *Tool) pollBlocks(ctx *cli.Context, cfg *define.Config, key define.Voter
 		}
 
 		// 查询validator情况
-		t.getActiveVotesForValidator_(ctx, cfg, key, voterInfo, validatorMap)
+		if err := t.getActiveVotesForValidator_(ctx, cfg, key, voterInfo, validatorMap); err != nil {
+			log.Error(title, "err", err, "sign", key.Voter.String()+" to "+key.Validator.String())
+			return false
+		}
 		f = new(big.Float).SetInt(voterInfo.VActive)
 		fSub := new(big.Float).SetInt(vaInfo.AllVotes)
 		if vaInfo.AllVotes.CmpAbs(big.NewInt(0)) > 0 {
@@ -301,7 +321,11 @@ func (t *Tool) getVoterRewardInfo_(curBlockNumber uint64, cfg *define.Config, ke
 	Epoch := istanbul.GetEpochNumber(curBlockNumber, epochSize)
 	query := mapprotocol.BuildQuery(t.electionTo, mapprotocol.EpochRewardsDistributedToVoters, EpochLast, EpochLast)
 	// querying for logs
-	conn := t.newConn(cfg.RPCAddr)
+	conn, err := t.newConn(cfg.RPCAddr)
+	if err != nil {
+		log.Error("getVoterRewardInfo_", "err", err)
+		return
+	}
 	logs, err := conn.FilterLogs(context.Background(), query)
 	if err != nil {
 		log.Error("getVoterRewardInfo_FilterLogs ", "err", err)
@@ -317,25 +341,30 @@ func (t *Tool) getVoterRewardInfo_(curBlockNumber uint64, cfg *define.Config, ke
 }
 
 func (t *Tool) getActiveVotesForValidator_(_ *cli.Context, cfg *define.Config, key define.VoterStruct,
-	voterInfo *define.VoterInfo, validatorMap map[common.Address]*define.ValidatorInfo) {
+	voterInfo *define.VoterInfo, validatorMap map[common.Address]*define.ValidatorInfo) error {
 	TargetAddress := key.Validator
 	valiInfo := validatorMap[voterInfo.Validator]
 	var ret interface{}
 	ElectionAddress := cfg.ElectionParameters.ElectionAddress
 	abiElection := cfg.ElectionParameters.ElectionABI
-	t.handleType3Msg(cfg, &ret, ElectionAddress, nil, abiElection, "getActiveVotesForValidator", TargetAddress)
+	if err := t.handleType3Msg(cfg, &ret, ElectionAddress, nil, abiElection, "getActiveVotesForValidator", TargetAddress); err != nil {
+		return err
+	}
 	log.Info("", "Validator all Votes", ret.(*big.Int), "sign", key.Voter.String()+" to "+key.Validator.String())
 	valiInfo.AllVotes = ret.(*big.Int)
+	return nil
 }
 
-func (t *Tool) getActiveVotesForValidatorByAccount_(_ *cli.Context, cfg *define.Config, key define.VoterStruct, voterInfo *define.VoterInfo) *big.Int {
+func (t *Tool) getActiveVotesForValidatorByAccount_(_ *cli.Context, cfg *define.Config, key define.VoterStruct, voterInfo *define.VoterInfo) (*big.Int, error) {
 	From := key.Voter
 	TargetAddress := key.Validator
 	var ret interface{}
-	t.handleType3Msg(cfg, &ret, t.electionTo, nil, t.electionAbi, "getActiveVotesForValidatorByAccount", TargetAddress, From)
+	if err := t.handleType3Msg(cfg, &ret, t.electionTo, nil, t.electionAbi, "getActiveVotesForValidatorByAccount", TargetAddress, From); err != nil {
+		return nil, err
+	}
 	log.Info("", "Active Vote", ret.(*big.Int))
 	voterInfo.VActive = ret.(*big.Int)
-	return voterInfo.VActive
+	return voterInfo.VActive, nil
 }
 
 func (t *Tool) getValidators(cfg *define.Config) []common.Address {
@@ -347,7 +376,7 @@ func (t *Tool) getValidators(cfg *define.Config) []common.Address {
 	return ret
 }
 
-func (t *Tool) getPendingInfo_(_ *cli.Context, cfg *define.Config, key define.VoterStruct, voterInfo *define.VoterInfo) (*big.Int, *big.Int) {
+func (t *Tool) getPendingInfo_(_ *cli.Context, cfg *define.Config, key define.VoterStruct, voterInfo *define.VoterInfo) (*big.Int, *big.Int, error) {
 	From := key.Voter
 	TargetAddress := key.Validator
 	type ret []interface{}
@@ -358,21 +387,19 @@ func (t *Tool) getPendingInfo_(_ *cli.Context, cfg *define.Config, key define.Vo
 	)
 	result := ret{&Value, &Epoch}
 
-	f := func(output []byte) {
-		err := t.electionAbi.UnpackIntoInterface(&result, "pendingInfo", output)
-		if err != nil {
-			log.Error("getPendingInfoForValidator", "err", err)
-			os.Exit(1)
-		}
+	f := func(output []byte) error {
+		return t.electionAbi.UnpackIntoInterface(&result, "pendingInfo", output)
+	}
+	if err := t.handleType4Msg(cfg, f, t.electionTo, nil, t.electionAbi, "pendingInfo", From, TargetAddress); err != nil {
+		return nil, nil, err
 	}
-	t.handleType4Msg(cfg, f, t.electionTo, nil, t.electionAbi, "pendingInfo", From, TargetAddress)
 	p := Epoch.(*big.Int)
 	log.Info("", "PendingVotes", Value.(*big.Int), "epoch", p.Add(p, big.NewInt(1)), "sign", key.Voter.String()+" to "+key.Validator.String())
 	voterInfo.VPending = big.NewInt(0)
 	if Epoch.(*big.Int).CmpAbs(big.NewInt(0).SetUint64(epochNum)) < 0 {
 		voterInfo.VPending = Value.(*big.Int)
 	}
-	return Epoch.(*big.Int), Value.(*big.Int)
+	return Epoch.(*big.Int), Value.(*big.Int), nil
 }
 
 func initCsv() (*os.File, error) {
@@ -16,7 +16,6 @@ import (
 	"github.com/mapprotocol/atlas/params"
 	"gopkg.in/urfave/cli.v1"
 	"math/big"
-	"os"
 	"sort"
 )
 
@@ -44,27 +43,47 @@ func (v *Validator) RegisterValidator(ctx *cli.Context, cfg *define.Config) erro
 	log.Info("=== Register validator ===")
 	commision := big.NewInt(0).SetUint64(cfg.Commission)
 	log.Info("=== commision ===", "commision", commision)
-	if v.isPendingDeRegisterValidator(cfg) {
-		_ = v.revertRegisterValidator(ctx, cfg)
+	pending, err := v.isPendingDeRegisterValidator(cfg)
+	if err != nil {
+		return err
+	}
+	if pending {
+		if err := v.revertRegisterValidator(ctx, cfg); err != nil {
+			return err
+		}
 		log.Info("the account is in PendingDeRegisterValidator list please use revertRegisterValidator command")
 		return nil
 	}
-	greater, lesser := v.registerUseFor(cfg)
+	_params, err := v.registerValidatorParams(cfg)
+	if err != nil {
+		return err
+	}
+	return v.handleType1Msg(cfg, v.to, nil, v.abi, "registerValidator", _params...)
+}
+
+// registerValidatorParams returns the registerValidator arguments for cfg.From, with the keys
+// of the signer if cfg.SignerPriv is set
+func (v *Validator) registerValidatorParams(cfg *define.Config) ([]interface{}, error) {
+	commision := big.NewInt(0).SetUint64(cfg.Commission)
+	greater, lesser, err := v.registerUseFor(cfg)
+	if err != nil {
+		return nil, err
+	}
 	if cfg.SignerPriv != "" {
 		SignerPriv := cfg.SignerPriv
 		priv, err := crypto.ToECDSA(common.FromHex(SignerPriv))
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		publicAddr := crypto.PubkeyToAddress(priv.PublicKey)
 		_account := &account.Account{Address: publicAddr, PrivateKey: priv}
 		blsPub, err := _account.BLSPublicKey()
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		blsG1Pub, err := _account.BLSG1PublicKey()
 		if err != nil {
-			panic(err)
+			return nil, err
 		}
 		cfg.PublicKey = _account.PublicKey()
 		cfg.BlsPub = blsPub
@@ -73,20 +92,24 @@ func (v *Validator) RegisterValidator(ctx *cli.Context, cfg *define.Config) erro
 		cfg.BLSProof = v.makeBLSProofOfPossessionFromSigner_(cfg.From, cfg.SignerPriv).Marshal()
 	}
 	validatorParams := [4][]byte{cfg.BlsPub[:], cfg.BlsG1Pub[:], cfg.BLSProof, cfg.PublicKey[1:]}
-
-	_params := []interface{}{commision, lesser, greater, validatorParams}
-	v.handleType1Msg(cfg, v.to, nil, v.abi, "registerValidator", _params...)
-	return nil
+	return []interface{}{commision, lesser, greater, validatorParams}, nil
 }
 
 func (v *Validator) RegisterValidatorByProof(_ *cli.Context, cfg *define.Config) error {
 	commission := new(big.Int).SetUint64(cfg.Commission)
 	log.Info("registerValidatorByProof", "commission", commission)
-	if v.isPendingDeRegisterValidator(cfg) {
+	pending, err := v.isPendingDeRegisterValidator(cfg)
+	if err != nil {
+		return err
+	}
+	if pending {
 		log.Info("the account is in PendingDeRegisterValidator list please use revertRegisterValidator command")
 		return nil
 	}
-	greater, lesser := v.registerUseFor(cfg)
+	greater, lesser, err := v.registerUseFor(cfg)
+	if err != nil {
+		return err
+	}
 	dec, err := hexutil.Decode(cfg.Proof)
 	if err != nil {
 		return err
@@ -98,24 +121,25 @@ func (v *Validator) RegisterValidatorByProof(_ *cli.Context, cfg *define.Config)
 
 	validatorParams := [4][]byte{pf.BLSPublicKey[:], pf.BLSG1PublicKey[:], pf.BLSProof, pf.PublicKey[1:]}
 	_params := []interface{}{commission, lesser, greater, validatorParams}
-	v.handleType1Msg(cfg, v.to, nil, v.abi, "registerValidator", _params)
-	return nil
+	return v.handleType1Msg(cfg, v.to, nil, v.abi, "registerValidator", _params)
 }
 
 func (v *Validator) RevertRegisterValidator(_ *cli.Context, cfg *define.Config) error {
-	if !v.isPendingDeRegisterValidator(cfg) {
+	pending, err := v.isPendingDeRegisterValidator(cfg)
+	if err != nil {
+		return err
+	}
+	if !pending {
 		log.Info("revert validator", "msg", "not in the deRegister list")
 		return nil
 	}
-	v.handleType1Msg(cfg, v.to, nil, v.abi, "revertRegisterValidator")
-	return nil
+	return v.handleType1Msg(cfg, v.to, nil, v.abi, "revertRegisterValidator")
 }
 
 func (v *Validator) DeregisterValidator(_ *cli.Context, cfg *define.Config) error {
 	//----------------------------- deregisterValidator ---------------------------------
 	log.Info("=== deregisterValidator ===")
-	v.handleType1Msg(cfg, v.to, nil, v.abi, "deregisterValidator")
-	return nil
+	return v.handleType1Msg(cfg, v.to, nil, v.abi, "deregisterValidator")
 }
 
 func (v *Validator) GenerateSignerProof(_ *cli.Context, cfg *define.Config) error {
@@ -151,16 +175,24 @@ func (v *Validator) GenerateSignerProof(_ *cli.Context, cfg *define.Config) erro
 
 func (v *Validator) QuicklyRegisterValidator(ctx *cli.Context, cfg *define.Config) error {
 	//---------------------------- create account ----------------------------------
-	_ = v.account.CreateAccount(ctx, cfg)
+	if err := v.account.CreateAccount(ctx, cfg); err != nil {
+		return err
+	}
 
 	if cfg.SignerPriv != "" {
-		_ = v.AuthorizeValidatorSigner(ctx, cfg)
+		if err := v.AuthorizeValidatorSigner(ctx, cfg); err != nil {
+			return err
+		}
 	}
 	//---------------------------- lock ----------------------------------
-	_ = v.LockedMAP(ctx, cfg)
+	if err := v.LockedMAP(ctx, cfg); err != nil {
+		return err
+	}
 
 	//----------------------------- registerValidator ---------------------------------
-	_ = v.RegisterValidator(ctx, cfg)
+	if err := v.RegisterValidator(ctx, cfg); err != nil {
+		return err
+	}
 	log.Info("=== End ===")
 	return nil
 }
@@ -169,8 +201,7 @@ func (v *Validator) LockedMAP(_ *cli.Context, cfg *define.Config) error {
 	lockedGold := new(big.Int).Mul(cfg.LockedNum, big.NewInt(1e18))
 	log.Info("=== Lock  gold ===")
 	log.Info("Lock  gold", "amount", lockedGold.String())
-	v.handleType2Msg(cfg, v.lockGoldTo, lockedGold, v.lockedGoldAbi, "lock")
-	return nil
+	return v.handleType2Msg(cfg, v.lockGoldTo, lockedGold, v.lockedGoldAbi, "lock")
 }
 
 /*
@@ -180,41 +211,48 @@ signer sign account
 need signer private
 */
 func (v *Validator) AuthorizeValidatorSigner(_ *cli.Context, cfg *define.Config) error {
+	_params, err := v.authorizeValidatorSignerParams(cfg)
+	if err != nil {
+		return err
+	}
+	logger := log.New("func", "authorizeValidatorSigner")
+	logger.Info("authorizeValidatorSigner", "validator", cfg.From, "signer", _params[0])
+	log.Info("=== authorizeValidatorSigner ===")
+	return v.handleType1Msg(cfg, v.account.to, nil, v.account.abi, "authorizeValidatorSigner", _params...)
+}
+
+// authorizeValidatorSignerParams returns the authorizeValidatorSigner arguments authorizing the
+// signer of cfg.SignerPriv for cfg.From
+func (v *Validator) authorizeValidatorSignerParams(cfg *define.Config) ([]interface{}, error) {
 	SignatureStr, signer := v.makeECDSASignatureFromSigner_(cfg.From, cfg.SignerPriv) // signer sign account
 	Signature, err := hexutil.Decode(SignatureStr)
 	if err != nil {
-		panic(err)
+		return nil, err
 	}
 	all := uint8(new(big.Int).SetBytes([]byte{Signature[64] + 27}).Uint64())
 	r := common.BytesToHash(Signature[:32])
 	s := common.BytesToHash(Signature[32:64])
-
-	logger := log.New("func", "authorizeValidatorSigner")
-	logger.Info("authorizeValidatorSigner", "validator", cfg.From, "signer", signer)
-	log.Info("=== authorizeValidatorSigner ===")
-	v.handleType1Msg(cfg, v.account.to, nil, v.account.abi, "authorizeValidatorSigner", signer, all, r, s)
-	return nil
+	return []interface{}{signer, all, r, s}, nil
 }
 
 func (v *Validator) AuthorizeValidatorSignerBySignature(_ *cli.Context, cfg *define.Config) error {
 	Signature, err := hexutil.Decode(cfg.Signature)
 	if err != nil {
-		panic(err)
+		return err
 	}
 	all := uint8(new(big.Int).SetBytes([]byte{Signature[64] + 27}).Uint64())
 	r := common.BytesToHash(Signature[:32])
 	s := common.BytesToHash(Signature[32:64])
 
 	log.Info("authorizeValidatorSignerBySignature", "signer", cfg.SignerAddress, "signature", cfg.Signature)
-	v.handleType1Msg(cfg, v.account.to, nil, v.account.abi, "authorizeValidatorSigner", cfg.SignerAddress, all, r, s)
-	return nil
+	return v.handleType1Msg(cfg, v.account.to, nil, v.account.abi, "authorizeValidatorSigner", cfg.SignerAddress, all, r, s)
 }
 
 func (v *Validator) UpdateValidatorSigner(_ *cli.Context, cfg *define.Config) error {
 	SignatureStr, signer := v.makeECDSASignatureFromSigner_(cfg.From, cfg.SignerPriv)
 	Signature, err := hexutil.Decode(SignatureStr)
 	if err != nil {
-		panic(err)
+		return err
 	}
 	all := uint8(new(big.Int).SetBytes([]byte{Signature[64] + 27}).Uint64())
 	r := common.BytesToHash(Signature[:32])
@@ -222,26 +260,25 @@ func (v *Validator) UpdateValidatorSigner(_ *cli.Context, cfg *define.Config) er
 
 	privateKey, err := crypto.ToECDSA(common.FromHex(cfg.SignerPriv))
 	if err != nil {
-		panic(err)
+		return err
 	}
 	publicAddr := crypto.PubkeyToAddress(privateKey.PublicKey)
 	_account := &account.Account{Address: publicAddr, PrivateKey: privateKey}
 	blsPublicKey, err := _account.BLSPublicKey()
 	if err != nil {
-		panic(err)
+		return err
 	}
 	blsG1PublicKey, err := _account.BLSG1PublicKey()
 	if err != nil {
-		panic(err)
+		return err
 	}
 	ecdsaPublicKey := _account.PublicKey()
 	blsPop := v.makeBLSProofOfPossessionFromSigner_(cfg.From, cfg.SignerPriv).Marshal()
 
 	logger := log.New("func", "UpdateValidatorSigner")
 	logger.Info("UpdateValidatorSigner", "account", cfg.From, "signer", signer)
-	v.handleType1Msg(cfg, v.account.to, nil, v.account.abi, "authorizeValidatorSignerWithKeys",
+	return v.handleType1Msg(cfg, v.account.to, nil, v.account.abi, "authorizeValidatorSignerWithKeys",
 		signer, all, r, s, ecdsaPublicKey[1:], blsPublicKey[:], blsG1PublicKey[:], blsPop[:])
-	return nil
 }
 
 func (v *Validator) MakeECDSASignatureFromSigner(_ *cli.Context, cfg *define.Config) error {
@@ -255,20 +292,31 @@ func (v *Validator) MakeBLSProofOfPossessionFromsigner(_ *cli.Context, cfg *defi
 	return nil
 }
 
-func (v *Validator) isPendingDeRegisterValidator(cfg *define.Config) bool {
+func (v *Validator) isPendingDeRegisterValidator(cfg *define.Config) (bool, error) {
 	//----------------------------- isPendingDeRegisterValidator ---------------------------------
 	var ret bool
-	v.handleType3Msg(cfg, &ret, v.to, nil, v.abi, "isPendingDeRegisterValidator")
-	return ret
+	err := v.handleType3Msg(cfg, &ret, v.to, nil, v.abi, "isPendingDeRegisterValidator")
+	return ret, err
+}
+
+// getValidatorSigner returns the signer authorized for the validator of the account, the account
+// itself if none is
+func (v *Validator) getValidatorSigner(cfg *define.Config) (common.Address, error) {
+	var ret common.Address
+	err := v.handleType3Msg(cfg, &ret, v.account.to, nil, v.account.abi, "getValidatorSigner", cfg.From)
+	return ret, err
 }
 
 func (v *Validator) revertRegisterValidator(_ *cli.Context, cfg *define.Config) error {
-	if !v.isPendingDeRegisterValidator(cfg) {
+	pending, err := v.isPendingDeRegisterValidator(cfg)
+	if err != nil {
+		return err
+	}
+	if !pending {
 		log.Info("revert validator", "msg", "not in the deRegister list")
 		return nil
 	}
-	v.handleType1Msg(cfg, v.to, nil, v.abi, "revertRegisterValidator")
-	return nil
+	return v.handleType1Msg(cfg, v.to, nil, v.abi, "revertRegisterValidator")
 }
 
 func (v *Validator) makeBLSProofOfPossessionFromSigner_(message common.Address, signerPrivate string) *bls.UnsafeSignature {
@@ -296,14 +344,15 @@ func (v *Validator) makeBLSProofOfPossessionFromSigner_(message common.Address,
 	return signature
 }
 
-func (v *Validator) registerUseFor(cfg *define.Config) (common.Address, common.Address) {
+func (v *Validator) registerUseFor(cfg *define.Config) (common.Address, common.Address, error) {
 	var ret1 interface{}
-	v.handleType3Msg(cfg, &ret1, v.electionTo, nil, v.electionAbi, "getTotalVotesForValidator", cfg.From)
+	if err := v.handleType3Msg(cfg, &ret1, v.electionTo, nil, v.electionAbi, "getTotalVotesForValidator", cfg.From); err != nil {
+		return params.ZeroAddress, params.ZeroAddress, err
+	}
 	result := ret1.(*big.Int)
 	log.Info("=== getTotalVotesForValidator ===", "result", result)
 	cfg.VoteNum = result
-	G, L, _ := v.getGL2(cfg, cfg.From)
-	return G, L
+	return v.getGL2(cfg, cfg.From)
 }
 
 type voteTotal struct {
@@ -325,14 +374,12 @@ func (v *Validator) getGL2(cfg *define.Config, target common.Address) (common.Ad
 	}
 
 	var t ret
-	f := func(output []byte) {
-		err := v.electionAbi.UnpackIntoInterface(&t, "getTotalVotesForEligibleValidators", output)
-		if err != nil {
-			log.Error("getTotalVotesForEligibleValidators setLesserGreater", "err", err)
-			os.Exit(1)
-		}
+	f := func(output []byte) error {
+		return v.electionAbi.UnpackIntoInterface(&t, "getTotalVotesForEligibleValidators", output)
+	}
+	if err := v.handleType4Msg(cfg, f, v.electionTo, nil, v.electionAbi, "getTotalVotesForEligibleValidators"); err != nil {
+		return params.ZeroAddress, params.ZeroAddress, err
 	}
-	v.handleType4Msg(cfg, f, v.electionTo, nil, v.electionAbi, "getTotalVotesForEligibleValidators")
 	validators := (t.Validators).([]common.Address)
 	votes := (t.Values).([]*big.Int)
 	voteTotals := make([]voteTotal, len(validators))
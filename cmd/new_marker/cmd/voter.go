@@ -15,7 +15,6 @@ import (
 	"github.com/mapprotocol/atlas/params"
 	"gopkg.in/urfave/cli.v1"
 	"math/big"
-	"os"
 	"sort"
 	"strings"
 )
@@ -54,41 +53,52 @@ func (v *Voter) Vote(_ *cli.Context, cfg *define.Config) error {
 	}
 	amount := new(big.Int).Mul(cfg.VoteNum, big.NewInt(1e18))
 	log.Info("=== vote Validator ===", "admin", cfg.From, "voteTargetValidator", cfg.TargetAddress.String(), "vote MAP Num", cfg.VoteNum.String())
-	v.handleType1Msg(cfg, v.electionTo, nil, v.electionAbi, "vote", cfg.TargetAddress, amount, lesser, greater)
-	return nil
+	return v.handleType1Msg(cfg, v.electionTo, nil, v.electionAbi, "vote", cfg.TargetAddress, amount, lesser, greater)
 }
 
 func (v *Voter) QuicklyVote(ctx *cli.Context, cfg *define.Config) error {
 	//---------------------------- create account ----------------
-	_ = v.account.CreateAccount(ctx, cfg)
+	if err := v.account.CreateAccount(ctx, cfg); err != nil {
+		return err
+	}
 	//---------------------------- lock --------------------------
-	_ = v.validator.LockedMAP(ctx, cfg)
+	if err := v.validator.LockedMAP(ctx, cfg); err != nil {
+		return err
+	}
 	//---------------------------- vote --------------------------
-	_ = v.Vote(ctx, cfg)
+	if err := v.Vote(ctx, cfg); err != nil {
+		return err
+	}
 	log.Info("=== End ===")
 	return nil
 }
 
 func (v *Voter) Activate(_ *cli.Context, cfg *define.Config) error {
 	log.Info("=== activate validator gold ===", "account.Address", cfg.From)
-	v.handleType1Msg(cfg, v.electionTo, nil, v.electionAbi, "activate", cfg.TargetAddress)
-	return nil
+	return v.handleType1Msg(cfg, v.electionTo, nil, v.electionAbi, "activate", cfg.TargetAddress)
 }
 
 func (v *Voter) GetActiveVotesForValidator(_ *cli.Context, cfg *define.Config) error {
 	var ret interface{}
 	log.Info("=== getActiveVotesForValidator ===", "admin", cfg.From)
-	v.handleType3Msg(cfg, &ret, v.electionTo, nil, v.electionAbi, "getActiveVotesForValidator", cfg.TargetAddress)
-	log.Info("ActiveVotes", "balance", ret.(*big.Int))
-	return nil
+	if err := v.handleType3Msg(cfg, &ret, v.electionTo, nil, v.electionAbi, "getActiveVotesForValidator", cfg.TargetAddress); err != nil {
+		return err
+	}
+	return writeResult(cfg, ValidatorVotesResult{Validator: cfg.TargetAddress, Votes: amount(ret.(*big.Int))}, func() {
+		log.Info("ActiveVotes", "balance", ret.(*big.Int))
+	})
 }
 
 func (v *Voter) GetPendingVotersForValidator(_ *cli.Context, cfg *define.Config) error {
 	var ret interface{}
 	log.Info("=== getPendingVotersForValidator ===", "admin", cfg.From)
-	v.handleType3Msg(cfg, &ret, v.electionTo, nil, v.electionAbi, "getPendingVotersForValidator", cfg.TargetAddress)
-	log.Info("getPendingVotersForValidator", "voters", ret.([]common.Address))
-	return nil
+	if err := v.handleType3Msg(cfg, &ret, v.electionTo, nil, v.electionAbi, "getPendingVotersForValidator", cfg.TargetAddress); err != nil {
+		return err
+	}
+	voters := append([]common.Address{}, ret.([]common.Address)...)
+	return writeResult(cfg, PendingVotersResult{Validator: cfg.TargetAddress, Voters: voters}, func() {
+		log.Info("getPendingVotersForValidator", "voters", voters)
+	})
 }
 
 func (v *Voter) GetPendingInfoForValidator(_ *cli.Context, cfg *define.Config) error {
@@ -98,80 +108,93 @@ func (v *Voter) GetPendingInfoForValidator(_ *cli.Context, cfg *define.Config) e
 		Epoch interface{}
 	)
 	t := ret{&Value, &Epoch}
-	f := func(output []byte) {
-		err := v.electionAbi.UnpackIntoInterface(&t, "pendingInfo", output)
-		if err != nil {
-			log.Error("getPendingInfoForValidator", "err", err)
-			os.Exit(1)
-		}
+	f := func(output []byte) error {
+		return v.electionAbi.UnpackIntoInterface(&t, "pendingInfo", output)
 	}
 	log.Info("=== getPendingInfoForValidator ===", "admin", cfg.From)
-	v.handleType4Msg(cfg, f, v.electionTo, nil, v.electionAbi, "pendingInfo", cfg.From, cfg.TargetAddress)
-	log.Info("getPendingInfoForValidator", "PendingEpoch", Epoch.(*big.Int), "Balance", Value.(*big.Int))
-	return nil
+	if err := v.handleType4Msg(cfg, f, v.electionTo, nil, v.electionAbi, "pendingInfo", cfg.From, cfg.TargetAddress); err != nil {
+		return err
+	}
+	result := PendingInfoResult{
+		Validator: cfg.TargetAddress,
+		Account:   cfg.From,
+		Votes:     amount(Value.(*big.Int)),
+		Epoch:     amount(Epoch.(*big.Int)),
+	}
+	return writeResult(cfg, result, func() {
+		log.Info("getPendingInfoForValidator", "PendingEpoch", Epoch.(*big.Int), "Balance", Value.(*big.Int))
+	})
 }
 
 func (v *Voter) RevokePending(_ *cli.Context, cfg *define.Config) error {
 	validator := cfg.TargetAddress
 	LockedNum := new(big.Int).Mul(cfg.LockedNum, big.NewInt(1e18))
 
-	greater, lesser, _ := v.getGLSub(cfg, LockedNum, validator)
-	list := v._getValidatorsVotedForByAccount(cfg, cfg.From)
+	greater, lesser, err := v.getGLSub(cfg, LockedNum, validator)
+	if err != nil {
+		return err
+	}
+	list, err := v._getValidatorsVotedForByAccount(cfg, cfg.From)
+	if err != nil {
+		return err
+	}
 	index, err := v.GetIndex(validator, list)
 	if err != nil {
-		log.Crit("revokePending", "err", err)
+		log.Error("revokePending", "err", err)
+		return err
 	}
 	//fmt.Println("=== greater,lesser,index ===", greater, lesser, index)
 	_params := []interface{}{validator, LockedNum, lesser, greater, index}
 	log.Info("=== revokePending ===", "admin", cfg.From)
-	v.handleType1Msg(cfg, v.electionTo, nil, v.electionAbi, "revokePending", _params...)
-	return nil
+	return v.handleType1Msg(cfg, v.electionTo, nil, v.electionAbi, "revokePending", _params...)
 }
 
 func (v *Voter) RevokeActive(_ *cli.Context, cfg *define.Config) error {
 	validator := cfg.TargetAddress
 	LockedNum := new(big.Int).Mul(cfg.LockedNum, big.NewInt(1e18))
-	greater, lesser, _ := v.getGLSub(cfg, LockedNum, validator)
+	greater, lesser, err := v.getGLSub(cfg, LockedNum, validator)
+	if err != nil {
+		return err
+	}
 
-	list := v._getValidatorsVotedForByAccount(cfg, cfg.From)
+	list, err := v._getValidatorsVotedForByAccount(cfg, cfg.From)
+	if err != nil {
+		return err
+	}
 	index, err := v.GetIndex(validator, list)
 	if err != nil {
-		log.Crit("revokePending", "err", err)
+		log.Error("revokeActive", "err", err)
+		return err
 	}
 	_params := []interface{}{validator, LockedNum, lesser, greater, index}
 	log.Info("=== revokeActive ===", "admin", cfg.From)
-	v.handleType1Msg(cfg, v.electionTo, nil, v.electionAbi, "revokeActive", _params...)
-	return nil
+	return v.handleType1Msg(cfg, v.electionTo, nil, v.electionAbi, "revokeActive", _params...)
 }
 
 func (v *Voter) LockedMAP(_ *cli.Context, cfg *define.Config) error {
 	lockedGold := new(big.Int).Mul(cfg.LockedNum, big.NewInt(1e18))
 	log.Info("=== Lock  gold ===")
 	log.Info("Lock  gold", "amount", lockedGold.String())
-	v.handleType2Msg(cfg, v.lockGoldTo, lockedGold, v.lockedGoldAbi, "lock")
-	return nil
+	return v.handleType2Msg(cfg, v.lockGoldTo, lockedGold, v.lockedGoldAbi, "lock")
 }
 
 func (v *Voter) UnlockedMAP(_ *cli.Context, cfg *define.Config) error {
 	lockedGold := new(big.Int).Mul(cfg.LockedNum, big.NewInt(1e18))
 	log.Info("=== unLock validator gold ===")
 	log.Info("unLock validator gold", "amount", lockedGold, "admin", cfg.From)
-	v.handleType1Msg(cfg, v.lockGoldTo, nil, v.lockedGoldAbi, "unlock", lockedGold)
-	return nil
+	return v.handleType1Msg(cfg, v.lockGoldTo, nil, v.lockedGoldAbi, "unlock", lockedGold)
 }
 
 func (v *Voter) RelockMAP(_ *cli.Context, cfg *define.Config) error {
 	lockedGold := new(big.Int).Mul(cfg.LockedNum, big.NewInt(1e18))
 	log.Info("=== relockMAP validator gold ===")
 	log.Info("relockMAP validator gold", "amount", lockedGold)
-	v.handleType1Msg(cfg, v.lockGoldTo, nil, v.lockedGoldAbi, "relock", cfg.RelockIndex, lockedGold)
-	return nil
+	return v.handleType1Msg(cfg, v.lockGoldTo, nil, v.lockedGoldAbi, "relock", cfg.RelockIndex, lockedGold)
 }
 
 func (v *Voter) Withdraw(_ *cli.Context, cfg *define.Config) error {
 	log.Info("=== withdraw validator gold ===", "admin", cfg.From.String())
-	v.handleType1Msg(cfg, v.lockGoldTo, nil, v.lockedGoldAbi, "withdraw", cfg.WithdrawIndex)
-	return nil
+	return v.handleType1Msg(cfg, v.lockGoldTo, nil, v.lockedGoldAbi, "withdraw", cfg.WithdrawIndex)
 }
 
 func (v *Voter) GetTotalVotesForEligibleValidators(_ *cli.Context, cfg *define.Config) error {
@@ -180,74 +203,105 @@ func (v *Voter) GetTotalVotesForEligibleValidators(_ *cli.Context, cfg *define.C
 		Values     interface{}
 	}
 	var t ret
-	f := func(output []byte) {
-		err := v.electionAbi.UnpackIntoInterface(&t, "getTotalVotesForEligibleValidators", output)
-		if err != nil {
-			log.Error("getTotalVotesForEligibleValidators", "err", err)
-			os.Exit(1)
-		}
+	f := func(output []byte) error {
+		return v.electionAbi.UnpackIntoInterface(&t, "getTotalVotesForEligibleValidators", output)
 	}
 	log.Info("=== getTotalVotesForEligibleValidators ===", "admin", cfg.From)
-	v.handleType4Msg(cfg, f, v.electionTo, nil, v.electionAbi, "getTotalVotesForEligibleValidators")
+	if err := v.handleType4Msg(cfg, f, v.electionTo, nil, v.electionAbi, "getTotalVotesForEligibleValidators"); err != nil {
+		return err
+	}
 	Validators := (t.Validators).([]common.Address)
 	Values := (t.Values).([]*big.Int)
+	result := EligibleValidatorsVotesResult{Validators: []ValidatorVotesResult{}}
 	for i := 0; i < len(Validators); i++ {
-		log.Info("Validator:", "addr", Validators[i], "vote amount", Values[i])
+		result.Validators = append(result.Validators, ValidatorVotesResult{Validator: Validators[i], Votes: amount(Values[i])})
 	}
-	return nil
+	return writeResult(cfg, result, func() {
+		for i := 0; i < len(Validators); i++ {
+			log.Info("Validator:", "addr", Validators[i], "vote amount", Values[i])
+		}
+	})
 }
 
 func (v *Voter) GetRegisteredValidatorSigners(_ *cli.Context, cfg *define.Config) error {
 	log.Info("==== getRegisteredValidatorSigners ===")
-	Validators := v._getRegisteredValidatorSigners(cfg)
-	if len(Validators) == 0 {
-		log.Info("nil")
-	}
-	for i := 0; i < len(Validators); i++ {
-		log.Info("Validator:", "index", i, "addr", Validators[i])
+	Validators, err := v._getRegisteredValidatorSigners(cfg)
+	if err != nil {
+		return err
 	}
-	return nil
+	return writeResult(cfg, ValidatorListResult{Validators: append([]common.Address{}, Validators...)}, func() {
+		if len(Validators) == 0 {
+			log.Info("nil")
+		}
+		for i := 0; i < len(Validators); i++ {
+			log.Info("Validator:", "index", i, "addr", Validators[i])
+		}
+	})
 }
 
-func (v *Voter) GetValidator(_ *cli.Context, cfg *define.Config) error {
-	type ret struct {
-		EcdsaPublicKey      interface{}
-		BlsPublicKey        interface{}
-		BlsG1PublicKey      interface{}
-		Score               interface{}
-		Signer              interface{}
-		Commission          interface{}
-		NextCommission      interface{}
-		NextCommissionBlock interface{}
-		SlashMultiplier     interface{}
-		LastSlashed         interface{}
+// validatorFields are the fields of a validator in the Validators contract
+type validatorFields struct {
+	EcdsaPublicKey      []byte
+	BlsPublicKey        []byte
+	BlsG1PublicKey      []byte
+	Score               *big.Int
+	Signer              common.Address
+	Commission          *big.Int
+	NextCommission      *big.Int
+	NextCommissionBlock *big.Int
+	SlashMultiplier     *big.Int
+	LastSlashed         *big.Int
+}
+
+func (v *Voter) queryValidator(cfg *define.Config, target common.Address) (*validatorFields, error) {
+	var t validatorFields
+	f := func(output []byte) error {
+		return v.validatorAbi.UnpackIntoInterface(&t, "getValidator", output)
 	}
-	var t ret
-	f := func(output []byte) {
-		err := v.validatorAbi.UnpackIntoInterface(&t, "getValidator", output)
-		if err != nil {
-			log.Error("getValidator", "err", err)
-			os.Exit(1)
-		}
+	if err := v.handleType4Msg(cfg, f, v.validatorTo, nil, v.validatorAbi, "getValidator", target); err != nil {
+		return nil, err
 	}
+	return &t, nil
+}
 
+func (v *Voter) GetValidator(_ *cli.Context, cfg *define.Config) error {
 	log.Info("=== getValidator ===", "admin", cfg.From)
-	v.handleType4Msg(cfg, f, v.validatorTo, nil, v.validatorAbi, "getValidator", cfg.TargetAddress)
-	log.Info("", "ecdsaPublicKey", common.BytesToHash(t.EcdsaPublicKey.([]byte)).String())
-	log.Info("", "BlsPublicKey", common.BytesToHash(t.BlsPublicKey.([]byte)).String())
-	log.Info("", "BlsG1PublicKey", common.BytesToHash(t.BlsG1PublicKey.([]byte)).String())
-	log.Info("", "Score", ConvertToFraction(t.Score))
-	log.Info("", "Signer", t.Signer)
-	log.Info("", "Commission", ConvertToFraction(t.Commission))
-	log.Info("", "NextCommission", ConvertToFraction(t.NextCommission))
-	log.Info("", "NextCommissionBlock", t.NextCommissionBlock)
-	log.Info("", "SlashMultiplier", ConvertToFraction(t.SlashMultiplier))
-	log.Info("", "LastSlashed", ConvertToFraction(t.LastSlashed))
-	return nil
+	t, err := v.queryValidator(cfg, cfg.TargetAddress)
+	if err != nil {
+		return err
+	}
+	result := ValidatorResult{
+		Validator:           cfg.TargetAddress,
+		EcdsaPublicKey:      t.EcdsaPublicKey,
+		BlsPublicKey:        t.BlsPublicKey,
+		BlsG1PublicKey:      t.BlsG1PublicKey,
+		Score:               ConvertToFraction(t.Score),
+		Signer:              t.Signer,
+		Commission:          ConvertToFraction(t.Commission),
+		NextCommission:      ConvertToFraction(t.NextCommission),
+		NextCommissionBlock: amount(t.NextCommissionBlock),
+		SlashMultiplier:     ConvertToFraction(t.SlashMultiplier),
+		LastSlashed:         amount(t.LastSlashed),
+	}
+	return writeResult(cfg, result, func() {
+		log.Info("", "ecdsaPublicKey", common.BytesToHash(t.EcdsaPublicKey).String())
+		log.Info("", "BlsPublicKey", common.BytesToHash(t.BlsPublicKey).String())
+		log.Info("", "BlsG1PublicKey", common.BytesToHash(t.BlsG1PublicKey).String())
+		log.Info("", "Score", result.Score)
+		log.Info("", "Signer", t.Signer)
+		log.Info("", "Commission", result.Commission)
+		log.Info("", "NextCommission", result.NextCommission)
+		log.Info("", "NextCommissionBlock", t.NextCommissionBlock)
+		log.Info("", "SlashMultiplier", result.SlashMultiplier)
+		log.Info("", "LastSlashed", ConvertToFraction(t.LastSlashed))
+	})
 }
 
 func (v *Voter) GetRewardInfo(_ *cli.Context, cfg *define.Config) error {
-	conn := v.newConn(cfg.RPCAddr)
+	conn, err := v.newConn(cfg.RPCAddr)
+	if err != nil {
+		return err
+	}
 	curBlockNumber, err := conn.BlockNumber(context.Background())
 	epochSize := chain.DefaultGenesisBlock().Config.Istanbul.Epoch
 	if err != nil {
@@ -267,18 +321,26 @@ func (v *Voter) GetRewardInfo(_ *cli.Context, cfg *define.Config) error {
 	if err != nil {
 		return err
 	}
+	result := RewardInfoResult{Epoch: Epoch, FromBlock: queryBlock.Uint64(), ToBlock: queryBlock.Uint64(), Rewards: []RewardResult{}}
 	for _, l := range logs {
 		//validator := common.Bytes2Hex(l.Topics[0].Bytes())
 		validator := common.BytesToAddress(l.Topics[1].Bytes())
 		reward := big.NewInt(0).SetBytes(l.Data[:32])
-		log.Info("", "validator", validator, "reward", reward)
+		result.Rewards = append(result.Rewards, RewardResult{BlockNumber: l.BlockNumber, Validator: validator, Reward: amount(reward)})
 	}
-	log.Info("=== END ===")
-	return nil
+	return writeResult(cfg, result, func() {
+		for _, r := range result.Rewards {
+			log.Info("", "validator", r.Validator, "reward", r.Reward)
+		}
+		log.Info("=== END ===")
+	})
 }
 
 func (v *Voter) getVoterRewardInfo(ctx *cli.Context, cfg *define.Config) error {
-	conn := v.newConn(cfg.RPCAddr)
+	conn, err := v.newConn(cfg.RPCAddr)
+	if err != nil {
+		return err
+	}
 	curBlockNumber, err := conn.BlockNumber(context.Background())
 	epochSize := chain.DefaultGenesisBlock().Config.Istanbul.Epoch
 	if err != nil {
@@ -299,55 +361,75 @@ func (v *Voter) getVoterRewardInfo(ctx *cli.Context, cfg *define.Config) error {
 	if err != nil {
 		return err
 	}
+	result := RewardInfoResult{Epoch: Epoch, FromBlock: firstBlock.Uint64(), ToBlock: endBlock.Uint64(), Rewards: []RewardResult{}}
 	for _, l := range logs {
 		validator := common.BytesToAddress(l.Topics[1].Bytes())
 		reward := big.NewInt(0).SetBytes(l.Data[:32])
-		log.Info("reward to voters", "validator", validator, "reward", reward)
+		result.Rewards = append(result.Rewards, RewardResult{BlockNumber: l.BlockNumber, Validator: validator, Reward: amount(reward)})
 	}
-	log.Info("=== END ===")
-	return nil
+	return writeResult(cfg, result, func() {
+		for _, r := range result.Rewards {
+			log.Info("reward to voters", "validator", r.Validator, "reward", r.Reward)
+		}
+		log.Info("=== END ===")
+	})
 }
 
 func (v *Voter) getNumRegisteredValidators(_ *cli.Context, cfg *define.Config) error {
 	var NumValidators interface{}
-	v.handleType3Msg(cfg, &NumValidators, v.validatorTo, nil, v.validatorAbi, "getNumRegisteredValidators")
+	if err := v.handleType3Msg(cfg, &NumValidators, v.validatorTo, nil, v.validatorAbi, "getNumRegisteredValidators"); err != nil {
+		return err
+	}
 	ret := NumValidators.(*big.Int)
-	log.Info("=== result ===", "num", ret.String())
-	return nil
+	return writeResult(cfg, CountResult{Count: amount(ret)}, func() {
+		log.Info("=== result ===", "num", ret.String())
+	})
 }
 
 func (v *Voter) getTopValidators(_ *cli.Context, cfg *define.Config) error {
 	var TopValidators interface{}
-	v.handleType3Msg(cfg, &TopValidators, v.validatorTo, nil, v.validatorAbi, "getTopValidators", cfg.TopNum)
-	Validators := TopValidators.([]common.Address)
-	for i := 0; i < len(Validators); i++ {
-		log.Info("Validator:", "index", i, "addr", Validators[i])
+	if err := v.handleType3Msg(cfg, &TopValidators, v.validatorTo, nil, v.validatorAbi, "getTopValidators", cfg.TopNum); err != nil {
+		return err
 	}
-	return nil
+	Validators := append([]common.Address{}, TopValidators.([]common.Address)...)
+	return writeResult(cfg, ValidatorListResult{Validators: Validators}, func() {
+		for i := 0; i < len(Validators); i++ {
+			log.Info("Validator:", "index", i, "addr", Validators[i])
+		}
+	})
 }
 
 func (v *Voter) getValidatorEligibility(_ *cli.Context, cfg *define.Config) error {
 	var ret interface{}
-	v.handleType3Msg(cfg, &ret, v.electionTo, nil, v.electionAbi, "getValidatorEligibility", cfg.TargetAddress)
-	log.Info("=== result ===", "bool", ret.(bool))
-	return nil
+	if err := v.handleType3Msg(cfg, &ret, v.electionTo, nil, v.electionAbi, "getValidatorEligibility", cfg.TargetAddress); err != nil {
+		return err
+	}
+	return writeResult(cfg, EligibilityResult{Validator: cfg.TargetAddress, Eligible: ret.(bool)}, func() {
+		log.Info("=== result ===", "bool", ret.(bool))
+	})
 }
 
 func (v *Voter) balanceOf(_ *cli.Context, cfg *define.Config) error {
 	var ret interface{}
 	log.Info("=== balanceOf ===", "admin", cfg.From)
-	v.handleType3Msg(cfg, &ret, v.goldTokenTo, nil, v.goldTokenAbi, "balanceOf", cfg.TargetAddress)
-	log.Info("=== result ===", "balance", ret.(*big.Int).String())
-	return nil
+	if err := v.handleType3Msg(cfg, &ret, v.goldTokenTo, nil, v.goldTokenAbi, "balanceOf", cfg.TargetAddress); err != nil {
+		return err
+	}
+	return writeResult(cfg, BalanceResult{Account: cfg.TargetAddress, Balance: amount(ret.(*big.Int))}, func() {
+		log.Info("=== result ===", "balance", ret.(*big.Int).String())
+	})
 }
 
 func (v *Voter) getTotalVotes(_ *cli.Context, cfg *define.Config) error {
 	var ret interface{}
 	log.Info("=== getAccountLockedGoldRequirement ===", "admin", cfg.From)
-	v.handleType3Msg(cfg, &ret, v.electionTo, nil, v.electionAbi, "getTotalVotes")
+	if err := v.handleType3Msg(cfg, &ret, v.electionTo, nil, v.electionAbi, "getTotalVotes"); err != nil {
+		return err
+	}
 	result := ret.(*big.Int)
-	log.Info("result", "getTotalVotes", result)
-	return nil
+	return writeResult(cfg, TotalVotesResult{TotalVotes: amount(result)}, func() {
+		log.Info("result", "getTotalVotes", result)
+	})
 }
 
 func (v *Voter) getPendingWithdrawals(_ *cli.Context, cfg *define.Config) error {
@@ -358,32 +440,34 @@ func (v *Voter) getPendingWithdrawals(_ *cli.Context, cfg *define.Config) error
 	)
 	t := ret{&Values, &Timestamps}
 	log.Info("=== getPendingWithdrawals ===", "admin", cfg.From, "target", cfg.TargetAddress.String())
-	f := func(output []byte) {
-		err := v.lockedGoldAbi.UnpackIntoInterface(&t, "getPendingWithdrawals", output)
-		if err != nil {
-			log.Error("getPendingWithdrawals", "err", err)
-			os.Exit(1)
-		}
+	f := func(output []byte) error {
+		return v.lockedGoldAbi.UnpackIntoInterface(&t, "getPendingWithdrawals", output)
+	}
+	if err := v.handleType4Msg(cfg, f, v.lockGoldTo, nil, v.lockedGoldAbi, "getPendingWithdrawals", cfg.TargetAddress); err != nil {
+		return err
 	}
-	v.handleType4Msg(cfg, f, v.lockGoldTo, nil, v.lockedGoldAbi, "getPendingWithdrawals", cfg.TargetAddress)
 	Values1 := (Values).([]*big.Int)
 	Timestamps1 := (Timestamps).([]*big.Int)
-	if len(Values1) == 0 {
-		log.Info("nil")
-		return nil
-	}
+	result := PendingWithdrawalsResult{Account: cfg.TargetAddress, Withdrawals: []PendingWithdrawal{}}
 	for i := 0; i < len(Values1); i++ {
-		log.Info("result:", "index", i, "values", Values1[i], "timestamps", Timestamps1[i])
+		result.Withdrawals = append(result.Withdrawals, PendingWithdrawal{Index: i, Value: amount(Values1[i]), Timestamp: amount(Timestamps1[i])})
 	}
-	return nil
+	return writeResult(cfg, result, func() {
+		if len(Values1) == 0 {
+			log.Info("nil")
+			return
+		}
+		for i := 0; i < len(Values1); i++ {
+			log.Info("result:", "index", i, "values", Values1[i], "timestamps", Timestamps1[i])
+		}
+	})
 }
 
 func (v *Voter) setValidatorLockedGoldRequirements(_ *cli.Context, cfg *define.Config) error {
 	value := new(big.Int).Mul(big.NewInt(int64(cfg.Value)), big.NewInt(1e18))
 	duration := big.NewInt(cfg.Duration)
 	log.Info("=== setValidatorLockedGoldRequirements ===", "admin", cfg.From.String())
-	v.handleType1Msg(cfg, v.validatorTo, nil, v.validatorAbi, "setValidatorLockedGoldRequirements", value, duration)
-	return nil
+	return v.handleType1Msg(cfg, v.validatorTo, nil, v.validatorAbi, "setValidatorLockedGoldRequirements", value, duration)
 }
 
 func (v *Voter) setImplementation(_ *cli.Context, cfg *define.Config) error {
@@ -391,8 +475,7 @@ func (v *Voter) setImplementation(_ *cli.Context, cfg *define.Config) error {
 	ContractAddress := cfg.ContractAddress
 	ProxyAbi := mapprotocol.AbiFor("Proxy")
 	log.Info("=== setImplementation ===", "admin", cfg.From.String())
-	v.handleType1Msg(cfg, ContractAddress, nil, ProxyAbi, "_setImplementation", implementation)
-	return nil
+	return v.handleType1Msg(cfg, ContractAddress, nil, ProxyAbi, "_setImplementation", implementation)
 }
 
 func (v *Voter) setContractOwner(_ *cli.Context, cfg *define.Config) error {
@@ -401,8 +484,7 @@ func (v *Voter) setContractOwner(_ *cli.Context, cfg *define.Config) error {
 	abiValidators := cfg.ValidatorParameters.ValidatorABI
 	log.Info("ProxyAddress", "ContractAddress", ContractAddress, "NewOwner", NewOwner.String())
 	log.Info("=== setOwner ===", "admin", cfg.From.String())
-	v.handleType1Msg(cfg, ContractAddress, nil, abiValidators, "transferOwnership", NewOwner)
-	return nil
+	return v.handleType1Msg(cfg, ContractAddress, nil, abiValidators, "transferOwnership", NewOwner)
 }
 
 func (v *Voter) setProxyContractOwner(_ *cli.Context, cfg *define.Config) error {
@@ -411,77 +493,79 @@ func (v *Voter) setProxyContractOwner(_ *cli.Context, cfg *define.Config) error
 	log.Info("ProxyAddress", "ContractAddress", ContractAddress, "NewOwner", NewOwner.String())
 	ProxyAbi := mapprotocol.AbiFor("Proxy") //代理ABI
 	log.Info("=== setOwner ===", "admin", cfg.From.String())
-	v.handleType1Msg(cfg, ContractAddress, nil, ProxyAbi, "_transferOwnership", NewOwner)
-	return nil
+	return v.handleType1Msg(cfg, ContractAddress, nil, ProxyAbi, "_transferOwnership", NewOwner)
 }
 
 func (v *Voter) getProxyContractOwner(_ *cli.Context, cfg *define.Config) error {
 	log.Info("=== getOwner ===", "admin", cfg.From.String())
 	var ret interface{}
 	ProxyAbi := mapprotocol.AbiFor("Proxy")
-	v.handleType3Msg(cfg, &ret, cfg.ContractAddress, nil, ProxyAbi, "_getOwner")
+	if err := v.handleType3Msg(cfg, &ret, cfg.ContractAddress, nil, ProxyAbi, "_getOwner"); err != nil {
+		return err
+	}
 	result := ret
-	log.Info("getOwner", "Owner ", result)
-	return nil
+	return writeResult(cfg, OwnerResult{Contract: cfg.ContractAddress, Owner: result.(common.Address)}, func() {
+		log.Info("getOwner", "Owner ", result)
+	})
 }
 
 func (v *Voter) getContractOwner(_ *cli.Context, cfg *define.Config) error {
 	log.Info("=== getOwner ===", "admin", cfg.From.String())
 	var ret interface{}
-	v.handleType3Msg(cfg, &ret, cfg.ContractAddress, nil, v.validatorAbi, "owner")
+	if err := v.handleType3Msg(cfg, &ret, cfg.ContractAddress, nil, v.validatorAbi, "owner"); err != nil {
+		return err
+	}
 	result := ret
-	log.Info("getOwner", "Owner ", result)
-	return nil
+	return writeResult(cfg, OwnerResult{Contract: cfg.ContractAddress, Owner: result.(common.Address)}, func() {
+		log.Info("getOwner", "Owner ", result)
+	})
 }
 
 func (v *Voter) updateBlsPublicKey(_ *cli.Context, cfg *define.Config) error {
 	log.Info("=== updateBlsPublicKey ===")
 	_params := []interface{}{cfg.PublicKey[1:], cfg.BlsPub[:], cfg.BlsG1Pub[:], cfg.BLSProof}
-	v.handleType1Msg(cfg, v.validatorTo, nil, v.validatorAbi, "updateBlsPublicKey", _params...)
-	return nil
+	return v.handleType1Msg(cfg, v.validatorTo, nil, v.validatorAbi, "updateBlsPublicKey", _params...)
 }
 
 func (v *Voter) setNextCommissionUpdate(_ *cli.Context, cfg *define.Config) error {
 	log.Info("=== setNextCommissionUpdate ===", "commission", cfg.Commission)
 	Commission := cfg.Commission
-	v.handleType1Msg(cfg, v.validatorTo, nil, v.validatorAbi, "setNextCommissionUpdate", big.NewInt(0).SetUint64(Commission))
-	return nil
+	return v.handleType1Msg(cfg, v.validatorTo, nil, v.validatorAbi, "setNextCommissionUpdate", big.NewInt(0).SetUint64(Commission))
 }
 
 func (v *Voter) updateCommission(_ *cli.Context, cfg *define.Config) error {
 	log.Info("=== updateCommission ===")
-	v.handleType1Msg(cfg, v.validatorTo, nil, v.validatorAbi, "updateCommission")
-	return nil
+	return v.handleType1Msg(cfg, v.validatorTo, nil, v.validatorAbi, "updateCommission")
 }
 
 func (v *Voter) setTargetValidatorEpochPayment(_ *cli.Context, cfg *define.Config) error {
 	value := new(big.Int).Mul(big.NewInt(int64(cfg.Value)), big.NewInt(1e18))
 	log.Info("=== setTargetValidatorEpochPayment ===", "admin", cfg.From.String())
-	v.handleType1Msg(cfg, v.epochRewardsTo, nil, v.epochRewardsAbi, "setTargetValidatorEpochPayment", value)
-	return nil
+	return v.handleType1Msg(cfg, v.epochRewardsTo, nil, v.epochRewardsAbi, "setTargetValidatorEpochPayment", value)
 }
 
 func (v *Voter) setEpochMaintainerPaymentFraction(_ *cli.Context, cfg *define.Config) error {
 	fixed := fixed.MustNew(cfg.Fixed).BigInt()
 	log.Info("=== setEpochMaintainerPaymentFraction ===", "admin", cfg.From.String())
-	v.handleType1Msg(cfg, v.epochRewardsTo, nil, v.epochRewardsAbi, "setEpochMaintainerPaymentFraction", fixed)
-	return nil
+	return v.handleType1Msg(cfg, v.epochRewardsTo, nil, v.epochRewardsAbi, "setEpochMaintainerPaymentFraction", fixed)
 }
 
 func (v *Voter) setMgrMaintainerAddress(_ *cli.Context, cfg *define.Config) error {
 	address := cfg.TargetAddress
 	log.Info("=== setMgrMaintainerAddress ===", "admin", cfg.From.String())
-	v.handleType1Msg(cfg, v.epochRewardsTo, nil, v.epochRewardsAbi, "setMgrMaintainerAddress", address)
-	return nil
+	return v.handleType1Msg(cfg, v.epochRewardsTo, nil, v.epochRewardsAbi, "setMgrMaintainerAddress", address)
 }
 
 func (v *Voter) getMgrMaintainerAddress(_ *cli.Context, cfg *define.Config) error {
 	log.Info("=== getMgrMaintainerAddress ===", "admin", cfg.From.String())
 	var ret interface{}
-	v.handleType3Msg(cfg, &ret, v.epochRewardsTo, nil, v.epochRewardsAbi, "getMgrMaintainerAddress")
+	if err := v.handleType3Msg(cfg, &ret, v.epochRewardsTo, nil, v.epochRewardsAbi, "getMgrMaintainerAddress"); err != nil {
+		return err
+	}
 	result := ret
-	log.Info("getMgrMaintainerAddress", "address ", result)
-	return nil
+	return writeResult(cfg, MaintainerResult{Maintainer: result.(common.Address)}, func() {
+		log.Info("getMgrMaintainerAddress", "address ", result)
+	})
 }
 
 func ConvertToFraction(num interface{}) string {
@@ -496,10 +580,12 @@ func ConvertToFraction(num interface{}) string {
 	return str
 }
 
-func (v *Voter) _getRegisteredValidatorSigners(cfg *define.Config) []common.Address {
+func (v *Voter) _getRegisteredValidatorSigners(cfg *define.Config) ([]common.Address, error) {
 	var ValidatorSigners interface{}
-	v.handleType3Msg(cfg, &ValidatorSigners, v.validatorTo, nil, v.validatorAbi, "getRegisteredValidatorSigners")
-	return ValidatorSigners.([]common.Address)
+	if err := v.handleType3Msg(cfg, &ValidatorSigners, v.validatorTo, nil, v.validatorAbi, "getRegisteredValidatorSigners"); err != nil {
+		return nil, err
+	}
+	return ValidatorSigners.([]common.Address), nil
 }
 
 func (v *Voter) getGL(cfg *define.Config, target common.Address) (common.Address, common.Address, error) {
@@ -510,14 +596,12 @@ func (v *Voter) getGL(cfg *define.Config, target common.Address) (common.Address
 	var t ret
 	electionAddress := cfg.ElectionParameters.ElectionAddress
 	abiElection := cfg.ElectionParameters.ElectionABI
-	f := func(output []byte) {
-		err := abiElection.UnpackIntoInterface(&t, "getTotalVotesForEligibleValidators", output)
-		if err != nil {
-			log.Error("getTotalVotesForEligibleValidators setLesserGreater", "err", err)
-			os.Exit(1)
-		}
+	f := func(output []byte) error {
+		return abiElection.UnpackIntoInterface(&t, "getTotalVotesForEligibleValidators", output)
+	}
+	if err := v.handleType4Msg(cfg, f, electionAddress, nil, abiElection, "getTotalVotesForEligibleValidators"); err != nil {
+		return params.ZeroAddress, params.ZeroAddress, err
 	}
-	v.handleType4Msg(cfg, f, electionAddress, nil, abiElection, "getTotalVotesForEligibleValidators")
 	validators := (t.Validators).([]common.Address)
 	votes := (t.Values).([]*big.Int)
 	voteTotals := make([]voteTotal, len(validators))
@@ -560,14 +644,12 @@ func (v *Voter) getGLSub(cfg *define.Config, SubValue *big.Int, target common.Ad
 		Values     interface{}
 	}
 	var t ret
-	f := func(output []byte) {
-		err := v.electionAbi.UnpackIntoInterface(&t, "getTotalVotesForEligibleValidators", output)
-		if err != nil {
-			log.Error("getTotalVotesForEligibleValidators setLesserGreater", "err", err)
-			os.Exit(1)
-		}
+	f := func(output []byte) error {
+		return v.electionAbi.UnpackIntoInterface(&t, "getTotalVotesForEligibleValidators", output)
+	}
+	if err := v.handleType4Msg(cfg, f, v.electionTo, nil, v.electionAbi, "getTotalVotesForEligibleValidators"); err != nil {
+		return params.ZeroAddress, params.ZeroAddress, err
 	}
-	v.handleType4Msg(cfg, f, v.electionTo, nil, v.electionAbi, "getTotalVotesForEligibleValidators")
 	validators := (t.Validators).([]common.Address)
 	votes := (t.Values).([]*big.Int)
 	voteTotals := make([]voteTotal, len(validators))
@@ -608,11 +690,13 @@ func (v *Voter) getGLSub(cfg *define.Config, SubValue *big.Int, target common.Ad
 	return params.ZeroAddress, params.ZeroAddress, define.NoTargetValidatorError
 }
 
-func (v *Voter) _getValidatorsVotedForByAccount(cfg *define.Config, target common.Address) []common.Address {
+func (v *Voter) _getValidatorsVotedForByAccount(cfg *define.Config, target common.Address) ([]common.Address, error) {
 	var ret interface{}
-	v.handleType3Msg(cfg, &ret, v.electionTo, nil, v.electionAbi, "getValidatorsVotedForByAccount", target)
+	if err := v.handleType3Msg(cfg, &ret, v.electionTo, nil, v.electionAbi, "getValidatorsVotedForByAccount", target); err != nil {
+		return nil, err
+	}
 	result := ret.([]common.Address)
-	return result
+	return result, nil
 }
 
 func (v *Voter) GetIndex(target common.Address, list []common.Address) (*big.Int, error) {
@@ -15,17 +15,17 @@ const (
 	localHost   = "localhost"
 )
 
-func DialConn(addr string) *ethclient.Client {
+func DialConn(addr string) (*ethclient.Client, error) {
 	conn, err := ethclient.Dial(addr)
 	if err != nil {
-		panic(fmt.Sprintf("Failed to connect to the map chain, addr: %s, error: %v", addr, err))
+		return nil, fmt.Errorf("failed to connect to the map chain, addr: %s, error: %v", addr, err)
 	}
 
 	_, err = conn.ChainID(context.Background())
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to connect to the map chain, addr: %s, error: %v", addr, err)
 	}
-	return conn
+	return conn, nil
 }
 
 func DialRpc(config *define.Config) (*rpc.Client, string) {
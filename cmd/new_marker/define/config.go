@@ -10,8 +10,12 @@ import (
 	"github.com/mapprotocol/atlas/params"
 	"golang.org/x/term"
 	"gopkg.in/urfave/cli.v1"
+	"io/ioutil"
 	"math/big"
+	"os"
+	"strings"
 	"syscall"
+	"time"
 )
 
 type LockedGoldParameters struct {
@@ -71,6 +75,14 @@ type Config struct {
 	ImplementationAddress common.Address
 	RPCAddr               string
 	GasLimit              int64
+	TxFile                string        // Unsigned transaction file written by build-tx
+	Journal               string        // Journal file of the plan applied by apply
+	ReceiptTimeout        time.Duration // Time to wait for the receipts of a batch of plan transactions
+	MetricsAddr           string        // Listening address of the monitor metrics
+	Webhook               string        // URL receiving the monitor alerts
+	ScoreDrop             float64       // Drop of a validator score raising a monitor alert
+	Output                string        // Output format of the query results
+	PasswordFile          string        // File holding the keystore password
 	Verbosity             string
 	Name                  string
 	MetadataURL           string
@@ -92,6 +104,9 @@ func AssemblyConfig(ctx *cli.Context) (*Config, error) {
 	config.Verbosity = "3"
 	config.Name = "validator"
 	config.From = common.HexToAddress("0x0000000000000000000000000000000000000000") //  default
+	config.Output = OutputText
+	config.MetricsAddr = MetricsAddrFlag.Value
+	config.ScoreDrop = ScoreDropFlag.Value
 
 	//-----------------------------------------------------
 	if ctx.IsSet(KeyStoreFlag.Name) {
@@ -169,26 +184,45 @@ func AssemblyConfig(ctx *cli.Context) (*Config, error) {
 	if ctx.IsSet(GasLimitFlag.Name) {
 		config.GasLimit = ctx.Int64(GasLimitFlag.Name)
 	}
+	if ctx.IsSet(TxFileFlag.Name) {
+		config.TxFile = ctx.String(TxFileFlag.Name)
+	}
+	if ctx.IsSet(JournalFlag.Name) {
+		config.Journal = ctx.String(JournalFlag.Name)
+	}
+	if ctx.IsSet(ReceiptTimeoutFlag.Name) {
+		config.ReceiptTimeout = ctx.Duration(ReceiptTimeoutFlag.Name)
+	}
+	if ctx.IsSet(MetricsAddrFlag.Name) {
+		config.MetricsAddr = ctx.String(MetricsAddrFlag.Name)
+	}
+	if ctx.IsSet(WebhookFlag.Name) {
+		config.Webhook = ctx.String(WebhookFlag.Name)
+	}
+	if ctx.IsSet(ScoreDropFlag.Name) {
+		config.ScoreDrop = ctx.Float64(ScoreDropFlag.Name)
+	}
+	if ctx.GlobalIsSet(OutputFlag.Name) {
+		config.Output = ctx.GlobalString(OutputFlag.Name)
+	}
+	if config.Output != OutputText && config.Output != OutputJSON {
+		return nil, fmt.Errorf("invalid output format %q, want %s or %s", config.Output, OutputText, OutputJSON)
+	}
+	if ctx.GlobalIsSet(PasswordFileFlag.Name) {
+		config.PasswordFile = ctx.GlobalString(PasswordFileFlag.Name)
+	}
 	if path != "" {
-		_account, err := LoadAccount(path, string(GetPassword(fmt.Sprintf("Enter password for key %s:", path))))
+		password, err := ReadPassword(config.PasswordFile, fmt.Sprintf("Enter password for key %s:", path))
 		if err != nil {
 			return nil, err
 		}
-		blsPub, err := _account.BLSPublicKey()
+		_account, err := LoadAccount(path, password)
 		if err != nil {
 			return nil, err
 		}
-		blsG1Pub, err := _account.BLSG1PublicKey()
-		if err != nil {
+		if err := config.SetAccount(_account); err != nil {
 			return nil, err
 		}
-		config.PublicKey = _account.PublicKey()
-
-		config.From = _account.Address
-		config.PrivateKey = _account.PrivateKey
-		config.BlsPub = blsPub
-		config.BlsG1Pub = blsG1Pub
-		config.BLSProof = _account.MustBLSProofOfPossession()
 	}
 
 	ValidatorAddress := mapprotocol.MustProxyAddressFor("Validators")
@@ -219,16 +253,61 @@ func AssemblyConfig(ctx *cli.Context) (*Config, error) {
 	return &config, nil
 }
 
-func GetPassword(msg string) []byte {
+// SetAccount makes the account the sender of the transactions
+func (c *Config) SetAccount(_account *Account) error {
+	blsPub, err := _account.BLSPublicKey()
+	if err != nil {
+		return err
+	}
+	blsG1Pub, err := _account.BLSG1PublicKey()
+	if err != nil {
+		return err
+	}
+	c.PublicKey = _account.PublicKey()
+
+	c.From = _account.Address
+	c.PrivateKey = _account.PrivateKey
+	c.BlsPub = blsPub
+	c.BlsG1Pub = blsG1Pub
+	c.BLSProof = _account.MustBLSProofOfPossession()
+	return nil
+}
+
+// ReadPassword returns the keystore password from the password file, else from the
+// PasswordEnv environment variable, else from an interactive prompt
+func ReadPassword(passwordFile string, msg string) (string, error) {
+	if passwordFile != "" {
+		data, err := ioutil.ReadFile(passwordFile)
+		if err != nil {
+			return "", fmt.Errorf("read password file: %v", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if password, ok := os.LookupEnv(PasswordEnv); ok {
+		return password, nil
+	}
+	password, err := GetPassword(msg)
+	if err != nil {
+		return "", err
+	}
+	return string(password), nil
+}
+
+// GetPassword prompts for a password on the terminal. The prompt is written to stderr so
+// that it does not mix with the output of the command.
+func GetPassword(msg string) ([]byte, error) {
+	if !term.IsTerminal(syscall.Stdin) {
+		return nil, fmt.Errorf("no terminal to prompt for the password, use --%s or %s", PasswordFileFlag.Name, PasswordEnv)
+	}
 	for {
-		fmt.Println(msg)
-		fmt.Print("> ")
+		fmt.Fprintln(os.Stderr, msg)
+		fmt.Fprint(os.Stderr, "> ")
 		password, err := term.ReadPassword(syscall.Stdin)
 		if err != nil {
-			fmt.Printf("invalid input: %s\n", err)
+			fmt.Fprintf(os.Stderr, "invalid input: %s\n", err)
 		} else {
-			fmt.Printf("\n")
-			return password
+			fmt.Fprintf(os.Stderr, "\n")
+			return password, nil
 		}
 	}
 }
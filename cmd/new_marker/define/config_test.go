@@ -0,0 +1,34 @@
+package define
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadPassword(t *testing.T) {
+	dir, err := ioutil.TempDir("", "marker-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "password")
+	if err := ioutil.WriteFile(file, []byte("from file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv(PasswordEnv, "from env")
+	defer os.Unsetenv(PasswordEnv)
+
+	// The password file takes precedence over the environment
+	if password, err := ReadPassword(file, ""); err != nil || password != "from file" {
+		t.Errorf("password file mismatch: have %q, %v, want %q", password, err, "from file")
+	}
+	if password, err := ReadPassword("", ""); err != nil || password != "from env" {
+		t.Errorf("password env mismatch: have %q, %v, want %q", password, err, "from env")
+	}
+	if _, err := ReadPassword(filepath.Join(dir, "missing"), ""); err == nil {
+		t.Error("expected error for missing password file")
+	}
+}
@@ -2,6 +2,16 @@ package define
 
 import "errors"
 
+// Output formats of the query commands
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// PasswordEnv is the environment variable holding the keystore password, read when no
+// password file is given
+const PasswordEnv = "MARKER_PASSWORD"
+
 var (
 	GetIndexError          = errors.New("get Index nil(no Address)")
 	NoTargetValidatorError = errors.New("not find target validator")
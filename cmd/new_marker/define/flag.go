@@ -1,6 +1,8 @@
 package define
 
 import (
+	"time"
+
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -124,6 +126,60 @@ var (
 		Usage: "The address corresponding to the keystore",
 		Value: "",
 	}
+	TxFileFlag = cli.StringFlag{
+		Name:  "txfile",
+		Usage: "Unsigned transaction file to write the transactions to instead of sending them",
+		Value: "",
+	}
+	JournalFlag = cli.StringFlag{
+		Name:  "journal",
+		Usage: "Journal file of the applied plan, <plan>.journal by default",
+		Value: "",
+	}
+	ReceiptTimeoutFlag = cli.DurationFlag{
+		Name:  "receiptTimeout",
+		Usage: "Time to wait for the receipts of a batch of plan transactions",
+		Value: 5 * time.Minute,
+	}
+	MetricsAddrFlag = cli.StringFlag{
+		Name:  "metricsAddr",
+		Usage: "HTTP listening address of the monitor, serving its metrics on /metrics",
+		Value: "127.0.0.1:6062",
+	}
+	WebhookFlag = cli.StringFlag{
+		Name:  "webhook",
+		Usage: "URL the monitor alerts are posted to as JSON",
+		Value: "",
+	}
+	ScoreDropFlag = cli.Float64Flag{
+		Name:  "scoreDrop",
+		Usage: "Drop of a validator score, as a fraction, raising a monitor alert",
+		Value: 0.01,
+	}
+	OutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "Output format of the query results, text or json (json is written to stdout)",
+		Value: OutputText,
+	}
+	PasswordFileFlag = cli.StringFlag{
+		Name:  "passwordfile",
+		Usage: "File holding the keystore password, read instead of prompting (or set " + PasswordEnv + ")",
+		Value: "",
+	}
+	EnvFlag = cli.StringFlag{
+		Name:  "env",
+		Usage: "Marker environment folder, created by genesis --newenv",
+	}
+	GethPathFlag = cli.StringFlag{
+		Name:  "geth",
+		Usage: "Path of the atlas binary run by the validators",
+		Value: "atlas",
+	}
+	ProxyPortFlag = cli.Int64Flag{
+		Name:  "proxyPort",
+		Usage: "First port of the proxies between the validators, required by the link faults (0 to connect them directly)",
+		Value: 40303,
+	}
 	BuildpathFlag = cli.StringFlag{
 		Name:  "buildpath",
 		Usage: "Directory where smartcontract truffle build file live",
@@ -169,6 +225,12 @@ var TemplateFlags = []cli.Flag{
 	},
 }
 
+// GlobalFlags are the flags of the app, given before the command
+var GlobalFlags = []cli.Flag{
+	OutputFlag,
+	PasswordFileFlag,
+}
+
 var BaseFlagCombination = []cli.Flag{
 	RPCAddrFlag,
 	KeyStoreFlag,
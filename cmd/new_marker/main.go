@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"github.com/mapprotocol/atlas/cmd/new_marker/cmd"
+	"github.com/mapprotocol/atlas/cmd/new_marker/define"
 	"gopkg.in/urfave/cli.v1"
 	"os"
 	"sort"
@@ -23,6 +24,7 @@ func init() {
 		_, _ = fmt.Fprintf(os.Stderr, "No such command: %s\n", cmd)
 		os.Exit(1)
 	}
+	app.Flags = define.GlobalFlags
 	app.Commands = append(app.Commands, cmd.AccountSet...)
 	app.Commands = append(app.Commands, cmd.ValidatorSet...)
 	app.Commands = append(app.Commands, cmd.VoterSet...)
@@ -0,0 +1,199 @@
+package writer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// JournalEntry is a line of the plan journal
+type JournalEntry struct {
+	Plan      *common.Hash  `json:"plan,omitempty"` // Hash of the plan, in the first line only
+	Operation int           `json:"op"`
+	Tx        hexutil.Bytes `json:"tx,omitempty"`   // Signed transaction, journaled before it is sent
+	Done      bool          `json:"done,omitempty"` // All the transactions of the operation succeeded
+}
+
+// Journal is an append only log of the transactions of a plan applied by marker apply, with the
+// aim of resuming the plan after a crash without sending any of its transactions twice. Each
+// transaction is journaled before it is sent, and each operation once all its transactions
+// succeeded.
+type Journal struct {
+	path   string
+	writer *os.File
+	sent   map[int][]*types.Transaction // Transactions sent per operation
+	done   map[int]bool                 // Operations whose transactions all succeeded
+	mu     sync.Mutex
+}
+
+// OpenJournal loads the journal of a plan, creating it if it doesn't exist. A journal written for
+// another plan is rejected.
+func OpenJournal(path string, plan common.Hash) (*Journal, error) {
+	journal := &Journal{
+		path: path,
+		sent: make(map[int][]*types.Transaction),
+		done: make(map[int]bool),
+	}
+	if err := journal.load(plan); err != nil {
+		return nil, err
+	}
+	writer, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	journal.writer = writer
+	if info, err := writer.Stat(); err == nil && info.Size() == 0 {
+		if err := journal.write(&JournalEntry{Plan: &plan}); err != nil {
+			writer.Close()
+			return nil, err
+		}
+	}
+	return journal, nil
+}
+
+// load parses the journal file, if any
+func (j *Journal) load(plan common.Hash) error {
+	input, err := os.Open(j.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(nil, 1024*1024)
+	total := 0
+	for line := 1; scanner.Scan(); line++ {
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A crash while writing leaves the last line truncated, drop it
+			log.Warn("Dropping invalid journal entry", "file", j.path, "line", line, "err", err)
+			continue
+		}
+		if line == 1 {
+			if entry.Plan == nil || *entry.Plan != plan {
+				return fmt.Errorf("journal %s belongs to another plan, remove it to apply the plan from the start", j.path)
+			}
+			continue
+		}
+		if entry.Done {
+			j.done[entry.Operation] = true
+		}
+		if len(entry.Tx) > 0 {
+			tx := new(types.Transaction)
+			if err := tx.UnmarshalBinary(entry.Tx); err != nil {
+				return fmt.Errorf("invalid transaction in journal %s line %d: %v", j.path, line, err)
+			}
+			j.sent[entry.Operation] = append(j.sent[entry.Operation], tx)
+			total++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	log.Info("Loaded plan journal", "file", j.path, "transactions", total, "operations", len(j.done))
+	return nil
+}
+
+// write appends the entry to the journal and flushes it to disk
+func (j *Journal) write(entry *JournalEntry) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(entry); err != nil {
+		return err
+	}
+	if _, err := j.writer.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return j.writer.Sync()
+}
+
+// Sent returns the journaled transactions of the operation
+func (j *Journal) Sent(op int) []*types.Transaction {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]*types.Transaction{}, j.sent[op]...)
+}
+
+// Done reports whether all the transactions of the operation succeeded
+func (j *Journal) Done(op int) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.done[op]
+}
+
+// Insert journals a signed transaction of the operation, before it is sent
+func (j *Journal) Insert(op int, tx *types.Transaction) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.write(&JournalEntry{Operation: op, Tx: raw}); err != nil {
+		return err
+	}
+	j.sent[op] = append(j.sent[op], tx)
+	return nil
+}
+
+// Complete marks all the transactions of the operation as succeeded
+func (j *Journal) Complete(op int) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if err := j.write(&JournalEntry{Operation: op, Done: true}); err != nil {
+		return err
+	}
+	j.done[op] = true
+	return nil
+}
+
+// Close closes the journal file
+func (j *Journal) Close() error {
+	return j.writer.Close()
+}
+
+// WaitJournaledTx waits for the receipt of a journaled transaction, sending it again if the node
+// doesn't know it, as after a crash before it was sent. It returns an error if the transaction
+// failed, or if it is not mined before the context is done.
+func WaitJournaledTx(ctx context.Context, conn *ethclient.Client, tx *types.Transaction) error {
+	logger := log.New("func", "WaitJournaledTx")
+	for {
+		receipt, err := conn.TransactionReceipt(ctx, tx.Hash())
+		if err == nil {
+			if receipt.Status == types.ReceiptStatusFailed {
+				logger.Error("Transaction Failed ", "txHash", tx.Hash(), "number", receipt.BlockNumber.Uint64())
+				return fmt.Errorf("transaction %s failed in block %d", tx.Hash().Hex(), receipt.BlockNumber.Uint64())
+			}
+			logger.Info("Transaction Success", "txHash", tx.Hash(), "number", receipt.BlockNumber.Uint64())
+			return nil
+		}
+		if ctx.Err() == nil {
+			if _, _, err := conn.TransactionByHash(ctx, tx.Hash()); err != nil && ctx.Err() == nil {
+				logger.Info("Sending journaled transaction", "txHash", tx.Hash(), "nonce", tx.Nonce())
+				if err := conn.SendTransaction(ctx, tx); err != nil && !strings.Contains(err.Error(), "already known") && ctx.Err() == nil {
+					logger.Error("SendTransaction", "txHash", tx.Hash(), "error", err)
+					return err
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("transaction %s not mined: %w", tx.Hash().Hex(), ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package writer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func TestJournalResume(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := types.LatestSignerForChainID(big.NewInt(211))
+	newTx := func(nonce uint64) *types.Transaction {
+		tx, err := types.SignTx(types.NewTransaction(nonce, common.HexToAddress("0x01"), big.NewInt(1), DefaultGasLimit, big.NewInt(1000), nil), signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+
+	dir, err := ioutil.TempDir("", "plan-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "plan.journal")
+	plan := common.HexToHash("0x01")
+
+	journal, err := OpenJournal(path, plan)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	tx0, tx1, tx2 := newTx(0), newTx(1), newTx(2)
+	for _, insert := range []struct {
+		op int
+		tx *types.Transaction
+	}{{0, tx0}, {0, tx1}, {2, tx2}} {
+		if err := journal.Insert(insert.op, insert.tx); err != nil {
+			t.Fatalf("Insert: %v", err)
+		}
+	}
+	if err := journal.Complete(0); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	journal.Close()
+
+	// A crash while writing leaves a truncated line
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0600)
+	f.WriteString(`{"op":2,"tx":"0x`)
+	f.Close()
+
+	journal, err = OpenJournal(path, plan)
+	if err != nil {
+		t.Fatalf("OpenJournal after crash: %v", err)
+	}
+	defer journal.Close()
+	if !journal.Done(0) || journal.Done(2) {
+		t.Errorf("done operations mismatch: op 0 %v, op 2 %v", journal.Done(0), journal.Done(2))
+	}
+	if sent := journal.Sent(0); len(sent) != 2 || sent[0].Hash() != tx0.Hash() || sent[1].Hash() != tx1.Hash() {
+		t.Errorf("operation 0 transactions mismatch: %v", sent)
+	}
+	if sent := journal.Sent(2); len(sent) != 1 || sent[0].Hash() != tx2.Hash() {
+		t.Errorf("operation 2 transactions mismatch: %v", sent)
+	}
+
+	// The journal of a plan is rejected for another one
+	if _, err := OpenJournal(path, common.HexToHash("0x02")); err == nil {
+		t.Error("expected error for journal of another plan")
+	}
+}
+
+func TestWaitJournaledTxTimeout(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	tx, err := types.SignTx(types.NewTransaction(0, common.HexToAddress("0x01"), big.NewInt(1), DefaultGasLimit, big.NewInt(1000), nil), types.LatestSignerForChainID(big.NewInt(211)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The node accepts the transaction but never mines it
+	var sent int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		result := "null"
+		if req.Method == "eth_sendRawTransaction" {
+			atomic.AddInt32(&sent, 1)
+			result = `"` + tx.Hash().Hex() + `"`
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, req.ID, result)
+	}))
+	defer server.Close()
+	conn, err := ethclient.Dial(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := WaitJournaledTx(ctx, conn, tx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error mismatch: have %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("wait not bounded by the context: %v", elapsed)
+	}
+	if atomic.LoadInt32(&sent) == 0 {
+		t.Error("unknown transaction not sent again")
+	}
+}
@@ -25,13 +25,14 @@ type Message struct {
 	abiMethod   string
 	to          common.Address
 	abi         *abi.ABI
-	DoneCh      chan<- struct{}
+	DoneCh      chan<- error
 	ret         interface{}
-	solveResult func([]byte)
+	solveResult func([]byte) error
 	gasLimit    uint64
+	txFile      string // Unsigned transaction file to write instead of sending the transaction
 }
 
-func NewMessage(messageType string, ch chan<- struct{}, cfg *define.Config, to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) Message {
+func NewMessage(messageType string, ch chan<- error, cfg *define.Config, to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) Message {
 	return Message{
 		messageType: messageType,
 		from:        cfg.From,
@@ -43,11 +44,12 @@ func NewMessage(messageType string, ch chan<- struct{}, cfg *define.Config, to c
 		input:       mapprotocol.PackInput(abi, abiMethod, params...),
 		DoneCh:      ch,
 		gasLimit:    uint64(cfg.GasLimit),
+		txFile:      cfg.TxFile,
 	}
 }
 
 //NewMessageRet1 need to handle return params
-func NewMessageRet1(messageType string, ch chan<- struct{}, cfg *define.Config, ret interface{}, to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) Message {
+func NewMessageRet1(messageType string, ch chan<- error, cfg *define.Config, ret interface{}, to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) Message {
 	return Message{
 		messageType: messageType,
 		from:        cfg.From,
@@ -60,11 +62,12 @@ func NewMessageRet1(messageType string, ch chan<- struct{}, cfg *define.Config,
 		DoneCh:      ch,
 		ret:         ret,
 		gasLimit:    uint64(cfg.GasLimit),
+		txFile:      cfg.TxFile,
 	}
 }
 
 //NewMessageRet2 need to handle return params
-func NewMessageRet2(messageType string, ch chan<- struct{}, cfg *define.Config, solveResult func([]byte), to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) Message {
+func NewMessageRet2(messageType string, ch chan<- error, cfg *define.Config, solveResult func([]byte) error, to common.Address, value *big.Int, abi *abi.ABI, abiMethod string, params ...interface{}) Message {
 	return Message{
 		messageType: messageType,
 		from:        cfg.From,
@@ -77,5 +80,6 @@ func NewMessageRet2(messageType string, ch chan<- struct{}, cfg *define.Config,
 		DoneCh:      ch,
 		solveResult: solveResult,
 		gasLimit:    uint64(cfg.GasLimit),
+		txFile:      cfg.TxFile,
 	}
 }
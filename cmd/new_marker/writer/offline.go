@@ -0,0 +1,182 @@
+package writer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+
+	ethchain "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// OfflineTx is a transaction built by build-tx on a connected machine, signed by sign-tx on an
+// air-gapped one and submitted by broadcast-tx
+type OfflineTx struct {
+	Method   string         `json:"method"` // Contract method called, for review before signing
+	ChainID  *hexutil.Big   `json:"chainId"`
+	From     common.Address `json:"from"`
+	To       common.Address `json:"to"`
+	Nonce    hexutil.Uint64 `json:"nonce"`
+	Gas      hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big   `json:"gasPrice"`
+	Value    *hexutil.Big   `json:"value"`
+	Input    hexutil.Bytes  `json:"input"`
+	Raw      hexutil.Bytes  `json:"raw,omitempty"`  // Signed transaction, set by sign-tx
+	Hash     *common.Hash   `json:"hash,omitempty"` // Signed transaction hash, set by sign-tx
+}
+
+// Transaction returns the unsigned transaction
+func (o *OfflineTx) Transaction() *types.Transaction {
+	return types.NewTransaction(uint64(o.Nonce), o.To, o.Value.ToInt(), uint64(o.Gas), o.GasPrice.ToInt(), o.Input)
+}
+
+// Sign signs the transaction with the key of its sender
+func (o *OfflineTx) Sign(privateKey *ecdsa.PrivateKey) error {
+	if from := crypto.PubkeyToAddress(privateKey.PublicKey); from != o.From {
+		return fmt.Errorf("transaction %d is from %s, not from the key %s", o.Nonce, o.From.Hex(), from.Hex())
+	}
+	signedTx, err := types.SignTx(o.Transaction(), types.LatestSignerForChainID(o.ChainID.ToInt()), privateKey)
+	if err != nil {
+		return err
+	}
+	raw, err := signedTx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	hash := signedTx.Hash()
+	o.Raw, o.Hash = raw, &hash
+	return nil
+}
+
+// SignedTransaction decodes the signed transaction and checks it matches the built one
+func (o *OfflineTx) SignedTransaction() (*types.Transaction, error) {
+	if len(o.Raw) == 0 {
+		return nil, fmt.Errorf("transaction %d of %s is not signed", o.Nonce, o.From.Hex())
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(o.Raw); err != nil {
+		return nil, err
+	}
+	signer := types.LatestSignerForChainID(o.ChainID.ToInt())
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	if from != o.From || o.Hash == nil || tx.Hash() != *o.Hash || signer.Hash(tx) != signer.Hash(o.Transaction()) {
+		return nil, fmt.Errorf("signed transaction %d of %s does not match the built one", o.Nonce, o.From.Hex())
+	}
+	return tx, nil
+}
+
+// BuildContractTransaction resolves the nonce, gas price, gas limit and chain ID of a transaction
+// without signing it
+func BuildContractTransaction(client *ethclient.Client, from, toAddress common.Address, value *big.Int, input []byte, gasLimitSetting uint64) (*types.Transaction, *big.Int, error) {
+	logger := log.New("func", "BuildContractTransaction")
+	nonce, err := client.PendingNonceAt(context.Background(), from)
+	if err != nil {
+		logger.Error("PendingNonceAt", "error", err)
+		return nil, nil, err
+	}
+	gasPrice, err := client.SuggestGasPrice(context.Background())
+	if err != nil {
+		logger.Error("SuggestGasPrice", "error", err)
+		return nil, nil, err
+	}
+
+	//If the contract surely has code (or code is not needed), estimate the transaction
+	msg := ethchain.CallMsg{From: from, To: &toAddress, GasPrice: gasPrice, Value: value, Data: input}
+	if _, err = client.EstimateGas(context.Background(), msg); err != nil {
+		logger.Error("Contract exec failed", "error", err)
+		return nil, nil, err
+	}
+	gasLimit := uint64(DefaultGasLimit)
+	if gasLimitSetting != 0 {
+		gasLimit = gasLimitSetting // in units
+	}
+
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		logger.Error("ChainID", "error", err)
+		return nil, nil, err
+	}
+	logger.Info("Tx Info", "from", from, "to", toAddress, "value", value, "nonce ", nonce, " gasLimit ", gasLimit, " gasPrice ", gasPrice, " chainID ", chainID)
+	return types.NewTransaction(nonce, toAddress, value, gasLimit, gasPrice, input), chainID, nil
+}
+
+// AppendOfflineTx builds a transaction and appends it to the transaction file. The nonce follows
+// the ones of the same sender already in the file, so that several transactions can be built
+// before any of them is sent.
+func AppendOfflineTx(client *ethclient.Client, path string, method string, from, toAddress common.Address, value *big.Int, input []byte, gasLimitSetting uint64) error {
+	if from == (common.Address{}) {
+		return fmt.Errorf("the sender address of an offline transaction is required")
+	}
+	txs, err := ReadOfflineTxs(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	tx, chainID, err := BuildContractTransaction(client, from, toAddress, value, input, gasLimitSetting)
+	if err != nil {
+		return err
+	}
+	nonce := tx.Nonce()
+	for _, o := range txs {
+		if o.From == from && uint64(o.Nonce) >= nonce {
+			nonce = uint64(o.Nonce) + 1
+		}
+	}
+	if value == nil {
+		value = new(big.Int)
+	}
+	txs = append(txs, &OfflineTx{
+		Method:   method,
+		ChainID:  (*hexutil.Big)(chainID),
+		From:     from,
+		To:       toAddress,
+		Nonce:    hexutil.Uint64(nonce),
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: (*hexutil.Big)(tx.GasPrice()),
+		Value:    (*hexutil.Big)(value),
+		Input:    input,
+	})
+	if err := WriteOfflineTxs(path, txs); err != nil {
+		return err
+	}
+	log.Info("Unsigned transaction written", "file", path, "method", method, "nonce", nonce)
+	return nil
+}
+
+// ReadOfflineTxs reads the transactions of a transaction file
+func ReadOfflineTxs(path string) ([]*OfflineTx, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var txs []*OfflineTx
+	if err := json.Unmarshal(data, &txs); err != nil {
+		return nil, fmt.Errorf("invalid transaction file %s: %v", path, err)
+	}
+	for i, o := range txs {
+		if o.ChainID == nil || o.GasPrice == nil || o.Value == nil {
+			return nil, fmt.Errorf("invalid transaction file %s: transaction %d is incomplete", path, i)
+		}
+	}
+	return txs, nil
+}
+
+// WriteOfflineTxs writes the transactions to a transaction file
+func WriteOfflineTxs(path string, txs []*OfflineTx) error {
+	data, err := json.MarshalIndent(txs, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(data, '\n'), 0600)
+}
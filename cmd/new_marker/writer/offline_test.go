@@ -0,0 +1,75 @@
+package writer
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestOfflineTxSignRoundTrip(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	other, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	dir, err := ioutil.TempDir("", "offline-tx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	unsigned, signed := filepath.Join(dir, "unsigned.json"), filepath.Join(dir, "signed.json")
+
+	txs := []*OfflineTx{{
+		Method:   "vote",
+		ChainID:  (*hexutil.Big)(big.NewInt(211)),
+		From:     from,
+		To:       common.HexToAddress("0x01"),
+		Nonce:    7,
+		Gas:      DefaultGasLimit,
+		GasPrice: (*hexutil.Big)(big.NewInt(1000)),
+		Value:    (*hexutil.Big)(big.NewInt(0)),
+		Input:    []byte{1, 2, 3},
+	}}
+	if err := WriteOfflineTxs(unsigned, txs); err != nil {
+		t.Fatalf("WriteOfflineTxs: %v", err)
+	}
+	txs, err = ReadOfflineTxs(unsigned)
+	if err != nil {
+		t.Fatalf("ReadOfflineTxs: %v", err)
+	}
+	if _, err := txs[0].SignedTransaction(); err == nil {
+		t.Error("expected error for unsigned transaction")
+	}
+	if err := txs[0].Sign(other); err == nil {
+		t.Error("expected error for key of another sender")
+	}
+	if err := txs[0].Sign(key); err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if err := WriteOfflineTxs(signed, txs); err != nil {
+		t.Fatalf("WriteOfflineTxs: %v", err)
+	}
+
+	txs, err = ReadOfflineTxs(signed)
+	if err != nil {
+		t.Fatalf("ReadOfflineTxs: %v", err)
+	}
+	tx, err := txs[0].SignedTransaction()
+	if err != nil {
+		t.Fatalf("SignedTransaction: %v", err)
+	}
+	if tx.Nonce() != 7 || tx.ChainId().Cmp(big.NewInt(211)) != 0 || tx.Hash() != *txs[0].Hash {
+		t.Errorf("signed transaction mismatch: nonce %d, chain id %v, hash %v", tx.Nonce(), tx.ChainId(), tx.Hash())
+	}
+
+	// A signed transaction that does not match the reviewed fields is rejected
+	txs[0].Nonce++
+	if _, err := txs[0].SignedTransaction(); err == nil {
+		t.Error("expected error for tampered transaction")
+	}
+}
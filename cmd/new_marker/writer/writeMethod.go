@@ -18,44 +18,13 @@ import (
 const DefaultGasLimit = 4500000
 
 func SendContractTransaction(client *ethclient.Client, from, toAddress common.Address, value *big.Int, privateKey *ecdsa.PrivateKey, input []byte, gasLimitSetting uint64) (common.Hash, error) {
-	// Ensure a valid value field and resolve the account nonce
 	logger := log.New("func", "SendContractTransaction")
-	nonce, err := client.PendingNonceAt(context.Background(), from)
+	tx, chainID, err := BuildContractTransaction(client, from, toAddress, value, input, gasLimitSetting)
 	if err != nil {
-		logger.Error("PendingNonceAt", "error", err)
 		return common.Hash{}, err
 	}
-	gasPrice, err := client.SuggestGasPrice(context.Background())
-	//gasPrice = big.NewInt(1000 000 000 000)
-	if err != nil {
-		logger.Error("SuggestGasPrice", "error", err)
-		return common.Hash{}, err
-	}
-	gasLimit := uint64(DefaultGasLimit) // in units
-
-	//If the contract surely has code (or code is not needed), estimate the transaction
 
-	msg := ethchain.CallMsg{From: from, To: &toAddress, GasPrice: gasPrice, Value: value, Data: input}
-	gasLimit, err = client.EstimateGas(context.Background(), msg)
-	if err != nil {
-		logger.Error("Contract exec failed", "error", err)
-		return common.Hash{}, err
-	}
-	if gasLimit < 1 {
-		//gasLimit = 866328
-		gasLimit = 2100000
-	}
-	gasLimit = uint64(DefaultGasLimit)
-
-	if gasLimitSetting != 0 {
-		gasLimit = gasLimitSetting // in units
-	}
-
-	// Create the transaction, sign it and schedule it for execution
-	tx := types.NewTransaction(nonce, toAddress, value, gasLimit, gasPrice, input)
-
-	chainID, _ := client.ChainID(context.Background())
-	logger.Info("Tx Info", "from", from, "to", toAddress, "value", value, "nonce ", nonce, " gasLimit ", gasLimit, " gasPrice ", gasPrice, " chainID ", chainID)
+	// Sign the transaction and schedule it for execution
 	signer := types.LatestSignerForChainID(chainID)
 	signedTx, err := types.SignTx(tx, signer, privateKey)
 	if err != nil {
@@ -71,7 +40,8 @@ func SendContractTransaction(client *ethclient.Client, from, toAddress common.Ad
 	return signedTx.Hash(), nil
 }
 
-func GetResult(conn *ethclient.Client, txHash common.Hash, contract bool) {
+// GetResult waits for the receipt of the transaction, and returns an error if it failed
+func GetResult(conn *ethclient.Client, txHash common.Hash, contract bool) error {
 	logger := log.New("func", "GetResult")
 	logger.Info("Please waiting ", " txHash ", txHash.String())
 	for {
@@ -79,8 +49,7 @@ func GetResult(conn *ethclient.Client, txHash common.Hash, contract bool) {
 		_, isPending, err := conn.TransactionByHash(context.Background(), txHash)
 		if err != nil {
 			logger.Error("TransactionByHash", "error", err)
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+			return err
 		}
 		if !isPending {
 			break
@@ -101,12 +70,12 @@ func GetResult(conn *ethclient.Client, txHash common.Hash, contract bool) {
 		logger.Error("TransactionReceipt", "error", err)
 	}
 
-	if receipt.Status == types.ReceiptStatusSuccessful {
-		logger.Info("Transaction Success", "number", receipt.BlockNumber.Uint64())
-	} else if receipt.Status == types.ReceiptStatusFailed {
+	if receipt.Status == types.ReceiptStatusFailed {
 		logger.Error("Transaction Failed ", "number", receipt.BlockNumber.Uint64())
-		os.Exit(1)
+		return fmt.Errorf("transaction %s failed in block %d", txHash.Hex(), receipt.BlockNumber.Uint64())
 	}
+	logger.Info("Transaction Success", "number", receipt.BlockNumber.Uint64())
+	return nil
 }
 
 func queryTx(conn *ethclient.Client, txHash common.Hash, contract bool, pending bool) {
@@ -141,23 +110,30 @@ func queryTx(conn *ethclient.Client, txHash common.Hash, contract bool, pending
 	}
 }
 
-func (w Writer) handleUnpackMethodSolveType3(m Message) {
+func (w Writer) handleUnpackMethodSolveType3(m Message) error {
 	msg := ethchain.CallMsg{From: m.from, To: &m.to, Data: m.input, GasFeeCap: big.NewInt(3000000000000)}
 	output, err := w.conn.CallContract(context.Background(), msg, nil)
 	if err != nil {
 		log.Error("method CallContract error", "error", err)
+		return fmt.Errorf("call %s: %v", m.abiMethod, err)
 	}
 	err = m.abi.UnpackIntoInterface(&m.ret, m.abiMethod, output)
 	if err != nil {
 		log.Error("handleUnpackMethodSolveType3", "err", err)
+		return fmt.Errorf("unpack %s: %v", m.abiMethod, err)
 	}
+	return nil
 }
 
-func (w Writer) handleUnpackMethodSolveType4(m Message) {
+func (w Writer) handleUnpackMethodSolveType4(m Message) error {
 	msg := ethchain.CallMsg{From: m.from, To: &m.to, Data: m.input, GasFeeCap: big.NewInt(3000000000000)}
 	output, err := w.conn.CallContract(context.Background(), msg, nil)
 	if err != nil {
 		log.Error("method CallContract error", "error", err)
+		return fmt.Errorf("call %s: %v", m.abiMethod, err)
+	}
+	if err := m.solveResult(output); err != nil {
+		return fmt.Errorf("unpack %s: %v", m.abiMethod, err)
 	}
-	m.solveResult(output)
+	return nil
 }
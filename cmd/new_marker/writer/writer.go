@@ -2,7 +2,7 @@ package writer
 
 import (
 	"fmt"
-	"os"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/ethclient"
 )
@@ -17,31 +17,34 @@ func New(conn *ethclient.Client) *Writer {
 	}
 }
 
-func (w *Writer) ResolveMessage(m Message) bool {
+// ResolveMessage handles the message and reports its error, if any, on the done channel
+// of the message
+func (w *Writer) ResolveMessage(m Message) {
+	var err error
 	switch m.messageType {
 	case SolveSendTranstion1:
-		txHash, err := SendContractTransaction(w.conn, m.from, m.to, nil, m.priKey, m.input, m.gasLimit)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
-		GetResult(w.conn, txHash, true)
-		m.DoneCh <- struct{}{}
+		err = w.sendTransaction(m, nil)
 	case SolveSendTranstion2:
-		txHash, err := SendContractTransaction(w.conn, m.from, m.to, m.value, m.priKey, m.input, m.gasLimit)
-		if err != nil {
-			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
-		}
-		GetResult(w.conn, txHash, true)
-		m.DoneCh <- struct{}{}
+		err = w.sendTransaction(m, m.value)
 	case SolveQueryResult3:
-		w.handleUnpackMethodSolveType3(m)
-		m.DoneCh <- struct{}{}
+		err = w.handleUnpackMethodSolveType3(m)
 	case SolveQueryResult4:
-		w.handleUnpackMethodSolveType4(m)
-		m.DoneCh <- struct{}{}
+		err = w.handleUnpackMethodSolveType4(m)
 	default:
+		err = fmt.Errorf("unknown message type %s", m.messageType)
 	}
-	return true
+	m.DoneCh <- err
+}
+
+// sendTransaction sends the transaction of the message and waits for its receipt, or writes it
+// to the unsigned transaction file of the message if any
+func (w *Writer) sendTransaction(m Message, value *big.Int) error {
+	if m.txFile != "" {
+		return AppendOfflineTx(w.conn, m.txFile, m.abiMethod, m.from, m.to, value, m.input, m.gasLimit)
+	}
+	txHash, err := SendContractTransaction(w.conn, m.from, m.to, value, m.priKey, m.input, m.gasLimit)
+	if err != nil {
+		return err
+	}
+	return GetResult(w.conn, txHash, true)
 }
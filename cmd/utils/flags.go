@@ -189,6 +189,34 @@ var (
 		Usage: "Max number of elements (0 = no limit)",
 		Value: 0,
 	}
+	FromSeqFlag = cli.Uint64Flag{
+		Name:  "from-seq",
+		Usage: "First consensus sequence (block number) to dump",
+	}
+	ToSeqFlag = cli.Uint64Flag{
+		Name:  "to-seq",
+		Usage: "Last consensus sequence (block number) to dump (0 = latest)",
+	}
+	NodeLabelFlag = cli.StringFlag{
+		Name:  "label",
+		Usage: "Name of the node in the dump (default = data directory)",
+	}
+	HeaderStoreChainFlag = cli.Uint64Flag{
+		Name:  "chain",
+		Usage: "Chain type of the relayed chain",
+	}
+	CheckpointNumberFlag = cli.Uint64Flag{
+		Name:  "number",
+		Usage: "Header number of the checkpoint (0 = latest stored header)",
+	}
+	CheckpointKeyFlag = cli.StringFlag{
+		Name:  "key",
+		Usage: "Private key file the checkpoint is signed with",
+	}
+	CheckpointSignerFlag = cli.StringFlag{
+		Name:  "signer",
+		Usage: "Address the checkpoint must be signed by",
+	}
 	defaultSyncMode = ethconfig.Defaults.SyncMode
 	SyncModeFlag    = TextMarshalerFlag{
 		Name:  "syncmode",
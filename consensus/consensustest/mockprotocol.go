@@ -88,6 +88,7 @@ func (serv *MockP2PServer) RemoveTrustedPeer(node *enode.Node, purpose p2p.Purpo
 type MockPeer struct {
 	node     *enode.Node
 	purposes p2p.PurposeFlag
+	rtt      time.Duration
 }
 
 func NewMockPeer(node *enode.Node, purposes p2p.PurposeFlag) *MockPeer {
@@ -120,6 +121,15 @@ func (mp *MockPeer) PurposeIsSet(purpose p2p.PurposeFlag) bool {
 	return true
 }
 
+func (mp *MockPeer) RTT() time.Duration {
+	return mp.rtt
+}
+
+// SetRTT sets the round trip time reported by the mock peer
+func (mp *MockPeer) SetRTT(rtt time.Duration) {
+	mp.rtt = rtt
+}
+
 type Mode uint
 
 // MockEngine provides a minimal fake implementation of a consensus engine for use in blockchain tests.
@@ -17,13 +17,17 @@
 package backend
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/mapprotocol/atlas/tools"
+	"math"
 	"math/big"
+	"sort"
 	"strconv"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/mapprotocol/atlas/consensus"
@@ -31,12 +35,26 @@ import (
 	vet "github.com/mapprotocol/atlas/consensus/istanbul/backend/internal/enodes"
 	"github.com/mapprotocol/atlas/consensus/istanbul/backend/internal/replica"
 	"github.com/mapprotocol/atlas/consensus/istanbul/core"
+	"github.com/mapprotocol/atlas/consensus/istanbul/epochproof"
 	"github.com/mapprotocol/atlas/consensus/istanbul/proxy"
 	"github.com/mapprotocol/atlas/consensus/istanbul/uptime"
 	"github.com/mapprotocol/atlas/consensus/istanbul/uptime/store"
 	"github.com/mapprotocol/atlas/consensus/istanbul/validator"
+	"github.com/mapprotocol/atlas/contracts/random"
+	ethCore "github.com/mapprotocol/atlas/core"
+	ethChain "github.com/mapprotocol/atlas/core/chain"
+	"github.com/mapprotocol/atlas/core/state"
 	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/core/vm"
 	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const (
+	// maxEpochTransitionProofs bounds the epochs proven by one GetEpochTransitionProof call
+	maxEpochTransitionProofs = 100
+	// maxSignerHistoryBlocks bounds the blocks covered by one GetSignerHistory call
+	maxSignerHistoryBlocks = 20000
 )
 
 // API is a user facing RPC API to dump Istanbul state
@@ -63,6 +81,116 @@ type EpochInfo struct {
 	Validators []Validator `json:"validators"`
 }
 
+// SignerBlock is the activity of a validator on a single block it was elected for
+type SignerBlock struct {
+	Number          uint64 `json:"number"`
+	Signed          bool   `json:"signed"`
+	Proposed        bool   `json:"proposed"`
+	MissedProposals uint64 `json:"missedProposals"`
+}
+
+// SignerHistory is the activity of a validator over a block range. MissedCommits counts the
+// elected blocks its commit is missing from, MissedProposals the rounds it was the proposer
+// of without getting a block committed.
+type SignerHistory struct {
+	Address         common.Address `json:"address"`
+	FromBlock       uint64         `json:"fromBlock"`
+	ToBlock         uint64         `json:"toBlock"`
+	Elected         uint64         `json:"elected"`
+	Signed          uint64         `json:"signed"`
+	MissedCommits   uint64         `json:"missedCommits"`
+	Proposed        uint64         `json:"proposed"`
+	MissedProposals uint64         `json:"missedProposals"`
+	Blocks          []SignerBlock  `json:"blocks"`
+}
+
+// ValidatorUptime is the uptime and signing activity of a validator during an epoch
+type ValidatorUptime struct {
+	Address         common.Address `json:"address"`
+	UpBlocks        uint64         `json:"upBlocks"`
+	Uptime          float64        `json:"uptime"`
+	Signed          uint64         `json:"signed"`
+	MissedCommits   uint64         `json:"missedCommits"`
+	Proposed        uint64         `json:"proposed"`
+	MissedProposals uint64         `json:"missedProposals"`
+}
+
+// EpochUptime is the uptime of the validators of an epoch, scored over the blocks of its
+// monitoring window accounted so far.
+type EpochUptime struct {
+	Epoch           uint64            `json:"epoch"`
+	FirstBlock      uint64            `json:"firstBlock"`
+	LastBlock       uint64            `json:"lastBlock"`
+	MonitoredBlocks uint64            `json:"monitoredBlocks"`
+	Validators      []ValidatorUptime `json:"validators"`
+}
+
+// EquivocationEvidence is a pair of conflicting consensus messages signed by a validator,
+// along with the transaction submitting it for slashing if any.
+type EquivocationEvidence struct {
+	Hash          common.Hash    `json:"hash"`
+	Code          uint64         `json:"code"`
+	Sequence      uint64         `json:"sequence"`
+	Round         uint64         `json:"round"`
+	Signer        common.Address `json:"signer"`
+	FirstMessage  hexutil.Bytes  `json:"firstMessage"`
+	SecondMessage hexutil.Bytes  `json:"secondMessage"`
+	Submission    *common.Hash   `json:"submission"`
+}
+
+// EpochRewardsOverrides replace the inputs of an epoch reward simulation, the ones left unset
+// are read from the chain.
+type EpochRewardsOverrides struct {
+	Uptimes          map[common.Address]float64                         `json:"uptimes"`          // Uptime of the signers, from 0 to 1
+	Votes            map[common.Address]map[common.Address]*hexutil.Big `json:"votes"`            // Active votes of the voters by validator account, only splitting the voter rewards
+	Voters           []common.Address                                   `json:"voters"`           // Voters to split the voter rewards to, along with the pending voters
+	ValidatorReward  *hexutil.Big                                       `json:"validatorReward"`  // Target reward of the validators and their voters
+	CommunityReward  *hexutil.Big                                       `json:"communityReward"`  // Target reward of the community partner
+	MaintainerReward *hexutil.Big                                       `json:"maintainerReward"` // Target reward of the relayers or the maintainer
+}
+
+// VoterReward is the share of a voter in the voter reward of a validator
+type VoterReward struct {
+	Account common.Address `json:"account"`
+	Votes   *hexutil.Big   `json:"votes"`
+	Reward  *hexutil.Big   `json:"reward"`
+}
+
+// ValidatorReward is the reward of a validator and its voters for an epoch
+type ValidatorReward struct {
+	Signer      common.Address `json:"signer"`
+	Account     common.Address `json:"account"`
+	Score       *hexutil.Big   `json:"score"`
+	Reward      *hexutil.Big   `json:"reward"`
+	VoterReward *hexutil.Big   `json:"voterReward"`
+	Voters      []VoterReward  `json:"voters"`
+}
+
+// EpochRewards are the simulated payouts of the rewards of an epoch. Rewarded is false before
+// the reward block, when only the validator scores are updated.
+type EpochRewards struct {
+	Epoch            uint64                          `json:"epoch"`
+	Block            uint64                          `json:"block"`
+	Rewarded         bool                            `json:"rewarded"`
+	ValidatorReward  *hexutil.Big                    `json:"validatorReward"`
+	CommunityReward  *hexutil.Big                    `json:"communityReward"`
+	MaintainerReward *hexutil.Big                    `json:"maintainerReward"`
+	Validators       []ValidatorReward               `json:"validators"`
+	CommunityPartner common.Address                  `json:"communityPartner"`
+	Maintainer       *common.Address                 `json:"maintainer"`
+	MaintainerPaid   *hexutil.Big                    `json:"maintainerPaid,omitempty"`
+	Relayers         map[common.Address]*hexutil.Big `json:"relayers"`
+}
+
+// blockSigners is the signing activity of the validator set of a single block
+type blockSigners struct {
+	number          uint64
+	valSet          istanbul.ValidatorSet
+	signers         *big.Int
+	proposer        common.Address
+	missedProposers []common.Address
+}
+
 // getHeaderByNumber retrieves the header requested block or current if unspecified.
 func (api *API) getHeaderByNumber(number *rpc.BlockNumber) (*types.Header, error) {
 	var header *types.Header
@@ -391,9 +519,10 @@ func (api *API) GetEpochInfo(epochNumber uint64) *EpochInfo {
 
 	validatorNum := len(ss.validators())
 	validators := make([]Validator, 0, validatorNum)
-	for _, v := range ss.validators() {
+	weights := ss.ValSet.Weights()
+	for i, v := range ss.validators() {
 		validators = append(validators, Validator{
-			Weight:  strconv.Itoa(1),
+			Weight:  weights[i].String(),
 			Address: v.Address,
 			G1PubKey: G1PublicKey{
 				X: tools.Bytes2Hex(v.BLSG1PublicKey[:32]),
@@ -405,8 +534,455 @@ func (api *API) GetEpochInfo(epochNumber uint64) *EpochInfo {
 	epochInfo := &EpochInfo{
 		Epoch:      strconv.FormatUint(epochNumber, 10),
 		EpochSize:  strconv.FormatUint(api.istanbul.config.Epoch, 10),
-		Threshold:  strconv.Itoa(ss.ValSet.MinQuorumSize()),
+		Threshold:  ss.ValSet.QuorumWeight().String(),
 		Validators: validators,
 	}
 	return epochInfo
 }
+
+// GetEpochTransitionProof retrieves the proofs of the validator set transitions at the
+// end of the epochs from to to, light clients verify them one after another from a
+// trusted epoch with the epochproof package.
+func (api *API) GetEpochTransitionProof(from, to uint64) ([]*epochproof.Proof, error) {
+	if from == 0 || to < from {
+		return nil, fmt.Errorf("invalid epoch range [%d, %d]", from, to)
+	}
+	if to-from+1 > maxEpochTransitionProofs {
+		return nil, fmt.Errorf("at most %d epochs can be proven at once", maxEpochTransitionProofs)
+	}
+	chain, ok := api.chain.(consensus.ChainReader)
+	if !ok {
+		return nil, errors.New("blocks are not available")
+	}
+
+	epochSize := api.istanbul.config.Epoch
+	proofs := make([]*epochproof.Proof, 0, to-from+1)
+	for epoch := from; epoch <= to; epoch++ {
+		header := api.chain.GetHeaderByNumber(epoch * epochSize)
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+		block := chain.GetBlock(header.Hash(), header.Number.Uint64())
+		if block == nil {
+			return nil, errUnknownBlock
+		}
+		snap, err := api.istanbul.snapshot(api.chain, header.Number.Uint64()-1, header.ParentHash, nil)
+		if err != nil {
+			return nil, err
+		}
+		proof, err := epochproof.NewProof(header, block.EpochSnarkData(), snap.validators(), epochSize)
+		if err != nil {
+			return nil, err
+		}
+		proofs = append(proofs, proof)
+	}
+	return proofs, nil
+}
+
+// GetEquivocationEvidences retrieves the equivocations this node saw in the consensus
+// messages for the blocks from fromBlock to toBlock.
+func (api *API) GetEquivocationEvidences(fromBlock, toBlock uint64) ([]*EquivocationEvidence, error) {
+	if toBlock < fromBlock {
+		return nil, fmt.Errorf("invalid block range [%d, %d]", fromBlock, toBlock)
+	}
+	records, err := api.istanbul.core.Evidences(fromBlock, toBlock)
+	if err != nil {
+		return nil, err
+	}
+	evidences := make([]*EquivocationEvidence, 0, len(records))
+	for _, record := range records {
+		ev := record.Evidence
+		evidence := &EquivocationEvidence{
+			Hash:          ev.Hash(),
+			Code:          ev.Code,
+			Sequence:      ev.View.Sequence.Uint64(),
+			Round:         ev.View.Round.Uint64(),
+			Signer:        ev.Signer,
+			FirstMessage:  ev.First,
+			SecondMessage: ev.Second,
+		}
+		if record.Submission != (common.Hash{}) {
+			submission := record.Submission
+			evidence.Submission = &submission
+		}
+		evidences = append(evidences, evidence)
+	}
+	return evidences, nil
+}
+
+// GetSignerHistory retrieves the blocks from fromBlock to toBlock a validator was elected for,
+// whether it signed them and whether it proposed them or missed proposing in an earlier round.
+// The signers of the head block are only known from its child, so the range ends before it.
+func (api *API) GetSignerHistory(address common.Address, fromBlock, toBlock rpc.BlockNumber) (*SignerHistory, error) {
+	from, err := api.getHeaderByNumber(&fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.getHeaderByNumber(&toBlock)
+	if err != nil {
+		return nil, err
+	}
+	first, last := from.Number.Uint64(), to.Number.Uint64()
+	if head := api.chain.CurrentHeader().Number.Uint64(); last >= head && head > 0 {
+		last = head - 1
+	}
+	if first == 0 {
+		first = 1
+	}
+	if last < first {
+		return nil, fmt.Errorf("invalid block range [%d, %d]", first, last)
+	}
+	if last-first+1 > maxSignerHistoryBlocks {
+		return nil, fmt.Errorf("at most %d blocks can be retrieved at once", maxSignerHistoryBlocks)
+	}
+
+	history := &SignerHistory{Address: address, FromBlock: first, ToBlock: last, Blocks: []SignerBlock{}}
+	err = api.walkSigners(first, last, func(b *blockSigners) {
+		index := b.valSet.GetIndex(address)
+		if index < 0 {
+			return
+		}
+		block := SignerBlock{
+			Number:   b.number,
+			Signed:   b.signers.Bit(index) == 1,
+			Proposed: b.proposer == address,
+		}
+		for _, proposer := range b.missedProposers {
+			if proposer == address {
+				block.MissedProposals++
+			}
+		}
+
+		history.Elected++
+		if block.Signed {
+			history.Signed++
+		} else {
+			history.MissedCommits++
+		}
+		if block.Proposed {
+			history.Proposed++
+		}
+		history.MissedProposals += block.MissedProposals
+		history.Blocks = append(history.Blocks, block)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetEpochUptime retrieves the uptime of the validators of an epoch, together with their
+// signed blocks, missed commits and missed proposals over the blocks of the epoch seen so far.
+func (api *API) GetEpochUptime(epoch uint64) (*EpochUptime, error) {
+	epochSize := api.istanbul.EpochSize()
+	first, err := istanbul.GetEpochFirstBlockNumber(epoch, epochSize)
+	if err != nil {
+		return nil, err
+	}
+	head := api.chain.CurrentHeader().Number.Uint64()
+	if first >= head {
+		return nil, fmt.Errorf("epoch %d has no signed blocks yet", epoch)
+	}
+	last := istanbul.GetEpochLastBlockNumber(epoch, epochSize)
+	if last >= head {
+		last = head - 1
+	}
+
+	parent := api.chain.GetHeaderByNumber(first - 1)
+	if parent == nil {
+		return nil, errUnknownBlock
+	}
+	valSet := api.istanbul.getValidators(parent.Number.Uint64(), parent.Hash())
+	if valSet.Size() == 0 {
+		return nil, errors.New("unable to fetch validators")
+	}
+
+	monitor := uptime.NewMonitor(store.New(api.istanbul.db), epochSize, api.istanbul.config.DefaultLookbackWindow)
+	accumulated, monitored, err := monitor.EpochUptime(epoch)
+	if err != nil {
+		return nil, err
+	}
+
+	validators := make([]ValidatorUptime, valSet.Size())
+	for i, val := range valSet.List() {
+		validators[i].Address = val.Address()
+		if i < len(accumulated.Entries) {
+			validators[i].UpBlocks = accumulated.Entries[i].UpBlocks
+		}
+		if monitored > 0 {
+			validators[i].Uptime = float64(validators[i].UpBlocks) / float64(monitored)
+		}
+	}
+	err = api.walkSigners(first, last, func(b *blockSigners) {
+		for i := range validators {
+			if b.signers.Bit(i) == 1 {
+				validators[i].Signed++
+			} else {
+				validators[i].MissedCommits++
+			}
+			if b.proposer == validators[i].Address {
+				validators[i].Proposed++
+			}
+			for _, proposer := range b.missedProposers {
+				if proposer == validators[i].Address {
+					validators[i].MissedProposals++
+				}
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &EpochUptime{
+		Epoch:           epoch,
+		FirstBlock:      first,
+		LastBlock:       last,
+		MonitoredBlocks: monitored,
+		Validators:      validators,
+	}, nil
+}
+
+// SimulateEpochRewards runs the reward path of the last block of an epoch, with the optional
+// overrides, and returns the payouts without committing them. Ended epochs run on the state
+// their rewards were paid on, the state of their last block before it was finalized, the
+// current epoch on the head state. Overridden votes only change how the voter reward of a
+// validator is split between its voters, they change neither the elected validators nor
+// the rewards of the validators.
+func (api *API) SimulateEpochRewards(epoch uint64, overrides *EpochRewardsOverrides) (*EpochRewards, error) {
+	epochSize := api.istanbul.EpochSize()
+	first, err := istanbul.GetEpochFirstBlockNumber(epoch, epochSize)
+	if err != nil {
+		return nil, err
+	}
+	last := istanbul.GetEpochLastBlockNumber(epoch, epochSize)
+	head := api.chain.CurrentHeader()
+	if first > head.Number.Uint64()+1 {
+		return nil, fmt.Errorf("epoch %d has not started yet", epoch)
+	}
+
+	var (
+		header, parent *types.Header
+		state          *state.StateDB
+	)
+	if last <= head.Number.Uint64() {
+		chain, ok := api.chain.(consensus.ChainReader)
+		if !ok {
+			return nil, errors.New("blocks are not available")
+		}
+		header = api.chain.GetHeaderByNumber(last)
+		if header == nil {
+			return nil, errUnknownBlock
+		}
+		block := chain.GetBlock(header.Hash(), last)
+		parent = api.chain.GetHeader(header.ParentHash, last-1)
+		if block == nil || parent == nil {
+			return nil, errUnknownBlock
+		}
+		header = types.CopyHeader(header)
+		if state, err = api.stateBeforeRewards(block); err != nil {
+			return nil, err
+		}
+	} else {
+		// The signers of the current epoch do not change, the last block is made up from the head
+		header = types.CopyHeader(head)
+		header.Number = new(big.Int).SetUint64(last)
+		header.ParentHash = head.Hash()
+		parent = head
+		if state, err = api.istanbul.stateAt(parent.Hash()); err != nil {
+			return nil, err
+		}
+	}
+
+	simulated := new(epochRewardsOverrides)
+	if overrides != nil {
+		simulated.uptimes = make(map[common.Address]*big.Int, len(overrides.Uptimes))
+		for signer, uptime := range overrides.Uptimes {
+			if uptime < 0 || uptime > 1 {
+				return nil, fmt.Errorf("uptime %v of %s out of range", uptime, signer.Hex())
+			}
+			simulated.uptimes[signer], _ = new(big.Float).Mul(big.NewFloat(uptime), new(big.Float).SetInt(params.Fixidity1)).Int(nil)
+		}
+		simulated.votes = make(map[common.Address]map[common.Address]*big.Int, len(overrides.Votes))
+		for validator, votes := range overrides.Votes {
+			simulated.votes[validator] = make(map[common.Address]*big.Int, len(votes))
+			for voter, v := range votes {
+				simulated.votes[validator][voter] = v.ToInt()
+			}
+		}
+		simulated.voters = overrides.Voters
+		if overrides.ValidatorReward != nil {
+			simulated.validatorVoterReward = overrides.ValidatorReward.ToInt()
+		}
+		if overrides.CommunityReward != nil {
+			simulated.communityReward = overrides.CommunityReward.ToInt()
+		}
+		if overrides.MaintainerReward != nil {
+			simulated.maintainerReward = overrides.MaintainerReward.ToInt()
+		}
+	}
+
+	signerSet := api.istanbul.GetValidators(parent.Number, parent.Hash())
+	rewarded := header.Number.Cmp(api.chain.Config().EnableRewardBlock) > 0
+	rewards, err := api.istanbul.simulateEpochRewards(header, state, signerSet, rewarded, simulated)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &EpochRewards{
+		Epoch:            epoch,
+		Block:            last,
+		Rewarded:         rewards.rewarded,
+		ValidatorReward:  (*hexutil.Big)(rewards.validatorVoterReward),
+		CommunityReward:  (*hexutil.Big)(rewards.communityReward),
+		MaintainerReward: (*hexutil.Big)(rewards.maintainerReward),
+		Validators:       make([]ValidatorReward, len(rewards.signers)),
+		CommunityPartner: rewards.communityPartner,
+		Relayers:         make(map[common.Address]*hexutil.Big, len(rewards.relayerRewards)),
+	}
+	if rewards.maintainer != (common.Address{}) {
+		result.Maintainer = &rewards.maintainer
+		result.MaintainerPaid = (*hexutil.Big)(rewards.maintainerPaid)
+	}
+	for addr, reward := range rewards.relayerRewards {
+		result.Relayers[addr] = (*hexutil.Big)(reward)
+	}
+	for i, signer := range rewards.signers {
+		account := rewards.accounts[i]
+		validator := ValidatorReward{
+			Signer:      signer.Address(),
+			Account:     account,
+			Score:       (*hexutil.Big)(rewards.scores[i]),
+			Reward:      (*hexutil.Big)(new(big.Int)),
+			VoterReward: (*hexutil.Big)(new(big.Int)),
+			Voters:      make([]VoterReward, 0, len(rewards.voterShares[account])),
+		}
+		if reward := rewards.validatorRewards[account]; reward != nil {
+			validator.Reward = (*hexutil.Big)(reward)
+		}
+		if reward := rewards.voterRewards[account]; reward != nil {
+			validator.VoterReward = (*hexutil.Big)(reward)
+		}
+		for voter, share := range rewards.voterShares[account] {
+			validator.Voters = append(validator.Voters, VoterReward{Account: voter, Votes: (*hexutil.Big)(share.votes), Reward: (*hexutil.Big)(share.reward)})
+		}
+		sort.Slice(validator.Voters, func(i, j int) bool {
+			return bytes.Compare(validator.Voters[i].Account[:], validator.Voters[j].Account[:]) < 0
+		})
+		result.Validators[i] = validator
+	}
+	return result, nil
+}
+
+// chainContext is the chain the transactions replayed by the API run in
+type chainContext struct {
+	consensus.ChainHeaderReader
+	engine consensus.Engine
+}
+
+func (c *chainContext) Engine() consensus.Engine {
+	return c.engine
+}
+
+// stateBeforeRewards returns the state the rewards of the last block of an epoch were paid
+// on: the state of its parent with the transactions of the block applied, as the rewards
+// are paid when the block is finalized.
+func (api *API) stateBeforeRewards(block *types.Block) (*state.StateDB, error) {
+	parentState, err := api.istanbul.stateAt(block.ParentHash())
+	if err != nil {
+		return nil, err
+	}
+	statedb := parentState.Copy()
+	header := block.Header()
+
+	vmRunner := api.istanbul.chain.NewEVMRunner(header, statedb)
+	if random.IsRunning(vmRunner) {
+		author, err := api.istanbul.Author(header)
+		if err != nil {
+			return nil, err
+		}
+		if err := random.RevealAndCommit(vmRunner, block.Randomness().Revealed, block.Randomness().Committed, author); err != nil {
+			return nil, err
+		}
+		statedb.IntermediateRoot(true)
+	}
+	var (
+		chain   = &chainContext{api.chain, api.istanbul}
+		gp      = new(ethCore.GasPool).AddGas(math.MaxUint64) // The block has been validated already
+		usedGas uint64
+	)
+	for i, tx := range block.Transactions() {
+		statedb.Prepare(tx.Hash(), i)
+		if _, err := ethChain.ApplyTransaction(api.chain.Config(), chain, nil, gp, statedb, header, tx, &usedGas, vm.Config{}); err != nil {
+			return nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
+		}
+	}
+	return statedb, nil
+}
+
+// orderedValidatorsAt returns a function giving the validators of a block in the proposer
+// order of its rounds, read with getOrdered from the parent block. The validator set and its
+// order only change with the first block of an epoch, except under the VRF policy where the
+// order is reseeded by every block, so the sets are cached per epoch for the other policies.
+func orderedValidatorsAt(epochSize uint64, policy istanbul.ProposerPolicy, getOrdered func(number uint64, hash common.Hash) istanbul.ValidatorSet) func(number uint64, parentHash common.Hash) istanbul.ValidatorSet {
+	type valSetKey struct {
+		epoch      uint64
+		firstBlock bool
+	}
+	valSets := make(map[valSetKey]istanbul.ValidatorSet)
+	return func(number uint64, parentHash common.Hash) istanbul.ValidatorSet {
+		if policy == istanbul.VRF {
+			return getOrdered(number-1, parentHash).Copy()
+		}
+		key := valSetKey{istanbul.GetEpochNumber(number, epochSize), istanbul.IsFirstBlockOfEpoch(number, epochSize)}
+		valSet, ok := valSets[key]
+		if !ok {
+			valSet = getOrdered(number-1, parentHash).Copy()
+			valSets[key] = valSet
+		}
+		return valSet
+	}
+}
+
+// walkSigners calls fn with the signing activity of the blocks from first to last, read from
+// the signer records of the uptime monitor or, for blocks processed before they were kept,
+// from the parent seal of the child block.
+func (api *API) walkSigners(first, last uint64, fn func(*blockSigners)) error {
+	uptimeStore := store.New(api.istanbul.db)
+	valSetAt := orderedValidatorsAt(api.istanbul.EpochSize(), api.istanbul.config.ProposerPolicy, api.istanbul.getOrderedValidators)
+
+	parent := api.chain.GetHeaderByNumber(first - 1)
+	if parent == nil {
+		return errUnknownBlock
+	}
+	lastProposer, _ := api.istanbul.Author(parent)
+	for number := first; number <= last; number++ {
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil {
+			return errUnknownBlock
+		}
+		record := uptimeStore.ReadSignerRecord(number)
+		if record == nil {
+			child := api.chain.GetHeaderByNumber(number + 1)
+			if child == nil {
+				return fmt.Errorf("signers of block %d are not known yet", number)
+			}
+			extra, err := types.ExtractIstanbulExtra(child)
+			if err != nil {
+				return err
+			}
+			record = &uptime.SignerRecord{Signers: extra.ParentAggregatedSeal.Bitmap, Round: extra.ParentAggregatedSeal.Round.Uint64()}
+		}
+
+		valSet := valSetAt(number, header.ParentHash)
+		proposer, _ := api.istanbul.Author(header)
+		b := &blockSigners{number: number, valSet: valSet, signers: record.Signers, proposer: proposer}
+		selector := validator.GetProposerSelector(api.istanbul.config.ProposerPolicy)
+		for round := uint64(0); round < record.Round && valSet.Size() > 0; round++ {
+			b.missedProposers = append(b.missedProposers, selector(valSet, lastProposer, round).Address())
+		}
+		fn(b)
+		lastProposer = proposer
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/consensus/istanbul/validator"
+)
+
+func TestOrderedValidatorsAt(t *testing.T) {
+	validators := make([]istanbul.ValidatorData, 5)
+	for i := range validators {
+		validators[i] = istanbul.ValidatorData{Address: common.BigToAddress(big.NewInt(int64(i + 1)))}
+	}
+	// every parent block reseeds the proposer order like the VRF output does
+	var reads int
+	getOrdered := func(number uint64, hash common.Hash) istanbul.ValidatorSet {
+		reads++
+		valSet := validator.NewSet(validators)
+		valSet.SetRandomness(common.BigToHash(new(big.Int).SetUint64(number)))
+		return valSet
+	}
+
+	const epochSize = 10
+	valSetAt := orderedValidatorsAt(epochSize, istanbul.RoundRobin, getOrdered)
+	for number := uint64(2); number < epochSize; number++ {
+		valSetAt(number, common.Hash{})
+	}
+	if reads != 1 {
+		t.Errorf("round robin reads mismatch: have %d, want 1", reads)
+	}
+
+	// The validators that missed proposing in earlier rounds of a block follow the order
+	// seeded by its own parent
+	reads = 0
+	selector := validator.GetProposerSelector(istanbul.VRF)
+	valSetAt = orderedValidatorsAt(epochSize, istanbul.VRF, getOrdered)
+	for number := uint64(2); number < epochSize; number++ {
+		want := getOrdered(number-1, common.Hash{})
+		have := valSetAt(number, common.Hash{})
+		for round := uint64(0); round < 3; round++ {
+			if h, w := selector(have, common.Address{}, round).Address(), selector(want, common.Address{}, round).Address(); h != w {
+				t.Errorf("block %d round %d: proposer mismatch: have %v, want %v", number, round, h, w)
+			}
+		}
+	}
+	if want := 2 * (epochSize - 2); reads != want {
+		t.Errorf("vrf reads mismatch: have %d, want %d", reads, want)
+	}
+}
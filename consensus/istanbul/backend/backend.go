@@ -78,6 +78,9 @@ func (ei EcdsaInfo) Sign(data []byte) ([]byte, error) {
 
 // SignHash signs the given hash with the ecdsa account
 func (ei EcdsaInfo) SignHash(hash common.Hash) ([]byte, error) {
+	if ei.signHash == nil {
+		return nil, errInvalidSigningFn
+	}
 	return ei.signHash(accounts.Account{Address: ei.Address}, hash.Bytes())
 }
 
@@ -141,6 +144,7 @@ func New(config *istanbul.Config, db ethdb.Database) consensus.Istanbul {
 		blocksMissedRoundsAsProposerMeter:  metrics.NewRegisteredMeter("consensus/istanbul/blocks/missedroundsasproposer", nil),
 		blocksElectedButNotSignedGauge:     metrics.NewRegisteredGauge("consensus/istanbul/blocks/missedbyusinarow", nil),
 		blocksDowntimeEventMeter:           metrics.NewRegisteredMeter("consensus/istanbul/blocks/downtimeevent", nil),
+		equivocationsMeter:                 metrics.NewRegisteredMeter("consensus/istanbul/equivocations", nil),
 		blocksFinalizedTransactionsGauge:   metrics.NewRegisteredGauge("consensus/istanbul/blocks/transactions", nil),
 		blocksFinalizedGasUsedGauge:        metrics.NewRegisteredGauge("consensus/istanbul/blocks/gasused", nil),
 		sleepGauge:                         metrics.NewRegisteredGauge("consensus/istanbul/backend/sleep", nil),
@@ -171,6 +175,9 @@ func New(config *istanbul.Config, db ethdb.Database) consensus.Istanbul {
 			logger.Crit("Can't open ReplicaStateDB", "err", err, "dbpath", config.ReplicaStateDBPath)
 		}
 		backend.replicaState = rs
+		if rs != nil && config.ReplicaFailoverBlocks > 0 {
+			rs.EnableFailover(config.ReplicaFailoverBlocks, backend.Sign, backend.signedParentSeals)
+		}
 	} else {
 		backend.replicaState = nil
 	}
@@ -232,6 +239,9 @@ type Backend struct {
 	stateAt      func(hash common.Hash) (*state.StateDB, error)
 	replicaState replica.State
 
+	txPool     TxPool
+	evidenceMu sync.Mutex
+
 	processBlock        func(block *types.Block, statedb *state.StateDB) (types.Receipts, []*types.Log, uint64, error)
 	validateState       func(block *types.Block, statedb *state.StateDB, receipts types.Receipts, usedGas uint64) error
 	onNewConsensusBlock func(block *types.Block, receipts []*types.Receipt, logs []*types.Log, state *state.StateDB)
@@ -292,6 +302,8 @@ type Backend struct {
 	blocksElectedButNotSignedGauge metrics.Gauge
 	// Meter for downtime events when we did not sign 12+ blocks in a row.
 	blocksDowntimeEventMeter metrics.Meter
+	// Meter for equivocations detected in the messages of other validators.
+	equivocationsMeter metrics.Meter
 
 	// Gauge for total signatures in parentSeal of last received block (how much better than quorum are we doing)
 	blocksTotalSigsGauge metrics.Gauge
@@ -505,7 +517,7 @@ func (sb *Backend) NextBlockValidators(proposal istanbul.Proposal) (istanbul.Val
 	}
 
 	// There was no change
-	if len(istExtra.AddedValidators) == 0 && istExtra.RemovedValidators.BitLen() == 0 {
+	if len(istExtra.AddedValidators) == 0 && istExtra.RemovedValidators.BitLen() == 0 && len(istExtra.ValidatorWeights) == 0 {
 		return sb.ParentBlockValidators(proposal), nil
 	}
 
@@ -526,6 +538,9 @@ func (sb *Backend) NextBlockValidators(proposal istanbul.Proposal) (istanbul.Val
 	if !snap.ValSet.AddValidators(addedValidators) {
 		return nil, fmt.Errorf("could not obtain next block validators: failed at add validators")
 	}
+	if !snap.ValSet.SetWeights(istExtra.ValidatorWeights) {
+		return nil, fmt.Errorf("could not obtain next block validators: failed at set weights")
+	}
 
 	return snap.ValSet, nil
 }
@@ -662,6 +677,37 @@ func (sb *Backend) getNewValidatorSet(header *types.Header, state *state.StateDB
 	return newValSet, err
 }
 
+// getNewValidatorWeights returns the votes of the validators of the next epoch as their
+// voting power, in the order the snapshot keeps them after applying the diff from the
+// old validator set. It returns nil before the weighted BFT fork or without any votes,
+// the validators then count one vote each.
+func (sb *Backend) getNewValidatorWeights(header *types.Header, state *state.StateDB, oldValSet, newValSet []istanbul.ValidatorData) ([]*big.Int, error) {
+	if !sb.chain.Config().IsWeightedBFT(header.Number) {
+		return nil, nil
+	}
+	addedValidators, removedValidators := istanbul.ValidatorSetDiff(oldValSet, newValSet)
+	next := make([]common.Address, 0, len(newValSet))
+	for i, val := range oldValSet {
+		if removedValidators.Bit(i) == 0 {
+			next = append(next, val.Address)
+		}
+	}
+	for _, val := range addedValidators {
+		next = append(next, val.Address)
+	}
+
+	weights, err := getValidatorVotes(sb.chain.NewEVMRunner(header, state), next)
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range weights {
+		if w.Sign() > 0 {
+			return weights, nil
+		}
+	}
+	return nil, nil
+}
+
 func (sb *Backend) verifyValSetDiff(proposal istanbul.Proposal, block *types.Block, state *state.StateDB) error {
 	header := block.Header()
 
@@ -673,7 +719,7 @@ func (sb *Backend) verifyValSetDiff(proposal istanbul.Proposal, block *types.Blo
 
 	newValSet, err := sb.getNewValidatorSet(block.Header(), state)
 	if err != nil {
-		if len(istExtra.AddedValidators) != 0 || istExtra.RemovedValidators.BitLen() != 0 {
+		if len(istExtra.AddedValidators) != 0 || istExtra.RemovedValidators.BitLen() != 0 || len(istExtra.ValidatorWeights) != 0 {
 			sb.logger.Error("verifyValSetDiff - Invalid val set diff.  Non empty diff when it should be empty.", "addedValidators", types.ConvertToStringSlice(istExtra.AddedValidators), "removedValidators", istExtra.RemovedValidators.Text(16))
 			return errInvalidValidatorSetDiff
 		}
@@ -714,11 +760,32 @@ func (sb *Backend) verifyValSetDiff(proposal istanbul.Proposal, block *types.Blo
 				"expected addedValidatorsG1PublicKeys", istanbul.ConvertG1PublicKeysToStringSlice(addedValidatorsG1PublicKeys))
 			return errInvalidValidatorSetDiff
 		}
+
+		weights, err := sb.getNewValidatorWeights(header, state, oldValSet, newValSet)
+		if err != nil {
+			return err
+		}
+		if !compareWeights(weights, istExtra.ValidatorWeights) {
+			sb.logger.Error("verifyValSetDiff - Invalid validator weights", "got", istExtra.ValidatorWeights, "expected", weights)
+			return errInvalidValidatorSetDiff
+		}
 	}
 
 	return nil
 }
 
+func compareWeights(a, b []*big.Int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Cmp(b[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
 // Sign implements istanbul.Backend.Sign
 func (sb *Backend) Sign(data []byte) ([]byte, error) {
 	return sb.wallets().Ecdsa.Sign(data)
@@ -804,6 +871,14 @@ func (sb *Backend) getOrderedValidators(number uint64, hash common.Hash) istanbu
 			}
 		}
 		valSet.SetRandomness(seed)
+	} else if sb.config.ProposerPolicy == istanbul.VRF && sb.chain.Config().IsVRF(new(big.Int).SetUint64(number+1)) {
+		// Without randomness the VRF selector keeps the round robin order until the fork.
+		randomness, err := sb.vrfRandomness(number, hash)
+		if err != nil {
+			sb.logger.Error("Failed to get randomness for proposer selection", "block_number", number, "hash", hash, "error", err)
+			return validator.NewSet(nil)
+		}
+		valSet.SetRandomness(randomness)
 	}
 
 	return valSet
@@ -1082,6 +1157,30 @@ func (sb *Backend) UpdateReplicaState(seq *big.Int) {
 	}
 }
 
+// signedParentSeals reports whether this validator is in the parent aggregated seal of one
+// of the last blocks of the chain, i.e. whether it signed one of the blocks before them.
+func (sb *Backend) signedParentSeals(blocks uint64) bool {
+	child := sb.currentBlock().Header()
+	for i := uint64(0); i < blocks && child.Number.Uint64() > 1; i++ {
+		number := child.Number.Uint64()
+		parent := sb.chain.GetHeader(child.ParentHash, number-1)
+		if parent == nil {
+			return false
+		}
+		extra, err := types.ExtractIstanbulExtra(child)
+		if err != nil {
+			return false
+		}
+		// The parent seal is signed by the validators of the parent block
+		valSet := sb.getValidators(number-2, parent.ParentHash)
+		if index, _ := valSet.GetByAddress(sb.Address()); index >= 0 && extra.ParentAggregatedSeal.Bitmap.Bit(index) != 0 {
+			return true
+		}
+		child = parent
+	}
+	return false
+}
+
 // recordBlockProductionTimes records information about the block production cycle and reports it through the CSVRecorder
 func (sb *Backend) recordBlockProductionTimes(blockNumber uint64, txCount int, gasUsed, round uint64) {
 	cycle := time.Since(sb.cycleStart)
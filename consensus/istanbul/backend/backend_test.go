@@ -25,8 +25,11 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/contracts/abis"
+	"github.com/mapprotocol/atlas/contracts/testutil"
 	"github.com/mapprotocol/atlas/core"
 	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
 )
 
 func TestSign(t *testing.T) {
@@ -172,3 +175,63 @@ func TestGetProposer(t *testing.T) {
 	}
 
 }
+
+type signerAccountsMock struct {
+	accounts map[common.Address]common.Address
+}
+
+func (m *signerAccountsMock) SignerToAccount(signer common.Address) common.Address {
+	if account, ok := m.accounts[signer]; ok {
+		return account
+	}
+	return signer
+}
+
+type eligibleVotesMock struct {
+	validators []common.Address
+	votes      []*big.Int
+}
+
+func (m *eligibleVotesMock) GetTotalVotesForEligibleValidators() ([]common.Address, []*big.Int) {
+	return m.validators, m.votes
+}
+
+func newValidatorVotesRunner(signerAccounts map[common.Address]common.Address, validators []common.Address, votes []*big.Int) *testutil.MockEVMRunner {
+	runner := testutil.NewMockEVMRunner()
+	registry := testutil.NewRegistryMock()
+	runner.RegisterContract(params.RegistrySmartContractAddress, registry)
+
+	accountsAddress, electionAddress := common.HexToAddress("0x0a"), common.HexToAddress("0x0e")
+	registry.AddContract(params.AccountsId, accountsAddress)
+	registry.AddContract(params.ElectionRegistryId, electionAddress)
+	accountsContract := testutil.NewContractMock(abis.Accounts, &signerAccountsMock{accounts: signerAccounts})
+	electionContract := testutil.NewContractMock(abis.Elections, &eligibleVotesMock{validators: validators, votes: votes})
+	runner.RegisterContract(accountsAddress, &accountsContract)
+	runner.RegisterContract(electionAddress, &electionContract)
+	return runner
+}
+
+func TestGetValidatorVotes(t *testing.T) {
+	signer, account := common.HexToAddress("0x51"), common.HexToAddress("0xa1")
+	other := common.HexToAddress("0xa2")
+
+	// the votes are kept under the account of a validator with an authorized signer
+	runner := newValidatorVotesRunner(
+		map[common.Address]common.Address{signer: account},
+		[]common.Address{account, other},
+		[]*big.Int{big.NewInt(30), big.NewInt(70)},
+	)
+	votes, err := getValidatorVotes(runner, []common.Address{signer, other})
+	if err != nil {
+		t.Fatalf("getValidatorVotes: %v", err)
+	}
+	if len(votes) != 2 || votes[0].Cmp(big.NewInt(30)) != 0 || votes[1].Cmp(big.NewInt(70)) != 0 {
+		t.Errorf("votes mismatch: have %v, want [30 70]", votes)
+	}
+
+	// a validator that is not eligible has no known voting power
+	runner = newValidatorVotesRunner(nil, []common.Address{other}, []*big.Int{big.NewInt(70)})
+	if _, err := getValidatorVotes(runner, []common.Address{signer, other}); err == nil {
+		t.Errorf("expected an error for a validator without votes")
+	}
+}
@@ -39,6 +39,7 @@ import (
 	ethChain "github.com/mapprotocol/atlas/core/chain"
 	"github.com/mapprotocol/atlas/core/state"
 	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/core/vm"
 	blscrypto "github.com/mapprotocol/atlas/helper/bls"
 	"github.com/mapprotocol/atlas/params"
 	"golang.org/x/crypto/sha3"
@@ -87,6 +88,10 @@ var (
 	errUnauthorizedAnnounceMessage = errors.New("unauthorized announce message")
 	// errNotAValidator is returned when the node is not configured as a validator
 	errNotAValidator = errors.New("Not configured as a validator")
+	// errMissingVRFProof is returned if a header proposed under the VRF policy carries no proof.
+	errMissingVRFProof = errors.New("missing vrf proof")
+	// errInvalidVRFProof is returned if the VRF proof in a header was not made by its signer.
+	errInvalidVRFProof = errors.New("invalid vrf proof")
 )
 
 var (
@@ -206,7 +211,7 @@ func (sb *Backend) verifyCascadingFields(chain consensus.ChainHeaderReader, head
 			return errInvalidTimestamp
 		}
 		// Verify validators in extraData. Validators in snapshot and extraData should be the same.
-		if err := sb.verifySigner(chain, header, parents); err != nil {
+		if err := sb.verifySigner(chain, header, parent, parents); err != nil {
 			return err
 		}
 	} else if err := sb.checkEpochBlockExists(chain, header, parents); err != nil {
@@ -247,8 +252,9 @@ func (sb *Backend) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*t
 	return abort, results
 }
 
-// verifySigner checks whether the signer is in parent's validator set
-func (sb *Backend) verifySigner(chain consensus.ChainHeaderReader, header *types.Header, parents []*types.Header) error {
+// verifySigner checks whether the signer is in parent's validator set, and under the VRF
+// policy that it made the VRF proof in the header
+func (sb *Backend) verifySigner(chain consensus.ChainHeaderReader, header, parent *types.Header, parents []*types.Header) error {
 	// Verifying the genesis block is not supported
 	number := header.Number.Uint64()
 	if number == 0 {
@@ -268,9 +274,13 @@ func (sb *Backend) verifySigner(chain consensus.ChainHeaderReader, header *types
 	}
 
 	// Signer should be in the validator set of previous block's extraData.
-	if _, v := snap.ValSet.GetByAddress(signer); v == nil {
+	_, v := snap.ValSet.GetByAddress(signer)
+	if v == nil {
 		return errUnauthorized
 	}
+	if sb.config.ProposerPolicy == istanbul.VRF && chain.Config().IsVRF(header.Number) {
+		return verifyVRFProof(chain.Config(), header, parent, v)
+	}
 	return nil
 }
 
@@ -349,15 +359,17 @@ func (sb *Backend) verifyAggregatedSeal(headerHash common.Hash, validators istan
 	proposalSeal := istanbulCore.PrepareCommittedSeal(headerHash, aggregatedSeal.Round)
 	// Find which public keys signed from the provided validator set
 	publicKeys := []blscrypto.SerializedPublicKey{}
+	signers := []common.Address{}
 	for i := 0; i < validators.Size(); i++ {
 		if aggregatedSeal.Bitmap.Bit(i) == 1 {
-			pubKey := validators.GetByIndex(uint64(i)).BLSPublicKey()
-			publicKeys = append(publicKeys, pubKey)
+			val := validators.GetByIndex(uint64(i))
+			publicKeys = append(publicKeys, val.BLSPublicKey())
+			signers = append(signers, val.Address())
 		}
 	}
-	// The length of a valid seal should be greater than the minimum quorum size
-	if len(publicKeys) < validators.MinQuorumSize() {
-		logger.Error("Aggregated seal does not aggregate enough seals", "numSeals", len(publicKeys), "minimum quorum size", validators.MinQuorumSize())
+	// The signers of a valid seal should hold a quorum of the voting power
+	if !validators.HasQuorum(signers) {
+		logger.Error("Aggregated seal does not aggregate enough seals", "numSeals", len(publicKeys), "quorum", validators.QuorumWeight())
 		return errInsufficientSeals
 	}
 	err := blscrypto.CryptoType().VerifyAggregatedSignature(publicKeys, proposalSeal, []byte{}, aggregatedSeal.Signature,
@@ -427,11 +439,18 @@ func (sb *Backend) Prepare(chain consensus.ChainHeaderReader, header *types.Head
 	// addParentSeal blocks for up to 500ms waiting for the core to reach the target sequence.
 	// Prepare is called from non-validators, so don't bother with the parent seal unless this
 	// block is to be proposed instead of for the local state.
-	if sb.IsValidating() {
-		return sb.addParentSeal(chain, header)
-	} else {
+	if !sb.IsValidating() {
 		return nil
 	}
+	if err := sb.addParentSeal(chain, header); err != nil {
+		return err
+	}
+	// The proof is made by every validator preparing a block, only the one selected as
+	// proposer will get to propose it.
+	if sb.config.ProposerPolicy == istanbul.VRF && chain.Config().IsVRF(header.Number) {
+		return sb.writeVRFProof(chain, parent, header)
+	}
+	return nil
 }
 
 // UpdateValSetDiff will update the validator set diff in the header, if the mined header is the last block of the epoch
@@ -447,12 +466,17 @@ func (sb *Backend) UpdateValSetDiff(chain consensus.ChainHeaderReader, header *t
 				return err
 			}
 
+			weights, err := sb.getNewValidatorWeights(header, state, snap.validators(), newValSet)
+			if err != nil {
+				return err
+			}
+
 			// add validators in snapshot to extraData's validators section
-			return writeValidatorSetDiff(header, snap.validators(), newValSet)
+			return writeValidatorSetDiff(header, snap.validators(), newValSet, weights)
 		}
 	}
 	// If it's not the last block or we were unable to pull the new validator set, then the validator set diff should be empty
-	return writeValidatorSetDiff(header, []istanbul.ValidatorData{}, []istanbul.ValidatorData{})
+	return writeValidatorSetDiff(header, []istanbul.ValidatorData{}, []istanbul.ValidatorData{}, nil)
 }
 
 // IsLastBlockOfEpoch returns whether or not a particular header represents the last block in the epoch.
@@ -518,6 +542,10 @@ func (sb *Backend) Finalize(chain consensus.ChainHeaderReader, header *types.Hea
 			sb.logger.Error("Failed to distribute epoch rewards", "blockNumber", header.Number, "err", err)
 			state.RevertToSnapshot(snapshot)
 		}
+		// The relayer counters of the epoch are dropped even if no rewards were paid for them
+		if chain.Config().IsRelayerSet(header.Number) {
+			vm.ClearEpochSubmissions(state, istanbul.GetEpochNumber(header.Number.Uint64(), sb.config.Epoch))
+		}
 	}
 
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
@@ -650,12 +678,19 @@ func (sb *Backend) updateReplicaStateLoop(bc *ethChain.BlockChain) {
 	for {
 		select {
 		case chainEvent := <-chainEventCh:
+			if sb.replicaState == nil {
+				continue
+			}
+			consensusBlock := new(big.Int).Add(chainEvent.Block.Number(), common.Big1)
 			sb.coreMu.RLock()
-			if !sb.isCoreStarted() && sb.replicaState != nil {
-				consensusBlock := new(big.Int).Add(chainEvent.Block.Number(), common.Big1)
+			coreStarted := sb.isCoreStarted()
+			sb.coreMu.RUnlock()
+			// The replica state may start the core, which needs the write lock
+			if !coreStarted {
 				sb.replicaState.NewChainHead(consensusBlock)
+			} else {
+				sb.sendReplicaHeartbeat(consensusBlock)
 			}
-			sb.coreMu.RUnlock()
 		case err := <-chainEventSub.Err():
 			log.Error("Error in istanbul's subscription to the blockchain's chain event", "err", err)
 			return
@@ -1132,8 +1167,8 @@ func writeEmptyIstanbulExtra(header *types.Header) error {
 }
 
 // writeValidatorSetDiff initializes the header's Extra field with any changes in the
-// validator set that occurred since the last block
-func writeValidatorSetDiff(header *types.Header, oldValSet []istanbul.ValidatorData, newValSet []istanbul.ValidatorData) error {
+// validator set that occurred since the last block, and the voting power of the new set
+func writeValidatorSetDiff(header *types.Header, oldValSet []istanbul.ValidatorData, newValSet []istanbul.ValidatorData, weights []*big.Int) error {
 	// compensate the lack bytes if header.Extra is not enough IstanbulExtraVanity bytes.
 	if len(header.Extra) < types.IstanbulExtraVanity {
 		header.Extra = append(header.Extra, bytes.Repeat([]byte{0x00}, types.IstanbulExtraVanity-len(header.Extra))...)
@@ -1158,6 +1193,7 @@ func writeValidatorSetDiff(header *types.Header, oldValSet []istanbul.ValidatorD
 	extra.AddedValidatorsPublicKeys = addedValidatorsPublicKeys
 	extra.AddedValidatorsG1PublicKeys = addedValidatorsG1PublicKeys
 	extra.RemovedValidators = removedValidators
+	extra.ValidatorWeights = weights
 
 	// update the header's extra with the new diff
 	payload, err := rlp.EncodeToBytes(extra)
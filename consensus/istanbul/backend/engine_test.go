@@ -354,7 +354,7 @@ func TestPrepareExtra(t *testing.T) {
 		Extra: append(make([]byte, types.IstanbulExtraVanity), extra...),
 	}
 
-	err = writeValidatorSetDiff(h, oldValidators, newValidators)
+	err = writeValidatorSetDiff(h, oldValidators, newValidators, nil)
 	g.Expect(err).ToNot(HaveOccurred())
 
 	// the header must have the updated extra data
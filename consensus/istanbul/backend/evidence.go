@@ -0,0 +1,153 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/contracts/validators"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+var (
+	// errNoTxPool is returned when submitting an evidence before the transaction pool is set
+	errNoTxPool = errors.New("no transaction pool to submit evidence to")
+	// errEvidenceNotSlashable is returned when submitting an evidence the double signing slasher
+	// cannot check, as it only accepts two sealed blocks of the same height
+	errEvidenceNotSlashable = errors.New("evidence is not backed by two sealed blocks")
+)
+
+// TxPool is the transaction pool equivocation evidences are submitted through
+type TxPool interface {
+	Nonce(addr common.Address) uint64
+	GasPrice() *big.Int
+	AddLocal(tx *types.Transaction) error
+}
+
+// SetTxPool sets the transaction pool used to submit equivocation evidences for slashing
+func (sb *Backend) SetTxPool(txPool TxPool) {
+	sb.evidenceMu.Lock()
+	defer sb.evidenceMu.Unlock()
+	sb.txPool = txPool
+}
+
+// ReportEquivocation implements core.CoreBackend.ReportEquivocation
+func (sb *Backend) ReportEquivocation(ev *istanbul.Evidence) {
+	sb.equivocationsMeter.Mark(1)
+	if !sb.config.SubmitEvidence {
+		return
+	}
+	go func() {
+		if err := sb.submitEvidence(ev); err == errEvidenceNotSlashable {
+			sb.logger.Debug("Equivocation evidence is not slashable", "evidence", ev)
+		} else if err != nil {
+			sb.logger.Warn("Failed to submit equivocation evidence", "evidence", ev, "err", err)
+		}
+	}()
+}
+
+// submitEvidence sends a transaction signed by this validator submitting the blocks the
+// evidence signer committed to the double signing slasher contract. Only commits for two
+// blocks that were both sealed can be slashed, any other equivocation is just recorded.
+func (sb *Backend) submitEvidence(ev *istanbul.Evidence) error {
+	// Serialize submissions, so that they don't reuse the same nonce
+	sb.evidenceMu.Lock()
+	defer sb.evidenceMu.Unlock()
+
+	if sb.txPool == nil {
+		return errNoTxPool
+	}
+	headerA, headerB, err := sb.equivocatedHeaders(ev)
+	if err != nil {
+		return err
+	}
+	index := sb.getValidators(headerA.Number.Uint64()-1, headerA.ParentHash).GetIndex(ev.Signer)
+	if index < 0 {
+		return errEvidenceNotSlashable
+	}
+	encodedA, err := rlp.EncodeToBytes(headerA)
+	if err != nil {
+		return err
+	}
+	encodedB, err := rlp.EncodeToBytes(headerB)
+	if err != nil {
+		return err
+	}
+
+	vmRunner, err := sb.chain.NewEVMRunnerForCurrentBlock()
+	if err != nil {
+		return err
+	}
+	slasher, err := validators.GetDoubleSigningSlasherAddress(vmRunner)
+	if err != nil {
+		return err
+	}
+	if _, err := validators.CheckForDoubleSigning(vmRunner, ev.Signer, index, encodedA, encodedB); err != nil {
+		return err
+	}
+	data, err := validators.EncodeSlashDoubleSigning(ev.Signer, index, encodedA, encodedB)
+	if err != nil {
+		return err
+	}
+
+	from := sb.Address()
+	tx := types.NewTransaction(sb.txPool.Nonce(from), slasher, common.Big0, params.MaxGasForSlashDoubleSigning, sb.txPool.GasPrice(), data)
+	signer := types.LatestSignerForChainID(sb.ChainConfig().ChainID)
+	sig, err := sb.wallets().Ecdsa.SignHash(signer.Hash(tx))
+	if err != nil {
+		return err
+	}
+	if tx, err = tx.WithSignature(signer, sig); err != nil {
+		return err
+	}
+	if err := sb.txPool.AddLocal(tx); err != nil {
+		return err
+	}
+	sb.logger.Info("Submitted equivocation evidence", "evidence", ev, "tx", tx.Hash())
+	return sb.core.MarkEvidenceSubmitted(ev, tx.Hash())
+}
+
+// equivocatedHeaders returns the sealed headers of the two blocks committed to by the
+// conflicting commits of the evidence.
+func (sb *Backend) equivocatedHeaders(ev *istanbul.Evidence) (*types.Header, *types.Header, error) {
+	if ev.Code != istanbul.MsgCommit {
+		return nil, nil, errEvidenceNotSlashable
+	}
+	var headers [2]*types.Header
+	for i, payload := range [][]byte{ev.First, ev.Second} {
+		msg := new(istanbul.Message)
+		if err := msg.FromPayload(payload, nil); err != nil {
+			return nil, nil, err
+		}
+		if err := msg.DecodeMessage(); err != nil {
+			return nil, nil, err
+		}
+		_, digest, ok := msg.ConsensusSubject()
+		if !ok {
+			return nil, nil, errEvidenceNotSlashable
+		}
+		if headers[i] = sb.chain.GetHeader(digest, ev.View.Sequence.Uint64()); headers[i] == nil {
+			return nil, nil, errEvidenceNotSlashable
+		}
+	}
+	return headers[0], headers[1], nil
+}
@@ -66,11 +66,14 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg, peer consensus.Pe
 		case istanbul.ConsensusMsg:
 			fallthrough
 		case istanbul.EnodeCertificateMsg:
+			fallthrough
+		case istanbul.ReplicaHeartbeatMsg:
 			// This will handle the following messages:
 			// 1) ValEnodesShareMsg
 			// 2) FwdMsg
 			// 3) ConsensusMsg
 			// 4) EnodeCertificateMsg
+			// 5) ReplicaHeartbeatMsg
 			// No error on skipped messages
 			return sb.proxyEngine.HandleMsg(peer, msg.Code, data)
 		case istanbul.DelegateSignMsg:
@@ -123,6 +126,9 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg, peer consensus.Pe
 		case istanbul.ValidatorHandshakeMsg:
 			logger.Warn("Received unexpected Istanbul validator handshake message")
 			return true, nil
+		case istanbul.ReplicaHeartbeatMsg:
+			go sb.handleReplicaHeartbeatMsg(peer, data)
+			return true, nil
 		default:
 			logger.Error("Unhandled istanbul message as primary", "address", addr, "peer's enodeURL", peer.Node().String(), "ethMsgCode", msg.Code)
 			return false, nil
@@ -156,6 +162,9 @@ func (sb *Backend) HandleMsg(addr common.Address, msg p2p.Msg, peer consensus.Pe
 		case istanbul.ValidatorHandshakeMsg:
 			logger.Warn("Received unexpected Istanbul validator handshake message")
 			return true, nil
+		case istanbul.ReplicaHeartbeatMsg:
+			go sb.handleReplicaHeartbeatMsg(peer, data)
+			return true, nil
 		default:
 			logger.Error("Unhandled istanbul message as replica", "address", addr, "peer's enodeURL", peer.Node().String(), "ethMsgCode", msg.Code)
 			return false, nil
@@ -68,6 +68,10 @@ func (p *MockPeer) PurposeIsSet(purpose p2p.PurposeFlag) bool {
 	return false
 }
 
+func (p *MockPeer) RTT() time.Duration {
+	return 0
+}
+
 func TestIstanbulMessage(t *testing.T) {
 	chain, backend := newBlockChain(1, true)
 	defer chain.Stop()
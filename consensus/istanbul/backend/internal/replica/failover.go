@@ -0,0 +1,214 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package replica
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+)
+
+var (
+	// errFailoverDisabled is returned when handling a heartbeat without failover enabled
+	errFailoverDisabled = errors.New("replica failover is disabled")
+	// errInvalidHeartbeat is returned when a heartbeat carries no fencing token or sequence
+	errInvalidHeartbeat = errors.New("invalid replica heartbeat")
+)
+
+// EnableFailover makes a replica take over as primary once it missed the heartbeats
+// of the primary for the given number of blocks, and signedFn reports the validator is
+// missing from the parent seals of these blocks as well. Fencing tokens are signed with
+// signFn.
+func (rs *replicaStateImpl) EnableFailover(blocks uint64, signFn func(data []byte) ([]byte, error), signedFn func(blocks uint64) bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.failoverBlocks = blocks
+	rs.signFn = signFn
+	rs.signedFn = signedFn
+}
+
+// Heartbeat returns the heartbeat for the given sequence to send to the replicas, or nil
+// if this node is not the primary. The first heartbeat of a primary issues its fencing token.
+func (rs *replicaStateImpl) Heartbeat(seq *big.Int) (*istanbul.ReplicaHeartbeat, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.failoverBlocks == 0 || !(rs.state == primaryPermanent || rs.state == primaryInRange) {
+		return nil, nil
+	}
+	if rs.token == nil {
+		oldToken, oldFence := rs.token, rs.fence
+		if err := rs.issueToken(seq); err != nil {
+			return nil, err
+		}
+		if err := rs.rsdb.StoreReplicaState(rs); err != nil {
+			rs.token, rs.fence = oldToken, oldFence
+			return nil, err
+		}
+	}
+	return &istanbul.ReplicaHeartbeat{Token: rs.token, Sequence: new(big.Int).Set(seq)}, nil
+}
+
+// HandleHeartbeat processes a heartbeat from the primary, whose fencing token must have
+// been checked to be signed by the validator. A primary seeing a token superseding its own
+// has been replaced and steps down, so of two nodes taking over with the same term only
+// the one with the lower nonce keeps signing.
+func (rs *replicaStateImpl) HandleHeartbeat(heartbeat *istanbul.ReplicaHeartbeat) error {
+	if heartbeat.Token == nil || heartbeat.Token.Sequence == nil || heartbeat.Sequence == nil {
+		return errInvalidHeartbeat
+	}
+	logger := log.New("func", "HandleHeartbeat", "seq", heartbeat.Sequence, "term", heartbeat.Token.Term)
+
+	rs.mu.Lock()
+	if rs.failoverBlocks == 0 {
+		rs.mu.Unlock()
+		return errFailoverDisabled
+	}
+	if rs.fence != nil && rs.fence.Supersedes(heartbeat.Token) {
+		rs.mu.Unlock()
+		logger.Debug("Ignoring heartbeat of a fenced primary", "fence", rs.fence)
+		return nil
+	}
+	isPrimary := rs.state == primaryPermanent || rs.state == primaryInRange
+	if isPrimary && rs.token != nil && heartbeat.Token.Equal(rs.token) {
+		// Our own heartbeat relayed back to us
+		rs.mu.Unlock()
+		return nil
+	}
+
+	rs.lastHeartbeat = new(big.Int).Set(heartbeat.Sequence)
+	if rs.fence != nil && heartbeat.Token.Equal(rs.fence) {
+		rs.mu.Unlock()
+		return nil
+	}
+
+	oldState, oldStart, oldStop := rs.state, rs.startValidatingBlock, rs.stopValidatingBlock
+	oldToken, oldFence := rs.token, rs.fence
+	rs.fence = heartbeat.Token
+	if isPrimary {
+		logger.Warn("Another node took over as primary, stepping down", "token", rs.token)
+		rs.state = replicaPermanent
+		rs.startValidatingBlock = nil
+		rs.stopValidatingBlock = nil
+		rs.token = nil
+	}
+	if err := rs.rsdb.StoreReplicaState(rs); err != nil {
+		if !isPrimary {
+			rs.fence = oldFence
+			rs.mu.Unlock()
+			return err
+		}
+		// Keep the fence in memory, so that this node does not sign anymore
+		logger.Error("Error when saving rsdb in HandleHeartbeat", "err", err)
+	}
+	rs.mu.Unlock()
+
+	if isPrimary {
+		// The core is stopped outside of the lock as it may wait on handlers checking IsPrimaryForSeq
+		if err := rs.stopFn(); err != nil {
+			logger.Error("Error stopping core after being fenced", "err", err, "state", oldState, "start", oldStart, "stop", oldStop, "token", oldToken)
+			return err
+		}
+	}
+	return nil
+}
+
+// checkFailover takes over as primary if the heartbeats of the primary have been missing
+// for more than failoverBlocks blocks at the given sequence, and the validator did not
+// sign these blocks. A primary that keeps signing is alive, only cut off from this replica.
+func (rs *replicaStateImpl) checkFailover(seq *big.Int) {
+	logger := log.New("func", "checkFailover", "seq", seq)
+
+	rs.mu.Lock()
+	if rs.failoverBlocks == 0 || rs.state != replicaPermanent {
+		rs.mu.Unlock()
+		return
+	}
+	if rs.lastHeartbeat == nil {
+		// Start counting the missed blocks from the first block seen
+		rs.lastHeartbeat = new(big.Int).Set(seq)
+		rs.mu.Unlock()
+		return
+	}
+	missed := new(big.Int).Sub(seq, rs.lastHeartbeat)
+	if missed.Cmp(new(big.Int).SetUint64(rs.failoverBlocks)) <= 0 {
+		rs.mu.Unlock()
+		return
+	}
+	if rs.signedFn != nil && rs.signedFn(rs.failoverBlocks) {
+		rs.mu.Unlock()
+		logger.Warn("Primary missed heartbeats but still signs blocks, not taking over", "missed", missed)
+		return
+	}
+
+	// The token is stored before the core starts, so that a restart never signs with a stale term
+	oldToken, oldFence := rs.token, rs.fence
+	if err := rs.issueToken(seq); err != nil {
+		rs.mu.Unlock()
+		logger.Error("Can't issue fencing token for failover", "err", err)
+		return
+	}
+	rs.state = primaryPermanent
+	if err := rs.rsdb.StoreReplicaState(rs); err != nil {
+		rs.state = replicaPermanent
+		rs.token, rs.fence = oldToken, oldFence
+		rs.mu.Unlock()
+		logger.Error("Error when saving rsdb in failover", "err", err)
+		return
+	}
+	logger.Warn("Primary missed heartbeats, taking over", "missed", missed, "token", rs.token)
+	rs.mu.Unlock()
+
+	if err := rs.startFn(); err != nil {
+		logger.Error("Error starting core in failover", "err", err)
+		rs.mu.Lock()
+		defer rs.mu.Unlock()
+		rs.state = replicaPermanent
+		rs.token = nil
+		rs.lastHeartbeat = new(big.Int).Set(seq)
+		if err := rs.rsdb.StoreReplicaState(rs); err != nil {
+			logger.Crit("Error when saving rsdb after failed failover", "err", err)
+		}
+	}
+}
+
+// issueToken signs a fencing token for a term above any seen so far, starting at seq.
+// The caller must hold the lock and store the state.
+func (rs *replicaStateImpl) issueToken(seq *big.Int) error {
+	term := uint64(1)
+	if rs.fence != nil {
+		term = rs.fence.Term + 1
+	}
+	token, err := istanbul.NewFencingToken(term, seq, rs.signFn)
+	if err != nil {
+		return err
+	}
+	rs.token = token
+	rs.fence = token
+	return nil
+}
+
+// fenced reports whether a token superseding this node's token has been seen.
+// The caller must hold the lock.
+func (rs *replicaStateImpl) fenced() bool {
+	if rs.failoverBlocks == 0 || rs.fence == nil {
+		return false
+	}
+	return rs.token == nil || rs.fence.Supersedes(rs.token)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
 	lvlerrors "github.com/syndtr/goleveldb/leveldb/errors"
 )
 
@@ -67,6 +68,13 @@ type State interface {
 	// Internal functions
 	// Updates replica state given the current block undergoing consensus.
 	NewChainHead(blockNumber *big.Int)
+	// Makes a replica take over after missing the primary's heartbeats for the given number of blocks,
+	// if the validator did not sign the parent seals of these blocks either.
+	EnableFailover(blocks uint64, signFn func(data []byte) ([]byte, error), signedFn func(blocks uint64) bool)
+	// Returns the heartbeat to send to the replicas for the given sequence, nil if not primary.
+	Heartbeat(seq *big.Int) (*istanbul.ReplicaHeartbeat, error)
+	// Processes a heartbeat of the primary, stepping down if it holds a newer fencing token.
+	HandleHeartbeat(heartbeat *istanbul.ReplicaHeartbeat) error
 	// Closes the replica state database.
 	Close() error
 
@@ -87,6 +95,14 @@ type replicaStateImpl struct {
 
 	startFn func() error
 	stopFn  func() error
+
+	// Automatic failover, disabled if failoverBlocks is 0
+	failoverBlocks uint64
+	signFn         func(data []byte) ([]byte, error)
+	signedFn       func(blocks uint64) bool // Whether the validator is in the parent seal of one of the last blocks
+	token          *istanbul.FencingToken   // The token this node signs with as primary
+	fence          *istanbul.FencingToken   // The token superseding all others seen
+	lastHeartbeat  *big.Int                 // The sequence of the latest heartbeat of the primary
 }
 
 // NewState creates a replicaState in the given replica state and opens or creates the replica state DB at `path`.
@@ -195,6 +211,8 @@ func (rs *replicaStateImpl) NewChainHead(blockNumber *big.Int) {
 				logger.Crit("Error when saving rsdb in NewChainHead in transition to primary. Rolled back transition.", "err", err)
 			}
 		}
+	case replicaPermanent:
+		rs.checkFailover(blockNumber)
 	default:
 		// pass
 	}
@@ -281,6 +299,8 @@ func (rs *replicaStateImpl) MakeReplica() error {
 	oldStart := rs.startValidatingBlock
 	oldStop := rs.stopValidatingBlock
 
+	oldToken := rs.token
+
 	if rs.state == primaryPermanent || rs.state == primaryInRange {
 		if err := rs.stopFn(); err != nil {
 			return err
@@ -289,6 +309,8 @@ func (rs *replicaStateImpl) MakeReplica() error {
 	rs.startValidatingBlock = nil
 	rs.stopValidatingBlock = nil
 	rs.state = replicaPermanent
+	rs.token = nil
+	rs.lastHeartbeat = nil
 
 	if err := rs.rsdb.StoreReplicaState(rs); err != nil {
 		if startErr := rs.startFn(); startErr != nil {
@@ -298,6 +320,7 @@ func (rs *replicaStateImpl) MakeReplica() error {
 		rs.state = oldState
 		rs.startValidatingBlock = oldStart
 		rs.stopValidatingBlock = oldStop
+		rs.token = oldToken
 		return fmt.Errorf("Error when saving rsdb in MakeReplica. err: %v", err)
 	}
 	return nil
@@ -312,8 +335,24 @@ func (rs *replicaStateImpl) MakePrimary() error {
 	oldStart := rs.startValidatingBlock
 	oldStop := rs.stopValidatingBlock
 
+	oldToken := rs.token
+	oldFence := rs.fence
+
+	// With failover, a new primary fences the previous one from its next heartbeat on
+	if rs.failoverBlocks > 0 && (rs.state == replicaPermanent || rs.state == replicaWaiting) {
+		seq := common.Big0
+		if rs.lastHeartbeat != nil {
+			seq = rs.lastHeartbeat
+		}
+		if err := rs.issueToken(seq); err != nil {
+			return err
+		}
+	}
+
 	if rs.state == replicaPermanent || rs.state == replicaWaiting {
 		if err := rs.startFn(); err != nil {
+			rs.token = oldToken
+			rs.fence = oldFence
 			return err
 		}
 	}
@@ -329,6 +368,8 @@ func (rs *replicaStateImpl) MakePrimary() error {
 		rs.state = oldState
 		rs.startValidatingBlock = oldStart
 		rs.stopValidatingBlock = oldStop
+		rs.token = oldToken
+		rs.fence = oldFence
 		return fmt.Errorf("Error when saving rsdb in MakePrimary. err: %v", err)
 	}
 	return nil
@@ -344,11 +385,16 @@ func (rs *replicaStateImpl) IsPrimary() bool {
 // IsPrimaryForSeq determines is this node is the primary validator.
 // If start/stop checking is enabled (via a call to start/stop at block)
 // determine if start <= seq < stop. If not enabled, check if this was
-// set up with replica mode.
+// set up with replica mode. With failover enabled, a primary fenced by a
+// newer fencing token is never primary.
 func (rs *replicaStateImpl) IsPrimaryForSeq(seq *big.Int) bool {
 	rs.mu.RLock()
 	defer rs.mu.RUnlock()
 
+	if rs.fenced() {
+		return false
+	}
+
 	switch rs.state {
 	case primaryPermanent:
 		return true
@@ -367,6 +413,10 @@ type ReplicaStateSummary struct {
 	IsPrimary            bool     `json:"isPrimary"`
 	StartValidatingBlock *big.Int `json:"startValidatingBlock"`
 	StopValidatingBlock  *big.Int `json:"stopValidatingBlock"`
+	FailoverBlocks       uint64   `json:"failoverBlocks,omitempty"`
+	Term                 uint64   `json:"term,omitempty"`
+	HighestTerm          uint64   `json:"highestTerm,omitempty"`
+	LastHeartbeat        *big.Int `json:"lastHeartbeat,omitempty"`
 }
 
 func (rs *replicaStateImpl) Summary() *ReplicaStateSummary {
@@ -378,6 +428,14 @@ func (rs *replicaStateImpl) Summary() *ReplicaStateSummary {
 		IsPrimary:            rs.state == primaryPermanent || rs.state == primaryInRange,
 		StartValidatingBlock: rs.startValidatingBlock,
 		StopValidatingBlock:  rs.stopValidatingBlock,
+		FailoverBlocks:       rs.failoverBlocks,
+		LastHeartbeat:        rs.lastHeartbeat,
+	}
+	if rs.token != nil {
+		summary.Term = rs.token.Term
+	}
+	if rs.fence != nil {
+		summary.HighestTerm = rs.fence.Term
 	}
 
 	return summary
@@ -387,6 +445,8 @@ type replicaStateRLP struct {
 	State                state
 	StartValidatingBlock *big.Int
 	StopValidatingBlock  *big.Int
+	Fence                *istanbul.FencingToken `rlp:"optional"` // Set whenever Token is
+	Token                *istanbul.FencingToken `rlp:"optional"`
 }
 
 // EncodeRLP should write the RLP encoding of its receiver to w.
@@ -402,6 +462,8 @@ func (rs *replicaStateImpl) EncodeRLP(w io.Writer) error {
 		State:                rs.state,
 		StartValidatingBlock: rs.startValidatingBlock,
 		StopValidatingBlock:  rs.stopValidatingBlock,
+		Fence:                rs.fence,
+		Token:                rs.token,
 	}
 	return rlp.Encode(w, entry)
 }
@@ -430,6 +492,8 @@ func (rs *replicaStateImpl) DecodeRLP(stream *rlp.Stream) error {
 	} else {
 		rs.stopValidatingBlock = data.StopValidatingBlock
 	}
+	rs.fence = data.Fence
+	rs.token = data.Token
 
 	return nil
 }
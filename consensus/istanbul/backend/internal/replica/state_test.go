@@ -21,6 +21,10 @@ import (
 	"fmt"
 	"math/big"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
 )
 
 func noop() error {
@@ -296,3 +300,211 @@ func TestSetStopValidatingBlock(t *testing.T) {
 	})
 
 }
+
+func newTestSigner(t *testing.T) (common.Address, func(data []byte) ([]byte, error)) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return crypto.PubkeyToAddress(key.PublicKey), func(data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), key)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	addr, signFn := newTestSigner(t)
+	started := false
+	start := func() error { started = true; return nil }
+	stop := func() error { started = false; return nil }
+
+	rsState, _ := NewState(true, "", start, stop)
+	rs := rsState.(*replicaStateImpl)
+	rs.EnableFailover(3, signFn, func(uint64) bool { return false })
+
+	primaryToken, err := istanbul.NewFencingToken(1, big.NewInt(5), signFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.NewChainHead(big.NewInt(10))
+	if err := rs.HandleHeartbeat(&istanbul.ReplicaHeartbeat{Token: primaryToken, Sequence: big.NewInt(11)}); err != nil {
+		t.Fatalf("unexpected error handling heartbeat: %v", err)
+	}
+	for seq := int64(12); seq <= 14; seq++ {
+		rs.NewChainHead(big.NewInt(seq))
+		if started || rs.IsPrimary() {
+			t.Fatalf("expected to stay replica at seq %d", seq)
+		}
+	}
+
+	// four blocks without heartbeat
+	rs.NewChainHead(big.NewInt(15))
+	if !started || !rs.IsPrimaryForSeq(big.NewInt(15)) {
+		t.Fatal("expected to take over as primary")
+	}
+	if err := rs.CheckRSDB(); err != nil {
+		t.Error(err)
+	}
+	loaded, err := rs.rsdb.GetReplicaState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.token == nil || loaded.token.Term != 2 || loaded.token.Sequence.Cmp(big.NewInt(15)) != 0 {
+		t.Fatalf("expected stored token with term 2 from seq 15, got %v", loaded.token)
+	}
+	if signer, err := loaded.token.Signer(); err != nil || signer != addr {
+		t.Errorf("expected token signed by %v, got %v (err: %v)", addr, signer, err)
+	}
+
+	heartbeat, err := rs.Heartbeat(big.NewInt(16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if heartbeat.Token.Term != 2 || heartbeat.Sequence.Cmp(big.NewInt(16)) != 0 {
+		t.Errorf("unexpected heartbeat %v", heartbeat)
+	}
+}
+
+func TestFailoverPartitioned(t *testing.T) {
+	_, signFn := newTestSigner(t)
+	started := false
+	start := func() error { started = true; return nil }
+	stop := func() error { started = false; return nil }
+
+	rsState, _ := NewState(true, "", start, stop)
+	rs := rsState.(*replicaStateImpl)
+	// the primary is cut off from this replica but keeps signing blocks
+	signed := true
+	rs.EnableFailover(3, signFn, func(blocks uint64) bool {
+		if blocks != 3 {
+			t.Errorf("expected the parent seals of 3 blocks to be checked, got %d", blocks)
+		}
+		return signed
+	})
+
+	rs.NewChainHead(big.NewInt(10))
+	for seq := int64(11); seq <= 20; seq++ {
+		rs.NewChainHead(big.NewInt(seq))
+		if started || rs.IsPrimary() {
+			t.Fatalf("expected to stay replica at seq %d while the primary signs", seq)
+		}
+	}
+	if rs.token != nil {
+		t.Fatalf("expected no fencing token, got %v", rs.token)
+	}
+
+	// the primary stopped signing as well
+	signed = false
+	rs.NewChainHead(big.NewInt(21))
+	if !started || !rs.IsPrimaryForSeq(big.NewInt(21)) {
+		t.Fatal("expected to take over as primary")
+	}
+}
+
+func TestFencing(t *testing.T) {
+	_, signFn := newTestSigner(t)
+	started := true
+	start := func() error { started = true; return nil }
+	stop := func() error { started = false; return nil }
+
+	rsState, _ := NewState(false, "", start, stop)
+	rs := rsState.(*replicaStateImpl)
+	rs.EnableFailover(3, signFn, func(uint64) bool { return false })
+
+	heartbeat, err := rs.Heartbeat(big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if heartbeat.Token.Term != 1 {
+		t.Fatalf("expected the first token to have term 1, got %v", heartbeat.Token)
+	}
+
+	// a stale heartbeat does not fence the primary
+	if err := rs.HandleHeartbeat(&istanbul.ReplicaHeartbeat{Token: heartbeat.Token, Sequence: big.NewInt(6)}); err != nil {
+		t.Fatal(err)
+	}
+	if !started || !rs.IsPrimaryForSeq(big.NewInt(6)) {
+		t.Fatal("expected to stay primary")
+	}
+
+	newer, err := istanbul.NewFencingToken(2, big.NewInt(7), signFn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rs.HandleHeartbeat(&istanbul.ReplicaHeartbeat{Token: newer, Sequence: big.NewInt(7)}); err != nil {
+		t.Fatal(err)
+	}
+	if started || rs.IsPrimaryForSeq(big.NewInt(8)) {
+		t.Fatal("expected to step down after seeing a newer token")
+	}
+	if err := rs.CheckRSDB(); err != nil {
+		t.Error(err)
+	}
+	if summary := rs.Summary(); summary.HighestTerm != 2 || summary.Term != 0 {
+		t.Errorf("unexpected summary %+v", summary)
+	}
+
+	// becoming primary again requires a term above the newest one
+	if err := rs.MakePrimary(); err != nil {
+		t.Fatal(err)
+	}
+	if rs.token == nil || rs.token.Term != 3 || !rs.IsPrimaryForSeq(big.NewInt(9)) {
+		t.Fatalf("expected primary with term 3, got %v", rs.token)
+	}
+}
+
+func TestSimultaneousFailover(t *testing.T) {
+	_, signFn := newTestSigner(t)
+	newReplica := func(started *bool) *replicaStateImpl {
+		start := func() error { *started = true; return nil }
+		stop := func() error { *started = false; return nil }
+		rsState, _ := NewState(true, "", start, stop)
+		rs := rsState.(*replicaStateImpl)
+		rs.EnableFailover(3, signFn, func(uint64) bool { return false })
+		return rs
+	}
+	var startedA, startedB bool
+	a, b := newReplica(&startedA), newReplica(&startedB)
+
+	// both replicas miss the heartbeats of the primary and take over with the same term
+	for seq := int64(10); seq <= 14; seq++ {
+		a.NewChainHead(big.NewInt(seq))
+		b.NewChainHead(big.NewInt(seq))
+	}
+	if !startedA || !startedB {
+		t.Fatal("expected both replicas to take over")
+	}
+	if a.token.Term != b.token.Term {
+		t.Fatalf("expected the same term, got %v and %v", a.token, b.token)
+	}
+
+	heartbeatA, err := a.Heartbeat(big.NewInt(15))
+	if err != nil {
+		t.Fatal(err)
+	}
+	heartbeatB, err := b.Heartbeat(big.NewInt(15))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// each node sees its own heartbeat relayed back as well as the other one
+	for _, rs := range []*replicaStateImpl{a, b} {
+		for _, heartbeat := range []*istanbul.ReplicaHeartbeat{heartbeatA, heartbeatB} {
+			if err := rs.HandleHeartbeat(heartbeat); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	winner, loser, winnerStarted, loserStarted := a, b, startedA, startedB
+	if heartbeatB.Token.Supersedes(heartbeatA.Token) {
+		winner, loser, winnerStarted, loserStarted = b, a, startedB, startedA
+	}
+	if !winnerStarted || !winner.IsPrimaryForSeq(big.NewInt(16)) {
+		t.Error("expected the node with the lower nonce to keep signing")
+	}
+	if loserStarted || loser.IsPrimaryForSeq(big.NewInt(16)) {
+		t.Error("expected the node with the higher nonce to step down")
+	}
+	if !loser.fence.Equal(winner.token) {
+		t.Errorf("expected the loser to be fenced by %v, got %v", winner.token, loser.fence)
+	}
+}
@@ -38,83 +38,60 @@ import (
 	"time"
 )
 
+// epochRewardsOverrides replace the inputs of the epoch reward path, nil fields are read
+// from the chain
+type epochRewardsOverrides struct {
+	uptimes              map[common.Address]*big.Int                    // Uptime of the signers, in fixidity
+	votes                map[common.Address]map[common.Address]*big.Int // Active votes of the voters by validator account
+	voters               []common.Address                               // Voters to split the voter rewards to
+	validatorVoterReward *big.Int
+	communityReward      *big.Int
+	maintainerReward     *big.Int
+}
+
+// epochRewards are the payouts of the rewards of an epoch
+type epochRewards struct {
+	validatorVoterReward *big.Int // Target reward of the validators and their voters
+	communityReward      *big.Int // Target reward of the community partner
+	maintainerReward     *big.Int // Target reward of the relayers or the maintainer
+	rewarded             bool     // Whether the rewards were paid out, they are not before the reward block
+
+	signers          []istanbul.Validator
+	accounts         []common.Address            // Accounts of the signers
+	scores           []*big.Int                  // Updated scores of the signers
+	validatorRewards map[common.Address]*big.Int // Validator rewards by account
+	voterRewards     map[common.Address]*big.Int // Voter rewards by validator account
+
+	communityPartner common.Address
+	relayerRewards   map[common.Address]*big.Int
+	maintainer       common.Address // Set if the maintainer was paid
+	maintainerPaid   *big.Int       // Part of the maintainer reward left to the maintainer by the relayers
+
+	voterShares map[common.Address]map[common.Address]*voterShare // Voter shares by validator account, only simulated
+}
+
+// voterShare is the share of a voter in the voter reward of a validator
+type voterShare struct {
+	votes  *big.Int // Active votes before the rewards
+	reward *big.Int
+}
+
 func (sb *Backend) distributeEpochRewards(header *types.Header, state *state.StateDB,
 	EnableRewardBlock, bn256Block, deregisterBlock *big.Int) error {
 	start := time.Now()
 	defer sb.rewardDistributionTimer.UpdateSince(start)
-	logger := sb.logger.New("func", "Backend.distributeEpochPaymentsAndRewards", "blocknum", header.Number.Uint64())
 
 	vmRunner := sb.chain.NewEVMRunner(header, state)
 
-	communityPartnerAddress, err := epoch_rewards.GetCommunityPartnerAddress(vmRunner)
-	if err != nil {
-		return err
-	}
-
-	validatorVoterReward, communityReward, maintainerReward, err := epoch_rewards.CalculateTargetEpochRewards(vmRunner)
-	if err != nil {
-		return err
-	}
-
-	if communityPartnerAddress == params.ZeroAddress {
-		communityReward = big.NewInt(0)
-	}
-
-	logger.Info("Calculated target rewards", "validatorReward", validatorVoterReward, "communityReward", communityReward, "maintainerReward", maintainerReward)
-
 	// The validator set that signs off on the last block of the epoch is the one that we need to
 	// iterate over.
 	signerSet := sb.GetValidators(big.NewInt(header.Number.Int64()-1), header.ParentHash)
-	if len(signerSet) == 0 {
-		err := errors.New("Unable to fetch validator set to update scores and distribute rewards")
-		logger.Error(err.Error())
-		return err
-	}
-	validators_, err := sb.GetAccountsFromSigners(vmRunner, signerSet)
-	if err != nil {
-		return err
-	}
-	uptimeRets, ignores, err := sb.updateValidatorScores(header, state, signerSet)
+	rewards, err := sb.payEpochRewards(header, state, signerSet, header.Number.Cmp(EnableRewardBlock) > 0, nil)
 	if err != nil {
 		return err
 	}
+	validators_ := rewards.accounts
 
-	if header.Number.Cmp(EnableRewardBlock) > 0 {
-		scores, err := sb.calculatePaymentScoreDenominator(vmRunner, uptimeRets, ignores)
-		if err != nil {
-			return err
-		}
-		// Reward Validators And voters
-		totalValidatorRewards, voterRewardData, err := sb.distributeValidatorRewards(vmRunner, signerSet, validators_, validatorVoterReward, scores)
-		if err != nil {
-			return err
-		}
-		log.Info("totalValidatorRewards", "maxReward", totalValidatorRewards.String())
-		totalVoterRewards, err := sb.distributeVoterRewards(vmRunner, validators_, voterRewardData)
-		if err != nil {
-			return err
-		}
-		log.Info("distributeVoterRewards", "totalVoterRewards", totalVoterRewards.String())
-		if communityReward.Cmp(new(big.Int)) != 0 {
-			if err = gold_token.Mint(vmRunner, communityPartnerAddress, communityReward); err != nil {
-				return err
-			}
-		}
-		// mint to mgrMaintainer
-		if maintainerReward.Cmp(new(big.Int)) != 0 {
-			mmAddress, err := epoch_rewards.GetMgrMaintainerAddress(vmRunner)
-			if err != nil {
-				return err
-			}
-			if mmAddress != params.ZeroAddress {
-				if err = gold_token.Mint(vmRunner, mmAddress, maintainerReward); err != nil {
-					log.Error("reward to maintainer fail", "addr", mmAddress, "maintainerReward", maintainerReward.String())
-					return err
-				}
-				log.Info("reward to maintainer success", "addr", mmAddress, "maintainerReward", maintainerReward.String())
-			}
-		}
-	}
 	//----------------------------- deRegister -------------------
 	if header.Number.Cmp(deregisterBlock) > 0 {
 		deRegisters, err := sb.deRegisterAllValidatorsInPending(vmRunner, true)
@@ -155,7 +132,221 @@ func (sb *Backend) distributeEpochRewards(header *types.Header, state *state.Sta
 	return nil
 }
 
-func (sb *Backend) updateValidatorScores(header *types.Header, state *state.StateDB, valSet []istanbul.Validator) ([]*big.Int, []bool, error) {
+// payEpochRewards updates the scores of the signers of the last block of the epoch, and pays
+// the validators, voters, community partner, relayers and maintainer if rewarded is set.
+// Overrides are only used to simulate the rewards.
+func (sb *Backend) payEpochRewards(header *types.Header, state *state.StateDB, signerSet []istanbul.Validator,
+	rewarded bool, overrides *epochRewardsOverrides) (*epochRewards, error) {
+	logger := sb.logger.New("func", "Backend.distributeEpochPaymentsAndRewards", "blocknum", header.Number.Uint64())
+	// Simulations are run by RPC calls, their payouts are only logged at debug level
+	info := logger.Info
+	if overrides == nil {
+		overrides = new(epochRewardsOverrides)
+	} else {
+		info = logger.Debug
+	}
+
+	vmRunner := sb.chain.NewEVMRunner(header, state)
+
+	communityPartnerAddress, err := epoch_rewards.GetCommunityPartnerAddress(vmRunner)
+	if err != nil {
+		return nil, err
+	}
+
+	validatorVoterReward, communityReward, maintainerReward, err := epoch_rewards.CalculateTargetEpochRewards(vmRunner)
+	if err != nil {
+		return nil, err
+	}
+	if overrides.validatorVoterReward != nil {
+		validatorVoterReward = overrides.validatorVoterReward
+	}
+	if overrides.communityReward != nil {
+		communityReward = overrides.communityReward
+	}
+	if overrides.maintainerReward != nil {
+		maintainerReward = overrides.maintainerReward
+	}
+
+	if communityPartnerAddress == params.ZeroAddress {
+		communityReward = big.NewInt(0)
+	}
+
+	info("Calculated target rewards", "validatorReward", validatorVoterReward, "communityReward", communityReward, "maintainerReward", maintainerReward)
+
+	if len(signerSet) == 0 {
+		err := errors.New("Unable to fetch validator set to update scores and distribute rewards")
+		logger.Error(err.Error())
+		return nil, err
+	}
+	validators_, err := sb.GetAccountsFromSigners(vmRunner, signerSet)
+	if err != nil {
+		return nil, err
+	}
+	uptimeRets, ignores, err := sb.updateValidatorScores(header, state, signerSet, overrides.uptimes)
+	if err != nil {
+		return nil, err
+	}
+
+	rewards := &epochRewards{
+		validatorVoterReward: validatorVoterReward,
+		communityReward:      communityReward,
+		maintainerReward:     maintainerReward,
+		rewarded:             rewarded,
+		signers:              signerSet,
+		accounts:             validators_,
+		scores:               uptimeRets,
+		communityPartner:     communityPartnerAddress,
+	}
+	if !rewarded {
+		return rewards, nil
+	}
+
+	scores, err := sb.calculatePaymentScoreDenominator(vmRunner, uptimeRets, ignores)
+	if err != nil {
+		return nil, err
+	}
+	// Reward Validators And voters
+	validatorRewards, voterRewardData, err := sb.distributeValidatorRewards(vmRunner, signerSet, validators_, validatorVoterReward, scores)
+	if err != nil {
+		return nil, err
+	}
+	rewards.validatorRewards, rewards.voterRewards = validatorRewards, voterRewardData
+	totalValidatorRewards := big.NewInt(0)
+	for _, reward := range validatorRewards {
+		totalValidatorRewards.Add(totalValidatorRewards, reward)
+	}
+	info("totalValidatorRewards", "maxReward", totalValidatorRewards.String())
+	totalVoterRewards, err := sb.distributeVoterRewards(vmRunner, validators_, voterRewardData)
+	if err != nil {
+		return nil, err
+	}
+	info("distributeVoterRewards", "totalVoterRewards", totalVoterRewards.String())
+	if communityReward.Cmp(new(big.Int)) != 0 {
+		if err = gold_token.Mint(vmRunner, communityPartnerAddress, communityReward); err != nil {
+			return nil, err
+		}
+	}
+	// since the relayer set fork the relayers that synced headers during the epoch share the
+	// governance set part of the mgrMaintainer reward, the mgrMaintainer is paid the rest
+	rewards.maintainerPaid = new(big.Int).Set(maintainerReward)
+	if sb.chain.Config().IsRelayerSet(header.Number) {
+		epoch := istanbul.GetEpochNumber(header.Number.Uint64(), sb.EpochSize())
+		submissions, err := vm.EpochSubmissions(state, epoch)
+		if err != nil {
+			return nil, err
+		}
+		if len(submissions) != 0 {
+			relayerReward := new(big.Int).Mul(maintainerReward, new(big.Int).SetUint64(vm.RelayerRewardShare(state)))
+			relayerReward.Div(relayerReward, big.NewInt(100))
+			rewards.relayerRewards = epoch_rewards.SplitRelayerReward(relayerReward, submissions)
+			// mint in submission order, the logs must be the same on every node
+			for _, r := range submissions {
+				addr, reward := r.Address, rewards.relayerRewards[r.Address]
+				if reward == nil || reward.Sign() == 0 {
+					continue
+				}
+				if err = gold_token.Mint(vmRunner, addr, reward); err != nil {
+					log.Error("reward to relayer fail", "addr", addr, "relayerReward", reward.String())
+					return nil, err
+				}
+				info("reward to relayer success", "addr", addr, "relayerReward", reward.String())
+				rewards.maintainerPaid.Sub(rewards.maintainerPaid, reward)
+			}
+		}
+	}
+	if rewards.maintainerPaid.Cmp(new(big.Int)) != 0 {
+		mmAddress, err := epoch_rewards.GetMgrMaintainerAddress(vmRunner)
+		if err != nil {
+			return nil, err
+		}
+		if mmAddress != params.ZeroAddress {
+			if err = gold_token.Mint(vmRunner, mmAddress, rewards.maintainerPaid); err != nil {
+				log.Error("reward to maintainer fail", "addr", mmAddress, "maintainerReward", rewards.maintainerPaid.String())
+				return nil, err
+			}
+			info("reward to maintainer success", "addr", mmAddress, "maintainerReward", rewards.maintainerPaid.String())
+			rewards.maintainer = mmAddress
+		}
+	}
+	return rewards, nil
+}
+
+// simulateEpochRewards runs the epoch reward path on the state, which must not be committed,
+// and splits the voter reward of each validator between its voters.  Without overridden votes
+// the voters are the overridden ones and the pending voters of the validator, and their shares
+// are read from the growth of their active votes.  With overridden votes the voter reward is
+// split pro rata over them.
+func (sb *Backend) simulateEpochRewards(header *types.Header, state *state.StateDB, signerSet []istanbul.Validator,
+	rewarded bool, overrides *epochRewardsOverrides) (*epochRewards, error) {
+	if overrides == nil {
+		overrides = new(epochRewardsOverrides)
+	}
+	vmRunner := sb.chain.NewEVMRunner(header, state)
+	accounts, err := sb.GetAccountsFromSigners(vmRunner, signerSet)
+	if err != nil {
+		return nil, err
+	}
+
+	shares := make(map[common.Address]map[common.Address]*voterShare, len(accounts))
+	for _, account := range accounts {
+		if _, ok := overrides.votes[account]; ok {
+			continue
+		}
+		pending, err := election.GetPendingVotersForValidator(vmRunner, account)
+		if err != nil {
+			return nil, err
+		}
+		shares[account] = make(map[common.Address]*voterShare)
+		for _, voter := range append(append([]common.Address{}, overrides.voters...), pending...) {
+			if _, ok := shares[account][voter]; ok {
+				continue
+			}
+			active, err := election.GetActiveVotesForValidatorByAccount(vmRunner, account, voter)
+			if err != nil {
+				return nil, err
+			}
+			shares[account][voter] = &voterShare{votes: active}
+		}
+	}
+
+	rewards, err := sb.payEpochRewards(header, state, signerSet, rewarded, overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, account := range accounts {
+		voterReward := rewards.voterRewards[account]
+		if voterReward == nil {
+			voterReward = new(big.Int)
+		}
+		if votes, ok := overrides.votes[account]; ok {
+			total := new(big.Int)
+			for _, v := range votes {
+				total.Add(total, v)
+			}
+			shares[account] = make(map[common.Address]*voterShare, len(votes))
+			for voter, v := range votes {
+				reward := new(big.Int)
+				if total.Sign() > 0 {
+					reward.Div(reward.Mul(voterReward, v), total)
+				}
+				shares[account][voter] = &voterShare{votes: v, reward: reward}
+			}
+			continue
+		}
+		for voter, share := range shares[account] {
+			active, err := election.GetActiveVotesForValidatorByAccount(vmRunner, account, voter)
+			if err != nil {
+				return nil, err
+			}
+			share.reward = new(big.Int).Sub(active, share.votes)
+		}
+	}
+	rewards.voterShares = shares
+	return rewards, nil
+}
+
+func (sb *Backend) updateValidatorScores(header *types.Header, state *state.StateDB, valSet []istanbul.Validator, uptimeOverrides map[common.Address]*big.Int) ([]*big.Int, []bool, error) {
 	epoch := istanbul.GetEpochNumber(header.Number.Uint64(), sb.EpochSize())
 	logger := sb.logger.New("func", "Backend.updateValidatorScores", "blocknum", header.Number.Uint64(), "epoch", epoch, "epochsize", sb.EpochSize())
 	ignore := make([]bool, len(valSet), len(valSet))
@@ -177,6 +368,9 @@ func (sb *Backend) updateValidatorScores(header *types.Header, state *state.Stat
 	vmRunner := sb.chain.NewEVMRunner(header, state)
 
 	for i, val := range valSet {
+		if uptime, ok := uptimeOverrides[val.Address()]; ok {
+			uptimes[i] = uptime
+		}
 		logger.Trace("Updating validator score", "uptime", uptimes[i], "address", val.Address())
 		uptimeRet, isValidator, err := validators.UpdateValidatorScore(vmRunner, val.Address(), uptimes[i])
 		if !isValidator {
@@ -195,8 +389,8 @@ func (sb *Backend) updateValidatorScores(header *types.Header, state *state.Stat
 /*
 @param maxReward is epochReward for all validators
 */
-func (sb *Backend) distributeValidatorRewards(vmRunner vm.EVMRunner, signerSet []istanbul.Validator, valSets []common.Address, maxReward *big.Int, scoreDenominator *big.Int) (*big.Int, map[common.Address]*big.Int, error) {
-	totalValidatorRewards := big.NewInt(0)
+func (sb *Backend) distributeValidatorRewards(vmRunner vm.EVMRunner, signerSet []istanbul.Validator, valSets []common.Address, maxReward *big.Int, scoreDenominator *big.Int) (map[common.Address]*big.Int, map[common.Address]*big.Int, error) {
+	validatorRewards := make(map[common.Address]*big.Int, len(signerSet))
 	voterRewards := make(map[common.Address]*big.Int, len(signerSet))
 	for i, val := range signerSet {
 		sb.logger.Debug("Distributing epoch reward for validator", "address", val.Address())
@@ -206,9 +400,9 @@ func (sb *Backend) distributeValidatorRewards(vmRunner vm.EVMRunner, signerSet [
 			continue
 		}
 		voterRewards[valSets[i]] = voterReward
-		totalValidatorRewards.Add(totalValidatorRewards, validatorReward)
+		validatorRewards[valSets[i]] = validatorReward
 	}
-	return totalValidatorRewards, voterRewards, nil
+	return validatorRewards, voterRewards, nil
 }
 
 func (sb *Backend) setInitialGoldTokenTotalSupplyIfUnset(vmRunner vm.EVMRunner) error {
@@ -312,6 +506,20 @@ func (sb *Backend) GetAccountsFromSignersAddress(vmRunner vm.EVMRunner, signers
 	return accountVals, nil
 }
 
+// getValidatorVotes returns the votes of the given validator signers, the votes are
+// kept by the election contract under the validator accounts.
+func getValidatorVotes(vmRunner vm.EVMRunner, signers []common.Address) ([]*big.Int, error) {
+	validatorAccounts := make([]common.Address, len(signers))
+	for i, signer := range signers {
+		account, err := accounts.GetSignerToAccountMethod(vmRunner, signer)
+		if err != nil {
+			return nil, err
+		}
+		validatorAccounts[i] = account
+	}
+	return election.GetElectedValidatorVotes(vmRunner, validatorAccounts)
+}
+
 func (sb *Backend) GetValidatorAccounts(vmRunner vm.EVMRunner) ([]common.Address, error) {
 	signers, err := election.GetElectedValidators(vmRunner)
 	if err != nil {
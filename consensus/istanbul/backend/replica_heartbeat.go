@@ -0,0 +1,101 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/mapprotocol/atlas/consensus"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+)
+
+var (
+	// errUnauthorizedReplicaHeartbeat is returned when a replica heartbeat or its fencing
+	// token is not signed by this node's validator
+	errUnauthorizedReplicaHeartbeat = errors.New("replica heartbeat not signed by the validator")
+)
+
+// sendReplicaHeartbeat sends the heartbeat of this primary for the given sequence to its
+// replicas through the proxies. It is a no-op unless failover is enabled.
+func (sb *Backend) sendReplicaHeartbeat(seq *big.Int) {
+	logger := sb.logger.New("func", "sendReplicaHeartbeat", "seq", seq)
+
+	heartbeat, err := sb.replicaState.Heartbeat(seq)
+	if err != nil {
+		logger.Warn("Error creating replica heartbeat", "err", err)
+		return
+	}
+	if heartbeat == nil {
+		return
+	}
+	if !sb.IsProxiedValidator() {
+		logger.Trace("Not sending replica heartbeat without proxies")
+		return
+	}
+
+	msg := istanbul.NewReplicaHeartbeatMessage(heartbeat, sb.ValidatorAddress())
+	if err := msg.Sign(sb.Sign); err != nil {
+		logger.Error("Error in signing a replica heartbeat", "err", err)
+		return
+	}
+	payload, err := msg.Payload()
+	if err != nil {
+		logger.Error("Error getting payload of replica heartbeat", "err", err)
+		return
+	}
+	if err := sb.proxiedValidatorEngine.SendReplicaHeartbeatToAllProxies(payload); err != nil {
+		logger.Warn("Error sending replica heartbeat", "err", err)
+	}
+}
+
+// handleReplicaHeartbeatMsg handles a heartbeat of the primary relayed by one of the proxies.
+func (sb *Backend) handleReplicaHeartbeatMsg(peer consensus.Peer, payload []byte) error {
+	logger := sb.logger.New("func", "handleReplicaHeartbeatMsg")
+
+	if sb.replicaState == nil || !sb.IsProxiedValidator() {
+		return nil
+	}
+	if isProxy, err := sb.proxiedValidatorEngine.IsProxyPeer(peer.Node().ID()); err != nil || !isProxy {
+		logger.Warn("Got a replica heartbeat from a peer that is not a proxy", "from", peer.Node().ID(), "err", err)
+		return nil
+	}
+
+	msg := new(istanbul.Message)
+	if err := msg.FromPayload(payload, istanbul.GetSignatureAddress); err != nil {
+		logger.Error("Failed to decode replica heartbeat", "err", err)
+		return err
+	}
+	if msg.Address != sb.ValidatorAddress() {
+		logger.Warn("Replica heartbeat from another validator", "sender", msg.Address)
+		return errUnauthorizedReplicaHeartbeat
+	}
+	heartbeat := msg.ReplicaHeartbeat()
+	if heartbeat == nil || heartbeat.Token == nil {
+		return errUnauthorizedReplicaHeartbeat
+	}
+	if signer, err := heartbeat.Token.Signer(); err != nil || signer != sb.ValidatorAddress() {
+		logger.Warn("Fencing token not signed by the validator", "signer", signer, "err", err)
+		return errUnauthorizedReplicaHeartbeat
+	}
+
+	if err := sb.replicaState.HandleHeartbeat(heartbeat); err != nil {
+		logger.Warn("Error handling replica heartbeat", "heartbeat", heartbeat, "err", err)
+		return err
+	}
+	return nil
+}
@@ -18,6 +18,7 @@ package backend
 
 import (
 	"encoding/json"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -150,6 +151,10 @@ func (s *Snapshot) apply(headers []*types.Header, db ethdb.Database) (*Snapshot,
 			log.Error("Error in adding the header's AddedValidators")
 			return nil, errInvalidValidatorSetDiff
 		}
+		if !snap.ValSet.SetWeights(istExtra.ValidatorWeights) {
+			log.Error("Error in setting the header's ValidatorWeights")
+			return nil, errInvalidValidatorSetDiff
+		}
 
 		snap.Epoch = s.Epoch
 		snap.Number += s.Epoch
@@ -172,15 +177,21 @@ type snapshotJSON struct {
 
 	// for validator set
 	Validators []istanbul.ValidatorDataWithBLSKeyCache `json:"validators"`
+	Weights    []*big.Int                              `json:"weights,omitempty"`
 }
 
 func (s *Snapshot) toJSONStruct() *snapshotJSON {
 	validators := validator.MapValidatorsToDataWithBLSKeyCache(s.ValSet.List())
+	var weights []*big.Int
+	if s.ValSet.Weighted() {
+		weights = s.ValSet.Weights()
+	}
 	return &snapshotJSON{
 		Epoch:      s.Epoch,
 		Number:     s.Number,
 		Hash:       s.Hash,
 		Validators: validators,
+		Weights:    weights,
 	}
 }
 
@@ -195,6 +206,9 @@ func (s *Snapshot) UnmarshalJSON(b []byte) error {
 	s.Number = j.Number
 	s.Hash = j.Hash
 	s.ValSet = validator.NewSetFromDataWithBLSKeyCache(j.Validators)
+	if !s.ValSet.SetWeights(j.Weights) {
+		return errInvalidValidatorSetDiff
+	}
 	return nil
 }
 
@@ -235,7 +235,7 @@ func TestValSetChange(t *testing.T) {
 		genesis.ExtraData = append(make([]byte, types.IstanbulExtraVanity), extra...)
 		b := genesis.ToBlock(nil)
 		h := b.Header()
-		err := writeValidatorSetDiff(h, []istanbul.ValidatorData{}, validators)
+		err := writeValidatorSetDiff(h, []istanbul.ValidatorData{}, validators, nil)
 		if err != nil {
 			t.Errorf("Could not update genesis validator set, got err: %v", err)
 		}
@@ -0,0 +1,124 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/mapprotocol/atlas/consensus"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/contracts"
+	"github.com/mapprotocol/atlas/contracts/random"
+	"github.com/mapprotocol/atlas/core/types"
+	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+	"github.com/mapprotocol/atlas/params"
+)
+
+// BLS signatures are unique for a key and message, which makes the proposer's signature over
+// an input fixed by the parent header a verifiable random function: nobody else can compute it
+// ahead of time, and the proposer cannot grind it.
+
+// vrfOutput returns the verifiable random output committed to in the header. Headers without
+// a proof, like the genesis block, fall back to their hash.
+func vrfOutput(header *types.Header) common.Hash {
+	if extra, err := types.ExtractIstanbulExtra(header); err == nil && len(extra.VRFProof) > 0 {
+		return crypto.Keccak256Hash(extra.VRFProof)
+	}
+	return header.Hash()
+}
+
+// vrfInput returns the message the proposer of the child of parent signs as its VRF proof.
+func vrfInput(parent *types.Header) []byte {
+	number := make([]byte, 8)
+	binary.BigEndian.PutUint64(number, parent.Number.Uint64()+1)
+	return crypto.Keccak256(vrfOutput(parent).Bytes(), number)
+}
+
+// writeVRFProof signs the VRF input of the header with the local BLS key and writes the proof
+// to the header's extra-data.
+func (sb *Backend) writeVRFProof(chain consensus.ChainHeaderReader, parent, header *types.Header) error {
+	fork, cur := new(big.Int).Set(chain.Config().BN256ForkBlock), new(big.Int).Set(header.Number)
+	proof, err := sb.SignBLS(vrfInput(parent), []byte{}, false, false, fork, cur)
+	if err != nil {
+		return err
+	}
+
+	istanbulExtra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return err
+	}
+
+	istanbulExtra.VRFProof = proof[:]
+	payload, err := rlp.EncodeToBytes(&istanbulExtra)
+	if err != nil {
+		return err
+	}
+
+	header.Extra = append(header.Extra[:types.IstanbulExtraVanity], payload...)
+	return nil
+}
+
+// verifyVRFProof checks that the VRF proof in the header was made by its signer over the
+// input derived from the parent header.
+func verifyVRFProof(config *params.ChainConfig, header, parent *types.Header, signer istanbul.Validator) error {
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return errInvalidExtraDataFormat
+	}
+	if len(extra.VRFProof) == 0 {
+		return errMissingVRFProof
+	}
+	if len(extra.VRFProof) != types.IstanbulExtraBlsSignature {
+		return errInvalidVRFProof
+	}
+
+	fork, cur := new(big.Int).Set(config.BN256ForkBlock), new(big.Int).Set(header.Number)
+	if err := blscrypto.CryptoType().VerifySignature(signer.BLSPublicKey(), vrfInput(parent), []byte{}, extra.VRFProof, false, false, fork, cur); err != nil {
+		return errInvalidVRFProof
+	}
+	return nil
+}
+
+// vrfRandomness returns the seed used to order the proposers of the child of the given block.
+// It mixes the VRF output of the block with the randomness the random beacon contract recorded
+// for it. The randomness is read from the state of the block itself, so that every node orders
+// the proposers of a height the same way however far it has synced.
+func (sb *Backend) vrfRandomness(number uint64, hash common.Hash) (common.Hash, error) {
+	header := sb.chain.GetHeader(hash, number)
+	if header == nil {
+		return common.Hash{}, errUnknownBlock
+	}
+	seed := vrfOutput(header)
+
+	state, err := sb.stateAt(hash)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	randomness, err := random.BlockRandomness(sb.chain.NewEVMRunner(header, state), number)
+	if err == contracts.ErrRegistryContractNotDeployed || err == contracts.ErrSmartContractNotDeployed {
+		// the state of the block decides this, so every node falls back alike
+		return seed, nil
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return crypto.Keccak256Hash(seed.Bytes(), randomness.Bytes()), nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/consensus/istanbul/validator"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/types"
+	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+	"github.com/mapprotocol/atlas/params"
+)
+
+func newVRFValidator(t *testing.T) (istanbul.Validator, *blscrypto.SecretKey) {
+	key, _ := crypto.GenerateKey()
+	blsPrivateKey, err := blscrypto.CryptoType().ECDSAToBLS(key)
+	if err != nil {
+		t.Fatalf("ECDSAToBLS: %v", err)
+	}
+	blsPublicKey, err := blscrypto.CryptoType().PrivateToPublic(blsPrivateKey)
+	if err != nil {
+		t.Fatalf("PrivateToPublic: %v", err)
+	}
+	secretKey, err := blscrypto.DeserializePrivateKey(blsPrivateKey)
+	if err != nil {
+		t.Fatalf("DeserializePrivateKey: %v", err)
+	}
+	return validator.New(crypto.PubkeyToAddress(key.PublicKey), blsPublicKey), secretKey
+}
+
+func newVRFHeader(t *testing.T, number int64, parent *types.Header, key *blscrypto.SecretKey) *types.Header {
+	header := &types.Header{Number: big.NewInt(number)}
+	if err := writeEmptyIstanbulExtra(header); err != nil {
+		t.Fatalf("writeEmptyIstanbulExtra: %v", err)
+	}
+	if parent == nil || key == nil {
+		return header
+	}
+	header.ParentHash = parent.Hash()
+
+	sig, err := blscrypto.UnsafeSign2(key, vrfInput(parent))
+	if err != nil {
+		t.Fatalf("UnsafeSign2: %v", err)
+	}
+	extra, _ := types.ExtractIstanbulExtra(header)
+	extra.VRFProof = sig.Marshal()
+	payload, err := rlp.EncodeToBytes(&extra)
+	if err != nil {
+		t.Fatalf("EncodeToBytes: %v", err)
+	}
+	header.Extra = append(header.Extra[:types.IstanbulExtraVanity], payload...)
+	return header
+}
+
+func TestVerifyVRFProof(t *testing.T) {
+	config := &params.ChainConfig{BN256ForkBlock: big.NewInt(0)}
+	proposer, proposerKey := newVRFValidator(t)
+	other, otherKey := newVRFValidator(t)
+
+	genesis := newVRFHeader(t, 0, nil, nil)
+	header := newVRFHeader(t, 1, genesis, proposerKey)
+	if err := verifyVRFProof(config, header, genesis, proposer); err != nil {
+		t.Fatalf("verifyVRFProof: %v", err)
+	}
+
+	// The proof must survive sealing the header
+	if err := writeSeal(header, make([]byte, types.IstanbulExtraSeal)); err != nil {
+		t.Fatalf("writeSeal: %v", err)
+	}
+	if err := verifyVRFProof(config, header, genesis, proposer); err != nil {
+		t.Errorf("verifyVRFProof after seal: %v", err)
+	}
+
+	if err := verifyVRFProof(config, header, genesis, other); err != errInvalidVRFProof {
+		t.Errorf("error mismatch for wrong signer: have %v, want %v", err, errInvalidVRFProof)
+	}
+	forged := newVRFHeader(t, 1, genesis, otherKey)
+	if err := verifyVRFProof(config, forged, genesis, proposer); err != errInvalidVRFProof {
+		t.Errorf("error mismatch for forged proof: have %v, want %v", err, errInvalidVRFProof)
+	}
+	child := newVRFHeader(t, 2, header, proposerKey)
+	if err := verifyVRFProof(config, child, genesis, proposer); err != errInvalidVRFProof {
+		t.Errorf("error mismatch for wrong parent: have %v, want %v", err, errInvalidVRFProof)
+	}
+	if err := verifyVRFProof(config, child, header, proposer); err != nil {
+		t.Errorf("verifyVRFProof for child: %v", err)
+	}
+	if err := verifyVRFProof(config, newVRFHeader(t, 1, nil, nil), genesis, proposer); err != errMissingVRFProof {
+		t.Errorf("error mismatch for missing proof: have %v, want %v", err, errMissingVRFProof)
+	}
+}
+
+func TestVRFOutput(t *testing.T) {
+	_, key := newVRFValidator(t)
+	genesis := newVRFHeader(t, 0, nil, nil)
+	if have, want := vrfOutput(genesis), genesis.Hash(); have != want {
+		t.Errorf("output mismatch for header without proof: have %v, want %v", have, want)
+	}
+
+	header := newVRFHeader(t, 1, genesis, key)
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		t.Fatalf("ExtractIstanbulExtra: %v", err)
+	}
+	if have, want := vrfOutput(header), crypto.Keccak256Hash(extra.VRFProof); have != want {
+		t.Errorf("output mismatch: have %v, want %v", have, want)
+	}
+	// The output only depends on the key and the parent
+	if have, want := vrfOutput(newVRFHeader(t, 1, genesis, key)), vrfOutput(header); have != want {
+		t.Errorf("output is not unique: have %v, want %v", have, want)
+	}
+}
+
+// A header with a VRF proof but without weights must stay unweighted, both when the
+// extra is decoded and when the epoch block is applied to a snapshot.
+func TestVRFHeaderWithoutWeights(t *testing.T) {
+	proposerKey, _ := crypto.GenerateKey()
+	proposer := crypto.PubkeyToAddress(proposerKey.PublicKey)
+	_, blsKey := newVRFValidator(t)
+
+	genesis := newVRFHeader(t, 0, nil, nil)
+	header := newVRFHeader(t, 1, genesis, blsKey)
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		t.Fatalf("ExtractIstanbulExtra: %v", err)
+	}
+	if len(extra.VRFProof) == 0 {
+		t.Fatalf("missing VRF proof")
+	}
+	if extra.ValidatorWeights != nil {
+		t.Errorf("weights mismatch: have %v, want nil", extra.ValidatorWeights)
+	}
+
+	hashData := crypto.Keccak256(sigHash(header).Bytes())
+	seal, _ := crypto.Sign(hashData, proposerKey)
+	if err := writeSeal(header, seal); err != nil {
+		t.Fatalf("writeSeal: %v", err)
+	}
+	snap := &Snapshot{
+		Epoch:  1,
+		Number: 0,
+		Hash:   genesis.Hash(),
+		ValSet: validator.NewSet([]istanbul.ValidatorData{{Address: proposer}}),
+	}
+	db := rawdb.NewMemoryDatabase()
+	defer db.Close()
+	next, err := snap.apply([]*types.Header{header}, db)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if next.ValSet.Weighted() {
+		t.Errorf("validator set is weighted: %v", next.ValSet.Weights())
+	}
+}
@@ -28,6 +28,11 @@ import (
 const (
 	//MinEpochSize represents the minimum permissible epoch size
 	MinEpochSize = 3
+
+	// RoundStateDBName is the directory of the round states DB within the node's instance directory
+	RoundStateDBName = "roundstates"
+	// EvidenceDBName is the directory of the equivocation evidences DB within the node's instance directory
+	EvidenceDBName = "evidences"
 )
 
 // ProposerPolicy represents the policy used to order elected validators within an epoch
@@ -37,6 +42,9 @@ const (
 	RoundRobin ProposerPolicy = iota
 	Sticky
 	ShuffledRoundRobin
+	// VRF selects the proposers of a block from the verifiable random output the proposer
+	// of the previous block committed to in its header, so they are not known in advance.
+	VRF
 )
 
 // Config represents the istanbul consensus engine
@@ -53,16 +61,23 @@ type Config struct {
 	ValidatorEnodeDBPath        string         `toml:",omitempty"` // The location for the validator enodes DB
 	VersionCertificateDBPath    string         `toml:",omitempty"` // The location for the signed announce version DB
 	RoundStateDBPath            string         `toml:",omitempty"` // The location for the round states DB
+	EvidenceDBPath              string         `toml:",omitempty"` // The location for the equivocation evidences DB
+	SubmitEvidence              bool           `toml:",omitempty"` // Specifies if equivocation evidences should be submitted for slashing by this validator
 	Validator                   bool           `toml:",omitempty"` // Specified if this node is configured to validate  (specifically if --mine command line is set)
 	Replica                     bool           `toml:",omitempty"` // Specified if this node is configured to be a replica
+	ReplicaFailoverBlocks       uint64         `toml:",omitempty"` // Blocks without heartbeat from the primary after which a replica takes over, 0 disables failover. Requires primary and replicas to share proxies
 
 	// Proxy Configs
 	Proxy                   bool           `toml:",omitempty"` // Specifies if this node is a proxy
 	ProxiedValidatorAddress common.Address `toml:",omitempty"` // The address of the proxied validator
 
 	// Proxied Validator Configs
-	Proxied      bool           `toml:",omitempty"` // Specifies if this node is proxied
-	ProxyConfigs []*ProxyConfig `toml:",omitempty"` // The set of proxy configs for this proxied validator at startup
+	Proxied              bool           `toml:",omitempty"` // Specifies if this node is proxied
+	ProxyConfigs         []*ProxyConfig `toml:",omitempty"` // The set of proxy configs for this proxied validator at startup
+	ProxyDiscoveryFile   string         `toml:",omitempty"` // If non-empty, a file listing "<internal enode url>;<external enode url>" proxy pairs, one per line, polled for changes
+	ProxyDiscoverySRV    string         `toml:",omitempty"` // If non-empty, a DNS SRV name resolving to the proxies' internal endpoints, polled for changes
+	ProxyDiscoveryPeriod uint64         `toml:",omitempty"` // Time duration (in seconds) between proxy discovery lookups
+	ProxyMaxLatency      uint64         `toml:",omitempty"` // Round trip time (in milliseconds) above which a proxy is unhealthy and its remote validators are moved to other proxies
 
 	// Announce Configs
 	AnnounceQueryEnodeGossipPeriod                 uint64 `toml:",omitempty"` // Time duration (in seconds) between gossiped query enode messages
@@ -70,7 +85,8 @@ type Config struct {
 	AnnounceAdditionalValidatorsToGossip           int64  `toml:",omitempty"` // Specifies the number of additional non-elected validators to gossip an announce
 
 	// Load test config
-	LoadTestCSVFile string `toml:",omitempty"` // If non-empty, specifies the file to write out csv metrics about the block production cycle to.
+	LoadTestCSVFile    string `toml:",omitempty"` // If non-empty, specifies the file to write out csv metrics about the block production cycle to.
+	ConsensusTraceFile string `toml:",omitempty"` // If non-empty, specifies the file to append a JSON line per consensus event (phases, messages, round changes) to.
 }
 
 // ProxyConfig represents the configuration for validator's proxies
@@ -93,14 +109,20 @@ var DefaultConfig = &Config{
 	ValidatorEnodeDBPath:           "",
 	VersionCertificateDBPath:       "",
 	RoundStateDBPath:               "",
+	EvidenceDBPath:                 "",
+	SubmitEvidence:                 false,
 	Validator:                      true, // as miner~~
 	Replica:                        false,
+	ReplicaFailoverBlocks:          0,
 	Proxy:                          false,
 	Proxied:                        false,
+	ProxyDiscoveryPeriod:           60,
+	ProxyMaxLatency:                500,
 	AnnounceQueryEnodeGossipPeriod: 300, // 5 minutes
 	AnnounceAggressiveQueryEnodeGossipOnEnablement: true,
 	AnnounceAdditionalValidatorsToGossip:           10,
 	LoadTestCSVFile:                                "", // disable by default
+	ConsensusTraceFile:                             "", // disable by default
 }
 
 //ApplyParamsChainConfigToConfig applies the istanbul config values from params.chainConfig to the istanbul.Config config
@@ -26,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/prque"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/metrics"
 )
 
 var (
@@ -104,6 +105,9 @@ type MsgBacklog interface {
 	// as a side effect it will call the eventListener for all backlog
 	// messages that belong to the current "state"
 	updateState(view *istanbul.View, state State)
+
+	// size returns the number of messages in the backlog
+	size() int
 }
 
 type msgBacklogImpl struct {
@@ -118,6 +122,12 @@ type msgBacklogImpl struct {
 	msgProcessor func(*istanbul.Message)
 	checkMessage func(msgCode uint64, msgView *istanbul.View) error
 	logger       log.Logger
+
+	// Number of messages and of sequences in the backlog
+	msgCountGauge metrics.Gauge
+	seqCountGauge metrics.Gauge
+	// Rate of messages dropped from the backlog, because they are too far in the future or over the limits
+	droppedMeter metrics.Meter
 }
 
 func newMsgBacklog(msgProcessor func(*istanbul.Message), checkMessage func(msgCode uint64, msgView *istanbul.View) error) MsgBacklog {
@@ -138,6 +148,10 @@ func newMsgBacklog(msgProcessor func(*istanbul.Message), checkMessage func(msgCo
 		checkMessage: checkMessage,
 		backlogsMu:   new(sync.Mutex),
 		logger:       log.New("type", "MsgBacklog"),
+
+		msgCountGauge: metrics.NewRegisteredGauge("consensus/istanbul/core/backlog/messages", nil),
+		seqCountGauge: metrics.NewRegisteredGauge("consensus/istanbul/core/backlog/sequences", nil),
+		droppedMeter:  metrics.NewRegisteredMeter("consensus/istanbul/core/backlog/dropped", nil),
 	}
 }
 
@@ -152,17 +166,20 @@ func (c *msgBacklogImpl) store(msg *istanbul.Message) {
 	// Never accept messages too far into the future
 	if view.Sequence.Cmp(new(big.Int).Add(c.currentView.Sequence, acceptMaxFutureSequence)) > 0 {
 		logger.Debug("Dropping message", "reason", "too far in the future", "m", msg)
+		c.droppedMeter.Mark(1)
 		return
 	}
 
 	if view.Round.Cmp(maxRoundForPriorityQueue) >= 0 {
 		logger.Debug("Dropping message", "reason", "round exceeds PQ bounds check", "m", msg)
+		c.droppedMeter.Mark(1)
 		return
 	}
 
 	// Check and inc per-validator future message limit
 	if c.msgCountBySrc[msg.Address] > acceptMaxFutureMsgsFromOneValidator {
 		logger.Debug("Dropping message", "reason", "exceeds per-address cap")
+		c.droppedMeter.Mark(1)
 		return
 	}
 
@@ -181,6 +198,7 @@ func (c *msgBacklogImpl) store(msg *istanbul.Message) {
 
 	// After insert, remove messages if we have more than "acceptMaxFutureMessages"
 	c.removeMessagesOverflow()
+	c.updateSizeMetrics()
 }
 
 // removeMessagesOverflow will remove messages if necessary to maintain the number of messages <= acceptMaxFutureMessages
@@ -189,6 +207,7 @@ func (c *msgBacklogImpl) removeMessagesOverflow() {
 	// Keep backlog below total max size by pruning future-most sequence first
 	// (we always leave one sequence's entire messages and rely on per-validator limits)
 	if c.msgCount > acceptMaxFutureMessages {
+		defer func(msgCount int) { c.droppedMeter.Mark(int64(msgCount - c.msgCount)) }(c.msgCount)
 		backlogSeqs := c.getSortedBacklogSeqs()
 		for i := len(backlogSeqs) - 1; i > 0; i-- {
 			seq := backlogSeqs[i]
@@ -307,6 +326,20 @@ func (c *msgBacklogImpl) processBacklog() {
 	if processedMsgsConsidered > 0 {
 		logger.Info("Processing istanbul backlog", "considered", processedMsgsConsidered, "future", processedMsgsFuture, "enqueued", processedMsgsEnqueued)
 	}
+	c.updateSizeMetrics()
+}
+
+// updateSizeMetrics reports the size of the backlog.
+// Call with backlogsMu held.
+func (c *msgBacklogImpl) updateSizeMetrics() {
+	c.msgCountGauge.Update(int64(c.msgCount))
+	c.seqCountGauge.Update(int64(len(c.backlogBySeq)))
+}
+
+func (c *msgBacklogImpl) size() int {
+	c.backlogsMu.Lock()
+	defer c.backlogsMu.Unlock()
+	return c.msgCount
 }
 
 // A safe maximum for round that prevents overflow
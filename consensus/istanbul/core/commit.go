@@ -62,26 +62,30 @@ func (c *core) generateEpochValidatorSetData(blockNumber uint64, round uint8, bl
 		blsPubKeys = append(blsPubKeys, v.BLSPublicKey())
 	}
 
-	maxNonSigners := uint32(newValSet.Size() - newValSet.MinQuorumSize())
-
-	// Before the Donut fork, use the snark data encoding with epoch entropy.
-
 	// Retrieve the block hash for the last block of the previous epoch.
 	parentEpochBlockHash := c.backend.HashForBlock(blockNumber - c.config.Epoch)
 	if blockNumber > 0 && parentEpochBlockHash == (common.Hash{}) {
 		return nil, nil, false, errors.New("unknown block")
 	}
 
-	maxNonSigners = maxValidators - uint32(newValSet.MinQuorumSize())
-	message, extraData, err := blscrypto.CryptoType().EncodeEpochSnarkDataCIP22(
-		blsPubKeys, maxNonSigners, maxValidators,
-		uint16(istanbul.GetEpochNumber(blockNumber, c.config.Epoch)),
+	message, extraData, err := EpochValidatorSetData(blockNumber, c.config.Epoch, round, blockHash, parentEpochBlockHash,
+		blsPubKeys, newValSet.MinQuorumSize())
+	// This is after the Donut hardfork, so signify this uses CIP22.
+	return message, extraData, true, err
+}
+
+// EpochValidatorSetData serializes the validator set of the epoch following the block for
+// the epoch validator set seal, light clients rebuild the signed data with it.
+func EpochValidatorSetData(blockNumber, epochSize uint64, round uint8, blockHash, parentEpochBlockHash common.Hash,
+	newValSet []blscrypto.SerializedPublicKey, minQuorumSize int) ([]byte, []byte, error) {
+	maxNonSigners := maxValidators - uint32(minQuorumSize)
+	return blscrypto.CryptoType().EncodeEpochSnarkDataCIP22(
+		newValSet, maxNonSigners, maxValidators,
+		uint16(istanbul.GetEpochNumber(blockNumber, epochSize)),
 		round,
 		blscrypto.EpochEntropyFromHash(blockHash),
 		blscrypto.EpochEntropyFromHash(parentEpochBlockHash),
 	)
-	// This is after the Donut hardfork, so signify this uses CIP22.
-	return message, extraData, true, err
 }
 
 func (c *core) broadcastCommit(sub *istanbul.Subject) {
@@ -212,7 +216,7 @@ func (c *core) handleCheckedCommitForCurrentSequence(msg *istanbul.Message, comm
 		return err
 	}
 	numberOfCommits := c.current.Commits().Size()
-	minQuorumSize := c.current.ValidatorSet().MinQuorumSize()
+	valSet := c.current.ValidatorSet()
 	logger.Trace("Accepted commit for current sequence", "Number of commits", numberOfCommits)
 
 	// Commit the proposal once we have enough COMMIT messages and we are not in the Committed state.
@@ -220,20 +224,21 @@ func (c *core) handleCheckedCommitForCurrentSequence(msg *istanbul.Message, comm
 	// If we already have a proposal, we may have chance to speed up the consensus process
 	// by committing the proposal without PREPARE messages.
 	// TODO(joshua): Remove state comparisons (or change the cmp function)
-	if numberOfCommits >= minQuorumSize && c.current.State().Cmp(StateCommitted) < 0 {
-		logger.Trace("Got a quorum of commits", "tag", "stateTransition", "commits", numberOfCommits, "quorum", minQuorumSize)
+	if valSet.HasQuorum(c.current.Commits().Addresses()) && c.current.State().Cmp(StateCommitted) < 0 {
+		logger.Trace("Got a quorum of commits", "tag", "stateTransition", "commits", numberOfCommits, "quorum", valSet.QuorumWeight())
 		err := c.commit()
 		if err != nil {
 			logger.Error("Failed to commit()", "err", err)
 			return err
 		}
 
-	} else if c.current.GetPrepareOrCommitSize() >= minQuorumSize && c.current.State().Cmp(StatePrepared) < 0 {
-		err := c.current.TransitionToPrepared(minQuorumSize)
+	} else if quorum, certificateSize := c.prepareOrCommitQuorum(); quorum && c.current.State().Cmp(StatePrepared) < 0 {
+		err := c.current.TransitionToPrepared(certificateSize)
 		if err != nil {
 			logger.Error("Failed to create and set prepared certificate", "err", err)
 			return err
 		}
+		c.recordPrepared()
 		// Process Backlog Messages
 		c.backlog.updateState(c.current.View(), c.current.State())
 
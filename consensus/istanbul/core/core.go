@@ -103,6 +103,9 @@ type CoreBackend interface {
 
 	IsPrimaryForSeq(seq *big.Int) bool
 	UpdateReplicaState(seq *big.Int)
+
+	// ReportEquivocation is called once for every new equivocation evidence
+	ReportEquivocation(ev *istanbul.Evidence)
 }
 
 type core struct {
@@ -130,12 +133,17 @@ type core struct {
 	current   RoundState
 	handlerWg *sync.WaitGroup
 
+	evdb          EvidenceDB
+	equivocations *equivocationDetector
+
 	roundChangeSet *roundChangeSet
 
 	pendingRequests   *prque.Prque
 	pendingRequestsMu *sync.Mutex
 
-	consensusTimestamp time.Time
+	consensusTimestamp  time.Time
+	roundStartTimestamp time.Time
+	preparedTimestamp   time.Time
 
 	// Time from accepting a pre-prepare (after block verifcation) to preparing or committing
 	consensusPrepareTimeGauge metrics.Gauge
@@ -146,6 +154,14 @@ type core struct {
 	handlePrePrepareTimer metrics.Timer
 	handlePrepareTimer    metrics.Timer
 	handleCommitTimer     metrics.Timer
+	// Histogram of the time spent in each consensus phase: from the start of the round to accepting a
+	// pre-prepare, from accepting it to preparing, and from preparing to committing
+	preprepareTimer metrics.Timer
+	prepareTimer    metrics.Timer
+	commitTimer     metrics.Timer
+
+	// Optional JSON trace of the consensus events
+	trace *consensusTrace
 }
 
 // New creates an Istanbul consensus core
@@ -154,6 +170,10 @@ func New(backend CoreBackend, config *istanbul.Config) Engine {
 	if err != nil {
 		log.Crit("Failed to open RoundStateDB", "err", err)
 	}
+	evdb, err := newEvidenceDB(config.EvidenceDBPath)
+	if err != nil {
+		log.Crit("Failed to open EvidenceDB", "err", err)
+	}
 
 	c := &core{
 		config:                    config,
@@ -166,12 +186,17 @@ func New(backend CoreBackend, config *istanbul.Config) Engine {
 		pendingRequestsMu:         new(sync.Mutex),
 		consensusTimestamp:        time.Time{},
 		rsdb:                      rsdb,
+		evdb:                      evdb,
+		equivocations:             newEquivocationDetector(),
 		consensusPrepareTimeGauge: metrics.NewRegisteredGauge("consensus/istanbul/core/consensus_prepare", nil),
 		consensusCommitTimeGauge:  metrics.NewRegisteredGauge("consensus/istanbul/core/consensus_commit", nil),
 		verifyGauge:               metrics.NewRegisteredGauge("consensus/istanbul/core/verify", nil),
 		handlePrePrepareTimer:     metrics.NewRegisteredTimer("consensus/istanbul/core/handle_preprepare", nil),
 		handlePrepareTimer:        metrics.NewRegisteredTimer("consensus/istanbul/core/handle_prepare", nil),
 		handleCommitTimer:         metrics.NewRegisteredTimer("consensus/istanbul/core/handle_commit", nil),
+		preprepareTimer:           metrics.NewRegisteredTimer("consensus/istanbul/core/phase/preprepare", nil),
+		prepareTimer:              metrics.NewRegisteredTimer("consensus/istanbul/core/phase/prepare", nil),
+		commitTimer:               metrics.NewRegisteredTimer("consensus/istanbul/core/phase/commit", nil),
 	}
 	msgBacklog := newMsgBacklog(
 		func(msg *istanbul.Message) {
@@ -363,10 +388,7 @@ func (c *core) commit() error {
 	}
 
 	// Update metrics.
-	if !c.consensusTimestamp.IsZero() {
-		c.consensusCommitTimeGauge.Update(time.Since(c.consensusTimestamp).Nanoseconds())
-		c.consensusTimestamp = time.Time{}
-	}
+	c.recordCommitted()
 
 	// Process Backlog Messages
 	c.backlog.updateState(c.current.View(), c.current.State())
@@ -377,6 +399,7 @@ func (c *core) commit() error {
 		if err != nil {
 			nextRound := new(big.Int).Add(c.current.Round(), common.Big1)
 			logger.Warn("Error on commit, waiting for desired round", "reason", "getAggregatedSeal", "err", err, "desired_round", nextRound)
+			c.recordRoundChange(roundChangeCauseCommitFailure, nextRound)
 			c.waitForDesiredRound(nextRound)
 			return nil
 		}
@@ -384,6 +407,7 @@ func (c *core) commit() error {
 		if err != nil {
 			nextRound := new(big.Int).Add(c.current.Round(), common.Big1)
 			c.logger.Warn("Error on commit, waiting for desired round", "reason", "GetAggregatedEpochValidatorSetSeal", "err", err, "desired_round", nextRound)
+			c.recordRoundChange(roundChangeCauseCommitFailure, nextRound)
 			c.waitForDesiredRound(nextRound)
 			return nil
 		}
@@ -393,6 +417,7 @@ func (c *core) commit() error {
 		if err := c.backend.Commit(proposal, aggregatedSeal, aggregatedEpochValidatorSetSeal, result); err != nil {
 			nextRound := new(big.Int).Add(c.current.Round(), common.Big1)
 			logger.Warn("Error on commit, waiting for desired round", "reason", "backend.Commit", "err", err, "desired_round", nextRound)
+			c.recordRoundChange(roundChangeCauseCommitFailure, nextRound)
 			c.waitForDesiredRound(nextRound)
 			return nil
 		}
@@ -433,7 +458,13 @@ func GetAggregatedEpochValidatorSetSeal(blockNumber, epoch uint64, seals Message
 func (c *core) getPreprepareWithRoundChangeCertificate(round *big.Int) (*istanbul.Request, istanbul.RoundChangeCertificate, error) {
 	logger := c.newLogger("func", "getPreprepareWithRoundChangeCertificate", "for_round", round)
 
-	roundChangeCertificate, err := c.roundChangeSet.getCertificate(round, c.current.ValidatorSet().MinQuorumSize())
+	var roundChangeCertificate istanbul.RoundChangeCertificate
+	var err error
+	if valSet := c.current.ValidatorSet(); valSet.Weighted() {
+		roundChangeCertificate, err = c.roundChangeSet.getWeightedCertificate(round, valSet)
+	} else {
+		roundChangeCertificate, err = c.roundChangeSet.getCertificate(round, valSet.MinQuorumSize())
+	}
 	if err != nil {
 		return &istanbul.Request{}, istanbul.RoundChangeCertificate{}, err
 	}
@@ -499,6 +530,7 @@ func (c *core) startNewRound(round *big.Int) error {
 
 	// Update the roundstate db
 	c.current.StartNewRound(round, valSet, nextProposer)
+	c.recordRoundStarted()
 
 	// Process backlog
 	c.processPendingRequests()
@@ -561,6 +593,7 @@ func (c *core) startNewSequence() error {
 	if err != nil {
 		return err
 	}
+	c.recordRoundStarted()
 
 	// Process backlog
 	c.processPendingRequests()
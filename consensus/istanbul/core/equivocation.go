@@ -0,0 +1,99 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+)
+
+type equivocationKey struct {
+	code   uint64
+	round  uint64
+	signer common.Address
+}
+
+// equivocationDetector remembers the first preprepare, prepare and commit signed by
+// each validator for every round of the current sequence, to catch conflicting ones.
+type equivocationDetector struct {
+	sequence uint64
+	seen     map[equivocationKey]*istanbul.Message
+}
+
+func newEquivocationDetector() *equivocationDetector {
+	return &equivocationDetector{seen: make(map[equivocationKey]*istanbul.Message)}
+}
+
+// check returns the evidence if msg conflicts with a message from the same signer
+// for the same code and view, or nil otherwise. Messages must be for sequence seq.
+func (d *equivocationDetector) check(msg *istanbul.Message, seq uint64) (*istanbul.Evidence, error) {
+	view, digest, ok := msg.ConsensusSubject()
+	if !ok || !view.Sequence.IsUint64() || view.Sequence.Uint64() != seq {
+		return nil, nil
+	}
+	if seq != d.sequence {
+		d.sequence = seq
+		d.seen = make(map[equivocationKey]*istanbul.Message)
+	}
+
+	key := equivocationKey{code: msg.Code, round: view.Round.Uint64(), signer: msg.Address}
+	first, ok := d.seen[key]
+	if !ok {
+		d.seen[key] = msg
+		return nil, nil
+	}
+	if _, firstDigest, _ := first.ConsensusSubject(); firstDigest == digest {
+		return nil, nil
+	}
+	return istanbul.NewEvidence(first, msg)
+}
+
+// detectEquivocation stores the evidence of msg conflicting with an earlier message of its
+// signer and reports it to the backend. Only messages for the current sequence are checked,
+// as the signer has only been verified to be in the validator set of the current sequence.
+func (c *core) detectEquivocation(msg *istanbul.Message) {
+	logger := c.newLogger("func", "detectEquivocation", "from", msg.Address)
+
+	ev, err := c.equivocations.check(msg, c.current.Sequence().Uint64())
+	if err != nil {
+		logger.Warn("Failed to build equivocation evidence", "err", err)
+		return
+	}
+	if ev == nil {
+		return
+	}
+	stored, err := c.evdb.StoreEvidence(ev)
+	if err != nil {
+		logger.Error("Failed to store equivocation evidence", "evidence", ev, "err", err)
+		return
+	}
+	if !stored {
+		return
+	}
+	logger.Warn("Validator equivocated", "evidence", ev)
+	c.backend.ReportEquivocation(ev)
+}
+
+// Evidences implements core.Engine.Evidences
+func (c *core) Evidences(fromSeq, toSeq uint64) ([]*EvidenceRecord, error) {
+	return c.evdb.GetEvidences(fromSeq, toSeq)
+}
+
+// MarkEvidenceSubmitted implements core.Engine.MarkEvidenceSubmitted
+func (c *core) MarkEvidenceSubmitted(ev *istanbul.Evidence, tx common.Hash) error {
+	return c.evdb.MarkEvidenceSubmitted(ev, tx)
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+)
+
+func TestDetectEquivocation(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	v0 := sys.backends[0]
+	v1 := sys.backends[1]
+	c := v0.engine.(*core)
+	c.current = newTestRoundState(newView(1, 0), v0.peers)
+
+	handlePrepare := func(view *istanbul.View, digest common.Hash) {
+		msg, err := v1.getPrepareMessage(*view, digest)
+		finishOnError(t, err)
+		payload, err := msg.Payload()
+		finishOnError(t, err)
+		c.handleMsg(payload)
+	}
+
+	handlePrepare(newView(1, 0), common.HexToHash("0x01"))
+	handlePrepare(newView(1, 0), common.HexToHash("0x01"))
+	handlePrepare(newView(1, 1), common.HexToHash("0x02"))
+	if len(v0.evidences) != 0 {
+		t.Fatalf("evidences reported for consistent prepares: %v", v0.evidences)
+	}
+
+	handlePrepare(newView(1, 0), common.HexToHash("0x02"))
+	handlePrepare(newView(1, 0), common.HexToHash("0x03"))
+	if len(v0.evidences) != 1 {
+		t.Fatalf("reported evidences mismatch: have %d, want 1", len(v0.evidences))
+	}
+	ev := v0.evidences[0]
+	if ev.Code != istanbul.MsgPrepare || ev.Signer != v1.address || ev.View.Cmp(newView(1, 0)) != 0 {
+		t.Errorf("evidence mismatch: have %v", ev)
+	}
+	if err := ev.Verify(); err != nil {
+		t.Errorf("evidence verification failed: %v", err)
+	}
+
+	records, err := c.Evidences(0, 10)
+	finishOnError(t, err)
+	if len(records) != 1 || records[0].Evidence.Hash() != ev.Hash() || records[0].Submission != (common.Hash{}) {
+		t.Fatalf("stored evidences mismatch: have %v", records)
+	}
+	tx := common.HexToHash("0xabcd")
+	finishOnError(t, c.MarkEvidenceSubmitted(ev, tx))
+	records, err = c.Evidences(1, 1)
+	finishOnError(t, err)
+	if len(records) != 1 || records[0].Submission != tx {
+		t.Errorf("submitted evidence mismatch: have %v", records)
+	}
+	if records, _ = c.Evidences(2, 10); len(records) != 0 {
+		t.Errorf("evidences for later sequences: have %v", records)
+	}
+
+	// Messages of other sequences are not checked against the current validator set
+	handlePrepare(newView(2, 0), common.HexToHash("0x01"))
+	handlePrepare(newView(2, 0), common.HexToHash("0x02"))
+	if len(v0.evidences) != 1 {
+		t.Errorf("reported evidences mismatch: have %d, want 1", len(v0.evidences))
+	}
+}
+
+func TestEvidenceVerify(t *testing.T) {
+	sys := NewTestSystemWithBackend(2, 1)
+	v0 := sys.backends[0]
+	v1 := sys.backends[1]
+	v0.engine.(*core).current = newTestRoundState(newView(1, 0), v0.peers)
+	v1.engine.(*core).current = newTestRoundState(newView(1, 0), v1.peers)
+
+	first, err := v0.getPrepareMessage(*newView(1, 0), common.HexToHash("0x01"))
+	finishOnError(t, err)
+	second, err := v0.getPrepareMessage(*newView(1, 0), common.HexToHash("0x02"))
+	finishOnError(t, err)
+	other, err := v1.getPrepareMessage(*newView(1, 0), common.HexToHash("0x02"))
+	finishOnError(t, err)
+
+	if _, err := istanbul.NewEvidence(&first, &first); err != istanbul.ErrInvalidEvidence {
+		t.Errorf("error mismatch for same digest: have %v, want %v", err, istanbul.ErrInvalidEvidence)
+	}
+	if _, err := istanbul.NewEvidence(&first, &other); err != istanbul.ErrInvalidEvidence {
+		t.Errorf("error mismatch for different signers: have %v, want %v", err, istanbul.ErrInvalidEvidence)
+	}
+	ev, err := istanbul.NewEvidence(&first, &second)
+	finishOnError(t, err)
+	if err := ev.Verify(); err != nil {
+		t.Errorf("evidence verification failed: %v", err)
+	}
+	reversed, err := istanbul.NewEvidence(&second, &first)
+	finishOnError(t, err)
+	if reversed.Hash() != ev.Hash() {
+		t.Errorf("evidence hash depends on message order")
+	}
+
+	ev.Signer = v1.address
+	if err := ev.Verify(); err != istanbul.ErrInvalidEvidence {
+		t.Errorf("error mismatch for wrong signer: have %v, want %v", err, istanbul.ErrInvalidEvidence)
+	}
+}
@@ -80,4 +80,6 @@ var (
 	errInvalidValidatorAddress = errors.New("failed to find an existing validator by address")
 	// Invalid round state
 	errInvalidState = errors.New("invalid round state")
+	// errRoundStateDBVersion is returned when reading a roundstate db of another version
+	errRoundStateDBVersion = errors.New("roundstate db version mismatch")
 )
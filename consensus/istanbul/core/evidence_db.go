@@ -0,0 +1,161 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const evidenceKey = "evidence-" // Database Key Prefix for equivocation evidences
+
+// EvidenceRecord is an equivocation evidence along with its slashing submission status.
+type EvidenceRecord struct {
+	Evidence   *istanbul.Evidence
+	Submission common.Hash // Hash of the transaction submitting the evidence, zero if not submitted
+}
+
+// EvidenceDB stores the equivocation evidences seen by this node. Unlike round states
+// evidences are never garbage collected, as they are needed to slash the signer.
+type EvidenceDB interface {
+	// StoreEvidence stores the evidence unless one for the same signer, code and view
+	// is already present, and reports whether it was stored.
+	StoreEvidence(ev *istanbul.Evidence) (bool, error)
+	// MarkEvidenceSubmitted records the transaction submitting the evidence.
+	MarkEvidenceSubmitted(ev *istanbul.Evidence, tx common.Hash) error
+	// GetEvidences returns the evidences for sequences from fromSeq to toSeq included.
+	GetEvidences(fromSeq, toSeq uint64) ([]*EvidenceRecord, error)
+	Close() error
+}
+
+type evidenceDBImpl struct {
+	db     *leveldb.DB
+	logger log.Logger
+}
+
+func newEvidenceDB(path string) (EvidenceDB, error) {
+	logger := log.New("func", "newEvidenceDB", "type", "evidenceDB", "evdb_path", path)
+
+	logger.Info("Open evidence db")
+	var db *leveldb.DB
+	var err error
+	if path == "" {
+		db, err = newMemoryDB()
+	} else {
+		db, err = newPersistentDB(path)
+	}
+
+	if err != nil {
+		logger.Error("Failed to open evidence db", "err", err)
+		return nil, err
+	}
+
+	return &evidenceDBImpl{
+		db:     db,
+		logger: logger,
+	}, nil
+}
+
+func (evdb *evidenceDBImpl) StoreEvidence(ev *istanbul.Evidence) (bool, error) {
+	key := evidence2Key(ev)
+	has, err := evdb.db.Has(key, nil)
+	if err != nil || has {
+		return false, err
+	}
+	if err := evdb.put(key, &EvidenceRecord{Evidence: ev}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (evdb *evidenceDBImpl) MarkEvidenceSubmitted(ev *istanbul.Evidence, tx common.Hash) error {
+	key := evidence2Key(ev)
+	rawEntry, err := evdb.db.Get(key, nil)
+	if err != nil {
+		return err
+	}
+	var entry EvidenceRecord
+	if err = rlp.DecodeBytes(rawEntry, &entry); err != nil {
+		return err
+	}
+	entry.Submission = tx
+	return evdb.put(key, &entry)
+}
+
+func (evdb *evidenceDBImpl) GetEvidences(fromSeq, toSeq uint64) ([]*EvidenceRecord, error) {
+	limit := util.BytesPrefix([]byte(evidenceKey)).Limit
+	if toSeq < ^uint64(0) {
+		limit = sequence2EvidenceKey(toSeq + 1)
+	}
+	iter := evdb.db.NewIterator(&util.Range{Start: sequence2EvidenceKey(fromSeq), Limit: limit}, nil)
+	defer iter.Release()
+
+	records := make([]*EvidenceRecord, 0)
+	for iter.Next() {
+		var entry EvidenceRecord
+		if err := rlp.DecodeBytes(iter.Value(), &entry); err != nil {
+			return nil, err
+		}
+		records = append(records, &entry)
+	}
+	return records, iter.Error()
+}
+
+func (evdb *evidenceDBImpl) Close() error {
+	return evdb.db.Close()
+}
+
+func (evdb *evidenceDBImpl) put(key []byte, entry *EvidenceRecord) error {
+	entryBytes, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		evdb.logger.Error("Failed to save evidence", "reason", "rlp encoding", "err", err)
+		return err
+	}
+	if err = evdb.db.Put(key, entryBytes, nil); err != nil {
+		evdb.logger.Error("Failed to save evidence", "reason", "levelDB write", "err", err)
+	}
+	return err
+}
+
+// sequence2EvidenceKey returns the smallest key of the evidences for the given sequence
+func sequence2EvidenceKey(seq uint64) []byte {
+	prefix := []byte(evidenceKey)
+	buff := make([]byte, len(prefix)+8)
+	copy(buff, prefix)
+	binary.BigEndian.PutUint64(buff[len(prefix):], seq)
+	return buff
+}
+
+// evidence2Key encodes the signer, code and view of an evidence so that keys are
+// sorted by view. The key format is [ prefix . BigEndian(Sequence) . BigEndian(Round) . Code . Signer ]
+func evidence2Key(ev *istanbul.Evidence) []byte {
+	seqKey := sequence2EvidenceKey(ev.View.Sequence.Uint64())
+	buff := make([]byte, len(seqKey)+8+8+common.AddressLength)
+
+	copy(buff, seqKey)
+	binary.BigEndian.PutUint64(buff[len(seqKey):], ev.View.Round.Uint64())
+	binary.BigEndian.PutUint64(buff[len(seqKey)+8:], ev.Code)
+	copy(buff[len(seqKey)+16:], ev.Signer.Bytes())
+
+	return buff
+}
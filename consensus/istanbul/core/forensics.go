@@ -0,0 +1,237 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+)
+
+const (
+	// CulpritNoProposal blames the proposer of a round no node got a proposal for
+	CulpritNoProposal = "no proposal"
+	// CulpritNoVote blames a validator no node got a prepare nor a commit from
+	CulpritNoVote = "no prepare nor commit"
+)
+
+// RoundChangeSummary is a round change message of a round change certificate
+type RoundChangeSummary struct {
+	Sender              common.Address                       `json:"sender"`
+	View                *istanbul.View                       `json:"view"`
+	PreparedCertificate *istanbul.PreparedCertificateSummary `json:"preparedCertificate"`
+}
+
+// RoundStateRecord is a round state stored in the RoundStateDB, along with the
+// round change certificate of its preprepare.
+type RoundStateRecord struct {
+	*RoundStateSummary
+	RoundChangeCertificate []*RoundChangeSummary `json:"roundChangeCertificate"`
+}
+
+// RoundStateDump holds the round states a node stored for a range of sequences
+type RoundStateDump struct {
+	Node    string              `json:"node"`
+	FromSeq uint64              `json:"fromSeq"`
+	ToSeq   uint64              `json:"toSeq"`
+	Rounds  []*RoundStateRecord `json:"rounds"`
+}
+
+// Culprit is a validator blamed for a round that ended in a round change
+type Culprit struct {
+	Address common.Address `json:"address"`
+	Reason  string         `json:"reason"`
+}
+
+// TimelineRound is a round as seen by all the nodes of the merged dumps. Prepares and
+// Commits are the validators any node got a message from.
+type TimelineRound struct {
+	Sequence     uint64            `json:"sequence"`
+	Round        uint64            `json:"round"`
+	Proposer     common.Address    `json:"proposer"`
+	ProposalHash *common.Hash      `json:"proposalHash"`
+	States       map[string]string `json:"states"`
+	Prepares     []common.Address  `json:"prepares"`
+	Commits      []common.Address  `json:"commits"`
+	RoundChange  bool              `json:"roundChange"`
+	Culprits     []Culprit         `json:"culprits"`
+
+	validators []common.Address
+}
+
+// RoundTimeline puts the round states dumped from several nodes on one timeline
+type RoundTimeline struct {
+	Nodes  []string                  `json:"nodes"`
+	Rounds []*TimelineRound          `json:"rounds"`
+	Blame  map[common.Address]uint64 `json:"blame"` // Number of failed rounds each validator is blamed for
+}
+
+// DumpRoundStates reads the round states of sequences fromSeq to toSeq from the
+// RoundStateDB at path, which must not be in use by a running node.
+func DumpRoundStates(path string, fromSeq, toSeq uint64) ([]*RoundStateRecord, error) {
+	rsdb, err := openRoundStateDBReadOnly(path)
+	if err != nil {
+		return nil, err
+	}
+	defer rsdb.Close()
+
+	roundStates, err := rsdb.GetRoundStates(fromSeq, toSeq)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*RoundStateRecord, 0, len(roundStates))
+	for _, rs := range roundStates {
+		records = append(records, newRoundStateRecord(rs))
+	}
+	return records, nil
+}
+
+func newRoundStateRecord(rs RoundState) *RoundStateRecord {
+	record := &RoundStateRecord{
+		RoundStateSummary:      rs.Summary(),
+		RoundChangeCertificate: []*RoundChangeSummary{},
+	}
+	preprepare := rs.Preprepare()
+	if preprepare == nil {
+		return record
+	}
+	messages := preprepare.RoundChangeCertificate.RoundChangeMessages
+	for i := range messages {
+		summary := &RoundChangeSummary{Sender: messages[i].Address}
+		if roundChange, err := messages[i].TryRoundChange(); err == nil {
+			summary.View = roundChange.View
+			if roundChange.HasPreparedCertificate() {
+				summary.PreparedCertificate = roundChange.PreparedCertificate.Summary()
+			}
+		}
+		record.RoundChangeCertificate = append(record.RoundChangeCertificate, summary)
+	}
+	return record
+}
+
+// MergeRoundStateDumps puts the dumps of several nodes on one timeline sorted by view.
+// A round ended in a round change if any node saw a later round of its sequence or
+// wanted to move on. Its proposer is blamed if no node got its proposal, otherwise the
+// validators no node got a prepare nor a commit from are.
+func MergeRoundStateDumps(dumps []*RoundStateDump) *RoundTimeline {
+	type viewKey struct{ seq, round uint64 }
+
+	timeline := &RoundTimeline{
+		Nodes:  make([]string, 0, len(dumps)),
+		Rounds: []*TimelineRound{},
+		Blame:  make(map[common.Address]uint64),
+	}
+	rounds := make(map[viewKey]*TimelineRound)
+	lastRound := make(map[uint64]uint64)
+	for _, dump := range dumps {
+		timeline.Nodes = append(timeline.Nodes, dump.Node)
+		for _, record := range dump.Rounds {
+			if record.RoundStateSummary == nil || record.Sequence == nil || record.Round == nil {
+				continue
+			}
+			key := viewKey{seq: record.Sequence.Uint64(), round: record.Round.Uint64()}
+			tr, ok := rounds[key]
+			if !ok {
+				tr = &TimelineRound{
+					Sequence: key.seq,
+					Round:    key.round,
+					Proposer: record.Proposer,
+					States:   make(map[string]string),
+				}
+				rounds[key] = tr
+			}
+			tr.States[dump.Node] = record.State
+			if tr.validators == nil {
+				tr.validators = record.ValidatorSet
+			}
+			if pp := record.Preprepare; pp != nil && pp.View != nil && pp.View.Round != nil && pp.View.Round.Uint64() == key.round {
+				hash := pp.ProposalHash
+				tr.ProposalHash = &hash
+			}
+			tr.Prepares = mergeAddresses(tr.Prepares, record.Prepares)
+			tr.Commits = mergeAddresses(tr.Commits, record.Commits)
+			if record.DesiredRound != nil && record.DesiredRound.Cmp(record.Round) > 0 {
+				tr.RoundChange = true
+			}
+			if key.round > lastRound[key.seq] {
+				lastRound[key.seq] = key.round
+			}
+		}
+	}
+
+	for key, tr := range rounds {
+		if key.round < lastRound[key.seq] {
+			tr.RoundChange = true
+		}
+		if tr.RoundChange {
+			tr.Culprits = roundCulprits(tr)
+			for _, culprit := range tr.Culprits {
+				timeline.Blame[culprit.Address]++
+			}
+		}
+		timeline.Rounds = append(timeline.Rounds, tr)
+	}
+	sort.Slice(timeline.Rounds, func(i, j int) bool {
+		a, b := timeline.Rounds[i], timeline.Rounds[j]
+		if a.Sequence != b.Sequence {
+			return a.Sequence < b.Sequence
+		}
+		return a.Round < b.Round
+	})
+	return timeline
+}
+
+// roundCulprits returns the validators blamed for a round that ended in a round change
+func roundCulprits(tr *TimelineRound) []Culprit {
+	if tr.ProposalHash == nil {
+		return []Culprit{{Address: tr.Proposer, Reason: CulpritNoProposal}}
+	}
+	voted := make(map[common.Address]bool)
+	for _, addr := range tr.Prepares {
+		voted[addr] = true
+	}
+	for _, addr := range tr.Commits {
+		voted[addr] = true
+	}
+	culprits := []Culprit{}
+	for _, addr := range tr.validators {
+		if !voted[addr] {
+			culprits = append(culprits, Culprit{Address: addr, Reason: CulpritNoVote})
+		}
+	}
+	return culprits
+}
+
+// mergeAddresses returns the sorted union of two address lists
+func mergeAddresses(a, b []common.Address) []common.Address {
+	set := make(map[common.Address]bool, len(a)+len(b))
+	merged := make([]common.Address, 0, len(a)+len(b))
+	for _, list := range [][]common.Address{a, b} {
+		for _, addr := range list {
+			if !set[addr] {
+				set[addr] = true
+				merged = append(merged, addr)
+			}
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return bytes.Compare(merged[i].Bytes(), merged[j].Bytes()) < 0
+	})
+	return merged
+}
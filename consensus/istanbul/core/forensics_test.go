@@ -0,0 +1,122 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+)
+
+func TestDumpRoundStates(t *testing.T) {
+	path := t.TempDir()
+	rsdb, err := newRoundStateDB(path, &RoundStateDBOptions{withGarbageCollector: false})
+	finishOnError(t, err)
+	valSet := newTestValidatorSet(4)
+	for _, view := range []*istanbul.View{newView(2, 0), newView(2, 1), newView(3, 0), newView(4, 2)} {
+		finishOnError(t, rsdb.UpdateLastRoundState(newTestRoundState(view, valSet)))
+	}
+	finishOnError(t, rsdb.Close())
+
+	records, err := DumpRoundStates(path, 2, 3)
+	finishOnError(t, err)
+	views := make([]*istanbul.View, 0, len(records))
+	for _, record := range records {
+		views = append(views, &istanbul.View{Sequence: record.Sequence, Round: record.Round})
+	}
+	want := []*istanbul.View{newView(2, 0), newView(2, 1), newView(3, 0)}
+	if len(views) != len(want) {
+		t.Fatalf("dumped views mismatch: have %v, want %v", views, want)
+	}
+	for i := range want {
+		assertEqualView(t, views[i], want[i])
+	}
+
+	if records, err = DumpRoundStates(path, 4, ^uint64(0)); err != nil || len(records) != 1 {
+		t.Errorf("dump to the latest sequence mismatch: have %d records, err %v", len(records), err)
+	}
+	if _, err := DumpRoundStates(t.TempDir()+"/missing", 0, 1); err == nil {
+		t.Errorf("expected an error when dumping a missing db")
+	}
+}
+
+func TestMergeRoundStateDumps(t *testing.T) {
+	a, b, c, d := common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03"), common.HexToAddress("0x04")
+	validators := []common.Address{a, b, c, d}
+	record := func(seq, round, desiredRound uint64, state string, proposer common.Address, proposal *common.Hash, prepares, commits []common.Address) *RoundStateRecord {
+		summary := &RoundStateSummary{
+			State:        state,
+			Sequence:     new(big.Int).SetUint64(seq),
+			Round:        new(big.Int).SetUint64(round),
+			DesiredRound: new(big.Int).SetUint64(desiredRound),
+			ValidatorSet: validators,
+			Proposer:     proposer,
+			Prepares:     prepares,
+			Commits:      commits,
+		}
+		if proposal != nil {
+			summary.Preprepare = &istanbul.PreprepareSummary{View: newView(seq, round), ProposalHash: *proposal}
+		}
+		return &RoundStateRecord{RoundStateSummary: summary}
+	}
+	proposal := common.HexToHash("0xaa")
+
+	dumps := []*RoundStateDump{
+		{Node: "a", Rounds: []*RoundStateRecord{
+			// Round 0 of sequence 5: the proposer d never proposed
+			record(5, 0, 1, StateAcceptRequest.String(), d, nil, nil, nil),
+			// Round 1: c never voted
+			record(5, 1, 1, StatePrepared.String(), a, &proposal, []common.Address{a, b}, []common.Address{a}),
+			record(5, 2, 2, StateCommitted.String(), b, &proposal, []common.Address{a, b, c}, []common.Address{a, b, c}),
+		}},
+		{Node: "b", Rounds: []*RoundStateRecord{
+			record(5, 1, 2, StateWaitingForNewRound.String(), a, &proposal, []common.Address{b, d}, nil),
+			record(5, 2, 2, StateCommitted.String(), b, &proposal, []common.Address{b, c}, []common.Address{b, c, d}),
+			record(6, 0, 0, StateAcceptRequest.String(), c, nil, nil, nil),
+		}},
+	}
+	timeline := MergeRoundStateDumps(dumps)
+
+	if !reflect.DeepEqual(timeline.Nodes, []string{"a", "b"}) {
+		t.Errorf("nodes mismatch: have %v", timeline.Nodes)
+	}
+	if len(timeline.Rounds) != 4 {
+		t.Fatalf("rounds mismatch: have %d, want 4", len(timeline.Rounds))
+	}
+	r0, r1, r2, next := timeline.Rounds[0], timeline.Rounds[1], timeline.Rounds[2], timeline.Rounds[3]
+	if r0.Round != 0 || !r0.RoundChange || !reflect.DeepEqual(r0.Culprits, []Culprit{{Address: d, Reason: CulpritNoProposal}}) {
+		t.Errorf("round 0 mismatch: have %+v", r0)
+	}
+	if r1.Round != 1 || !r1.RoundChange || !reflect.DeepEqual(r1.Culprits, []Culprit{{Address: c, Reason: CulpritNoVote}}) {
+		t.Errorf("round 1 mismatch: have %+v", r1)
+	}
+	if !reflect.DeepEqual(r1.Prepares, []common.Address{a, b, d}) || !reflect.DeepEqual(r1.States, map[string]string{"a": "Prepared", "b": "Waiting for new round"}) {
+		t.Errorf("round 1 merge mismatch: prepares %v, states %v", r1.Prepares, r1.States)
+	}
+	if r2.Round != 2 || r2.RoundChange || len(r2.Culprits) != 0 {
+		t.Errorf("round 2 mismatch: have %+v", r2)
+	}
+	if next.Sequence != 6 || next.RoundChange {
+		t.Errorf("sequence 6 mismatch: have %+v", next)
+	}
+	if want := map[common.Address]uint64{c: 1, d: 1}; !reflect.DeepEqual(timeline.Blame, want) {
+		t.Errorf("blame mismatch: have %v, want %v", timeline.Blame, want)
+	}
+}
@@ -34,10 +34,17 @@ func (c *core) Start() error {
 	c.current = roundState
 	c.roundChangeSet = newRoundChangeSet(c.current.ValidatorSet())
 
+	// The trace file is open while the core runs, a replica stopping and restarting its
+	// core appends to it again
+	if c.config.ConsensusTraceFile != "" {
+		c.trace = openConsensusTrace(c.config.ConsensusTraceFile)
+	}
+
 	// Reset the Round Change timer for the current round to timeout.
 	// (If we've restored RoundState such that we are in StateWaitingForRoundChange,
 	// this may also start a timer to send a repeat round change message.)
 	c.resetRoundChangeTimer()
+	c.recordRoundStarted()
 
 	// Process backlog
 	c.processPendingRequests()
@@ -59,6 +66,11 @@ func (c *core) Stop() error {
 	// Make sure the handler goroutine exits
 	c.handlerWg.Wait()
 
+	if err := c.trace.Close(); err != nil {
+		c.logger.Error("Failed to close consensus trace file", "err", err)
+	}
+	c.trace = nil
+
 	c.current = nil
 	return nil
 }
@@ -176,6 +188,8 @@ func (c *core) handleMsg(payload []byte) error {
 		logger.Error("Invalid address in message", "m", msg)
 		return istanbul.ErrUnauthorizedAddress
 	}
+	c.detectEquivocation(msg)
+	c.recordMessage(msg)
 
 	return c.handleCheckedMsg(msg, src)
 }
@@ -221,6 +235,7 @@ func (c *core) handleTimeoutAndMoveToNextRound(timedOutView *istanbul.View) erro
 
 	logger.Debug("Timed out, trying to wait for next round")
 	nextRound := new(big.Int).Add(timedOutView.Round, common.Big1)
+	c.recordRoundChange(roundChangeCauseTimeout, nextRound)
 	return c.waitForDesiredRound(nextRound)
 }
 
@@ -40,7 +40,8 @@ func (c *core) verifyPreparedCertificate(preparedCertificate istanbul.PreparedCe
 		return nil, errInvalidPreparedCertificateProposal
 	}
 
-	if len(preparedCertificate.PrepareOrCommitMessages) > c.current.ValidatorSet().Size() || len(preparedCertificate.PrepareOrCommitMessages) < c.current.ValidatorSet().MinQuorumSize() {
+	valSet := c.current.ValidatorSet()
+	if len(preparedCertificate.PrepareOrCommitMessages) > valSet.Size() || (!valSet.Weighted() && len(preparedCertificate.PrepareOrCommitMessages) < valSet.MinQuorumSize()) {
 		return nil, errInvalidPreparedCertificateNumMsgs
 	}
 
@@ -121,12 +122,25 @@ func (c *core) verifyPreparedCertificate(preparedCertificate istanbul.PreparedCe
 			}
 		}
 	}
+
+	// The signers must hold a quorum of the voting power
+	signers := make([]common.Address, 0, len(seen))
+	for signer := range seen {
+		signers = append(signers, signer)
+	}
+	if !valSet.HasQuorum(signers) {
+		return nil, errInvalidPreparedCertificateNumMsgs
+	}
 	return view, nil
 }
 
 // Extract the view from a PreparedCertificate that has already been verified.
 func (c *core) getViewFromVerifiedPreparedCertificate(preparedCertificate istanbul.PreparedCertificate) (*istanbul.View, error) {
-	if len(preparedCertificate.PrepareOrCommitMessages) < c.current.ValidatorSet().MinQuorumSize() {
+	signers := make([]common.Address, 0, len(preparedCertificate.PrepareOrCommitMessages))
+	for _, message := range preparedCertificate.PrepareOrCommitMessages {
+		signers = append(signers, message.Address)
+	}
+	if len(signers) == 0 || !c.current.ValidatorSet().HasQuorum(signers) {
 		return nil, errInvalidPreparedCertificateNumMsgs
 	}
 
@@ -161,25 +175,23 @@ func (c *core) handlePrepare(msg *istanbul.Message) error {
 	}
 
 	preparesAndCommits := c.current.GetPrepareOrCommitSize()
-	minQuorumSize := c.current.ValidatorSet().MinQuorumSize()
+	quorum, certificateSize := c.prepareOrCommitQuorum()
 	logger = logger.New("prepares_and_commits", preparesAndCommits, "commits", c.current.Commits().Size(), "prepares", c.current.Prepares().Size())
 	logger.Trace("Accepted prepare")
 
 	// Change to Prepared state if we've received enough PREPARE messages and we are in earlier state
 	// before Prepared state.
 	// TODO(joshua): Remove state comparisons (or change the cmp function)
-	if (preparesAndCommits >= minQuorumSize) && c.current.State().Cmp(StatePrepared) < 0 {
+	if quorum && c.current.State().Cmp(StatePrepared) < 0 {
 
-		err := c.current.TransitionToPrepared(minQuorumSize)
+		err := c.current.TransitionToPrepared(certificateSize)
 		if err != nil {
 			logger.Error("Failed to create and set preprared certificate", "err", err)
 			return err
 		}
 		logger.Trace("Got quorum prepares or commits", "tag", "stateTransition")
 		// Update metrics.
-		if !c.consensusTimestamp.IsZero() {
-			c.consensusPrepareTimeGauge.Update(time.Since(c.consensusTimestamp).Nanoseconds())
-		}
+		c.recordPrepared()
 
 		// Process Backlog Messages
 		c.backlog.updateState(c.current.View(), c.current.State())
@@ -190,6 +202,21 @@ func (c *core) handlePrepare(msg *istanbul.Message) error {
 	return nil
 }
 
+// prepareOrCommitQuorum reports whether the validators that sent a PREPARE or COMMIT hold a
+// quorum, and how many of the messages make up the prepared certificate. With weighted
+// validators it takes all of them, so that the certificate holds the quorum's voting power.
+func (c *core) prepareOrCommitQuorum() (bool, int) {
+	valSet := c.current.ValidatorSet()
+	addrs := c.current.GetPrepareOrCommitAddresses()
+	if !valSet.HasQuorum(addrs) {
+		return false, 0
+	}
+	if valSet.Weighted() {
+		return true, len(addrs)
+	}
+	return true, valSet.MinQuorumSize()
+}
+
 // verifyPrepare verifies if the received PREPARE message is equivalent to our subject
 func (c *core) verifyPrepare(prepare *istanbul.Subject) error {
 	logger := c.newLogger("func", "verifyPrepare", "prepare_round", prepare.View.Round, "prepare_seq", prepare.View.Sequence, "prepare_digest", prepare.Digest.String())
@@ -124,7 +124,7 @@ func (c *core) handlePreprepare(msg *istanbul.Message) error {
 
 	if c.current.State() == StateAcceptRequest {
 		logger.Trace("Accepted preprepare", "tag", "stateTransition")
-		c.consensusTimestamp = time.Now()
+		c.recordPreprepared()
 
 		err := c.current.TransitionToPreprepared(preprepare)
 		if err != nil {
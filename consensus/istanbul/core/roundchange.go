@@ -52,7 +52,8 @@ func (c *core) buildRoundChangeMsg(round *big.Int) *istanbul.Message {
 func (c *core) handleRoundChangeCertificate(proposal istanbul.Subject, roundChangeCertificate istanbul.RoundChangeCertificate) error {
 	logger := c.newLogger("func", "handleRoundChangeCertificate", "proposal_round", proposal.View.Round, "proposal_seq", proposal.View.Sequence, "proposal_digest", proposal.Digest.String())
 
-	if len(roundChangeCertificate.RoundChangeMessages) > c.current.ValidatorSet().Size() || len(roundChangeCertificate.RoundChangeMessages) < c.current.ValidatorSet().MinQuorumSize() {
+	valSet := c.current.ValidatorSet()
+	if len(roundChangeCertificate.RoundChangeMessages) > valSet.Size() || (!valSet.Weighted() && len(roundChangeCertificate.RoundChangeMessages) < valSet.MinQuorumSize()) {
 		return errInvalidRoundChangeCertificateNumMsgs
 	}
 
@@ -132,12 +133,26 @@ func (c *core) handleRoundChangeCertificate(proposal istanbul.Subject, roundChan
 		c.roundChangeSet.Add(roundChange.View.Round, &message)
 	}
 
+	// With weighted validators the signers must hold a quorum of the voting power
+	if valSet.Weighted() {
+		signers := make([]common.Address, 0, len(seen))
+		for signer := range seen {
+			signers = append(signers, signer)
+		}
+		if !valSet.HasQuorum(signers) {
+			return errInvalidRoundChangeCertificateNumMsgs
+		}
+	}
+
 	if maxRound.Cmp(big.NewInt(-1)) > 0 && proposal.Digest != preferredDigest {
 		return errInvalidPreparedCertificateDigestMismatch
 	}
 
 	// May have already moved to this round based on quorum round change messages.
 	logger.Trace("Trying to move to round change certificate's round", "target round", proposal.View.Round)
+	if proposal.View.Round.Cmp(c.current.Round()) > 0 {
+		c.recordRoundChange(roundChangeCauseCertificate, proposal.View.Round)
+	}
 
 	return c.startNewRound(proposal.View.Round)
 }
@@ -182,17 +197,30 @@ func (c *core) handleRoundChange(msg *istanbul.Message) error {
 
 	// Skip to the highest round we know F+1 (one honest validator) is at, but
 	// don't start a round until we have a quorum who want to start a given round.
-	ffRound := c.roundChangeSet.MaxRound(c.current.ValidatorSet().F() + 1)
-	quorumRound := c.roundChangeSet.MaxOnOneRound(c.current.ValidatorSet().MinQuorumSize())
+	// With weighted validators F+1 and the quorum are measured in voting power.
+	var ffRound, quorumRound *big.Int
+	if valSet := c.current.ValidatorSet(); valSet.Weighted() {
+		ffRound = c.roundChangeSet.MaxRoundWeight(valSet, fPlusOneWeight(valSet))
+		quorumRound = c.roundChangeSet.MaxOnOneRoundQuorum(valSet)
+	} else {
+		ffRound = c.roundChangeSet.MaxRound(valSet.F() + 1)
+		quorumRound = c.roundChangeSet.MaxOnOneRound(valSet.MinQuorumSize())
+	}
 	logger = logger.New("ffRound", ffRound, "quorumRound", quorumRound)
 	logger.Trace("Got round change message", "rcs", c.roundChangeSet.String())
 	// On f+1 round changes we send a round change and wait for the next round if we haven't done so already
 	// On quorum round change messages we go to the next round immediately.
 	if quorumRound != nil && quorumRound.Cmp(c.current.DesiredRound()) >= 0 {
 		logger.Debug("Got quorum round change messages, starting new round.")
+		if quorumRound.Cmp(c.current.Round()) > 0 {
+			c.recordRoundChange(roundChangeCauseQuorum, quorumRound)
+		}
 		return c.startNewRound(quorumRound)
 	} else if ffRound != nil {
 		logger.Debug("Got f+1 round change messages, sending own round change message and waiting for next round.")
+		if ffRound.Cmp(c.current.DesiredRound()) > 0 {
+			c.recordRoundChange(roundChangeCauseFPlusOne, ffRound)
+		}
 		c.waitForDesiredRound(ffRound)
 	}
 
@@ -288,6 +316,67 @@ func (rcs *roundChangeSet) MaxRound(num int) *big.Int {
 	return nil
 }
 
+// fPlusOneWeight returns the voting power that holds at least one honest validator as long
+// as the faulty ones hold less than 1/3 of it, ceil(total / 3). With all weights 1 it is F+1.
+func fPlusOneWeight(valSet istanbul.ValidatorSet) *big.Int {
+	weight := new(big.Int).Add(valSet.TotalWeight(), big.NewInt(2))
+	return weight.Div(weight, big.NewInt(3))
+}
+
+// votingPower returns the voting power of the validators among addrs.
+func votingPower(valSet istanbul.ValidatorSet, addrs []common.Address) *big.Int {
+	weights, power := valSet.Weights(), new(big.Int)
+	for _, addr := range addrs {
+		if i := valSet.GetIndex(addr); i >= 0 && i < len(weights) {
+			power.Add(power, weights[i])
+		}
+	}
+	return power
+}
+
+// MaxRoundWeight returns the max round which the messages of that round or later hold at
+// least weight of the voting power
+func (rcs *roundChangeSet) MaxRoundWeight(valSet istanbul.ValidatorSet, weight *big.Int) *big.Int {
+	rcs.mu.Lock()
+	defer rcs.mu.Unlock()
+
+	// Sort rounds descending
+	var sortedRounds []uint64
+	for r := range rcs.msgsForRound {
+		sortedRounds = append(sortedRounds, r)
+	}
+	sort.Slice(sortedRounds, func(i, j int) bool { return sortedRounds[i] > sortedRounds[j] })
+
+	var addrs []common.Address
+	for _, r := range sortedRounds {
+		addrs = append(addrs, rcs.msgsForRound[r].Addresses()...)
+		if votingPower(valSet, addrs).Cmp(weight) >= 0 {
+			return new(big.Int).SetUint64(r)
+		}
+	}
+	return nil
+}
+
+// MaxOnOneRoundQuorum returns the max round whose messages hold a quorum of the voting power
+func (rcs *roundChangeSet) MaxOnOneRoundQuorum(valSet istanbul.ValidatorSet) *big.Int {
+	rcs.mu.Lock()
+	defer rcs.mu.Unlock()
+
+	// Sort rounds descending
+	var sortedRounds []uint64
+	for r := range rcs.msgsForRound {
+		sortedRounds = append(sortedRounds, r)
+	}
+	sort.Slice(sortedRounds, func(i, j int) bool { return sortedRounds[i] > sortedRounds[j] })
+
+	for _, r := range sortedRounds {
+		if valSet.HasQuorum(rcs.msgsForRound[r].Addresses()) {
+			return new(big.Int).SetUint64(r)
+		}
+	}
+	return nil
+}
+
 // MaxOnOneRound returns the max round which the number of messages is >= num
 func (rcs *roundChangeSet) MaxOnOneRound(num int) *big.Int {
 	rcs.mu.Lock()
@@ -373,3 +462,40 @@ func (rcs *roundChangeSet) getCertificate(minRound *big.Int, quorumSize int) (is
 	// Didn't find a quorum of messages. Return an empty certificate with error.
 	return istanbul.RoundChangeCertificate{}, errFailedCreateRoundChangeCertificate
 }
+
+// Gets a round change certificate for a specific round from weighted validators. Includes the
+// highest-round messages of that round or later until their senders hold a quorum of the
+// voting power. If they never do, returns an empty cert and errFailedCreateRoundChangeCertificate.
+func (rcs *roundChangeSet) getWeightedCertificate(minRound *big.Int, valSet istanbul.ValidatorSet) (istanbul.RoundChangeCertificate, error) {
+	rcs.mu.Lock()
+	defer rcs.mu.Unlock()
+
+	// Sort rounds descending
+	var sortedRounds []uint64
+	for r := range rcs.msgsForRound {
+		sortedRounds = append(sortedRounds, r)
+	}
+	sort.Slice(sortedRounds, func(i, j int) bool { return sortedRounds[i] > sortedRounds[j] })
+
+	var messages []istanbul.Message
+	var addrs []common.Address
+	for _, r := range sortedRounds {
+		if r < minRound.Uint64() {
+			break
+		}
+		for _, message := range rcs.msgsForRound[r].Values() {
+			messages = append(messages, *message)
+			addrs = append(addrs, message.Address)
+
+			// Stop when the highest-round messages hold a quorum.
+			if valSet.HasQuorum(addrs) {
+				return istanbul.RoundChangeCertificate{
+					RoundChangeMessages: messages,
+				}, nil
+			}
+		}
+	}
+
+	// Didn't find a quorum of messages. Return an empty certificate with error.
+	return istanbul.RoundChangeCertificate{}, errFailedCreateRoundChangeCertificate
+}
@@ -633,3 +633,49 @@ loop2:
 	}
 	close(sys.quit)
 }
+
+func TestWeightedRoundChangeSet(t *testing.T) {
+	vals, _, _ := generateValidators(4)
+	vset := validator.NewSet(vals)
+	if !vset.SetWeights([]*big.Int{big.NewInt(1), big.NewInt(1), big.NewInt(1), big.NewInt(3)}) {
+		t.Fatal("failed to set the weights")
+	}
+	rc := newRoundChangeSet(vset)
+	list := vset.List()
+	add := func(round int64, v istanbul.Validator) {
+		view := &istanbul.View{Sequence: big.NewInt(1), Round: big.NewInt(round)}
+		rc.Add(view.Round, istanbul.NewPrepareMessage(&istanbul.Subject{View: view}, v.Address()))
+	}
+
+	// three of four validators hold half of the voting power
+	for _, v := range list[:3] {
+		add(2, v)
+	}
+	if r := rc.MaxOnOneRound(vset.MinQuorumSize()); r == nil || r.Uint64() != 2 {
+		t.Errorf("headcount quorum round: have %v, want 2", r)
+	}
+	if r := rc.MaxOnOneRoundQuorum(vset); r != nil {
+		t.Errorf("weighted quorum round: have %v, want nil", r)
+	}
+	if r := rc.MaxRoundWeight(vset, fPlusOneWeight(vset)); r == nil || r.Uint64() != 2 {
+		t.Errorf("weighted f+1 round: have %v, want 2", r)
+	}
+	if _, err := rc.getWeightedCertificate(big.NewInt(2), vset); err != errFailedCreateRoundChangeCertificate {
+		t.Errorf("weighted certificate: have %v, want %v", err, errFailedCreateRoundChangeCertificate)
+	}
+
+	add(1, list[3])
+	cert, err := rc.getWeightedCertificate(big.NewInt(1), vset)
+	if err != nil || len(cert.RoundChangeMessages) != 4 {
+		t.Errorf("weighted certificate: have %d messages, %v, want 4", len(cert.RoundChangeMessages), err)
+	}
+
+	// the heaviest validator alone holds f+1 of the voting power
+	add(3, list[3])
+	if r := rc.MaxRound(vset.F() + 1); r == nil || r.Uint64() != 2 {
+		t.Errorf("headcount f+1 round: have %v, want 2", r)
+	}
+	if r := rc.MaxRoundWeight(vset, fPlusOneWeight(vset)); r == nil || r.Uint64() != 3 {
+		t.Errorf("weighted f+1 round: have %v, want 3", r)
+	}
+}
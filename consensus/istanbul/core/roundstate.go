@@ -58,6 +58,7 @@ type RoundState interface {
 	DesiredRound() *big.Int
 	State() State
 	GetPrepareOrCommitSize() int
+	GetPrepareOrCommitAddresses() []common.Address
 	GetValidatorByAddress(address common.Address) istanbul.Validator
 	ValidatorSet() istanbul.ValidatorSet
 	Proposer() istanbul.Validator
@@ -199,6 +200,20 @@ func (rs *roundStateImpl) GetPrepareOrCommitSize() int {
 	return result
 }
 
+// GetPrepareOrCommitAddresses returns the validators that sent a PREPARE or a COMMIT message.
+func (rs *roundStateImpl) GetPrepareOrCommitAddresses() []common.Address {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	addrs := rs.commits.Addresses()
+	for _, addr := range rs.prepares.Addresses() {
+		if rs.commits.Get(addr) == nil {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
 func (rs *roundStateImpl) Subject() *istanbul.Subject {
 	rs.mu.RLock()
 	defer rs.mu.RUnlock()
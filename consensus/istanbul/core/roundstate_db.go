@@ -47,6 +47,8 @@ type RoundStateDB interface {
 	// it might or might not be present on the db
 	GetOldestValidView() (*istanbul.View, error)
 	GetRoundStateFor(view *istanbul.View) (RoundState, error)
+	// GetRoundStates returns the round states stored for sequences fromSeq to toSeq included, sorted by view
+	GetRoundStates(fromSeq, toSeq uint64) ([]RoundState, error)
 	UpdateLastRoundState(rs RoundState) error
 	Close() error
 }
@@ -116,6 +118,29 @@ func newRoundStateDB(path string, opts *RoundStateDBOptions) (RoundStateDB, erro
 	return rsdb, nil
 }
 
+// openRoundStateDBReadOnly opens an existing roundstate db for reading, without
+// flushing it on a version mismatch nor running the garbage collector.
+func openRoundStateDBReadOnly(path string) (RoundStateDB, error) {
+	logger := log.New("func", "openRoundStateDBReadOnly", "type", "roundStateDB", "rsdb_path", path)
+
+	db, err := leveldb.OpenFile(path, &opt.Options{ReadOnly: true, ErrorIfMissing: true})
+	if err != nil {
+		return nil, err
+	}
+	currentVer := make([]byte, binary.MaxVarintLen64)
+	currentVer = currentVer[:binary.PutVarint(currentVer, int64(dbVersion))]
+	if blob, err := db.Get([]byte(dbVersionKey), nil); err != nil || !bytes.Equal(blob, currentVer) {
+		db.Close()
+		return nil, errRoundStateDBVersion
+	}
+
+	return &roundStateDBImpl{
+		db:     db,
+		opts:   RoundStateDBOptions{withGarbageCollector: false},
+		logger: logger,
+	}, nil
+}
+
 // newMemoryDB creates a new in-memory node database without a persistent backend.
 func newMemoryDB() (*leveldb.DB, error) {
 	db, err := leveldb.Open(storage.NewMemStorage(), nil)
@@ -229,6 +254,27 @@ func (rsdb *roundStateDBImpl) GetRoundStateFor(view *istanbul.View) (RoundState,
 	return &entry, nil
 }
 
+func (rsdb *roundStateDBImpl) GetRoundStates(fromSeq, toSeq uint64) ([]RoundState, error) {
+	fromViewKey := view2Key(&istanbul.View{Sequence: new(big.Int).SetUint64(fromSeq), Round: common.Big0})
+	limit := util.BytesPrefix([]byte(rsKey)).Limit
+	if toSeq < ^uint64(0) {
+		limit = view2Key(&istanbul.View{Sequence: new(big.Int).SetUint64(toSeq + 1), Round: common.Big0})
+	}
+
+	iter := rsdb.db.NewIterator(&util.Range{Start: fromViewKey, Limit: limit}, nil)
+	defer iter.Release()
+
+	var roundStates []RoundState
+	for iter.Next() {
+		var entry roundStateImpl
+		if err := rlp.DecodeBytes(iter.Value(), &entry); err != nil {
+			return nil, err
+		}
+		roundStates = append(roundStates, &entry)
+	}
+	return roundStates, iter.Error()
+}
+
 func (rsdb *roundStateDBImpl) Close() error {
 	if rsdb.opts.withGarbageCollector {
 		rsdb.stopGarbageCollector()
@@ -129,6 +129,11 @@ func (rsp *rsSaveDecorator) ValidatorSet() istanbul.ValidatorSet { return rsp.rs
 // GetPrepareOrCommitSize implements RoundState.GetPrepareOrCommitSize
 func (rsp *rsSaveDecorator) GetPrepareOrCommitSize() int { return rsp.rs.GetPrepareOrCommitSize() }
 
+// GetPrepareOrCommitAddresses implements RoundState.GetPrepareOrCommitAddresses
+func (rsp *rsSaveDecorator) GetPrepareOrCommitAddresses() []common.Address {
+	return rsp.rs.GetPrepareOrCommitAddresses()
+}
+
 // GetValidatorByAddress implements RoundState.GetValidatorByAddress
 func (rsp *rsSaveDecorator) GetValidatorByAddress(address common.Address) istanbul.Validator {
 	return rsp.rs.GetValidatorByAddress(address)
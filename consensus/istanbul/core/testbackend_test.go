@@ -59,6 +59,7 @@ type testSystemBackend struct {
 
 	committedMsgs []testCommittedMsgs
 	sentMsgs      [][]byte // store the message when Send is called by core
+	evidences     []*istanbul.Evidence
 
 	key     ecdsa.PrivateKey
 	blsKey  []byte
@@ -277,6 +278,10 @@ func (self *testSystemBackend) ParentBlockValidators(proposal istanbul.Proposal)
 
 func (self *testSystemBackend) UpdateReplicaState(seq *big.Int) { /* pass */ }
 
+func (self *testSystemBackend) ReportEquivocation(ev *istanbul.Evidence) {
+	self.evidences = append(self.evidences, ev)
+}
+
 func (self *testSystemBackend) finalizeAndReturnMessage(msg *istanbul.Message) (istanbul.Message, error) {
 	message := new(istanbul.Message)
 	data, err := self.engine.(*core).finalizeMessage(msg)
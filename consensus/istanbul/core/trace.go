@@ -0,0 +1,240 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/metrics"
+)
+
+// Round change causes
+const (
+	roundChangeCauseTimeout       = "timeout"        // The round change timer fired
+	roundChangeCauseFPlusOne      = "f_plus_one"     // F+1 validators asked for a later round
+	roundChangeCauseQuorum        = "quorum"         // A quorum of validators asked for a later round
+	roundChangeCauseCertificate   = "certificate"    // A preprepare came with a round change certificate for a later round
+	roundChangeCauseCommitFailure = "commit_failure" // Committing the proposal failed
+)
+
+// Trace events
+const (
+	traceRoundStarted = "round_started"
+	traceMessage      = "message"
+	tracePreprepared  = "preprepared"
+	tracePrepared     = "prepared"
+	traceCommitted    = "committed"
+	traceRoundChange  = "round_change"
+)
+
+// TraceEvent is a line of the consensus trace file
+type TraceEvent struct {
+	Time     time.Time       `json:"time"`
+	Event    string          `json:"event"`
+	Sequence *big.Int        `json:"seq"`
+	Round    *big.Int        `json:"round"`
+	Code     string          `json:"code,omitempty"`    // Code of the received message
+	From     *common.Address `json:"from,omitempty"`    // Sender of the received message
+	Elapsed  time.Duration   `json:"elapsed,omitempty"` // Time since the start of the round for messages, or of the previous phase for phases, in nanoseconds
+	Cause    string          `json:"cause,omitempty"`   // Cause of the round change
+	Target   *big.Int        `json:"target,omitempty"`  // Round the round change moves to
+	Backlog  int             `json:"backlog,omitempty"` // Number of future messages in the backlog
+}
+
+// consensusTrace writes the consensus events of a node as JSON lines, one per event,
+// so that the consensus timing of each block can be plotted.
+// Writing is thread safe, and a nil trace drops all events.
+type consensusTrace struct {
+	enc    *json.Encoder
+	closer io.Closer // Closed with the trace, if the writer can be closed
+	failed bool      // Set by the first failed write, the events are dropped from then on
+	mu     sync.Mutex
+}
+
+// newConsensusTrace creates a consensus trace that writes to the supplied writer
+func newConsensusTrace(w io.Writer) *consensusTrace {
+	t := &consensusTrace{enc: json.NewEncoder(w)}
+	if closer, ok := w.(io.Closer); ok {
+		t.closer = closer
+	}
+	return t
+}
+
+// openConsensusTrace creates a consensus trace appending to the file at path, or returns
+// nil if the file can't be opened
+func openConsensusTrace(path string) *consensusTrace {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Error("Failed to open consensus trace file", "path", path, "err", err)
+		return nil
+	}
+	return newConsensusTrace(f)
+}
+
+// Write writes out the event as a JSON line. Tracing stops at the first failed write.
+func (t *consensusTrace) Write(ev *TraceEvent) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.failed {
+		return
+	}
+	if err := t.enc.Encode(ev); err != nil {
+		t.failed = true
+		log.Error("Failed to write consensus trace, tracing disabled", "err", err)
+	}
+}
+
+// Close stops the trace and closes its writer
+func (t *consensusTrace) Close() error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.failed = true
+	if t.closer == nil {
+		return nil
+	}
+	return t.closer.Close()
+}
+
+func msgCodeName(code uint64) string {
+	switch code {
+	case istanbul.MsgPreprepare:
+		return "preprepare"
+	case istanbul.MsgPrepare:
+		return "prepare"
+	case istanbul.MsgCommit:
+		return "commit"
+	case istanbul.MsgRoundChange:
+		return "round_change"
+	default:
+		return "unknown"
+	}
+}
+
+// newTraceEvent returns a trace event for the current view
+func (c *core) newTraceEvent(event string) *TraceEvent {
+	return &TraceEvent{
+		Time:     time.Now(),
+		Event:    event,
+		Sequence: c.current.Sequence(),
+		Round:    c.current.Round(),
+	}
+}
+
+// recordRoundStarted marks the start of the current round, from which the phase
+// latencies and the lateness of messages are measured
+func (c *core) recordRoundStarted() {
+	c.roundStartTimestamp = time.Now()
+	c.preparedTimestamp = time.Time{}
+
+	ev := c.newTraceEvent(traceRoundStarted)
+	ev.Backlog = c.backlog.size()
+	c.trace.Write(ev)
+}
+
+// recordMessage records how late a message for the current view arrived after the start
+// of the round, per index of the sender in the validator set, so that the number of timers
+// is bounded by the size of the set
+func (c *core) recordMessage(msg *istanbul.Message) {
+	if c.roundStartTimestamp.IsZero() {
+		return
+	}
+	if msg.Code != istanbul.MsgPreprepare && msg.Code != istanbul.MsgPrepare && msg.Code != istanbul.MsgCommit {
+		return
+	}
+	if view := extractMessageView(msg); view.Cmp(c.current.View()) != 0 {
+		return
+	}
+
+	lateness := time.Since(c.roundStartTimestamp)
+	if i, _ := c.current.ValidatorSet().GetByAddress(msg.Address); i >= 0 {
+		metrics.GetOrRegisterTimer("consensus/istanbul/core/lateness/"+strconv.Itoa(i), nil).Update(lateness)
+	}
+
+	ev := c.newTraceEvent(traceMessage)
+	ev.Code = msgCodeName(msg.Code)
+	ev.From = &msg.Address
+	ev.Elapsed = lateness
+	c.trace.Write(ev)
+}
+
+// recordPreprepared records the time from the start of the round to accepting its preprepare
+func (c *core) recordPreprepared() {
+	c.consensusTimestamp = time.Now()
+
+	ev := c.newTraceEvent(tracePreprepared)
+	if !c.roundStartTimestamp.IsZero() {
+		ev.Elapsed = time.Since(c.roundStartTimestamp)
+		c.preprepareTimer.Update(ev.Elapsed)
+	}
+	c.trace.Write(ev)
+}
+
+// recordPrepared records the time from accepting the preprepare to getting a quorum of
+// prepares or commits
+func (c *core) recordPrepared() {
+	c.preparedTimestamp = time.Now()
+
+	ev := c.newTraceEvent(tracePrepared)
+	if !c.consensusTimestamp.IsZero() {
+		ev.Elapsed = time.Since(c.consensusTimestamp)
+		c.consensusPrepareTimeGauge.Update(ev.Elapsed.Nanoseconds())
+		c.prepareTimer.Update(ev.Elapsed)
+	}
+	c.trace.Write(ev)
+}
+
+// recordCommitted records the time from getting prepared to getting a quorum of commits.
+// A node that got the quorum of commits before being prepared only records the time from
+// accepting the preprepare.
+func (c *core) recordCommitted() {
+	ev := c.newTraceEvent(traceCommitted)
+	if !c.preparedTimestamp.IsZero() {
+		ev.Elapsed = time.Since(c.preparedTimestamp)
+		c.commitTimer.Update(ev.Elapsed)
+	}
+	if !c.consensusTimestamp.IsZero() {
+		c.consensusCommitTimeGauge.Update(time.Since(c.consensusTimestamp).Nanoseconds())
+		c.consensusTimestamp = time.Time{}
+	}
+	ev.Backlog = c.backlog.size()
+	c.trace.Write(ev)
+}
+
+// recordRoundChange records the cause of moving from the current round to round
+func (c *core) recordRoundChange(cause string, round *big.Int) {
+	metrics.GetOrRegisterMeter("consensus/istanbul/core/roundchange/"+cause, nil).Mark(1)
+
+	ev := c.newTraceEvent(traceRoundChange)
+	ev.Cause = cause
+	ev.Target = new(big.Int).Set(round)
+	c.trace.Write(ev)
+}
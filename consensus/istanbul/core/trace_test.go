@@ -0,0 +1,131 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+)
+
+func TestConsensusTrace(t *testing.T) {
+	sys := NewTestSystemWithBackend(4, 1)
+	v0 := sys.backends[0]
+	v1 := sys.backends[1]
+	c := v0.engine.(*core)
+	c.current = newTestRoundState(newView(1, 0), v0.peers)
+
+	var buf bytes.Buffer
+	c.trace = newConsensusTrace(&buf)
+
+	handlePrepare := func(view *istanbul.View) {
+		msg, err := v1.getPrepareMessage(*view, common.HexToHash("0x01"))
+		finishOnError(t, err)
+		payload, err := msg.Payload()
+		finishOnError(t, err)
+		c.handleMsg(payload)
+	}
+
+	c.recordRoundStarted()
+	handlePrepare(newView(1, 0))
+	// Messages for other views are not traced
+	handlePrepare(newView(1, 1))
+	handlePrepare(newView(2, 0))
+	c.recordPreprepared()
+	c.recordPrepared()
+	c.recordCommitted()
+	c.recordRoundChange(roundChangeCauseTimeout, big.NewInt(1))
+
+	var events []TraceEvent
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var ev TraceEvent
+		finishOnError(t, dec.Decode(&ev))
+		events = append(events, ev)
+	}
+
+	want := []string{traceRoundStarted, traceMessage, tracePreprepared, tracePrepared, traceCommitted, traceRoundChange}
+	if len(events) != len(want) {
+		t.Fatalf("trace events count mismatch: have %d, want %d: %v", len(events), len(want), events)
+	}
+	for i, ev := range events {
+		if ev.Event != want[i] {
+			t.Errorf("trace event %d mismatch: have %s, want %s", i, ev.Event, want[i])
+		}
+		if ev.Sequence.Cmp(big.NewInt(1)) != 0 || ev.Round.Sign() != 0 {
+			t.Errorf("trace event %d view mismatch: have seq %v round %v", i, ev.Sequence, ev.Round)
+		}
+	}
+
+	if msg := events[1]; msg.Code != "prepare" || msg.From == nil || *msg.From != v1.address || msg.Elapsed <= 0 {
+		t.Errorf("message trace event mismatch: have %+v", msg)
+	}
+	if events[2].Elapsed <= 0 || events[3].Elapsed <= 0 || events[4].Elapsed <= 0 {
+		t.Errorf("phase latencies not traced: preprepared %v, prepared %v, committed %v", events[2].Elapsed, events[3].Elapsed, events[4].Elapsed)
+	}
+	// Without a preprepare all the prepares are in the backlog
+	if events[4].Backlog != 3 {
+		t.Errorf("backlog size mismatch: have %d, want 3", events[4].Backlog)
+	}
+	if rc := events[5]; rc.Cause != roundChangeCauseTimeout || rc.Target.Cmp(big.NewInt(1)) != 0 {
+		t.Errorf("round change trace event mismatch: have %+v", rc)
+	}
+
+	// A nil trace drops the events
+	c.trace = nil
+	c.recordRoundStarted()
+	if buf.Len() != 0 {
+		t.Errorf("events written without a trace: %s", buf.String())
+	}
+}
+
+// failingWriter fails every write and records whether it was closed
+type failingWriter struct {
+	writes int
+	closed bool
+}
+
+func (w *failingWriter) Write([]byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+func (w *failingWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestConsensusTraceFailure(t *testing.T) {
+	w := new(failingWriter)
+	trace := newConsensusTrace(w)
+	ev := &TraceEvent{Event: traceRoundStarted, Sequence: big.NewInt(1), Round: big.NewInt(0)}
+
+	// The trace is disabled after the first failed write
+	trace.Write(ev)
+	trace.Write(ev)
+	if w.writes != 1 {
+		t.Errorf("writes mismatch: have %d, want 1", w.writes)
+	}
+	if err := trace.Close(); err != nil || !w.closed {
+		t.Errorf("writer not closed: %v", err)
+	}
+}
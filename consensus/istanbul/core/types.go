@@ -34,6 +34,10 @@ type Engine interface {
 	ParentCommits() MessageSet
 	// ForceRoundChange will force round change to the current desiredRound + 1
 	ForceRoundChange()
+	// Evidences returns the equivocation evidences seen for sequences fromSeq to toSeq included
+	Evidences(fromSeq, toSeq uint64) ([]*EvidenceRecord, error)
+	// MarkEvidenceSubmitted records the transaction submitting an evidence for slashing
+	MarkEvidenceSubmitted(ev *istanbul.Evidence, tx common.Hash) error
 }
 
 // State represents the IBFT state
@@ -0,0 +1,322 @@
+// Package epochproof proves the validator set transitions of Atlas to light clients,
+// which then follow the validator set from a trusted epoch without every block header.
+package epochproof
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	istanbulCore "github.com/mapprotocol/atlas/consensus/istanbul/core"
+	"github.com/mapprotocol/atlas/core/types"
+	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+)
+
+var (
+	errUnexpectedEpoch      = errors.New("proof is not for the next epoch")
+	errNotEpochBlock        = errors.New("header is not the last block of its epoch")
+	errInsufficientSeals    = errors.New("not enough validators signed")
+	errValidatorSetMismatch = errors.New("validators of the proof do not match the header")
+	errInvalidValidatorDiff = errors.New("invalid validator set diff")
+	errInvalidWeights       = errors.New("invalid validator weights")
+)
+
+// EpochSeal is the aggregated signature of the validators over the validator set of
+// the next epoch, the IstanbulEpochValidatorSetSeal of the last block of an epoch.
+type EpochSeal struct {
+	Bitmap    *hexutil.Big  `json:"bitmap"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+// Proof proves the validator set change at the last block of an epoch. The header is
+// signed by the validators of the epoch, who also sign the validator set of the next
+// epoch in the epoch seal.
+type Proof struct {
+	Epoch                hexutil.Uint64           `json:"epoch"`
+	Header               *types.Header            `json:"header"`
+	EpochSeal            EpochSeal                `json:"epochValidatorSetSeal"`
+	AggregatedSealBitmap *hexutil.Big             `json:"aggregatedSealBitmap"`
+	AddedValidators      []istanbul.ValidatorData `json:"addedValidators"`
+	RemovedValidators    []istanbul.ValidatorData `json:"removedValidators"`
+}
+
+// NewProof builds the proof of the last block of an epoch from the validators that
+// signed it.
+func NewProof(header *types.Header, seal *types.EpochSnarkData, validators []istanbul.ValidatorData, epochSize uint64) (*Proof, error) {
+	number := header.Number.Uint64()
+	if number == 0 || !istanbul.IsLastBlockOfEpoch(number, epochSize) {
+		return nil, errNotEpochBlock
+	}
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return nil, err
+	}
+	added, err := istanbul.CombineIstanbulExtraToValidatorData(extra.AddedValidators, extra.AddedValidatorsPublicKeys, extra.AddedValidatorsG1PublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	removedBitmap := bitmapOrZero(extra.RemovedValidators)
+	if removedBitmap.BitLen() > len(validators) {
+		return nil, errInvalidValidatorDiff
+	}
+	removed := make([]istanbul.ValidatorData, 0)
+	for i, v := range validators {
+		if removedBitmap.Bit(i) == 1 {
+			removed = append(removed, v)
+		}
+	}
+	if seal == nil || seal.Bitmap == nil {
+		seal = &types.EpochSnarkData{Bitmap: new(big.Int)}
+	}
+	return &Proof{
+		Epoch:                hexutil.Uint64(istanbul.GetEpochNumber(number, epochSize)),
+		Header:               header,
+		EpochSeal:            EpochSeal{Bitmap: (*hexutil.Big)(seal.Bitmap), Signature: seal.Signature},
+		AggregatedSealBitmap: (*hexutil.Big)(extra.AggregatedSeal.Bitmap),
+		AddedValidators:      added,
+		RemovedValidators:    removed,
+	}, nil
+}
+
+// Verifier follows the validator set of Atlas from a trusted epoch by verifying the
+// proofs of the following epochs one after another.
+type Verifier struct {
+	epochSize      uint64
+	bn256ForkBlock *big.Int
+
+	epoch      uint64                   // last verified epoch
+	hash       common.Hash              // hash of the last block of the epoch
+	validators []istanbul.ValidatorData // validators of the next epoch
+	weights    []*big.Int               // their voting power, nil if one vote each
+}
+
+// NewVerifier creates a verifier trusting the last block of epoch, with the given hash,
+// and the validators it elected. The genesis block is the last block of epoch 0, the
+// fork block is the BN256ForkBlock of the Atlas chain config.
+func NewVerifier(epochSize uint64, bn256ForkBlock *big.Int, epoch uint64, hash common.Hash, validators []istanbul.ValidatorData) *Verifier {
+	return &Verifier{
+		epochSize:      epochSize,
+		bn256ForkBlock: bn256ForkBlock,
+		epoch:          epoch,
+		hash:           hash,
+		validators:     append([]istanbul.ValidatorData(nil), validators...),
+	}
+}
+
+// Epoch returns the last verified epoch and the hash of its last block.
+func (v *Verifier) Epoch() (uint64, common.Hash) {
+	return v.epoch, v.hash
+}
+
+// Validators returns the validators of the epoch after the last verified one.
+func (v *Verifier) Validators() []istanbul.ValidatorData {
+	return append([]istanbul.ValidatorData(nil), v.validators...)
+}
+
+// Weights returns the voting power of the validators, nil if each counts as one vote.
+func (v *Verifier) Weights() []*big.Int {
+	return append([]*big.Int(nil), v.weights...)
+}
+
+// SetWeights sets the voting power of the trusted validators, for a trusted epoch after
+// the weighted BFT fork.
+func (v *Verifier) SetWeights(weights []*big.Int) error {
+	if weights == nil {
+		v.weights = nil
+		return nil
+	}
+	if !validWeights(weights, len(v.validators)) {
+		return errInvalidWeights
+	}
+	v.weights = append([]*big.Int(nil), weights...)
+	return nil
+}
+
+// VerifyChain verifies consecutive proofs, the verifier moves to the last valid one.
+func (v *Verifier) VerifyChain(proofs []*Proof) error {
+	for _, p := range proofs {
+		if err := v.Verify(p); err != nil {
+			return fmt.Errorf("epoch %d: %w", p.Epoch, err)
+		}
+	}
+	return nil
+}
+
+// Verify checks the proof of the epoch after the last verified one and moves the
+// verifier to the validator set it elects.
+func (v *Verifier) Verify(proof *Proof) error {
+	header := proof.Header
+	if header == nil || header.Number == nil {
+		return errNotEpochBlock
+	}
+	number := header.Number.Uint64()
+	if uint64(proof.Epoch) != v.epoch+1 || number != (v.epoch+1)*v.epochSize {
+		return errUnexpectedEpoch
+	}
+	extra, err := types.ExtractIstanbulExtra(header)
+	if err != nil {
+		return err
+	}
+	fork, cur := v.bn256ForkBlock, header.Number
+
+	// the header is signed by the current validators
+	seal := extra.AggregatedSeal
+	if seal.Bitmap == nil || seal.Round == nil || len(seal.Signature) != types.IstanbulExtraBlsSignature {
+		return errInsufficientSeals
+	}
+	signers, err := v.signers(seal.Bitmap)
+	if err != nil {
+		return err
+	}
+	if err := blscrypto.CryptoType().VerifyAggregatedSignature(signers, istanbulCore.PrepareCommittedSeal(header.Hash(), seal.Round),
+		[]byte{}, seal.Signature, false, false, fork, cur); err != nil {
+		return err
+	}
+
+	next, err := v.apply(extra, proof)
+	if err != nil {
+		return err
+	}
+	var nextWeights []*big.Int
+	if len(extra.ValidatorWeights) > 0 {
+		if !validWeights(extra.ValidatorWeights, len(next)) {
+			return errInvalidWeights
+		}
+		nextWeights = extra.ValidatorWeights
+	}
+
+	// and so is the validator set of the next epoch. The header seal already commits
+	// to the validator diff in the extra, the epoch seal is checked as the chain does.
+	if proof.EpochSeal.Bitmap == nil {
+		return errInsufficientSeals
+	}
+	signers, err = v.signers(proof.EpochSeal.Bitmap.ToInt())
+	if err != nil {
+		return err
+	}
+	keys := make([]blscrypto.SerializedPublicKey, 0, len(next))
+	for _, val := range next {
+		keys = append(keys, val.BLSPublicKey)
+	}
+	message, extraData, err := istanbulCore.EpochValidatorSetData(number, v.epochSize, uint8(seal.Round.Uint64()), header.Hash(), v.hash,
+		keys, minQuorumSize(len(next)))
+	if err != nil {
+		return err
+	}
+	if err := blscrypto.CryptoType().VerifyAggregatedSignature(signers, message, extraData, proof.EpochSeal.Signature,
+		true, true, fork, cur); err != nil {
+		return err
+	}
+
+	v.epoch, v.hash, v.validators, v.weights = v.epoch+1, header.Hash(), next, nextWeights
+	return nil
+}
+
+// signers returns the keys of the current validators set in the bitmap, which must hold
+// a quorum of the voting power.
+func (v *Verifier) signers(bitmap *big.Int) ([]blscrypto.SerializedPublicKey, error) {
+	if bitmap.BitLen() > len(v.validators) {
+		return nil, errInsufficientSeals
+	}
+	keys := make([]blscrypto.SerializedPublicKey, 0, len(v.validators))
+	power, total := new(big.Int), new(big.Int)
+	for i, val := range v.validators {
+		weight := big.NewInt(1)
+		if v.weights != nil {
+			weight = v.weights[i]
+		}
+		total.Add(total, weight)
+		if bitmap.Bit(i) == 1 {
+			keys = append(keys, val.BLSPublicKey)
+			power.Add(power, weight)
+		}
+	}
+	// a quorum holds at least 2/3 of the voting power
+	if new(big.Int).Mul(power, big.NewInt(3)).Cmp(new(big.Int).Mul(total, big.NewInt(2))) < 0 {
+		return nil, errInsufficientSeals
+	}
+	return keys, nil
+}
+
+// apply returns the validator set after the header, in the order the snapshot of the
+// Istanbul backend keeps it, and checks the validators listed by the proof against it.
+func (v *Verifier) apply(extra *types.IstanbulExtra, proof *Proof) ([]istanbul.ValidatorData, error) {
+	added, err := istanbul.CombineIstanbulExtraToValidatorData(extra.AddedValidators, extra.AddedValidatorsPublicKeys, extra.AddedValidatorsG1PublicKeys)
+	if err != nil {
+		return nil, err
+	}
+	removedBitmap := bitmapOrZero(extra.RemovedValidators)
+	if removedBitmap.BitLen() > len(v.validators) {
+		return nil, errInvalidValidatorDiff
+	}
+
+	next := make([]istanbul.ValidatorData, 0, len(v.validators)+len(added))
+	removed := make([]istanbul.ValidatorData, 0)
+	for i, val := range v.validators {
+		if removedBitmap.Bit(i) == 1 {
+			removed = append(removed, val)
+		} else {
+			next = append(next, val)
+		}
+	}
+	known := make(map[common.Address]bool, len(next))
+	for _, val := range next {
+		known[val.Address] = true
+	}
+	for _, val := range added {
+		if known[val.Address] {
+			return nil, errInvalidValidatorDiff
+		}
+		known[val.Address] = true
+	}
+	next = append(next, added...)
+
+	if !sameValidators(added, proof.AddedValidators) || !sameValidators(removed, proof.RemovedValidators) {
+		return nil, errValidatorSetMismatch
+	}
+	return next, nil
+}
+
+func sameValidators(a, b []istanbul.ValidatorData) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// validWeights checks the voting power of n validators as the validator set does.
+func validWeights(weights []*big.Int, n int) bool {
+	if len(weights) != n {
+		return false
+	}
+	total := new(big.Int)
+	for _, w := range weights {
+		if w == nil || w.Sign() < 0 {
+			return false
+		}
+		total.Add(total, w)
+	}
+	return total.Sign() > 0
+}
+
+func bitmapOrZero(bitmap *big.Int) *big.Int {
+	if bitmap == nil {
+		return new(big.Int)
+	}
+	return bitmap
+}
+
+// minQuorumSize mirrors the quorum of the Istanbul validator set.
+func minQuorumSize(size int) int {
+	return int(math.Ceil(float64(2*size) / 3))
+}
@@ -0,0 +1,209 @@
+package epochproof
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	istanbulCore "github.com/mapprotocol/atlas/consensus/istanbul/core"
+	"github.com/mapprotocol/atlas/core/types"
+	blscrypto "github.com/mapprotocol/atlas/helper/bls"
+)
+
+const testEpochSize = 10
+
+var testFork = big.NewInt(0)
+
+type testValidator struct {
+	data istanbul.ValidatorData
+	key  *blscrypto.SecretKey
+}
+
+func newTestValidators(t *testing.T, n int) []*testValidator {
+	vals := make([]*testValidator, 0, n)
+	for i := 0; i < n; i++ {
+		ecdsaKey, _ := crypto.GenerateKey()
+		keyBytes, err := blscrypto.CryptoType().ECDSAToBLS(ecdsaKey)
+		assert.Nil(t, err)
+		key, err := blscrypto.DeserializePrivateKey(keyBytes)
+		assert.Nil(t, err)
+		pub, err := blscrypto.CryptoType().PrivateToPublic(keyBytes)
+		assert.Nil(t, err)
+		g1, err := blscrypto.CryptoType().PrivateToG1Public(keyBytes)
+		assert.Nil(t, err)
+		vals = append(vals, &testValidator{
+			data: istanbul.ValidatorData{Address: crypto.PubkeyToAddress(ecdsaKey.PublicKey), BLSPublicKey: pub, BLSG1PublicKey: g1},
+			key:  key,
+		})
+	}
+	return vals
+}
+
+func validatorData(vals []*testValidator) []istanbul.ValidatorData {
+	data := make([]istanbul.ValidatorData, 0, len(vals))
+	for _, v := range vals {
+		data = append(data, v.data)
+	}
+	return data
+}
+
+// aggregate signs msg with the validators set in the bitmap.
+func aggregate(t *testing.T, vals []*testValidator, bitmap *big.Int, msg []byte) []byte {
+	sigs := make([][]byte, 0, len(vals))
+	for i, v := range vals {
+		if bitmap.Bit(i) == 0 {
+			continue
+		}
+		sig, err := blscrypto.UnsafeSign2(v.key, msg)
+		assert.Nil(t, err)
+		sigs = append(sigs, sig.Marshal())
+	}
+	asig, err := blscrypto.CryptoType().AggregateSignatures(sigs)
+	assert.Nil(t, err)
+	return asig
+}
+
+// makeProof seals the last block of epoch with the validators in cur, electing next.
+func makeProof(t *testing.T, epoch uint64, parentEpochHash common.Hash, cur []*testValidator, removed *big.Int, added []*testValidator, bitmap *big.Int, weights []*big.Int) *Proof {
+	addrs, pubs, g1s := istanbul.SeparateValidatorDataIntoIstanbulExtra(validatorData(added))
+	extra := &types.IstanbulExtra{
+		AddedValidators:             addrs,
+		AddedValidatorsPublicKeys:   pubs,
+		AddedValidatorsG1PublicKeys: g1s,
+		RemovedValidators:           removed,
+		Seal:                        []byte{},
+		AggregatedSeal:              types.IstanbulAggregatedSeal{Bitmap: new(big.Int), Signature: []byte{}, Round: big.NewInt(0)},
+		ParentAggregatedSeal:        types.IstanbulAggregatedSeal{Bitmap: new(big.Int), Signature: []byte{}, Round: big.NewInt(0)},
+		ValidatorWeights:            weights,
+	}
+	header := &types.Header{Number: new(big.Int).SetUint64(epoch * testEpochSize)}
+	writeExtra := func() {
+		payload, err := rlp.EncodeToBytes(extra)
+		assert.Nil(t, err)
+		header.Extra = append(make([]byte, types.IstanbulExtraVanity), payload...)
+	}
+	writeExtra()
+
+	round := big.NewInt(0)
+	extra.AggregatedSeal = types.IstanbulAggregatedSeal{
+		Bitmap:    bitmap,
+		Signature: aggregate(t, cur, bitmap, istanbulCore.PrepareCommittedSeal(header.Hash(), round)),
+		Round:     round,
+	}
+	hash := header.Hash()
+	writeExtra()
+	assert.Equal(t, hash, header.Hash())
+
+	// the validators of the next epoch, as the snapshot applies the diff
+	var next []istanbul.ValidatorData
+	for i, v := range cur {
+		if removed.Bit(i) == 0 {
+			next = append(next, v.data)
+		}
+	}
+	next = append(next, validatorData(added)...)
+	keys := make([]blscrypto.SerializedPublicKey, 0, len(next))
+	for _, v := range next {
+		keys = append(keys, v.BLSPublicKey)
+	}
+	msg, _, err := istanbulCore.EpochValidatorSetData(header.Number.Uint64(), testEpochSize, uint8(round.Uint64()), hash, parentEpochHash,
+		keys, minQuorumSize(len(next)))
+	assert.Nil(t, err)
+	seal := &types.EpochSnarkData{Bitmap: bitmap, Signature: aggregate(t, cur, bitmap, msg)}
+
+	proof, err := NewProof(header, seal, validatorData(cur), testEpochSize)
+	assert.Nil(t, err)
+	return proof
+}
+
+func proofSeal(t *testing.T, proof *Proof) []byte {
+	extra, err := types.ExtractIstanbulExtra(proof.Header)
+	assert.Nil(t, err)
+	return extra.AggregatedSeal.Signature
+}
+
+func TestVerifier_VerifyChain(t *testing.T) {
+	vals := newTestValidators(t, 5)
+	genesis := common.HexToHash("0x01")
+
+	// epoch 1 replaces the second validator by the fifth
+	first := makeProof(t, 1, genesis, vals[:4], big.NewInt(2), vals[4:], big.NewInt(0b1011), nil)
+	assert.Equal(t, []istanbul.ValidatorData{vals[1].data}, first.RemovedValidators)
+	assert.Equal(t, []istanbul.ValidatorData{vals[4].data}, first.AddedValidators)
+
+	next := []*testValidator{vals[0], vals[2], vals[3], vals[4]}
+	second := makeProof(t, 2, first.Header.Hash(), next, new(big.Int), nil, big.NewInt(0b1110), nil)
+
+	v := NewVerifier(testEpochSize, testFork, 0, genesis, validatorData(vals[:4]))
+	assert.Nil(t, v.VerifyChain([]*Proof{first, second}))
+	epoch, hash := v.Epoch()
+	assert.Equal(t, uint64(2), epoch)
+	assert.Equal(t, second.Header.Hash(), hash)
+	assert.Equal(t, validatorData(next), v.Validators())
+
+	// proofs must follow each other
+	v = NewVerifier(testEpochSize, testFork, 0, genesis, validatorData(vals[:4]))
+	assert.Equal(t, errUnexpectedEpoch, v.Verify(second))
+}
+
+func TestVerifier_Weighted(t *testing.T) {
+	vals := newTestValidators(t, 4)
+	genesis := common.HexToHash("0x01")
+	weights := []*big.Int{big.NewInt(70), big.NewInt(10), big.NewInt(10), big.NewInt(10)}
+
+	// epoch 1 assigns the voting power, the heaviest validator and one other then
+	// make a quorum in epoch 2, while three light ones do not
+	first := makeProof(t, 1, genesis, vals, new(big.Int), nil, big.NewInt(0b0111), weights)
+	second := makeProof(t, 2, first.Header.Hash(), vals, new(big.Int), nil, big.NewInt(0b0011), nil)
+	v := NewVerifier(testEpochSize, testFork, 0, genesis, validatorData(vals))
+	assert.Nil(t, v.VerifyChain([]*Proof{first, second}))
+	assert.Nil(t, v.Weights())
+
+	light := makeProof(t, 2, first.Header.Hash(), vals, new(big.Int), nil, big.NewInt(0b1110), nil)
+	v = NewVerifier(testEpochSize, testFork, 0, genesis, validatorData(vals))
+	assert.Nil(t, v.Verify(first))
+	assert.Equal(t, weights, v.Weights())
+	assert.Equal(t, errInsufficientSeals, v.Verify(light))
+
+	// the weights must cover the next validator set
+	bad := makeProof(t, 1, genesis, vals, new(big.Int), nil, big.NewInt(0b0111), weights[:3])
+	v = NewVerifier(testEpochSize, testFork, 0, genesis, validatorData(vals))
+	assert.Equal(t, errInvalidWeights, v.Verify(bad))
+}
+
+func TestVerifier_Reject(t *testing.T) {
+	vals := newTestValidators(t, 4)
+	genesis := common.HexToHash("0x01")
+
+	// two of four validators are below the quorum
+	proof := makeProof(t, 1, genesis, vals, new(big.Int), nil, big.NewInt(0b0011), nil)
+	v := NewVerifier(testEpochSize, testFork, 0, genesis, validatorData(vals))
+	assert.Equal(t, errInsufficientSeals, v.Verify(proof))
+
+	// the header seal commits to the validator diff
+	proof = makeProof(t, 1, genesis, vals, new(big.Int), nil, big.NewInt(0b0111), nil)
+	other := makeProof(t, 1, genesis, vals, big.NewInt(1), nil, big.NewInt(0b0111), nil)
+	assert.Nil(t, NewVerifier(testEpochSize, testFork, 0, genesis, validatorData(vals)).Verify(other))
+	extra, err := types.ExtractIstanbulExtra(other.Header)
+	assert.Nil(t, err)
+	extra.AggregatedSeal = types.IstanbulAggregatedSeal{Bitmap: big.NewInt(0b0111), Signature: proofSeal(t, proof), Round: big.NewInt(0)}
+	payload, err := rlp.EncodeToBytes(extra)
+	assert.Nil(t, err)
+	other.Header.Extra = append(other.Header.Extra[:types.IstanbulExtraVanity:types.IstanbulExtraVanity], payload...)
+	assert.NotNil(t, NewVerifier(testEpochSize, testFork, 0, genesis, validatorData(vals)).Verify(other))
+
+	// the listed validators must match the header
+	proof = makeProof(t, 1, genesis, vals, new(big.Int), nil, big.NewInt(0b0111), nil)
+	proof.RemovedValidators = []istanbul.ValidatorData{vals[0].data}
+	assert.Equal(t, errValidatorSetMismatch, NewVerifier(testEpochSize, testFork, 0, genesis, validatorData(vals)).Verify(proof))
+
+	// and the header must be signed by the trusted validators
+	proof = makeProof(t, 1, genesis, vals, new(big.Int), nil, big.NewInt(0b0111), nil)
+	others := newTestValidators(t, 4)
+	assert.NotNil(t, NewVerifier(testEpochSize, testFork, 0, genesis, validatorData(others)).Verify(proof))
+}
@@ -44,4 +44,6 @@ var (
 	ErrValidatorNotProxied = errors.New("validator not proxied")
 	// ErrInvalidEnodeCertMsgMapOldVersion is returned if a validator sends old enode certificate message
 	ErrInvalidEnodeCertMsgMapOldVersion = errors.New("invalid enode certificate message map because of old version")
+	// ErrInvalidEvidence is returned if an evidence does not hold two conflicting messages of the same signer and view
+	ErrInvalidEvidence = errors.New("invalid equivocation evidence")
 )
@@ -32,10 +32,11 @@ const (
 	VersionCertificatesMsg = 0x16
 	EnodeCertificateMsg    = 0x17
 	ValidatorHandshakeMsg  = 0x18
+	ReplicaHeartbeatMsg    = 0x19
 )
 
 func IsIstanbulMsg(msg p2p.Msg) bool {
-	return msg.Code >= ConsensusMsg && msg.Code <= ValidatorHandshakeMsg
+	return msg.Code >= ConsensusMsg && msg.Code <= ReplicaHeartbeatMsg
 }
 
 // IsGossipedMsg specifies which messages should be gossiped throughout the network (as opposed to directly sent to a peer).
@@ -0,0 +1,248 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+)
+
+// proxyDiscoverer looks up the proxies of the proxied validator
+type proxyDiscoverer interface {
+	discover() ([]*istanbul.ProxyConfig, error)
+}
+
+// fileProxyDiscoverer reads the proxies from a file with one "<internal enode url>;<external enode url>"
+// pair per line.  The external enode url can be omitted if it is the same as the internal one.
+// Empty lines and lines starting with '#' are ignored.
+type fileProxyDiscoverer struct {
+	path string
+}
+
+func (d *fileProxyDiscoverer) discover() ([]*istanbul.ProxyConfig, error) {
+	data, err := ioutil.ReadFile(d.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var proxies []*istanbul.ProxyConfig
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		proxy, err := parseProxyConfig(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", d.path, i+1, err)
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, nil
+}
+
+// parseProxyConfig parses a "<internal enode url>;<external enode url>" proxy pair
+func parseProxyConfig(pair string) (*istanbul.ProxyConfig, error) {
+	urls := strings.Split(pair, ";")
+	if len(urls) > 2 {
+		return nil, fmt.Errorf("invalid proxy enode url pair %q", pair)
+	}
+	internalNode, err := enode.ParseV4(strings.TrimSpace(urls[0]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid internal enode url %q: %v", urls[0], err)
+	}
+	externalNode := internalNode
+	if len(urls) == 2 {
+		if externalNode, err = enode.ParseV4(strings.TrimSpace(urls[1])); err != nil {
+			return nil, fmt.Errorf("invalid external enode url %q: %v", urls[1], err)
+		}
+	}
+	return &istanbul.ProxyConfig{InternalNode: internalNode, ExternalNode: externalNode}, nil
+}
+
+// srvProxyDiscoverer looks up the proxies' internal endpoints from the SRV records of a DNS name.
+// The node key of each proxy is read from an "enode=<hex node key>" TXT record of the SRV target, along
+// with an optional "external=<external enode url>" TXT record if the external enode url differs from
+// the internal one.
+type srvProxyDiscoverer struct {
+	name      string
+	lookupSRV func(name string) ([]*net.SRV, error)
+	lookupTXT func(name string) ([]string, error)
+	lookupIP  func(host string) ([]net.IP, error)
+}
+
+func newSRVProxyDiscoverer(name string) *srvProxyDiscoverer {
+	return &srvProxyDiscoverer{
+		name: name,
+		lookupSRV: func(name string) ([]*net.SRV, error) {
+			_, addrs, err := net.LookupSRV("", "", name)
+			return addrs, err
+		},
+		lookupTXT: net.LookupTXT,
+		lookupIP:  net.LookupIP,
+	}
+}
+
+func (d *srvProxyDiscoverer) discover() ([]*istanbul.ProxyConfig, error) {
+	records, err := d.lookupSRV(d.name)
+	if err != nil {
+		return nil, err
+	}
+
+	proxies := make([]*istanbul.ProxyConfig, 0, len(records))
+	for _, record := range records {
+		target := strings.TrimSuffix(record.Target, ".")
+		txts, err := d.lookupTXT(target)
+		if err != nil {
+			return nil, err
+		}
+		var nodeKey, external string
+		for _, txt := range txts {
+			if strings.HasPrefix(txt, "enode=") {
+				nodeKey = strings.TrimPrefix(txt, "enode=")
+			} else if strings.HasPrefix(txt, "external=") {
+				external = strings.TrimPrefix(txt, "external=")
+			}
+		}
+		if nodeKey == "" {
+			return nil, fmt.Errorf("no enode TXT record for proxy %s", target)
+		}
+		ips, err := d.lookupIP(target)
+		if err != nil {
+			return nil, err
+		}
+		if len(ips) == 0 {
+			return nil, fmt.Errorf("no address for proxy %s", target)
+		}
+
+		pair := fmt.Sprintf("enode://%s@%s", nodeKey, net.JoinHostPort(ips[0].String(), fmt.Sprint(record.Port)))
+		if external != "" {
+			pair += ";" + external
+		}
+		proxy, err := parseProxyConfig(pair)
+		if err != nil {
+			return nil, fmt.Errorf("proxy %s: %v", target, err)
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies, nil
+}
+
+// newProxyDiscoverers returns the proxy discoverers enabled in the config
+func newProxyDiscoverers(config *istanbul.Config) []proxyDiscoverer {
+	var discoverers []proxyDiscoverer
+	if config.ProxyDiscoveryFile != "" {
+		discoverers = append(discoverers, &fileProxyDiscoverer{path: config.ProxyDiscoveryFile})
+	}
+	if config.ProxyDiscoverySRV != "" {
+		discoverers = append(discoverers, newSRVProxyDiscoverer(config.ProxyDiscoverySRV))
+	}
+	return discoverers
+}
+
+// diffDiscoveredProxies compares the proxies found by a discovery round with the ones found by
+// the previous round, skipping the static ones.  A proxy whose enode urls changed is both removed
+// and added.  It returns the proxies to add and remove, and the new set of discovered proxies.
+func diffDiscoveredProxies(current map[enode.ID]*istanbul.ProxyConfig, found []*istanbul.ProxyConfig, static map[enode.ID]bool) ([]*istanbul.ProxyConfig, []*enode.Node, map[enode.ID]*istanbul.ProxyConfig) {
+	next := make(map[enode.ID]*istanbul.ProxyConfig)
+	for _, proxy := range found {
+		if id := proxy.InternalNode.ID(); !static[id] {
+			next[id] = proxy
+		}
+	}
+
+	changed := func(a, b *istanbul.ProxyConfig) bool {
+		return a.InternalNode.String() != b.InternalNode.String() || a.ExternalNode.String() != b.ExternalNode.String()
+	}
+
+	var removed []*enode.Node
+	for id, proxy := range current {
+		if newProxy, ok := next[id]; !ok || changed(proxy, newProxy) {
+			removed = append(removed, proxy.InternalNode)
+		}
+	}
+	var added []*istanbul.ProxyConfig
+	for id, proxy := range next {
+		if oldProxy, ok := current[id]; !ok || changed(oldProxy, proxy) {
+			added = append(added, proxy)
+		}
+	}
+	return added, removed, next
+}
+
+// discoveryRun periodically looks up the proxies, and adds the new ones to and removes the
+// vanished ones from the proxy set.  Proxies from the static config are left alone, and
+// a failed lookup leaves the proxy set unchanged.
+func (pv *proxiedValidatorEngine) discoveryRun(discoverers []proxyDiscoverer, period time.Duration) {
+	defer pv.loopWG.Done()
+	logger := pv.logger.New("func", "discoveryRun")
+
+	static := make(map[enode.ID]bool)
+	for _, proxy := range pv.config.ProxyConfigs {
+		static[proxy.InternalNode.ID()] = true
+	}
+	discovered := make(map[enode.ID]*istanbul.ProxyConfig)
+
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		var found []*istanbul.ProxyConfig
+		var err error
+		for _, discoverer := range discoverers {
+			var proxies []*istanbul.ProxyConfig
+			if proxies, err = discoverer.discover(); err != nil {
+				break
+			}
+			found = append(found, proxies...)
+		}
+
+		if err != nil {
+			logger.Warn("Failed to discover proxies", "err", err)
+		} else {
+			added, removed, next := diffDiscoveredProxies(discovered, found, static)
+			if len(removed) > 0 {
+				logger.Info("Removing undiscovered proxies", "count", len(removed))
+				select {
+				case pv.removeProxies <- removed:
+				case <-pv.quit:
+					return
+				}
+			}
+			if len(added) > 0 {
+				logger.Info("Adding discovered proxies", "count", len(added))
+				select {
+				case pv.addProxies <- added:
+				case <-pv.quit:
+					return
+				}
+			}
+			discovered = next
+		}
+
+		select {
+		case <-ticker.C:
+		case <-pv.quit:
+			return
+		}
+	}
+}
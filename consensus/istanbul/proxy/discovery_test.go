@@ -0,0 +1,161 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+)
+
+func TestFileProxyDiscoverer(t *testing.T) {
+	proxy0Config := createProxyConfig(0)
+	proxy1Config := createProxyConfig(1)
+
+	dir, err := ioutil.TempDir("", "proxy-discovery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "proxies")
+
+	content := fmt.Sprintf("# proxies\n%s;%s\n\n  %s  \n", proxy0Config.InternalNode, proxy0Config.ExternalNode, proxy1Config.InternalNode)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	proxies, err := (&fileProxyDiscoverer{path: path}).discover()
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if len(proxies) != 2 {
+		t.Fatalf("proxies count mismatch: have %d, want 2", len(proxies))
+	}
+	if proxies[0].InternalNode.String() != proxy0Config.InternalNode.String() || proxies[0].ExternalNode.String() != proxy0Config.ExternalNode.String() {
+		t.Errorf("proxy 0 mismatch: have %v;%v", proxies[0].InternalNode, proxies[0].ExternalNode)
+	}
+	if proxies[1].InternalNode.String() != proxy1Config.InternalNode.String() || proxies[1].ExternalNode.String() != proxy1Config.InternalNode.String() {
+		t.Errorf("proxy 1 mismatch: have %v;%v", proxies[1].InternalNode, proxies[1].ExternalNode)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("enode://invalid\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&fileProxyDiscoverer{path: path}).discover(); err == nil {
+		t.Error("expected error for invalid enode url")
+	}
+}
+
+func TestSRVProxyDiscoverer(t *testing.T) {
+	key0, _ := crypto.GenerateKey()
+	key1, _ := crypto.GenerateKey()
+	nodeKey := func(key *ecdsa.PrivateKey) string {
+		return fmt.Sprintf("%x", crypto.FromECDSAPub(&key.PublicKey)[1:])
+	}
+	external1 := enode.NewV4(&key1.PublicKey, net.ParseIP("1.2.3.4"), 30303, 30303)
+
+	d := &srvProxyDiscoverer{
+		name: "_proxies._tcp.example.org",
+		lookupSRV: func(name string) ([]*net.SRV, error) {
+			return []*net.SRV{{Target: "proxy0.example.org.", Port: 30503}, {Target: "proxy1.example.org.", Port: 30504}}, nil
+		},
+		lookupTXT: func(name string) ([]string, error) {
+			switch name {
+			case "proxy0.example.org":
+				return []string{"v=spf1", "enode=" + nodeKey(key0)}, nil
+			case "proxy1.example.org":
+				return []string{"enode=" + nodeKey(key1), "external=" + external1.String()}, nil
+			}
+			return nil, fmt.Errorf("unknown name %s", name)
+		},
+		lookupIP: func(host string) ([]net.IP, error) {
+			return []net.IP{net.ParseIP("10.0.0.1")}, nil
+		},
+	}
+
+	proxies, err := d.discover()
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	want := []*istanbul.ProxyConfig{
+		{InternalNode: enode.NewV4(&key0.PublicKey, net.ParseIP("10.0.0.1"), 30503, 30503)},
+		{InternalNode: enode.NewV4(&key1.PublicKey, net.ParseIP("10.0.0.1"), 30504, 30504), ExternalNode: external1},
+	}
+	want[0].ExternalNode = want[0].InternalNode
+	if len(proxies) != len(want) {
+		t.Fatalf("proxies count mismatch: have %d, want %d", len(proxies), len(want))
+	}
+	for i := range want {
+		if proxies[i].InternalNode.String() != want[i].InternalNode.String() || proxies[i].ExternalNode.String() != want[i].ExternalNode.String() {
+			t.Errorf("proxy %d mismatch: have %v;%v, want %v;%v", i, proxies[i].InternalNode, proxies[i].ExternalNode, want[i].InternalNode, want[i].ExternalNode)
+		}
+	}
+
+	d.lookupTXT = func(name string) ([]string, error) { return nil, nil }
+	if _, err := d.discover(); err == nil {
+		t.Error("expected error for missing enode TXT record")
+	}
+}
+
+func TestDiffDiscoveredProxies(t *testing.T) {
+	proxy0Config := createProxyConfig(0)
+	proxy1Config := createProxyConfig(1)
+	proxy2Config := createProxyConfig(2)
+	staticConfig := createProxyConfig(3)
+	static := map[enode.ID]bool{staticConfig.InternalNode.ID(): true}
+
+	added, removed, discovered := diffDiscoveredProxies(map[enode.ID]*istanbul.ProxyConfig{}, []*istanbul.ProxyConfig{proxy0Config, proxy1Config, staticConfig}, static)
+	if len(added) != 2 || len(removed) != 0 || len(discovered) != 2 {
+		t.Fatalf("first round mismatch: added %d, removed %d, discovered %d", len(added), len(removed), len(discovered))
+	}
+
+	// proxy 0 vanishes, proxy 1 changes its external enode url, proxy 2 appears
+	movedProxy1Config := &istanbul.ProxyConfig{InternalNode: proxy1Config.InternalNode, ExternalNode: proxy2Config.ExternalNode}
+	added, removed, discovered = diffDiscoveredProxies(discovered, []*istanbul.ProxyConfig{movedProxy1Config, proxy2Config}, static)
+	if len(discovered) != 2 {
+		t.Fatalf("discovered count mismatch: have %d, want 2", len(discovered))
+	}
+	removedIDs := make(map[enode.ID]bool)
+	for _, node := range removed {
+		removedIDs[node.ID()] = true
+	}
+	if len(removed) != 2 || !removedIDs[proxy0Config.InternalNode.ID()] || !removedIDs[proxy1Config.InternalNode.ID()] {
+		t.Errorf("removed proxies mismatch: have %v", removed)
+	}
+	addedIDs := make(map[enode.ID]bool)
+	for _, proxy := range added {
+		addedIDs[proxy.InternalNode.ID()] = true
+	}
+	if len(added) != 2 || !addedIDs[proxy1Config.InternalNode.ID()] || !addedIDs[proxy2Config.InternalNode.ID()] {
+		t.Errorf("added proxies mismatch: have %v", added)
+	}
+
+	// Nothing changes
+	added, removed, _ = diffDiscoveredProxies(discovered, []*istanbul.ProxyConfig{movedProxy1Config, proxy2Config}, static)
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("unchanged round mismatch: added %d, removed %d", len(added), len(removed))
+	}
+}
@@ -113,6 +113,8 @@ type proxiedValidatorEngine struct {
 
 	sendFwdMsgsCh chan *fwdMsgInfo // Used to send a forward message to all of the proxies
 
+	sendReplicaHeartbeatCh chan []byte // Used to send a replica heartbeat message to all of the proxies
+
 	newBlockchainEpoch chan struct{} // Used to notify to the thread that a new blockchain epoch has started
 }
 
@@ -141,6 +143,7 @@ func NewProxiedValidatorEngine(backend BackendForProxiedValidatorEngine, config
 		sendValEnodeShareMsgsCh: make(chan struct{}),
 		sendEnodeCertsCh:        make(chan map[enode.ID]*istanbul.EnodeCertMsg),
 		sendFwdMsgsCh:           make(chan *fwdMsgInfo),
+		sendReplicaHeartbeatCh:  make(chan []byte),
 		newBlockchainEpoch:      make(chan struct{}),
 	}
 
@@ -167,6 +170,15 @@ func (pv *proxiedValidatorEngine) Start() error {
 		}
 	}
 
+	if discoverers := newProxyDiscoverers(pv.config); len(discoverers) > 0 {
+		period := time.Duration(pv.config.ProxyDiscoveryPeriod) * time.Second
+		if period == 0 {
+			period = time.Duration(istanbul.DefaultConfig.ProxyDiscoveryPeriod) * time.Second
+		}
+		pv.loopWG.Add(1)
+		go pv.discoveryRun(discoverers, period)
+	}
+
 	pv.isRunning = true
 	pv.logger.Info("Proxied validator engine started")
 	return nil
@@ -357,6 +369,22 @@ func (pv *proxiedValidatorEngine) SendForwardMsgToAllProxies(finalDestAddresses
 	return nil
 }
 
+// SendReplicaHeartbeatToAllProxies will signal to the running thread to send a replica heartbeat to all proxies.
+func (pv *proxiedValidatorEngine) SendReplicaHeartbeatToAllProxies(payload []byte) error {
+	if !pv.Running() {
+		return istanbul.ErrStoppedProxiedValidatorEngine
+	}
+
+	select {
+	case pv.sendReplicaHeartbeatCh <- payload:
+
+	case <-pv.quit:
+		return istanbul.ErrStoppedProxiedValidatorEngine
+	}
+
+	return nil
+}
+
 // NewEpoch will notify the proxied validator's thread that a new epoch started
 func (pv *proxiedValidatorEngine) NewEpoch() error {
 	if !pv.Running() {
@@ -383,6 +411,13 @@ func (pv *proxiedValidatorEngine) threadRun() {
 		// The duration of time between thread update, which are occasional check-ins to ensure proxy/validator assignments are as intended
 		schedulerPeriod time.Duration = 30 * time.Second
 
+		// The minimum time that a proxy stays connected to a remote validator that was reassigned to another proxy,
+		// so that the remote validator can connect to the new proxy before the old one disconnects.
+		minProxyHandoffTime time.Duration = 60 * time.Second
+
+		// The round trip time above which a proxy is no longer assigned remote validators
+		maxProxyLatency time.Duration = time.Duration(pv.config.ProxyMaxLatency) * time.Millisecond
+
 		// Used to keep track of proxies & validators the proxies are associated with
 		ps *proxySet = newProxySet(newConsistentHashingPolicy())
 	)
@@ -491,15 +526,25 @@ loop:
 		case fwdMsg := <-pv.sendFwdMsgsCh:
 			pv.sendForwardMsg(ps, fwdMsg.destAddresses, fwdMsg.ethMsgCode, fwdMsg.payload)
 
+		case payload := <-pv.sendReplicaHeartbeatCh:
+			pv.sendReplicaHeartbeat(ps, payload)
+
 		case <-schedulerTicker.C:
 			logger.Trace("schedulerTicker ticked")
 
 			// Remove validator assignement for proxies that are disconnected for a minimum of `minProxyDisconnectTime` seconds.
 			// The reason for not immediately removing the validator asssignments is so that if there is a
 			// network disconnect then a quick reconnect, the validator assignments wouldn't be changed.
+			// Validators are also moved off the proxies whose latency is too high, and back once it recovers.
 			// If no reassignments were made, then resend all enode certificates and val enode share messages to the
-			// proxies, in case previous attempts failed.
-			if valsReassigned := ps.unassignDisconnectedProxies(minProxyDisconnectTime); valsReassigned {
+			// proxies, in case previous attempts failed.  Either way, the val enode share messages drop the
+			// expired handoffs.
+			ps.expireHandoffs(minProxyHandoffTime)
+			valsReassigned := ps.unassignDisconnectedProxies(minProxyDisconnectTime)
+			if maxProxyLatency > 0 {
+				valsReassigned = ps.updateProxyHealth(maxProxyLatency) || valsReassigned
+			}
+			if valsReassigned {
 				pv.backend.UpdateAnnounceVersion()
 				pv.sendValEnodeShareMsgs(ps)
 			} else {
@@ -532,6 +577,8 @@ func (pv *proxiedValidatorEngine) sendValEnodeShareMsgs(ps *proxySet) {
 			for valAddress := range assignedValidators {
 				valAddresses = append(valAddresses, valAddress)
 			}
+			// Keep the proxy connected to the validators it is handing off
+			valAddresses = append(valAddresses, ps.getHandoffValidators(proxy.ID())...)
 			logger.Info("Sending val enode share msg to proxy", "proxy peer", proxy.peer, "valAddresses length", len(valAddresses))
 			logger.Trace("Sending val enode share msg to proxy with validator addresses", "valAddresses", types.ConvertToStringSlice(valAddresses))
 			pv.sendValEnodesShareMsg(proxy.peer, valAddresses)
@@ -103,6 +103,8 @@ func (p *proxyEngine) HandleMsg(peer consensus.Peer, msgCode uint64, payload []b
 		return p.handleForwardMsg(peer, payload)
 	} else if msgCode == istanbul.ConsensusMsg {
 		return p.handleConsensusMsg(peer, payload)
+	} else if msgCode == istanbul.ReplicaHeartbeatMsg {
+		return p.handleReplicaHeartbeatMsg(peer, payload)
 	} else if msgCode == istanbul.EnodeCertificateMsg {
 		// See if the message is coming from the proxied validator
 		p.proxiedValidatorsMu.RLock()
@@ -84,6 +84,7 @@ func (ps *proxySet) removeProxy(proxyID enode.ID) bool {
 	}
 	valsReassigned := ps.valAssigner.removeProxy(proxy, ps.valAssignments)
 	delete(ps.proxiesByID, proxyID)
+	delete(ps.valAssignments.handoffs, proxyID)
 	return valsReassigned
 }
 
@@ -97,7 +98,10 @@ func (ps *proxySet) setProxyPeer(proxyID enode.ID, peer consensus.Peer) bool {
 	valsReassigned := false
 	if proxy != nil {
 		proxy.peer = peer
+		proxy.latency = 0
+		proxy.unhealthy = false
 		logger.Trace("Assigning validators to proxy", "proxyID", proxyID)
+		proxy.assigned = true
 		valsReassigned = ps.valAssigner.assignProxy(proxy, ps.valAssignments)
 	}
 
@@ -185,10 +189,10 @@ func (ps *proxySet) getValidatorAssignments(validators []common.Address, proxyID
 func (ps *proxySet) unassignDisconnectedProxies(minAge time.Duration) bool {
 	logger := ps.logger.New("func", "unassignDisconnectedProxies")
 	valsReassigned := false
-	for proxyID := range ps.valAssignments.proxyToVals {
-		proxy := ps.getProxy(proxyID)
-		if proxy != nil && proxy.peer == nil && time.Since(proxy.disconnectTS) >= minAge {
+	for _, proxy := range ps.proxiesByID {
+		if proxy.assigned && proxy.peer == nil && time.Since(proxy.disconnectTS) >= minAge {
 			logger.Debug("Unassigning disconnected proxy", "proxy", proxy.String())
+			proxy.assigned = false
 			valsReassigned = ps.valAssigner.removeProxy(proxy, ps.valAssignments) || valsReassigned
 		}
 	}
@@ -196,6 +200,58 @@ func (ps *proxySet) unassignDisconnectedProxies(minAge time.Duration) bool {
 	return valsReassigned
 }
 
+// updateProxyHealth samples the latency of the peered proxies, and unassigns the proxies whose latency
+// rose above maxLatency and reassigns the ones whose latency fell back below 3/4 of it.  If none of the
+// peered proxies is healthy, all of them are kept assigned.
+func (ps *proxySet) updateProxyHealth(maxLatency time.Duration) bool {
+	logger := ps.logger.New("func", "updateProxyHealth")
+	anyHealthy := false
+	for _, proxy := range ps.proxiesByID {
+		if proxy.peer == nil {
+			continue
+		}
+		proxy.sampleLatency()
+		if !proxy.unhealthy && proxy.latency > maxLatency {
+			logger.Warn("Proxy latency is too high", "proxy", proxy.String(), "maxLatency", maxLatency)
+			proxy.unhealthy = true
+		} else if proxy.unhealthy && proxy.latency <= maxLatency*3/4 {
+			logger.Info("Proxy latency recovered", "proxy", proxy.String())
+			proxy.unhealthy = false
+		}
+		anyHealthy = anyHealthy || !proxy.unhealthy
+	}
+
+	valsReassigned := false
+	for _, proxy := range ps.proxiesByID {
+		if proxy.peer == nil {
+			continue
+		}
+		if assign := !proxy.unhealthy || !anyHealthy; assign && !proxy.assigned {
+			logger.Debug("Assigning validators to proxy", "proxy", proxy.String())
+			proxy.assigned = true
+			valsReassigned = ps.valAssigner.assignProxy(proxy, ps.valAssignments) || valsReassigned
+		} else if !assign && proxy.assigned {
+			logger.Debug("Unassigning unhealthy proxy", "proxy", proxy.String())
+			proxy.assigned = false
+			valsReassigned = ps.valAssigner.removeProxy(proxy, ps.valAssignments) || valsReassigned
+		}
+	}
+
+	return valsReassigned
+}
+
+// expireHandoffs stops handing off the validators that were reassigned at least minAge ago
+// from their previous proxy.  Returns true if any handoff expired.
+func (ps *proxySet) expireHandoffs(minAge time.Duration) bool {
+	return ps.valAssignments.expireHandoffs(minAge)
+}
+
+// getHandoffValidators returns the validators that the proxy with ID proxyID is still handing
+// off to the proxy they were reassigned to
+func (ps *proxySet) getHandoffValidators(proxyID enode.ID) []common.Address {
+	return ps.valAssignments.getHandoffValidators(proxyID)
+}
+
 // getValidators returns all validators that are known by the proxy set
 func (ps *proxySet) getValidators() []common.Address {
 	return ps.valAssignments.getValidators()
@@ -21,6 +21,7 @@ import (
 	"math/rand"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -348,3 +349,99 @@ func proxyOutput(p *Proxy) string {
 		return p.node.ID().String()
 	}
 }
+
+func TestProxySetHealth(t *testing.T) {
+	proxy0Config := createProxyConfig(0)
+	proxy1Config := createProxyConfig(1)
+	proxy0ID := proxy0Config.InternalNode.ID()
+	proxy1ID := proxy1Config.InternalNode.ID()
+	proxy0Peer := consensustest.NewMockPeer(proxy0Config.InternalNode, p2p.ProxyPurpose)
+	proxy1Peer := consensustest.NewMockPeer(proxy1Config.InternalNode, p2p.ProxyPurpose)
+
+	remoteVals := []common.Address{
+		common.BytesToAddress([]byte("32526362351")),
+		common.BytesToAddress([]byte("64362643436")),
+		common.BytesToAddress([]byte("72436452463")),
+		common.BytesToAddress([]byte("46346373463")),
+		common.BytesToAddress([]byte("25364624352")),
+		common.BytesToAddress([]byte("73576426242")),
+	}
+	maxLatency := 100 * time.Millisecond
+
+	ps := newProxySet(newConsistentHashingPolicy())
+	ps.addProxy(proxy0Config)
+	ps.addProxy(proxy1Config)
+	ps.addRemoteValidators(remoteVals)
+	ps.setProxyPeer(proxy0ID, proxy0Peer)
+	ps.setProxyPeer(proxy1ID, proxy1Peer)
+
+	assignedTo := func(proxyID enode.ID) []common.Address {
+		vals := make([]common.Address, 0)
+		for val := range ps.getValidatorAssignments(nil, []enode.ID{proxyID}) {
+			vals = append(vals, val)
+		}
+		return vals
+	}
+	if len(assignedTo(proxy0ID)) == 0 || len(assignedTo(proxy1ID)) == 0 {
+		t.Fatalf("validators not balanced across proxies: proxy0 %d, proxy1 %d", len(assignedTo(proxy0ID)), len(assignedTo(proxy1ID)))
+	}
+	proxy0Vals := assignedTo(proxy0ID)
+	// Drop the handoffs from proxy 0 to proxy 1 made when proxy 1 connected
+	ps.expireHandoffs(0)
+
+	// Both proxies are fast enough
+	proxy0Peer.SetRTT(10 * time.Millisecond)
+	proxy1Peer.SetRTT(20 * time.Millisecond)
+	if ps.updateProxyHealth(maxLatency) {
+		t.Error("validators reassigned while all proxies are healthy")
+	}
+
+	// Proxy 0 gets slow, so all validators move to proxy 1, while proxy 0 hands its validators off
+	proxy0Peer.SetRTT(time.Second)
+	if !ps.updateProxyHealth(maxLatency) {
+		t.Fatal("validators not reassigned away from the unhealthy proxy")
+	}
+	if ps.getProxy(proxy0ID).IsHealthy() {
+		t.Error("slow proxy is healthy")
+	}
+	if vals := assignedTo(proxy0ID); len(vals) != 0 {
+		t.Errorf("unhealthy proxy still assigned validators: %v", vals)
+	}
+	if vals := assignedTo(proxy1ID); len(vals) != len(remoteVals) {
+		t.Errorf("healthy proxy assigned %d validators, want %d", len(vals), len(remoteVals))
+	}
+	if vals := ps.getHandoffValidators(proxy0ID); len(vals) != len(proxy0Vals) {
+		t.Errorf("unhealthy proxy hands off %d validators, want %d", len(vals), len(proxy0Vals))
+	}
+	if ps.expireHandoffs(time.Hour) {
+		t.Error("handoffs expired early")
+	}
+	if !ps.expireHandoffs(0) || len(ps.getHandoffValidators(proxy0ID)) != 0 {
+		t.Error("handoffs not expired")
+	}
+
+	// If no proxy is healthy, all of them are used
+	proxy1Peer.SetRTT(time.Second)
+	for i := 0; i < 10; i++ {
+		ps.updateProxyHealth(maxLatency)
+	}
+	if len(assignedTo(proxy0ID)) == 0 || len(assignedTo(proxy1ID)) == 0 {
+		t.Errorf("validators not balanced across unhealthy proxies: proxy0 %d, proxy1 %d", len(assignedTo(proxy0ID)), len(assignedTo(proxy1ID)))
+	}
+
+	// Proxy 0 recovers once its smoothed latency falls back well below the maximum
+	proxy0Peer.SetRTT(10 * time.Millisecond)
+	ps.updateProxyHealth(maxLatency)
+	if ps.getProxy(proxy0ID).IsHealthy() {
+		t.Error("proxy recovered before its smoothed latency dropped")
+	}
+	for i := 0; i < 10; i++ {
+		ps.updateProxyHealth(maxLatency)
+	}
+	if !ps.getProxy(proxy0ID).IsHealthy() {
+		t.Error("proxy did not recover")
+	}
+	if vals := assignedTo(proxy0ID); len(vals) != len(remoteVals) {
+		t.Errorf("recovered proxy assigned %d validators, want %d", len(vals), len(remoteVals))
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package proxy
+
+import (
+	"github.com/mapprotocol/atlas/consensus"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+)
+
+// sendReplicaHeartbeat sends an already signed replica heartbeat message to all of the peered proxies.
+func (pv *proxiedValidatorEngine) sendReplicaHeartbeat(ps *proxySet, payload []byte) {
+	logger := pv.logger.New("func", "sendReplicaHeartbeat")
+
+	for _, proxy := range ps.proxiesByID {
+		if proxy.IsPeered() {
+			logger.Trace("Sending replica heartbeat to proxy", "proxy peer", proxy.peer)
+			pv.backend.Unicast(proxy.peer, payload, istanbul.ReplicaHeartbeatMsg)
+		}
+	}
+}
+
+// handleReplicaHeartbeatMsg relays a heartbeat from one of the proxied validators, the primary,
+// to the other proxied validators, its replicas.
+func (p *proxyEngine) handleReplicaHeartbeatMsg(peer consensus.Peer, payload []byte) (bool, error) {
+	logger := p.logger.New("func", "handleReplicaHeartbeatMsg")
+
+	p.proxiedValidatorsMu.RLock()
+	defer p.proxiedValidatorsMu.RUnlock()
+	if ok := p.proxiedValidatorIDs[peer.Node().ID()]; !ok {
+		logger.Warn("Got a replica heartbeat from a peer that is not a proxied validator. Ignoring it", "from", peer.Node().ID())
+		return false, nil
+	}
+
+	istMsg := new(istanbul.Message)
+	if err := istMsg.FromPayload(payload, istanbul.GetSignatureAddress); err != nil {
+		logger.Error("Failed to decode message from payload", "from", peer.Node().ID(), "err", err)
+		return true, err
+	}
+	if istMsg.Address != p.config.ProxiedValidatorAddress {
+		logger.Error("Unauthorized replica heartbeat", "sender address", istMsg.Address, "authorized sender address", p.config.ProxiedValidatorAddress)
+		return true, errUnauthorizedMessageFromProxiedValidator
+	}
+
+	for proxiedValidator := range p.proxiedValidators {
+		if proxiedValidator.Node().ID() != peer.Node().ID() {
+			p.backend.Unicast(proxiedValidator, payload, istanbul.ReplicaHeartbeatMsg)
+		}
+	}
+	return true, nil
+}
@@ -112,6 +112,10 @@ type ProxiedValidatorEngine interface {
 	// SendEnodeCertsToAllProxies will send the enode certs to the appropriate proxy.
 	SendEnodeCertsToAllProxies(map[enode.ID]*istanbul.EnodeCertMsg) error
 
+	// SendReplicaHeartbeatToAllProxies will send the signed replica heartbeat message to all of the
+	// proxies, which relay it to the other validators they proxy.
+	SendReplicaHeartbeatToAllProxies(payload []byte) error
+
 	// GetValidatorProxyAssignments will retrieve all the remote validator to proxy assignments.
 	GetValidatorProxyAssignments(validators []common.Address) (map[common.Address]*Proxy, error)
 
@@ -135,6 +139,9 @@ type Proxy struct {
 	externalNode *enode.Node    // Enode for the external network interface
 	peer         consensus.Peer // Connected proxy peer.  Is nil if this node is not connected to the proxy
 	disconnectTS time.Time      // Timestamp when this proxy's peer last disconnected. Initially set to the timestamp of when the proxy was added
+	latency      time.Duration  // Smoothed round trip time to the proxy peer.  Is zero until measured
+	unhealthy    bool           // Set if the proxy's latency is too high for it to be assigned remote validators
+	assigned     bool           // Set if the proxy is known by the valAssigner, and can be assigned remote validators
 }
 
 func (p *Proxy) ID() enode.ID {
@@ -149,8 +156,29 @@ func (p *Proxy) IsPeered() bool {
 	return p.peer != nil
 }
 
+// IsHealthy returns whether the proxy is peered and its latency is low enough to be assigned remote validators
+func (p *Proxy) IsHealthy() bool {
+	return p.peer != nil && !p.unhealthy
+}
+
+// sampleLatency folds the round trip time last measured on the proxy peer into the proxy's latency
+func (p *Proxy) sampleLatency() {
+	if p.peer == nil {
+		return
+	}
+	rtt := p.peer.RTT()
+	if rtt <= 0 {
+		return
+	}
+	if p.latency == 0 {
+		p.latency = rtt
+	} else {
+		p.latency = (7*p.latency + 3*rtt) / 10
+	}
+}
+
 func (p *Proxy) String() string {
-	return fmt.Sprintf("{internalNode: %v, externalNode %v, dcTimestamp: %v, ID: %v}", p.node, p.externalNode, p.disconnectTS, p.ID())
+	return fmt.Sprintf("{internalNode: %v, externalNode %v, dcTimestamp: %v, latency: %v, healthy: %v, ID: %v}", p.node, p.externalNode, p.disconnectTS, p.latency, p.IsHealthy(), p.ID())
 }
 
 // ProxyInfo is used to provide info on a proxy that can be given via an RPC
@@ -158,6 +186,8 @@ type ProxyInfo struct {
 	InternalNode             *enode.Node      `json:"internalEnodeUrl"`
 	ExternalNode             *enode.Node      `json:"externalEnodeUrl"`
 	IsPeered                 bool             `json:"isPeered"`
+	IsHealthy                bool             `json:"isHealthy"`
+	Latency                  int64            `json:"latency"`               // Smoothed round trip time to the proxy in milliseconds, zero if unknown
 	AssignedRemoteValidators []common.Address `json:"validators"`            // All validator addresses assigned to the proxy
 	DisconnectTS             int64            `json:"disconnectedTimestamp"` // Unix time of the last disconnect of the peer
 }
@@ -167,6 +197,8 @@ func NewProxyInfo(p *Proxy, assignedVals []common.Address) *ProxyInfo {
 		InternalNode:             p.node,
 		ExternalNode:             p.ExternalNode(),
 		IsPeered:                 p.IsPeered(),
+		IsHealthy:                p.IsHealthy(),
+		Latency:                  p.latency.Milliseconds(),
 		DisconnectTS:             p.disconnectTS.Unix(),
 		AssignedRemoteValidators: assignedVals,
 	}
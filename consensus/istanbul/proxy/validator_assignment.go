@@ -17,6 +17,8 @@
 package proxy
 
 import (
+	"time"
+
 	"github.com/buraksezer/consistent"
 	"github.com/cespare/xxhash/v2"
 	"github.com/mapprotocol/atlas/core/types"
@@ -33,8 +35,9 @@ import (
 // WARNING:  None of this object's functions are threadsafe, so it's
 //           the user's responsibility to ensure that.
 type valAssignments struct {
-	valToProxy  map[common.Address]*enode.ID             // map of validator address -> proxy assignment ID
-	proxyToVals map[enode.ID]map[common.Address]struct{} // map of proxy ID to set of validator addresses
+	valToProxy  map[common.Address]*enode.ID              // map of validator address -> proxy assignment ID
+	proxyToVals map[enode.ID]map[common.Address]struct{}  // map of proxy ID to set of validator addresses
+	handoffs    map[enode.ID]map[common.Address]time.Time // map of proxy ID to the validators reassigned away from it, and when
 	logger      log.Logger
 }
 
@@ -42,6 +45,7 @@ func newValAssignments() *valAssignments {
 	return &valAssignments{
 		valToProxy:  make(map[common.Address]*enode.ID),
 		proxyToVals: make(map[enode.ID]map[common.Address]struct{}),
+		handoffs:    make(map[enode.ID]map[common.Address]time.Time),
 		logger:      log.New(),
 	}
 }
@@ -63,6 +67,9 @@ func (va *valAssignments) removeValidators(vals []common.Address) {
 	for _, val := range vals {
 		va.unassignValidator(val)
 		delete(va.valToProxy, val)
+		for proxyID := range va.handoffs {
+			va.removeHandoff(proxyID, val)
+		}
 	}
 }
 
@@ -76,6 +83,56 @@ func (va *valAssignments) assignValidator(valAddress common.Address, proxyID eno
 	}
 
 	va.proxyToVals[proxyID][valAddress] = struct{}{}
+	va.removeHandoff(proxyID, valAddress)
+}
+
+// reassignValidator moves a validator with address valAddress to the proxy with ID proxyID.
+// The proxy it was assigned to keeps it as a handoff, so that the proxy stays connected
+// to the validator until the validator has learned about its new proxy.
+func (va *valAssignments) reassignValidator(valAddress common.Address, proxyID enode.ID) {
+	if oldProxyID := va.valToProxy[valAddress]; oldProxyID != nil && *oldProxyID != proxyID {
+		if _, ok := va.handoffs[*oldProxyID]; !ok {
+			va.handoffs[*oldProxyID] = make(map[common.Address]time.Time)
+		}
+		va.handoffs[*oldProxyID][valAddress] = time.Now()
+	}
+	va.unassignValidator(valAddress)
+	va.assignValidator(valAddress, proxyID)
+}
+
+// removeHandoff removes the validator with address valAddress from the handoffs of the proxy
+// with ID proxyID
+func (va *valAssignments) removeHandoff(proxyID enode.ID, valAddress common.Address) {
+	if vals, ok := va.handoffs[proxyID]; ok {
+		delete(vals, valAddress)
+		if len(vals) == 0 {
+			delete(va.handoffs, proxyID)
+		}
+	}
+}
+
+// getHandoffValidators returns the validators that were reassigned away from the proxy with ID proxyID
+// and are still handed off by it
+func (va *valAssignments) getHandoffValidators(proxyID enode.ID) []common.Address {
+	vals := make([]common.Address, 0, len(va.handoffs[proxyID]))
+	for val := range va.handoffs[proxyID] {
+		vals = append(vals, val)
+	}
+	return vals
+}
+
+// expireHandoffs removes the handoffs that are at least minAge old.  Returns true if any was removed.
+func (va *valAssignments) expireHandoffs(minAge time.Duration) bool {
+	expired := false
+	for proxyID, vals := range va.handoffs {
+		for val, reassignedTS := range vals {
+			if time.Since(reassignedTS) >= minAge {
+				va.removeHandoff(proxyID, val)
+				expired = true
+			}
+		}
+	}
+	return expired
 }
 
 // unassignValidator unassigns a validator with address valAddress from
@@ -204,8 +261,7 @@ func (ch *consistentHashingPolicy) reassignValidators(valAssignments *valAssignm
 			}
 			logger.Trace("Reassigning validator", "validator", val, "original proxy", proxyIDStr, "new proxy", newProxyID.String())
 
-			valAssignments.unassignValidator(val)
-			valAssignments.assignValidator(val, enode.HexID(newProxyID.String()))
+			valAssignments.reassignValidator(val, enode.HexID(newProxyID.String()))
 			anyAssignmentsChanged = true
 		}
 	}
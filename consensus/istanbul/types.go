@@ -17,7 +17,9 @@
 package istanbul
 
 import (
+	"bytes"
 	"crypto/ecdsa"
+	"crypto/rand"
 	"fmt"
 	"github.com/ethereum/go-ethereum/log"
 	"io"
@@ -647,6 +649,7 @@ type Message struct {
 	enodeCertificate    *EnodeCertificate
 	versionCertificates []*VersionCertificate
 	valEnodeShareData   *ValEnodesShareData
+	replicaHeartbeat    *ReplicaHeartbeat
 }
 
 // setMessageBytes sets the Msg field of msg to the rlp serialised bytes of
@@ -715,6 +718,10 @@ func (m *Message) DecodeMessage() error {
 		var v *ValEnodesShareData
 		err = m.decode(&v)
 		m.valEnodeShareData = v
+	case ReplicaHeartbeatMsg:
+		var h *ReplicaHeartbeat
+		err = m.decode(&h)
+		m.replicaHeartbeat = h
 	default:
 		err = fmt.Errorf("unrecognised message code %d", m.Code)
 	}
@@ -803,6 +810,20 @@ func (m *Message) Prepare() *Subject {
 	return m.prepare
 }
 
+// ConsensusSubject returns the view and the digest of the proposal a preprepare,
+// prepare or commit message is about. ok is false for any other message.
+func (m *Message) ConsensusSubject() (view *View, digest common.Hash, ok bool) {
+	switch {
+	case m.Code == MsgPreprepare && m.prePrepare != nil && m.prePrepare.View != nil && m.prePrepare.Proposal != nil:
+		return m.prePrepare.View, m.prePrepare.Proposal.Hash(), true
+	case m.Code == MsgPrepare && m.prepare != nil && m.prepare.View != nil:
+		return m.prepare.View, m.prepare.Digest, true
+	case m.Code == MsgCommit && m.committedSubject != nil && m.committedSubject.Subject != nil && m.committedSubject.Subject.View != nil:
+		return m.committedSubject.Subject.View, m.committedSubject.Subject.Digest, true
+	}
+	return nil, common.Hash{}, false
+}
+
 // Prepare returns round change if this is a round change message.
 func (m *Message) TryRoundChange() (*RoundChange, error) {
 	if m.roundChange != nil {
@@ -849,6 +870,11 @@ func (m *Message) ValEnodesShareData() *ValEnodesShareData {
 	return m.valEnodeShareData
 }
 
+// ReplicaHeartbeat returns the heartbeat if this is a replica heartbeat message.
+func (m *Message) ReplicaHeartbeat() *ReplicaHeartbeat {
+	return m.replicaHeartbeat
+}
+
 func (m *Message) Copy() *Message {
 	return &Message{
 		Code:      m.Code,
@@ -1192,3 +1218,165 @@ func (sd *ValEnodesShareData) DecodeRLP(s *rlp.Stream) error {
 	sd.ValEnodes = msg.ValEnodes
 	return nil
 }
+
+// ## ReplicaHeartbeat ######################################################################
+
+// NewReplicaHeartbeatMessage constructs a Message instance with the given sender
+// and heartbeat. Both the heartbeat instance and the serialized bytes of
+// heartbeat are part of the returned Message.
+func NewReplicaHeartbeatMessage(heartbeat *ReplicaHeartbeat, sender common.Address) *Message {
+	message := &Message{
+		Address:          sender,
+		Code:             ReplicaHeartbeatMsg,
+		replicaHeartbeat: heartbeat,
+	}
+	setMessageBytes(message, heartbeat)
+	return message
+}
+
+// FencingToken grants the node holding it the right to sign from Sequence onward.
+// A node taking over as primary signs a token with a term above any it has seen,
+// so for every sequence the token superseding all others starting at or before it
+// designates the only node allowed to sign. Nodes taking over at the same time may
+// pick the same term, the random Nonce breaks the tie.
+type FencingToken struct {
+	Term      uint64
+	Sequence  *big.Int
+	Signature []byte
+	Nonce     common.Hash `rlp:"optional"`
+}
+
+// NewFencingToken creates a fencing token for the given term and sequence with a random
+// nonce, signed with signFn.
+func NewFencingToken(term uint64, seq *big.Int, signFn func(data []byte) ([]byte, error)) (*FencingToken, error) {
+	token := &FencingToken{Term: term, Sequence: new(big.Int).Set(seq)}
+	if _, err := rand.Read(token.Nonce[:]); err != nil {
+		return nil, err
+	}
+	payload, err := token.signaturePayload()
+	if err != nil {
+		return nil, err
+	}
+	if token.Signature, err = signFn(payload); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+func (ft *FencingToken) signaturePayload() ([]byte, error) {
+	return rlp.EncodeToBytes([]interface{}{ft.Term, ft.Sequence, ft.Nonce})
+}
+
+// Signer recovers the address that signed the token.
+func (ft *FencingToken) Signer() (common.Address, error) {
+	payload, err := ft.signaturePayload()
+	if err != nil {
+		return common.Address{}, err
+	}
+	return GetSignatureAddress(payload, ft.Signature)
+}
+
+// Supersedes reports whether ft takes precedence over other: it has a higher term, or the
+// same term and a lower nonce.
+func (ft *FencingToken) Supersedes(other *FencingToken) bool {
+	if ft.Term != other.Term {
+		return ft.Term > other.Term
+	}
+	return bytes.Compare(ft.Nonce[:], other.Nonce[:]) < 0
+}
+
+// Equal reports whether ft and other are the very same token.
+func (ft *FencingToken) Equal(other *FencingToken) bool {
+	return ft.Term == other.Term && ft.Nonce == other.Nonce && ft.Sequence.Cmp(other.Sequence) == 0 &&
+		bytes.Equal(ft.Signature, other.Signature)
+}
+
+func (ft *FencingToken) String() string {
+	return fmt.Sprintf("{Term: %d, Sequence: %v, Nonce: %s}", ft.Term, ft.Sequence, ft.Nonce.TerminalString())
+}
+
+// ReplicaHeartbeat is sent by a primary validator to its replicas through the shared
+// proxies on every new sequence, carrying the fencing token it signs with.
+type ReplicaHeartbeat struct {
+	Token    *FencingToken
+	Sequence *big.Int
+}
+
+func (h *ReplicaHeartbeat) String() string {
+	return fmt.Sprintf("{Token: %v, Sequence: %v}", h.Token, h.Sequence)
+}
+
+// ## Evidence ##############################################################################
+
+// Evidence proves that a validator equivocated: it holds two conflicting consensus
+// messages with the same code and view, both signed by Signer.
+type Evidence struct {
+	Code   uint64
+	View   *View
+	Signer common.Address
+	First  []byte // Signed payload of the message seen first
+	Second []byte // Signed payload of the conflicting message
+}
+
+// NewEvidence creates the evidence for two conflicting messages, which must have
+// been decoded from their payloads and had their signatures checked.
+func NewEvidence(first, second *Message) (*Evidence, error) {
+	view, digest, ok := first.ConsensusSubject()
+	if !ok || first.Code != second.Code || first.Address != second.Address {
+		return nil, ErrInvalidEvidence
+	}
+	otherView, otherDigest, ok := second.ConsensusSubject()
+	if !ok || view.Cmp(otherView) != 0 || digest == otherDigest {
+		return nil, ErrInvalidEvidence
+	}
+	firstPayload, err := first.Payload()
+	if err != nil {
+		return nil, err
+	}
+	secondPayload, err := second.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return &Evidence{
+		Code:   first.Code,
+		View:   &View{Round: new(big.Int).Set(view.Round), Sequence: new(big.Int).Set(view.Sequence)},
+		Signer: first.Address,
+		First:  firstPayload,
+		Second: secondPayload,
+	}, nil
+}
+
+// Hash identifies the evidence independently of the order its messages were seen in.
+func (e *Evidence) Hash() common.Hash {
+	first, second := e.First, e.Second
+	if bytes.Compare(first, second) > 0 {
+		first, second = second, first
+	}
+	return crypto.Keccak256Hash(first, second)
+}
+
+// Verify checks that both messages are signed by Signer and conflict for the evidence's code and view.
+func (e *Evidence) Verify() error {
+	var first, second Message
+	if err := first.FromPayload(e.First, GetSignatureAddress); err != nil {
+		return err
+	}
+	if err := second.FromPayload(e.Second, GetSignatureAddress); err != nil {
+		return err
+	}
+	if first.Address != e.Signer || first.Code != e.Code {
+		return ErrInvalidEvidence
+	}
+	expected, err := NewEvidence(&first, &second)
+	if err != nil {
+		return err
+	}
+	if expected.View.Cmp(e.View) != 0 {
+		return ErrInvalidEvidence
+	}
+	return nil
+}
+
+func (e *Evidence) String() string {
+	return fmt.Sprintf("{Code: %d, View: %v, Signer: %v}", e.Code, e.View, e.Signer.Hex())
+}
@@ -23,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
 
 	"github.com/mapprotocol/atlas/core/types"
@@ -391,3 +392,35 @@ func TestForwardMessageRLPEncoding(t *testing.T) {
 		t.Fatalf("RLP Encode/Decode mismatch. Got %v, expected %v", result, original)
 	}
 }
+
+func TestReplicaHeartbeatMessageRLPEncoding(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	signFn := func(data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), key)
+	}
+
+	token, err := NewFencingToken(3, big.NewInt(100), signFn)
+	if err != nil {
+		t.Fatalf("Error %v", err)
+	}
+	original := NewReplicaHeartbeatMessage(&ReplicaHeartbeat{Token: token, Sequence: big.NewInt(105)}, addr)
+	if err := original.Sign(signFn); err != nil {
+		t.Fatalf("Error %v", err)
+	}
+	payload, err := original.Payload()
+	if err != nil {
+		t.Fatalf("Error %v", err)
+	}
+
+	result := new(Message)
+	if err := result.FromPayload(payload, GetSignatureAddress); err != nil {
+		t.Fatalf("Error %v", err)
+	}
+	if !reflect.DeepEqual(original.ReplicaHeartbeat(), result.ReplicaHeartbeat()) {
+		t.Fatalf("RLP Encode/Decode mismatch. Got %v, expected %v", result.ReplicaHeartbeat(), original.ReplicaHeartbeat())
+	}
+	if signer, err := result.ReplicaHeartbeat().Token.Signer(); err != nil || signer != addr {
+		t.Fatalf("Expected token signed by %v, got %v (err: %v)", addr, signer, err)
+	}
+}
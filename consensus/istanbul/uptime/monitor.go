@@ -17,14 +17,26 @@ import (
 type Store interface {
 	ReadAccumulatedEpochUptime(epoch uint64) *Uptime
 	WriteAccumulatedEpochUptime(epoch uint64, uptime *Uptime)
+	ReadSignerRecord(number uint64) *SignerRecord
+	WriteSignerRecord(number uint64, record *SignerRecord)
+}
+
+// SignerRecord contains the signing history of a single block, as carried by the parent
+// aggregated seal of its child. The `i`th bit of Signers is set if the `i`th validator of the
+// block's validator set committed to it. Round is the round in which the block was committed,
+// so the proposers of the rounds before it failed to get a block in.
+type SignerRecord struct {
+	Signers *big.Int
+	Round   uint64
 }
 
 // Uptime contains the latest block for which uptime metrics were accounted. It also contains
 // an array of Entries where the `i`th entry represents the uptime statistics of the `i`th validator
 // in the validator set for that epoch
 type Uptime struct {
-	LatestBlock uint64
-	Entries     []UptimeEntry
+	LatestBlock    uint64
+	Entries        []UptimeEntry
+	LookbackWindow uint64 `rlp:"optional"` // lookback window the entries were accounted with, 0 if unknown
 }
 
 // UptimeEntry contains the uptime score of a validator during an epoch as well as the
@@ -137,11 +149,38 @@ func (um *Monitor) GetValidatorsActivity(epoch, numberWithinEpoch uint64, valSet
 	return accumulated.Entries, uptimes, nil
 }
 
+// EpochUptime retrieves the accumulated uptime of a given epoch, along with the number of blocks
+// of its monitoring window accounted so far, which is the window size once the epoch is over
+func (um *Monitor) EpochUptime(epoch uint64) (*Uptime, uint64, error) {
+	accumulated := um.store.ReadAccumulatedEpochUptime(epoch)
+	if accumulated == nil {
+		return nil, 0, fmt.Errorf("accumulated uptimes not found for epoch %d", epoch)
+	}
+	lookbackWindow := accumulated.LookbackWindow
+	if lookbackWindow == 0 {
+		lookbackWindow = um.lookbackWindow
+	}
+	window, err := MonitoringWindow(epoch, um.epochSize, lookbackWindow)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// The latest block accounted the signatures of its parent
+	last := accumulated.LatestBlock - 1
+	switch {
+	case accumulated.LatestBlock == 0 || last < window.Start:
+		return accumulated, 0, nil
+	case last > window.End:
+		return accumulated, window.Size(), nil
+	default:
+		return accumulated, last - window.Start + 1, nil
+	}
+}
+
 // ProcessBlock uses the block's signature bitmap (which encodes who signed the parent block) to update the epoch's Uptime data
+// and records the signers of the parent block in the signing history
 func (um *Monitor) ProcessBlock(block *types.Block) error {
-	// The epoch's first block's aggregated parent signatures is for the previous epoch's valset.
-	// We can ignore updating the tally for that block.
-	if istanbul.IsFirstBlockOfEpoch(block.NumberU64(), um.epochSize) {
+	if block.NumberU64() == 0 {
 		return nil
 	}
 
@@ -152,6 +191,13 @@ func (um *Monitor) ProcessBlock(block *types.Block) error {
 		return errors.New("could not extract block header extra")
 	}
 	signedValidatorsBitmap := extra.ParentAggregatedSeal.Bitmap
+	um.store.WriteSignerRecord(block.NumberU64()-1, newSignerRecord(extra.ParentAggregatedSeal))
+
+	// The epoch's first block's aggregated parent signatures is for the previous epoch's valset.
+	// We can ignore updating the tally for that block.
+	if istanbul.IsFirstBlockOfEpoch(block.NumberU64(), um.epochSize) {
+		return nil
+	}
 
 	// Get the uptime scores
 	epochNum := istanbul.GetEpochNumber(block.NumberU64(), um.epochSize)
@@ -162,6 +208,7 @@ func (um *Monitor) ProcessBlock(block *types.Block) error {
 	if uptime == nil || uptime.LatestBlock < block.NumberU64() {
 		uptime = updateUptime(uptime, block.NumberU64()-1, signedValidatorsBitmap, um.lookbackWindow, um.MonitoringWindow(epochNum))
 		uptime.LatestBlock = block.NumberU64()
+		uptime.LookbackWindow = um.lookbackWindow
 		um.store.WriteAccumulatedEpochUptime(epochNum, uptime)
 	} else {
 		log.Trace("WritingBlockWithState with block number less than a block we previously wrote", "latestUptimeBlock", uptime.LatestBlock, "blockNumber", block.NumberU64())
@@ -170,6 +217,18 @@ func (um *Monitor) ProcessBlock(block *types.Block) error {
 	return nil
 }
 
+// newSignerRecord builds the signing history of a block from the parent aggregated seal of its child
+func newSignerRecord(seal types.IstanbulAggregatedSeal) *SignerRecord {
+	record := &SignerRecord{Signers: new(big.Int)}
+	if seal.Bitmap != nil {
+		record.Signers.Set(seal.Bitmap)
+	}
+	if seal.Round != nil {
+		record.Round = seal.Round.Uint64()
+	}
+	return record
+}
+
 // updateUptime updates the accumulated uptime given a block and its validator's signatures bitmap
 func updateUptime(uptime *Uptime, blockNumber uint64, bitmap *big.Int, lookbackWindowSize uint64, monitoringWindow Window) *Uptime {
 	if uptime == nil {
@@ -4,6 +4,9 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/mapprotocol/atlas/core/types"
 )
 
 func TestUptime(t *testing.T) {
@@ -102,3 +105,90 @@ func TestUptimeSingle(t *testing.T) {
 		t.Fatalf("uptimes were not updated correctly, got %v, expected %v", uptimes, expected)
 	}
 }
+
+type memoryStore struct {
+	uptimes map[uint64]*Uptime
+	signers map[uint64]*SignerRecord
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{uptimes: make(map[uint64]*Uptime), signers: make(map[uint64]*SignerRecord)}
+}
+
+func (s *memoryStore) ReadAccumulatedEpochUptime(epoch uint64) *Uptime { return s.uptimes[epoch] }
+func (s *memoryStore) WriteAccumulatedEpochUptime(epoch uint64, uptime *Uptime) {
+	s.uptimes[epoch] = uptime
+}
+func (s *memoryStore) ReadSignerRecord(number uint64) *SignerRecord { return s.signers[number] }
+func (s *memoryStore) WriteSignerRecord(number uint64, record *SignerRecord) {
+	s.signers[number] = record
+}
+
+func newTestBlock(t *testing.T, number uint64, bitmap *big.Int, round int64) *types.Block {
+	extra := &types.IstanbulExtra{
+		RemovedValidators:    new(big.Int),
+		Seal:                 []byte{},
+		AggregatedSeal:       types.IstanbulAggregatedSeal{Bitmap: new(big.Int), Signature: []byte{}, Round: new(big.Int)},
+		ParentAggregatedSeal: types.IstanbulAggregatedSeal{Bitmap: bitmap, Signature: []byte{}, Round: big.NewInt(round)},
+	}
+	payload, err := rlp.EncodeToBytes(extra)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := &types.Header{
+		Number: new(big.Int).SetUint64(number),
+		Extra:  append(make([]byte, types.IstanbulExtraVanity), payload...),
+	}
+	return types.NewBlockWithHeader(header)
+}
+
+func TestProcessBlockSignerRecords(t *testing.T) {
+	store := newMemoryStore()
+	monitor := NewMonitor(store, 10, 2) // monitoring window of epoch 1 is [2,8]
+
+	// blocks 1 to 9 carry the signers of blocks 0 to 8, block 4 took two extra rounds
+	for number := uint64(1); number <= 9; number++ {
+		bitmap, round := big.NewInt(7), int64(0)
+		if number == 5 {
+			bitmap, round = big.NewInt(5), 2
+		}
+		if err := monitor.ProcessBlock(newTestBlock(t, number, bitmap, round)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if record := store.ReadSignerRecord(4); record.Signers.Cmp(big.NewInt(5)) != 0 || record.Round != 2 {
+		t.Errorf("signer record of block 4 = %v, want signers 5 and round 2", record)
+	}
+	if record := store.ReadSignerRecord(9); record != nil {
+		t.Errorf("signers of block 9 are not known yet, got %v", record)
+	}
+
+	uptime, monitored, err := monitor.EpochUptime(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if monitored != 7 {
+		t.Errorf("monitored blocks = %d, want 7", monitored)
+	}
+	if uptime.LookbackWindow != 2 {
+		t.Errorf("lookback window = %d, want 2", uptime.LookbackWindow)
+	}
+	// the second validator missed block 4, but signed within the lookback window of all monitored blocks
+	for i, want := range []uint64{7, 7, 7} {
+		if got := uptime.Entries[i].UpBlocks; got != want {
+			t.Errorf("validator %d up blocks = %d, want %d", i, got, want)
+		}
+	}
+
+	// the first block of the next epoch still records the last block of this one
+	if err := monitor.ProcessBlock(newTestBlock(t, 11, big.NewInt(3), 0)); err != nil {
+		t.Fatal(err)
+	}
+	if record := store.ReadSignerRecord(10); record == nil || record.Signers.Cmp(big.NewInt(3)) != 0 {
+		t.Errorf("signer record of block 10 = %v, want signers 3", record)
+	}
+	if _, _, err := monitor.EpochUptime(2); err == nil {
+		t.Error("expected no uptime for epoch 2")
+	}
+}
@@ -22,3 +22,10 @@ func (us *uptimeStoreImpl) ReadAccumulatedEpochUptime(epoch uint64) *uptime.Upti
 func (us *uptimeStoreImpl) WriteAccumulatedEpochUptime(epoch uint64, uptime *uptime.Uptime) {
 	rawdb.WriteAccumulatedEpochUptime(us.db, epoch, uptime)
 }
+
+func (us *uptimeStoreImpl) ReadSignerRecord(number uint64) *uptime.SignerRecord {
+	return rawdb.ReadSignerRecord(us.db, number)
+}
+func (us *uptimeStoreImpl) WriteSignerRecord(number uint64, record *uptime.SignerRecord) {
+	rawdb.WriteSignerRecord(us.db, number, record)
+}
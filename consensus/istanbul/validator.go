@@ -125,6 +125,21 @@ type ValidatorSet interface {
 	// Get the minimum quorum size
 	MinQuorumSize() int
 
+	// Weighted reports whether the validators have voting power assigned, otherwise
+	// each of them counts as one vote
+	Weighted() bool
+	// Weights returns the voting power of the validators in the order of List
+	Weights() []*big.Int
+	// SetWeights assigns the voting power of the validators in the order of List,
+	// nil or an empty list makes the set unweighted
+	SetWeights(weights []*big.Int) bool
+	// TotalWeight returns the voting power of all validators
+	TotalWeight() *big.Int
+	// QuorumWeight returns the minimum voting power of a quorum, MinQuorumSize if unweighted
+	QuorumWeight() *big.Int
+	// HasQuorum reports whether the given validators hold a quorum of the voting power
+	HasQuorum(addrs []common.Address) bool
+
 	// List returns all the validators
 	List() []Validator
 	// Return the validator index
@@ -157,6 +172,7 @@ type ValidatorSet interface {
 type ValidatorSetData struct {
 	Validators []ValidatorData
 	Randomness common.Hash
+	Weights    []*big.Int `rlp:"optional"`
 }
 
 type ValidatorSetDataWithBLSKeyCache struct {
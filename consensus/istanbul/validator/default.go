@@ -18,6 +18,7 @@ package validator
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -32,6 +33,8 @@ import (
 	blscrypto "github.com/mapprotocol/atlas/helper/bls"
 )
 
+var errInvalidWeights = errors.New("invalid validator weights")
+
 type defaultValidator struct {
 	address                  common.Address
 	blsPublicKey             blscrypto.SerializedPublicKey
@@ -142,6 +145,8 @@ type defaultSet struct {
 	// This is set when we call `getOrderedValidators`
 	// TODO Rename to `EpochState` that has validators & randomness
 	randomness common.Hash
+	// voting power of the validators, nil if each validator counts as one vote
+	weights []*big.Int
 }
 
 func newDefaultSet(validators []istanbul.ValidatorData) *defaultSet {
@@ -180,6 +185,103 @@ func newDefaultSetFromDataWithBLSKeyCache(validators []istanbul.ValidatorDataWit
 func (valSet *defaultSet) F() int             { return int(math.Ceil(float64(valSet.Size())/3)) - 1 }
 func (valSet *defaultSet) MinQuorumSize() int { return int(math.Ceil(float64(2*valSet.Size()) / 3)) }
 
+func (valSet *defaultSet) Weighted() bool {
+	valSet.validatorMu.RLock()
+	defer valSet.validatorMu.RUnlock()
+	return valSet.weights != nil
+}
+
+func (valSet *defaultSet) Weights() []*big.Int {
+	valSet.validatorMu.RLock()
+	defer valSet.validatorMu.RUnlock()
+	weights := make([]*big.Int, len(valSet.validators))
+	for i := range valSet.validators {
+		weights[i] = valSet.weightAt(i)
+	}
+	return weights
+}
+
+// weightAt returns the voting power of the i-th validator, the caller holds the lock.
+func (valSet *defaultSet) weightAt(i int) *big.Int {
+	if valSet.weights == nil {
+		return big.NewInt(1)
+	}
+	return new(big.Int).Set(valSet.weights[i])
+}
+
+func (valSet *defaultSet) SetWeights(weights []*big.Int) bool {
+	valSet.validatorMu.Lock()
+	defer valSet.validatorMu.Unlock()
+	if len(weights) == 0 {
+		valSet.weights = nil
+		return true
+	}
+	if len(weights) != len(valSet.validators) {
+		return false
+	}
+	total := new(big.Int)
+	for _, w := range weights {
+		if w == nil || w.Sign() < 0 {
+			return false
+		}
+		total.Add(total, w)
+	}
+	// without any voting power every set of validators would be a quorum
+	if total.Sign() == 0 {
+		return false
+	}
+	valSet.weights = make([]*big.Int, len(weights))
+	for i, w := range weights {
+		valSet.weights[i] = new(big.Int).Set(w)
+	}
+	return true
+}
+
+func (valSet *defaultSet) TotalWeight() *big.Int {
+	valSet.validatorMu.RLock()
+	defer valSet.validatorMu.RUnlock()
+	total := new(big.Int)
+	for i := range valSet.validators {
+		total.Add(total, valSet.weightAt(i))
+	}
+	return total
+}
+
+// QuorumWeight follows the same rule as MinQuorumSize: any two sets holding at least 2/3 of
+// the voting power overlap by more than 1/3 of it, so the overlap holds some honest power as
+// long as the faulty validators hold less than 1/3. With all weights 1 it is MinQuorumSize.
+func (valSet *defaultSet) QuorumWeight() *big.Int {
+	if !valSet.Weighted() {
+		return big.NewInt(int64(valSet.MinQuorumSize()))
+	}
+	// ceil(2 * total / 3)
+	quorum := new(big.Int).Mul(valSet.TotalWeight(), big.NewInt(2))
+	quorum.Add(quorum, big.NewInt(2))
+	return quorum.Div(quorum, big.NewInt(3))
+}
+
+func (valSet *defaultSet) HasQuorum(addrs []common.Address) bool {
+	quorum := valSet.QuorumWeight()
+
+	valSet.validatorMu.RLock()
+	defer valSet.validatorMu.RUnlock()
+	seen := make(map[common.Address]bool, len(addrs))
+	power := new(big.Int)
+	for _, addr := range addrs {
+		if seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		for i, v := range valSet.validators {
+			if v.Address() == addr {
+				power.Add(power, valSet.weightAt(i))
+				break
+			}
+		}
+	}
+	return power.Cmp(quorum) >= 0
+}
+
 func (valSet *defaultSet) SetRandomness(seed common.Hash) { valSet.randomness = seed }
 func (valSet *defaultSet) GetRandomness() common.Hash     { return valSet.randomness }
 
@@ -272,6 +374,10 @@ func (valSet *defaultSet) AddValidators(validators []istanbul.ValidatorData) boo
 	}
 
 	valSet.validators = append(valSet.validators, newValidators...)
+	// The voting power of new validators is unknown, it is set again by SetWeights
+	if len(newValidators) > 0 {
+		valSet.weights = nil
+	}
 
 	return true
 }
@@ -291,13 +397,21 @@ func (valSet *defaultSet) RemoveValidators(removedValidators *big.Int) bool {
 	// Using this method to filter the validators list: https://github.com/golang/go/wiki/SliceTricks#filtering-without-allocating, so that no
 	// new memory will be allocated
 	tempList := valSet.validators[:0]
+	var tempWeights []*big.Int
+	if valSet.weights != nil {
+		tempWeights = valSet.weights[:0]
+	}
 	for i, v := range valSet.validators {
 		if removedValidators.Bit(i) == 0 {
 			tempList = append(tempList, v)
+			if valSet.weights != nil {
+				tempWeights = append(tempWeights, valSet.weights[i])
+			}
 		}
 	}
 
 	valSet.validators = tempList
+	valSet.weights = tempWeights
 	return true
 }
 
@@ -309,6 +423,12 @@ func (valSet *defaultSet) Copy() istanbul.ValidatorSet {
 	for i, v := range valSet.validators {
 		newValSet.validators[i] = v.Copy()
 	}
+	if valSet.weights != nil {
+		newValSet.weights = make([]*big.Int, len(valSet.weights))
+		for i, w := range valSet.weights {
+			newValSet.weights[i] = new(big.Int).Set(w)
+		}
+	}
 	newValSet.SetRandomness(valSet.randomness)
 	return newValSet
 }
@@ -328,6 +448,7 @@ func (valSet *defaultSet) AsData() *istanbul.ValidatorSetData {
 	return &istanbul.ValidatorSetData{
 		Validators: MapValidatorsToData(valSet.validators),
 		Randomness: valSet.randomness,
+		Weights:    valSet.weights,
 	}
 }
 
@@ -342,6 +463,9 @@ func (val *defaultSet) UnmarshalJSON(b []byte) error {
 	}
 	*val = *newDefaultSet(data.Validators)
 	val.SetRandomness(data.Randomness)
+	if !val.SetWeights(data.Weights) {
+		return errInvalidWeights
+	}
 	return nil
 }
 
@@ -356,6 +480,9 @@ func (val *defaultSet) DecodeRLP(stream *rlp.Stream) error {
 	}
 	*val = *newDefaultSet(data.Validators)
 	val.SetRandomness(data.Randomness)
+	if !val.SetWeights(data.Weights) {
+		return errInvalidWeights
+	}
 	return nil
 }
 
@@ -40,6 +40,7 @@ func TestValidatorSet(t *testing.T) {
 	t.Run("EmptyValSet", testEmptyValSet)
 	t.Run("AddAndRemoveValidator", testAddAndRemoveValidator)
 	t.Run("QuorumSizes", testQuorumSizes)
+	t.Run("WeightedQuorum", testWeightedQuorum)
 }
 
 func testNewValidatorSet(t *testing.T) {
@@ -214,6 +215,58 @@ func testQuorumSizes(t *testing.T) {
 	}
 }
 
+func testWeightedQuorum(t *testing.T) {
+	addrs := []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02"), common.HexToAddress("0x03"), common.HexToAddress("0x04")}
+	data := make([]istanbul.ValidatorData, len(addrs))
+	for i, addr := range addrs {
+		data[i] = istanbul.ValidatorData{Address: addr}
+	}
+	valSet := newDefaultSet(data)
+
+	// unweighted, three of four validators are a quorum
+	if valSet.Weighted() || valSet.QuorumWeight().Int64() != 3 {
+		t.Fatalf("unweighted quorum: have %v, want 3", valSet.QuorumWeight())
+	}
+	if valSet.HasQuorum(addrs[:2]) || !valSet.HasQuorum(addrs[:3]) {
+		t.Errorf("unweighted quorum mismatch")
+	}
+
+	if valSet.SetWeights([]*big.Int{big.NewInt(1)}) || valSet.SetWeights(make([]*big.Int, 4)) {
+		t.Fatalf("invalid weights accepted")
+	}
+	if valSet.SetWeights([]*big.Int{big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0)}) {
+		t.Fatalf("weights without voting power accepted")
+	}
+	if !valSet.SetWeights([]*big.Int{big.NewInt(60), big.NewInt(20), big.NewInt(10), big.NewInt(10)}) {
+		t.Fatalf("failed to set weights")
+	}
+	if valSet.TotalWeight().Int64() != 100 || valSet.QuorumWeight().Int64() != 67 {
+		t.Errorf("weighted quorum: have %v of %v, want 67 of 100", valSet.QuorumWeight(), valSet.TotalWeight())
+	}
+	// the heaviest validator and any other one hold 70
+	if !valSet.HasQuorum([]common.Address{addrs[0], addrs[3]}) {
+		t.Errorf("expected a quorum of 70")
+	}
+	// three validators without the heaviest one only hold 40, duplicates count once
+	if valSet.HasQuorum([]common.Address{addrs[1], addrs[2], addrs[3], addrs[3]}) {
+		t.Errorf("unexpected quorum of 40")
+	}
+
+	// removing keeps the weights of the others, adding resets them
+	cpy := valSet.Copy()
+	cpy.RemoveValidators(big.NewInt(1))
+	if !cpy.Weighted() || cpy.TotalWeight().Int64() != 40 {
+		t.Errorf("weights after remove: have %v, want 40", cpy.TotalWeight())
+	}
+	cpy.AddValidators([]istanbul.ValidatorData{{Address: common.HexToAddress("0x05")}})
+	if cpy.Weighted() {
+		t.Errorf("expected unweighted set after adding validators")
+	}
+	if valSet.TotalWeight().Int64() != 100 {
+		t.Errorf("copy changed the weights of the original set")
+	}
+}
+
 func TestValidatorRLPEncoding(t *testing.T) {
 
 	val := New(common.BytesToAddress([]byte(string(rune(2)))), bls.SerializedPublicKey{1, 2, 3})
@@ -253,4 +306,16 @@ func TestValidatorSetRLPEncoding(t *testing.T) {
 	if !reflect.DeepEqual(valSet, result) {
 		t.Errorf("validatorSet mismatch: have %v, want %v", valSet, result)
 	}
+
+	valSet.SetWeights([]*big.Int{big.NewInt(5), big.NewInt(7)})
+	if rawVal, err = rlp.EncodeToBytes(valSet); err != nil {
+		t.Errorf("Error %v", err)
+	}
+	result = nil
+	if err = rlp.DecodeBytes(rawVal, &result); err != nil {
+		t.Errorf("Error %v", err)
+	}
+	if !reflect.DeepEqual(valSet.Weights(), result.Weights()) {
+		t.Errorf("weights mismatch: have %v, want %v", result.Weights(), valSet.Weights())
+	}
 }
@@ -50,6 +50,20 @@ func ShuffledRoundRobinProposer(valSet istanbul.ValidatorSet, proposer common.Ad
 	return valSet.List()[shuffle[idx%uint64(valSet.Size())]]
 }
 
+// VRFProposer selects the proposer of each round from an order shuffled by the VRF output of the
+// previous block. The previous proposer is ignored, as the seed already changes with every block.
+// Before the VRF fork no randomness is set and the round robin order is kept.
+func VRFProposer(valSet istanbul.ValidatorSet, proposer common.Address, round uint64) istanbul.Validator {
+	if valSet.Size() == 0 {
+		return nil
+	}
+	if valSet.GetRandomness() == (common.Hash{}) {
+		return RoundRobinProposer(valSet, proposer, round)
+	}
+	shuffle := random.Permutation(valSet.GetRandomness(), valSet.Size())
+	return valSet.List()[shuffle[round%uint64(valSet.Size())]]
+}
+
 // RoundRobinProposer selects the next proposer with a round robin strategy according to storage order.
 func RoundRobinProposer(valSet istanbul.ValidatorSet, proposer common.Address, round uint64) istanbul.Validator {
 	if valSet.Size() == 0 {
@@ -83,6 +97,8 @@ func GetProposerSelector(pp istanbul.ProposerPolicy) istanbul.ProposerSelector {
 		return RoundRobinProposer
 	case istanbul.ShuffledRoundRobin:
 		return ShuffledRoundRobinProposer
+	case istanbul.VRF:
+		return VRFProposer
 	default:
 		// Programming error.
 		panic(fmt.Sprintf("unknown proposer selection policy: %v", pp))
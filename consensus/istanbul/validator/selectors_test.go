@@ -18,6 +18,7 @@ package validator
 
 import (
 	"fmt"
+	"math/big"
 	"reflect"
 	"testing"
 
@@ -272,3 +273,60 @@ func TestShuffledRoundRobinProposer(t *testing.T) {
 		}
 	})
 }
+
+func TestVRFProposer(t *testing.T) {
+	var addrs []common.Address
+	for _, strAddr := range testAddresses {
+		addrs = append(addrs, common.HexToAddress(strAddr))
+	}
+
+	v, err := istanbul.CombineIstanbulExtraToValidatorData(addrs, make([]bls.SerializedPublicKey, len(addrs)),
+		make([]bls.SerializedG1PublicKey, len(addrs)))
+	if err != nil {
+		t.Fatalf("CombineIstanbulExtraToValidatorData(...): %v", err)
+	}
+	valSet := newDefaultSet(v)
+	selector := GetProposerSelector(istanbul.VRF)
+
+	// Verify that every validator proposes once before any proposes again during round changes,
+	// and that the last proposer has no influence on the selection.
+	valSet.SetRandomness(common.HexToHash("f36aa9716b892ec8"))
+	first := selector(valSet, common.Address{}, 0)
+	seen := make(map[common.Address]bool)
+	for round := uint64(0); round < uint64(len(addrs)); round++ {
+		proposer := selector(valSet, common.Address{}, round)
+		if seen[proposer.Address()] {
+			t.Errorf("proposer %v selected twice within %d rounds", proposer.Address(), len(addrs))
+		}
+		seen[proposer.Address()] = true
+		for _, lastProposer := range addrs {
+			if val := selector(valSet, lastProposer, round); !reflect.DeepEqual(val, proposer) {
+				t.Errorf("proposer mismatch on round %d after %v: have %v, want %v", round, lastProposer, val, proposer)
+			}
+		}
+	}
+	if val := selector(valSet, common.Address{}, uint64(len(addrs))); !reflect.DeepEqual(val, first) {
+		t.Errorf("proposer mismatch after a full cycle: have %v, want %v", val, first)
+	}
+
+	// Verify that the first proposer of a sequence changes with the randomness.
+	proposers := make(map[common.Address]bool)
+	for i := 1; i <= 100; i++ {
+		valSet.SetRandomness(common.BigToHash(big.NewInt(int64(i))))
+		proposers[selector(valSet, common.Address{}, 0).Address()] = true
+	}
+	if len(proposers) != len(addrs) {
+		t.Errorf("only %d of %d validators selected as first proposer over 100 seeds", len(proposers), len(addrs))
+	}
+
+	// Verify that the round robin order is kept before the fork, when no randomness is set.
+	valSet.SetRandomness(common.Hash{})
+	for round := uint64(0); round < uint64(len(addrs)); round++ {
+		for _, lastProposer := range addrs {
+			want := RoundRobinProposer(valSet, lastProposer, round)
+			if val := selector(valSet, lastProposer, round); !reflect.DeepEqual(val, want) {
+				t.Errorf("proposer mismatch on round %d after %v without randomness: have %v, want %v", round, lastProposer, val, want)
+			}
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package consensus
 
 import (
+	"time"
+
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/mapprotocol/atlas/p2p"
 )
@@ -40,4 +42,6 @@ type Peer interface {
 	Inbound() bool
 	// PurposeIsSet returns if the peer has a purpose set
 	PurposeIsSet(purpose p2p.PurposeFlag) bool
+	// RTT returns the round trip time of the last ping answered by the peer, or zero if unknown
+	RTT() time.Duration
 }
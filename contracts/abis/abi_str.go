@@ -8454,3 +8454,107 @@ const AccountsStr = `[
       "type": "function"
     }
   ]`
+
+const DoubleSigningSlasherStr = `[
+    {
+      "constant": true,
+      "inputs": [
+        {
+          "internalType": "address",
+          "name": "signer",
+          "type": "address"
+        },
+        {
+          "internalType": "uint256",
+          "name": "index",
+          "type": "uint256"
+        },
+        {
+          "internalType": "bytes",
+          "name": "headerA",
+          "type": "bytes"
+        },
+        {
+          "internalType": "bytes",
+          "name": "headerB",
+          "type": "bytes"
+        }
+      ],
+      "name": "checkForDoubleSigning",
+      "outputs": [
+        {
+          "internalType": "uint256",
+          "name": "",
+          "type": "uint256"
+        }
+      ],
+      "payable": false,
+      "stateMutability": "view",
+      "type": "function"
+    },
+    {
+      "constant": false,
+      "inputs": [
+        {
+          "internalType": "address",
+          "name": "signer",
+          "type": "address"
+        },
+        {
+          "internalType": "uint256",
+          "name": "index",
+          "type": "uint256"
+        },
+        {
+          "internalType": "bytes",
+          "name": "headerA",
+          "type": "bytes"
+        },
+        {
+          "internalType": "bytes",
+          "name": "headerB",
+          "type": "bytes"
+        },
+        {
+          "internalType": "uint256",
+          "name": "groupMembershipHistoryIndex",
+          "type": "uint256"
+        },
+        {
+          "internalType": "address[]",
+          "name": "validatorElectionLessers",
+          "type": "address[]"
+        },
+        {
+          "internalType": "address[]",
+          "name": "validatorElectionGreaters",
+          "type": "address[]"
+        },
+        {
+          "internalType": "uint256[]",
+          "name": "validatorElectionIndices",
+          "type": "uint256[]"
+        },
+        {
+          "internalType": "address[]",
+          "name": "groupElectionLessers",
+          "type": "address[]"
+        },
+        {
+          "internalType": "address[]",
+          "name": "groupElectionGreaters",
+          "type": "address[]"
+        },
+        {
+          "internalType": "uint256[]",
+          "name": "groupElectionIndices",
+          "type": "uint256[]"
+        }
+      ],
+      "name": "slash",
+      "outputs": [],
+      "payable": false,
+      "stateMutability": "nonpayable",
+      "type": "function"
+    }
+  ]`
@@ -21,6 +21,7 @@ var (
 	Random               *abi.ABI = mustParseAbi("Random", RandomStr)
 	Validators           *abi.ABI = mustParseAbi("Validators", ValidatorsStr)
 	Accounts             *abi.ABI = mustParseAbi("Accounts", AccountsStr)
+	DoubleSigningSlasher *abi.ABI = mustParseAbi("DoubleSigningSlasher", DoubleSigningSlasherStr)
 )
 
 func mustParseAbi(name, abiStr string) *abi.ABI {
@@ -40,6 +41,7 @@ var byRegistryId = map[common.Hash]*abi.ABI{
 	params.GoldTokenRegistryId:            GoldToken,
 	params.RandomRegistryId:               Random,
 	params.ValidatorsRegistryId:           Validators,
+	params.DoubleSigningSlasherRegistryId: DoubleSigningSlasher,
 }
 
 func AbiFor(registryId common.Hash) *abi.ABI {
@@ -22,6 +22,7 @@ import (
 	"github.com/mapprotocol/atlas/contracts/abis"
 	"github.com/mapprotocol/atlas/core/vm"
 	"github.com/mapprotocol/atlas/params"
+	"fmt"
 	"math/big"
 	"sort"
 	"time"
@@ -36,6 +37,8 @@ var (
 
 	activeAllPendingMethod             = contracts.NewRegisteredContractMethod(params.ElectionRegistryId, abis.Elections, "activeAllPending", params.MaxGasForActiveAllPending)
 	getPendingVotersForValidatorMethod = contracts.NewRegisteredContractMethod(params.ElectionRegistryId, abis.Elections, "getPendingVotersForValidator", params.MaxGasForActiveAllPending)
+
+	getActiveVotesForValidatorByAccountMethod = contracts.NewRegisteredContractMethod(params.ElectionRegistryId, abis.Elections, "getActiveVotesForValidatorByAccount", params.MaxGasForGetEligibleValidatorsVoteTotals)
 )
 
 func GetElectedValidators(vmRunner vm.EVMRunner) ([]common.Address, error) {
@@ -48,6 +51,29 @@ func GetElectedValidators(vmRunner vm.EVMRunner) ([]common.Address, error) {
 	return newValSet, nil
 }
 
+// GetElectedValidatorVotes returns the total votes for each of the given validator
+// accounts, in the same order. It fails if a validator is not eligible, since its
+// voting power would be unknown.
+func GetElectedValidatorVotes(vmRunner vm.EVMRunner, validators []common.Address) ([]*big.Int, error) {
+	voteTotals, err := getTotalVotesForEligibleValidators(vmRunner)
+	if err != nil {
+		return nil, err
+	}
+	votes := make(map[common.Address]*big.Int, len(voteTotals))
+	for _, voteTotal := range voteTotals {
+		votes[voteTotal.Validator] = voteTotal.Value
+	}
+	result := make([]*big.Int, len(validators))
+	for i, validator := range validators {
+		value := votes[validator]
+		if value == nil {
+			return nil, fmt.Errorf("no votes for validator %s", validator.Hex())
+		}
+		result[i] = new(big.Int).Set(value)
+	}
+	return result, nil
+}
+
 func ElectNValidatorSigners(vmRunner vm.EVMRunner, additionalAboveMaxElectable int64) ([]common.Address, error) {
 	// Get the electable min and max
 	var minElectableValidators *big.Int
@@ -65,6 +91,20 @@ func ElectNValidatorSigners(vmRunner vm.EVMRunner, additionalAboveMaxElectable i
 	return electedValidators, nil
 }
 
+// GetPendingVotersForValidator returns the accounts with pending votes for the validator
+func GetPendingVotersForValidator(vmRunner vm.EVMRunner, validator common.Address) ([]common.Address, error) {
+	var voters []common.Address
+	err := getPendingVotersForValidatorMethod.Query(vmRunner, &voters, validator)
+	return voters, err
+}
+
+// GetActiveVotesForValidatorByAccount returns the active votes of the account for the validator
+func GetActiveVotesForValidatorByAccount(vmRunner vm.EVMRunner, validator, account common.Address) (*big.Int, error) {
+	var votes *big.Int
+	err := getActiveVotesForValidatorByAccountMethod.Query(vmRunner, &votes, validator, account)
+	return votes, err
+}
+
 type voteTotal struct {
 	Validator common.Address
 	Value     *big.Int
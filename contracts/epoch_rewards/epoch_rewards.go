@@ -63,3 +63,22 @@ func GetMgrMaintainerAddress(vmRunner vm.EVMRunner) (common.Address, error) {
 	}
 	return mgrAddress, nil
 }
+
+// SplitRelayerReward divides the relayer reward of an epoch between the relayers in
+// proportion to the headers they submitted, the remainder of the division is left to
+// the caller.
+func SplitRelayerReward(total *big.Int, submissions []*vm.Relayer) map[common.Address]*big.Int {
+	sum := new(big.Int)
+	for _, r := range submissions {
+		sum.Add(sum, new(big.Int).SetUint64(r.Submissions))
+	}
+	rewards := make(map[common.Address]*big.Int, len(submissions))
+	if sum.Sign() == 0 || total == nil || total.Sign() <= 0 {
+		return rewards
+	}
+	for _, r := range submissions {
+		reward := new(big.Int).Mul(total, new(big.Int).SetUint64(r.Submissions))
+		rewards[r.Address] = reward.Div(reward, sum)
+	}
+	return rewards
+}
@@ -2,11 +2,13 @@ package epoch_rewards
 
 import (
 	"fmt"
+	"math/big"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mapprotocol/atlas/contracts"
 	"github.com/mapprotocol/atlas/contracts/testutil"
+	"github.com/mapprotocol/atlas/core/vm"
 	"github.com/mapprotocol/atlas/params"
 	. "github.com/onsi/gomega"
 )
@@ -46,3 +48,18 @@ func Test_01(t *testing.T) {
 	}
 	fmt.Println(ret)
 }
+
+func TestSplitRelayerReward(t *testing.T) {
+	g := NewGomegaWithT(t)
+	a, b := common.HexToAddress("0x01"), common.HexToAddress("0x02")
+
+	rewards := SplitRelayerReward(big.NewInt(1000), []*vm.Relayer{
+		{Address: a, Submissions: 3},
+		{Address: b, Submissions: 1},
+	})
+	g.Expect(rewards[a]).To(Equal(big.NewInt(750)))
+	g.Expect(rewards[b]).To(Equal(big.NewInt(250)))
+
+	g.Expect(SplitRelayerReward(big.NewInt(1000), nil)).To(BeEmpty())
+	g.Expect(SplitRelayerReward(big.NewInt(0), []*vm.Relayer{{Address: a, Submissions: 1}})).To(BeEmpty())
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+package validators
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mapprotocol/atlas/contracts"
+	"github.com/mapprotocol/atlas/contracts/abis"
+	"github.com/mapprotocol/atlas/core/vm"
+	"github.com/mapprotocol/atlas/params"
+)
+
+var checkForDoubleSigningMethod = contracts.NewRegisteredContractMethod(params.DoubleSigningSlasherRegistryId, abis.DoubleSigningSlasher, "checkForDoubleSigning", params.MaxGasForCheckForDoubleSigning)
+
+// GetDoubleSigningSlasherAddress returns the address of the double signing slasher contract.
+func GetDoubleSigningSlasherAddress(vmRunner vm.EVMRunner) (common.Address, error) {
+	return contracts.GetRegisteredAddress(vmRunner, params.DoubleSigningSlasherRegistryId)
+}
+
+// CheckForDoubleSigning returns the number of the two RLP encoded headers if the double signing
+// slasher accepts them as evidence that the signer at index in the validator set signed two
+// different blocks at the same height. It fails otherwise, e.g. once the epoch of the blocks is over.
+func CheckForDoubleSigning(vmRunner vm.EVMRunner, signer common.Address, index int, headerA, headerB []byte) (*big.Int, error) {
+	var blockNumber *big.Int
+	err := checkForDoubleSigningMethod.Query(vmRunner, &blockNumber, signer, big.NewInt(int64(index)), headerA, headerB)
+	return blockNumber, err
+}
+
+// EncodeSlashDoubleSigning returns the input of the transaction submitting two RLP encoded headers
+// signed by the signer at index in the validator set to the double signing slasher contract. The
+// group membership and election hints are left empty, as validators are not members of groups.
+func EncodeSlashDoubleSigning(signer common.Address, index int, headerA, headerB []byte) ([]byte, error) {
+	return abis.DoubleSigningSlasher.Pack("slash", signer, big.NewInt(int64(index)), headerA, headerB,
+		common.Big0, []common.Address{}, []common.Address{}, []*big.Int{}, []common.Address{}, []common.Address{}, []*big.Int{})
+}
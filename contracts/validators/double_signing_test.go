@@ -0,0 +1,67 @@
+package validators
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mapprotocol/atlas/contracts"
+	"github.com/mapprotocol/atlas/contracts/abis"
+	"github.com/mapprotocol/atlas/contracts/testutil"
+	"github.com/mapprotocol/atlas/params"
+	. "github.com/onsi/gomega"
+)
+
+// The selectors of the header based double signing slasher deployed as DoubleSigningSlasher
+const (
+	checkForDoubleSigningSignature = "checkForDoubleSigning(address,uint256,bytes,bytes)"
+	slashDoubleSigningSignature    = "slash(address,uint256,bytes,bytes,uint256,address[],address[],uint256[],address[],address[],uint256[])"
+)
+
+func TestDoubleSigningSlasherABI(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(abis.DoubleSigningSlasher.Methods["checkForDoubleSigning"].ID).To(Equal(crypto.Keccak256([]byte(checkForDoubleSigningSignature))[:4]))
+	g.Expect(abis.DoubleSigningSlasher.Methods["slash"].ID).To(Equal(crypto.Keccak256([]byte(slashDoubleSigningSignature))[:4]))
+}
+
+func TestCheckForDoubleSigning(t *testing.T) {
+	signer := common.HexToAddress("0x09")
+	headerA, headerB := []byte{0x01}, []byte{0x02}
+
+	testutil.TestFailOnFailingRunner(t, CheckForDoubleSigning, signer, 3, headerA, headerB)
+	testutil.TestFailsWhenContractNotDeployed(t, contracts.ErrSmartContractNotDeployed, CheckForDoubleSigning, signer, 3, headerA, headerB)
+
+	t.Run("should return the block number of the headers", func(t *testing.T) {
+		g := NewGomegaWithT(t)
+		vmrunner := testutil.NewSingleMethodRunner(params.DoubleSigningSlasherRegistryId, "checkForDoubleSigning", func(s common.Address, index *big.Int, a, b []byte) *big.Int {
+			g.Expect(s).To(Equal(signer))
+			g.Expect(index).To(Equal(big.NewInt(3)))
+			g.Expect(a).To(Equal(headerA))
+			g.Expect(b).To(Equal(headerB))
+			return big.NewInt(100)
+		})
+
+		blockNumber, err := CheckForDoubleSigning(vmrunner, signer, 3, headerA, headerB)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(blockNumber).To(Equal(big.NewInt(100)))
+	})
+}
+
+func TestEncodeSlashDoubleSigning(t *testing.T) {
+	g := NewGomegaWithT(t)
+	signer := common.HexToAddress("0x09")
+	headerA, headerB := []byte{0x01}, []byte{0x02}
+
+	data, err := EncodeSlashDoubleSigning(signer, 3, headerA, headerB)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(data[:4]).To(Equal(crypto.Keccak256([]byte(slashDoubleSigningSignature))[:4]))
+
+	args, err := abis.DoubleSigningSlasher.Methods["slash"].Inputs.Unpack(data[4:])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(args[0]).To(Equal(signer))
+	g.Expect(args[1]).To(Equal(big.NewInt(3)))
+	g.Expect(args[2]).To(Equal(headerA))
+	g.Expect(args[3]).To(Equal(headerB))
+	g.Expect(args[4].(*big.Int).Sign()).To(BeZero())
+}
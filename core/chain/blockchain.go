@@ -43,6 +43,7 @@ import (
 	"github.com/mapprotocol/atlas/consensus/istanbul/uptime/store"
 	"github.com/mapprotocol/atlas/core"
 	"github.com/mapprotocol/atlas/core/abstract"
+	mmr "github.com/mapprotocol/atlas/core/mmr"
 	"github.com/mapprotocol/atlas/core/rawdb"
 	"github.com/mapprotocol/atlas/core/state"
 	"github.com/mapprotocol/atlas/core/state/snapshot"
@@ -181,6 +182,8 @@ type BlockChain struct {
 	txLookupLimit uint64
 
 	hc            *HeaderChain
+	headerMMR     *mmr.HeaderMMR // MMR of the canonical block hashes, proofs are served to light clients
+	headerMMRCh   chan struct{}  // signals the header MMR lags behind the head and needs indexing
 	rmLogsFeed    event.Feed
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
@@ -254,6 +257,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			Preimages: cacheConfig.Preimages,
 		}),
 		quit:           make(chan struct{}),
+		headerMMRCh:    make(chan struct{}, 1),
 		chainmu:        syncx.NewClosableMutex(),
 		shouldPreserve: shouldPreserve,
 		bodyCache:      bodyCache,
@@ -278,6 +282,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	if bc.genesisBlock == nil {
 		return nil, core.ErrNoGenesis
 	}
+	bc.headerMMR = mmr.LoadHeaderMMR(db)
 
 	var nilBlock *types.Block
 	bc.currentBlock.Store(nilBlock)
@@ -379,6 +384,12 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			}
 		}
 	}
+	// Bring the header MMR in line with the head, it lags behind if the node crashed
+	// after a fast sync or the database predates the MMR. Long gaps are indexed in the
+	// background by indexHeaderMMR.
+	if err := bc.updateHeaderMMR(); err != nil {
+		return nil, err
+	}
 
 	// Load any existing snapshot, regenerating it if loading failed
 	if bc.cacheConfig.SnapshotLimit > 0 {
@@ -400,6 +411,10 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 	bc.wg.Add(1)
 	go bc.futureBlocksLoop()
 
+	// Start header MMR indexer.
+	bc.wg.Add(1)
+	go bc.indexHeaderMMR()
+
 	// Start tx indexer/unindexer.
 	if txLookupLimit != nil {
 		bc.txLookupLimit = *txLookupLimit
@@ -649,7 +664,71 @@ func (bc *BlockChain) SetHeadBeyondRoot(head uint64, root common.Hash) (uint64,
 	bc.txLookupCache.Purge()
 	bc.futureBlocks.Purge()
 
-	return rootNumber, bc.loadLastState()
+	if err := bc.loadLastState(); err != nil {
+		return rootNumber, err
+	}
+	return rootNumber, bc.updateHeaderMMR()
+}
+
+// updateHeaderMMR rolls the header MMR back or forward to the current head block.
+func (bc *BlockChain) updateHeaderMMR() error {
+	batch := bc.db.NewBatch()
+	if !bc.headerMMR.Update(batch, bc.CurrentBlock().Header()) {
+		bc.signalHeaderMMR()
+	}
+	return batch.Write()
+}
+
+// signalHeaderMMR wakes up the header MMR indexer, unless it is already signalled.
+func (bc *BlockChain) signalHeaderMMR() {
+	select {
+	case bc.headerMMRCh <- struct{}{}:
+	default:
+	}
+}
+
+// indexHeaderMMR catches the header MMR up with the head whenever it lags too far
+// behind to be updated along with the head. Every batch of leaves is flushed with
+// the leaf count, so that a restart resumes from the last stored leaf.
+func (bc *BlockChain) indexHeaderMMR() {
+	defer bc.wg.Done()
+
+	for {
+		select {
+		case <-bc.headerMMRCh:
+		case <-bc.quit:
+			return
+		}
+		start := time.Now()
+		for done := false; !done; {
+			select {
+			case <-bc.quit:
+				return
+			default:
+			}
+			// The chain mutex keeps the head from moving while a batch is indexed
+			if !bc.chainmu.TryLock() {
+				return
+			}
+			batch := bc.db.NewBatch()
+			var err error
+			done, err = bc.headerMMR.Index(batch)
+			if werr := batch.Write(); err == nil {
+				err = werr
+			}
+			bc.chainmu.Unlock()
+			if err != nil {
+				log.Error("Failed to index header MMR", "leaves", bc.headerMMR.LeafCount(), "err", err)
+				break
+			}
+			log.Info("Indexing header MMR", "leaves", bc.headerMMR.LeafCount(), "elapsed", common.PrettyDuration(time.Since(start)))
+		}
+	}
+}
+
+// HeaderMMR retrieves the MMR of the canonical block hashes.
+func (bc *BlockChain) HeaderMMR() *mmr.HeaderMMR {
+	return bc.headerMMR
 }
 
 // FastSyncCommitHead sets the current head block to the one defined by the hash
@@ -814,6 +893,9 @@ func (bc *BlockChain) writeHeadBlock(block *types.Block) {
 	rawdb.WriteCanonicalHash(batch, block.Hash(), block.NumberU64())
 	rawdb.WriteTxLookupEntriesByBlock(batch, block)
 	rawdb.WriteHeadBlockHash(batch, block.Hash())
+	if !bc.headerMMR.Update(batch, block.Header()) {
+		bc.signalHeaderMMR()
+	}
 
 	// If the block is better than our head or is on a different chain, force update heads
 	if updateHeads {
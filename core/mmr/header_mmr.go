@@ -0,0 +1,241 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+// indexBatchSize is the number of leaves the range is extended by at once. A range
+// lagging further behind the head is caught up through Index in batches of this size.
+var indexBatchSize uint64 = 10000
+
+// ErrNotIndexed is returned for proofs against blocks the range has not caught up with.
+var ErrNotIndexed = errors.New("header MMR is not yet indexed up to the tip")
+
+// HeaderMMR is the merkle mountain range of the canonical Atlas chain, the leaf
+// at position n is the hash of block n. Every node of the range is persisted
+// through rawdb at its position in the range, so neither loading the range nor
+// proving against an older tip needs the range in memory.
+type HeaderMMR struct {
+	db    ethdb.Database
+	count uint64
+	head  uint64 // leaves needed to reach the head, above count while indexing
+	lock  sync.RWMutex
+}
+
+// LoadHeaderMMR opens the header MMR stored in db.
+func LoadHeaderMMR(db ethdb.Database) *HeaderMMR {
+	h := &HeaderMMR{db: db, count: rawdb.ReadMMRLeafCount(db)}
+	if h.count > 0 && rawdb.ReadMMRNode(db, GetNodeFromLeaf(h.count)-1) == (common.Hash{}) {
+		log.Warn("Header MMR nodes missing, rebuilding", "count", h.count)
+		h.count = 0
+	}
+	h.head = h.count
+	log.Info("Loaded header MMR", "leaves", h.count, "root", h.Root())
+	return h
+}
+
+// Atlas blocks have a difficulty of one, so the difficulty of every node equals
+// the number of blocks below it.
+func newLeaf(hash common.Hash) *Node {
+	return NewNode(hash, common.Big1, common.Big1, common.Big1, 0)
+}
+
+// leaf returns the hash of block number in the range.
+func (h *HeaderMMR) leaf(number uint64) common.Hash {
+	return rawdb.ReadMMRNode(h.db, GetNodeFromLeaf(number))
+}
+
+// Update makes head the last leaf of the range. Leaves of blocks which are no
+// longer canonical are rolled back and missing canonical blocks are appended.
+// The changes are written into batch, which should be flushed together with the
+// new head. If more than indexBatchSize blocks are missing, none are appended
+// and false is returned, the range is then caught up by Index.
+func (h *HeaderMMR) Update(batch ethdb.KeyValueWriter, head *types.Header) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	number := head.Number.Uint64()
+	count := h.count
+	if count > number {
+		count = number
+	}
+	// Roll back the leaves reorged out below the new head
+	for count > 0 {
+		want := head.ParentHash
+		if count < number {
+			want = rawdb.ReadCanonicalHash(h.db, count-1)
+		}
+		if h.leaf(count-1) == want {
+			break
+		}
+		count--
+	}
+	for pos := GetNodeFromLeaf(count); pos < GetNodeFromLeaf(h.count); pos++ {
+		rawdb.DeleteMMRNode(batch, pos)
+	}
+	h.count = count
+	h.head = number + 1
+	if number-h.count >= indexBatchSize {
+		rawdb.WriteMMRLeafCount(batch, h.count)
+		return false
+	}
+
+	// Nodes written into batch are not readable from the database yet
+	pending := make(map[uint64]common.Hash)
+	for ; h.count < number; h.count++ {
+		hash := rawdb.ReadCanonicalHash(h.db, h.count)
+		if hash == (common.Hash{}) {
+			log.Error("Canonical hash missing, header MMR not updated", "number", h.count, "head", number)
+			rawdb.WriteMMRLeafCount(batch, h.count)
+			return false
+		}
+		h.append(batch, pending, hash)
+	}
+	h.append(batch, pending, head.Hash())
+	h.count++
+	rawdb.WriteMMRLeafCount(batch, h.count)
+	return true
+}
+
+// Index appends up to indexBatchSize canonical blocks the range lags behind the
+// head by into batch, and reports whether the range reached the head. The head
+// passed to Update must have been written before.
+func (h *HeaderMMR) Index(batch ethdb.KeyValueWriter) (bool, error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	pending := make(map[uint64]common.Hash)
+	for end := h.count + indexBatchSize; h.count < h.head && h.count < end; h.count++ {
+		hash := rawdb.ReadCanonicalHash(h.db, h.count)
+		if hash == (common.Hash{}) {
+			rawdb.WriteMMRLeafCount(batch, h.count)
+			return false, fmt.Errorf("canonical hash of block %d missing", h.count)
+		}
+		h.append(batch, pending, hash)
+	}
+	rawdb.WriteMMRLeafCount(batch, h.count)
+	return h.count >= h.head, nil
+}
+
+// append writes the next leaf and the parents it completes.
+func (h *HeaderMMR) append(batch ethdb.KeyValueWriter, pending map[uint64]common.Hash, hash common.Hash) {
+	pos, height := GetNodeFromLeaf(h.count), 0
+	for {
+		rawdb.WriteMMRNode(batch, pos, hash)
+		pending[pos] = hash
+		if posHeightInTree(pos+1) <= height {
+			return
+		}
+		left, ok := pending[pos-siblingOffset(height)]
+		if !ok {
+			left = rawdb.ReadMMRNode(h.db, pos-siblingOffset(height))
+		}
+		pos, height, hash = pos+1, height+1, merge2(left, hash)
+	}
+}
+
+// node returns the hash of the node covering the cnt blocks from start. The
+// nodes of perfect subtrees are stored, the others are merged from their
+// children.
+func (h *HeaderMMR) node(start, cnt uint64) common.Hash {
+	if IsPowerOfTwo(cnt) {
+		return rawdb.ReadMMRNode(h.db, GetNodeFromLeaf(start)+2*cnt-2)
+	}
+	left := NextPowerOfTwo(cnt) / 2
+	return merge2(h.node(start, left), h.node(start+left, cnt-left))
+}
+
+// LeafCount returns the number of blocks in the range.
+func (h *HeaderMMR) LeafCount() uint64 {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	return h.count
+}
+
+// Root returns the root of the range.
+func (h *HeaderMMR) Root() common.Hash {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if h.count == 0 {
+		return common.Hash{}
+	}
+	return h.node(0, h.count)
+}
+
+// GenerateProof proves block number against the range of the blocks up to and
+// including tip. The proof is built from the stored nodes, it reads O(log² n)
+// of them whatever the tip.
+func (h *HeaderMMR) GenerateProof(number, tip uint64) (*ProofInfo, error) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	if tip >= h.count && tip < h.head {
+		return nil, fmt.Errorf("%w, tip: %d, leaves: %d, head: %d", ErrNotIndexed, tip, h.count, h.head-1)
+	}
+	if tip >= h.count {
+		return nil, fmt.Errorf("tip %d is beyond the MMR head, leaves: %d", tip, h.count)
+	}
+	if number > tip {
+		return nil, fmt.Errorf("block %d is beyond the tip %d", number, tip)
+	}
+	root := &ProofRes{H: h.node(0, tip+1), TD: new(big.Int).SetUint64(tip + 1)}
+	elems := h.proveRecursive(number, 0, tip+1, nil)
+	elems = append(elems, &ProofElem{Cat: 0, Res: root, LeafNum: tip + 1})
+	return &ProofInfo{
+		RootHash:       root.H,
+		RootDifficulty: new(big.Int).Set(root.TD),
+		LeafNumber:     tip + 1,
+		Elems:          elems,
+		Checked:        []uint64{number},
+	}, nil
+}
+
+// proveRecursive appends the proof of block number below the node covering the
+// cnt blocks from start, in the order of generateProofRecursive.
+func (h *HeaderMMR) proveRecursive(number, start, cnt uint64, elems []*ProofElem) []*ProofElem {
+	if cnt == 1 {
+		return append(elems, &ProofElem{Cat: 2, Res: h.proofRes(start, cnt)})
+	}
+	left := cnt / 2
+	if !IsPowerOfTwo(cnt) {
+		left = NextPowerOfTwo(cnt) / 2
+	}
+	if number < start+left {
+		elems = h.proveRecursive(number, start, left, elems)
+		return append(elems, &ProofElem{Cat: 1, Res: h.proofRes(start+left, cnt-left), Right: true})
+	}
+	elems = append(elems, &ProofElem{Cat: 1, Res: h.proofRes(start, left)})
+	return h.proveRecursive(number, start+left, cnt-left, elems)
+}
+
+func (h *HeaderMMR) proofRes(start, cnt uint64) *ProofRes {
+	return &ProofRes{H: h.node(start, cnt), TD: new(big.Int).SetUint64(cnt)}
+}
@@ -0,0 +1,200 @@
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/types"
+)
+
+func makeHeaders(parent *types.Header, n int, seed byte) []*types.Header {
+	var headers []*types.Header
+	for i := 0; i < n; i++ {
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     new(big.Int).Add(parent.Number, common.Big1),
+			Extra:      []byte{seed},
+		}
+		headers = append(headers, header)
+		parent = header
+	}
+	return headers
+}
+
+// writeHead mimics the block chain: the head is made canonical and the MMR is
+// updated in the same batch.
+func writeHead(db ethdb.Database, h *HeaderMMR, header *types.Header) {
+	batch := db.NewBatch()
+	rawdb.WriteCanonicalHash(batch, header.Hash(), header.Number.Uint64())
+	h.Update(batch, header)
+	batch.Write()
+}
+
+func rootOf(headers []*types.Header) common.Hash {
+	m := NewMMR()
+	for _, header := range headers {
+		m.Push(newLeaf(header.Hash()))
+	}
+	return m.GetRoot2()
+}
+
+func TestHeaderMMR_Update(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	h := LoadHeaderMMR(db)
+
+	genesis := &types.Header{Number: big.NewInt(0)}
+	chain := append([]*types.Header{genesis}, makeHeaders(genesis, 20, 0)...)
+	for _, header := range chain {
+		writeHead(db, h, header)
+	}
+	assert.Equal(t, uint64(21), h.LeafCount())
+	assert.Equal(t, rootOf(chain), h.Root())
+
+	// the range survives a restart
+	reloaded := LoadHeaderMMR(db)
+	assert.Equal(t, h.LeafCount(), reloaded.LeafCount())
+	assert.Equal(t, h.Root(), reloaded.Root())
+
+	// reorg onto a fork from block 15
+	fork := makeHeaders(chain[15], 8, 1)
+	for _, header := range fork {
+		writeHead(db, h, header)
+	}
+	want := append(append([]*types.Header{}, chain[:16]...), fork...)
+	assert.Equal(t, uint64(24), h.LeafCount())
+	assert.Equal(t, rootOf(want), h.Root())
+	assert.Equal(t, fork[0].Hash(), rawdb.ReadMMRNode(db, GetNodeFromLeaf(16)))
+
+	// rewind the head
+	writeHead(db, h, want[10])
+	assert.Equal(t, uint64(11), h.LeafCount())
+	assert.Equal(t, rootOf(want[:11]), h.Root())
+	assert.Equal(t, common.Hash{}, rawdb.ReadMMRNode(db, GetNodeFromLeaf(11)))
+	assert.Equal(t, rootOf(want[:11]), LoadHeaderMMR(db).Root())
+	assert.Equal(t, uint64(11), rawdb.ReadMMRLeafCount(db))
+}
+
+func TestHeaderMMR_UpdateGap(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	h := LoadHeaderMMR(db)
+
+	genesis := &types.Header{Number: big.NewInt(0)}
+	chain := append([]*types.Header{genesis}, makeHeaders(genesis, 10, 0)...)
+	for _, header := range chain {
+		rawdb.WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+	}
+	// blocks imported without passing through the head, e.g. by fast sync
+	writeHead(db, h, chain[10])
+	assert.Equal(t, uint64(11), h.LeafCount())
+	assert.Equal(t, rootOf(chain), h.Root())
+}
+
+func TestHeaderMMR_Index(t *testing.T) {
+	defer func(size uint64) { indexBatchSize = size }(indexBatchSize)
+	indexBatchSize = 4
+
+	db := rawdb.NewMemoryDatabase()
+	h := LoadHeaderMMR(db)
+
+	genesis := &types.Header{Number: big.NewInt(0)}
+	chain := append([]*types.Header{genesis}, makeHeaders(genesis, 10, 0)...)
+	for _, header := range chain {
+		rawdb.WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+	}
+	batch := db.NewBatch()
+	assert.False(t, h.Update(batch, chain[10]))
+	batch.Write()
+	assert.Equal(t, uint64(0), h.LeafCount())
+	_, err := h.GenerateProof(2, 10)
+	assert.True(t, errors.Is(err, ErrNotIndexed))
+
+	index := func(h *HeaderMMR) bool {
+		batch := db.NewBatch()
+		done, err := h.Index(batch)
+		assert.Nil(t, err)
+		batch.Write()
+		return done
+	}
+	assert.False(t, index(h))
+	assert.Equal(t, uint64(4), h.LeafCount())
+
+	// a restart resumes from the last stored leaf
+	h = LoadHeaderMMR(db)
+	assert.Equal(t, uint64(4), h.LeafCount())
+	batch = db.NewBatch()
+	assert.False(t, h.Update(batch, chain[10]))
+	batch.Write()
+	assert.False(t, index(h))
+	assert.True(t, index(h))
+	assert.Equal(t, uint64(11), h.LeafCount())
+	assert.Equal(t, rootOf(chain), h.Root())
+
+	proof, err := h.GenerateProof(2, 10)
+	assert.Nil(t, err)
+	assert.Equal(t, rootOf(chain), proof.RootHash)
+
+	// blocks close to the head are appended along with it
+	next := makeHeaders(chain[10], 3, 0)
+	for _, header := range next[:2] {
+		rawdb.WriteCanonicalHash(db, header.Hash(), header.Number.Uint64())
+	}
+	writeHead(db, h, next[2])
+	assert.Equal(t, uint64(14), h.LeafCount())
+	assert.Equal(t, rootOf(append(chain, next...)), h.Root())
+}
+
+func TestHeaderMMR_GenerateProof(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	h := LoadHeaderMMR(db)
+
+	genesis := &types.Header{Number: big.NewInt(0)}
+	chain := append([]*types.Header{genesis}, makeHeaders(genesis, 30, 0)...)
+	for _, header := range chain {
+		writeHead(db, h, header)
+	}
+
+	for _, tip := range []uint64{5, 17, 30} {
+		for number := uint64(0); number <= tip; number++ {
+			proof, err := h.GenerateProof(number, tip)
+			assert.Nil(t, err)
+			assert.Equal(t, tip+1, proof.LeafNumber)
+			assert.Equal(t, rootOf(chain[:tip+1]), proof.RootHash)
+
+			blocks, err := VerifyRequiredBlocks2(proof)
+			assert.Nil(t, err)
+			assert.True(t, proof.VerifyProof2(blocks))
+		}
+	}
+
+	_, err := h.GenerateProof(3, 31)
+	assert.NotNil(t, err)
+	_, err = h.GenerateProof(6, 5)
+	assert.NotNil(t, err)
+}
+
+func TestHeaderMMR_GenerateProofMatchesMmr(t *testing.T) {
+	db := rawdb.NewMemoryDatabase()
+	h := LoadHeaderMMR(db)
+
+	genesis := &types.Header{Number: big.NewInt(0)}
+	chain := append([]*types.Header{genesis}, makeHeaders(genesis, 40, 0)...)
+	m := NewMMR()
+	for _, header := range chain {
+		writeHead(db, h, header)
+		m.Push(newLeaf(header.Hash()))
+	}
+
+	for tip := uint64(0); tip <= 40; tip++ {
+		for number := uint64(0); number <= tip; number++ {
+			proof, err := h.GenerateProof(number, tip)
+			assert.Nil(t, err)
+			assert.Equal(t, m.GenerateProof(number, tip+1).String(), proof.String(), "number %d, tip %d", number, tip)
+		}
+	}
+}
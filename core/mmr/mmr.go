@@ -281,6 +281,18 @@ func (v *VerifyElems) is_empty() bool {
 type ProofBlock struct {
 	Number     uint64
 	AggrWeight float64
+	Weight     *big.Int // aggregated difficulty sampled in integer mode, AggrWeight is unused if set
+}
+
+// checkWeight reports whether the aggregated difficulty sampled for the block lies
+// within [left, left+difficulty).
+func (p *ProofBlock) checkWeight(left, difficulty, root_difficulty *big.Int) bool {
+	right := new(big.Int).Add(left, difficulty)
+	if p.Weight != nil {
+		return left.Cmp(p.Weight) <= 0 && right.Cmp(p.Weight) > 0
+	}
+	middle := new(big.Float).Mul(new(big.Float).SetInt(root_difficulty), big.NewFloat(p.AggrWeight))
+	return new(big.Float).SetInt(left).Cmp(middle) <= 0 && new(big.Float).SetInt(right).Cmp(middle) > 0
 }
 
 func (p *ProofBlock) equal(oth *ProofBlock) bool {
@@ -755,9 +767,7 @@ func (p *ProofInfo) VerifyProof(blocks []*ProofBlock) bool {
 					//dies nicht überprüfen, wenn doch irgendwann vorhanden, dann einfach
 					//'block_header.mmr == old_root_hash' überprüfen
 					_, left_difficulty := get_root(nodes)
-					left, middle := new(big.Float).SetInt(left_difficulty), new(big.Float).Mul(new(big.Float).SetInt(root_elem.Res.TD), big.NewFloat(proof_block.AggrWeight))
-					right := new(big.Float).Add(new(big.Float).SetInt(left_difficulty), new(big.Float).SetInt(proof_elem.Res.TD))
-					if left.Cmp(middle) > 0 || right.Cmp(middle) <= 0 {
+					if !proof_block.checkWeight(left_difficulty, proof_elem.Res.TD, root_elem.Res.TD) {
 						// "aggregated difficulty is not correct, should coincide with: {} <= {} < {}",left, middle, right
 						return false
 					}
@@ -1045,11 +1055,8 @@ func (m *Mmr) GenerateProof(proofHeight, EndHeight uint64) *ProofInfo {
 	// })
 
 	mmrClone := m.Copy()
-	lRemove := int64(EndHeight - mmrClone.leafNum)
-	if lRemove > 0 {
-		for i := 0; i < int(lRemove); i++ {
-			mmrClone.pop()
-		}
+	for mmrClone.leafNum > EndHeight {
+		mmrClone.pop()
 	}
 
 	info := mmrClone.genProof(big.NewInt(0), []uint64{proofHeight})
@@ -0,0 +1,146 @@
+// Copyright 2021 MAP Protocol Authors.
+// This file is part of MAP Protocol.
+
+// MAP Protocol is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+
+// MAP Protocol is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+
+// You should have received a copy of the GNU General Public License
+// along with MAP Protocol.  If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"math/bits"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Integer sampling mode
+//
+// CreateNewProof and VerifyRequiredBlocks sample the checked blocks with float64
+// math, which a verifier on another chain cannot reproduce bit for bit. The
+// integer mode below only uses unsigned integer arithmetic so that a contract can
+// recompute the sampled blocks from the encoded proof:
+//
+//	D = RootDifficulty, R = the right difficulty checked manually by the verifier
+//	l = bitlen(D + R) - bitlen(R)
+//	m = 1                                                if l <= 1
+//	m = (lambda - 1 + bitlen(LeafNumber)) * l * 693147 / 1000000 + 1  otherwise
+//
+// m is vdCalculateM for c = 1/2 with the logarithms rounded to bit lengths and
+// ln(2) fixed to 693147/1000000. For i in [0, m):
+//
+//	seed  = keccak256(RootHash ++ uint64_be(i)) as uint256
+//	k     = seed % K, K = max(bitlen(D) - bitlen(R), 1)
+//	r     = seed / K
+//	hi    = D >> k, lo = D >> (k + 1)
+//	d     = hi                        if hi == lo
+//	d     = lo + 1 + r % (hi - lo)    otherwise
+//	w_i   = D - d
+//
+// So the distance of a sample from the end of the chain is log-uniform over the
+// power of two buckets between R and D, the integer counterpart of cdf. The
+// weights are sorted ascending and the block checked for w_i is the leaf whose
+// aggregated difficulty range [left, left+difficulty) contains w_i.
+//
+// Wire format
+//
+// ProofInfoToBytes is the RLP encoding of
+//
+//	ProofInfo = [RootHash: bytes32, RootDifficulty: uint, LeafNumber: uint, Elems: [Elem, ...], Checked: [uint, ...]]
+//	Elem      = [Cat: uint, Res: [H: bytes32, TD: uint], Right: bool, LeafNum: uint]
+//
+// Cat is 2 for a checked leaf, 1 for a sibling node and 0 for the root, which is
+// always the last element. The elements are in depth first, left to right order
+// of the tree. A parent hash is SHA3-256 (FIPS 202, not keccak) of the RLP list
+// [left, right], its difficulty is the sum of the difficulties of its children.
+// Checked holds the block numbers of the sampled leaves in ascending order.
+
+// RequiredQueries returns how many blocks an integer mode proof samples.
+func RequiredQueries(right_difficulty, root_difficulty *big.Int, leaf_number uint64) uint64 {
+	total := new(big.Int).Add(root_difficulty, right_difficulty)
+	l := uint64(total.BitLen() - right_difficulty.BitLen())
+	if l <= 1 {
+		return 1
+	}
+	numerator := lambda - 1 + uint64(bits.Len64(leaf_number))
+	return numerator*l*693147/1000000 + 1
+}
+
+// SampleWeights returns the sorted aggregated difficulties the integer mode samples
+// for the given root.
+func SampleWeights(root_hash common.Hash, root_difficulty, right_difficulty *big.Int, queries uint64) []*big.Int {
+	buckets := int64(root_difficulty.BitLen() - right_difficulty.BitLen())
+	if buckets < 1 {
+		buckets = 1
+	}
+	K := big.NewInt(buckets)
+
+	weights := make([]*big.Int, 0, queries)
+	for i := uint64(0); i < queries; i++ {
+		var index [8]byte
+		binary.BigEndian.PutUint64(index[:], i)
+		seed := new(big.Int).SetBytes(crypto.Keccak256(root_hash[:], index[:]))
+
+		r, k := new(big.Int).DivMod(seed, K, new(big.Int))
+		hi := new(big.Int).Rsh(root_difficulty, uint(k.Uint64()))
+		lo := new(big.Int).Rsh(root_difficulty, uint(k.Uint64()+1))
+		d := hi
+		if width := new(big.Int).Sub(hi, lo); width.Sign() > 0 {
+			d = new(big.Int).Add(lo, common.Big1)
+			d.Add(d, r.Mod(r, width))
+		}
+		weights = append(weights, new(big.Int).Sub(root_difficulty, d))
+	}
+	sort.Slice(weights, func(i, j int) bool { return weights[i].Cmp(weights[j]) < 0 })
+	return weights
+}
+
+// CreateIntegerProof is CreateNewProof in integer sampling mode.
+func (m *Mmr) CreateIntegerProof(right_difficulty *big.Int) *ProofInfo {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	root_difficulty := m.getRootDifficulty()
+	queries := RequiredQueries(right_difficulty, root_difficulty, m.getLeafNumber())
+	blocks := []uint64{}
+	for _, w := range SampleWeights(m.getRoot(), root_difficulty, right_difficulty, queries) {
+		blocks = append(blocks, m.getChildByAggrWeightDisc(w))
+	}
+	info := m.genProof(right_difficulty, blocks)
+	info.Checked = blocks
+	return info
+}
+
+// VerifyRequiredBlocksInteger is VerifyRequiredBlocks in integer sampling mode,
+// the returned blocks carry the sampled weights checked by VerifyProof.
+func VerifyRequiredBlocksInteger(info *ProofInfo, right_difficulty *big.Int) ([]*ProofBlock, error) {
+	queries := RequiredQueries(right_difficulty, info.RootDifficulty, info.LeafNumber)
+	if queries != uint64(len(info.Checked)) {
+		return nil, fmt.Errorf("false number of blocks provided: required: %v, got: %v", queries, len(info.Checked))
+	}
+	weights := SampleWeights(info.RootHash, info.RootDifficulty, right_difficulty, queries)
+	proof_blocks := []*ProofBlock{}
+	for i, v := range info.Checked {
+		if i > 0 && v < info.Checked[i-1] {
+			return nil, fmt.Errorf("blocks are not sorted: %v after %v", v, info.Checked[i-1])
+		}
+		proof_blocks = append(proof_blocks, &ProofBlock{
+			Number: v,
+			Weight: weights[i],
+		})
+	}
+	return proof_blocks, nil
+}
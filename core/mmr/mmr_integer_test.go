@@ -0,0 +1,66 @@
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMMR(n int, difficulty int64) *Mmr {
+	m := NewMMR()
+	for i := 0; i < n; i++ {
+		m.Push(NewNode(BytesToHash(IntToBytes(i)), big.NewInt(difficulty), big.NewInt(difficulty), big.NewInt(difficulty), 0))
+	}
+	return m
+}
+
+func TestRequiredQueries(t *testing.T) {
+	assert.Equal(t, uint64(1), RequiredQueries(big.NewInt(1000), big.NewInt(1000), 1))
+	// l = bitlen(1000001000) - bitlen(1000) = 30 - 10, (50 - 1 + 20) * 20 * ln(2) + 1
+	assert.Equal(t, uint64(957), RequiredQueries(big.NewInt(1000), big.NewInt(1000000000), 1000000))
+}
+
+func TestIntegerProof(t *testing.T) {
+	right := big.NewInt(1000)
+	for _, n := range []int{1, 2, 7, 64, 100, 1500} {
+		m := newTestMMR(n, 1000)
+		proof := m.CreateIntegerProof(right)
+
+		// the proof survives the wire format
+		enc, err := ProofInfoToBytes(proof)
+		assert.Nil(t, err)
+		dec, err := ProofInfoFromBytes(enc)
+		assert.Nil(t, err)
+
+		blocks, err := VerifyRequiredBlocksInteger(dec, right)
+		assert.Nil(t, err, n)
+		for _, b := range blocks {
+			assert.True(t, b.Number < uint64(n))
+		}
+		assert.True(t, dec.VerifyProof(blocks), n)
+
+		// proofs are deterministic
+		again, _ := ProofInfoToBytes(m.CreateIntegerProof(right))
+		assert.Equal(t, enc, again)
+	}
+}
+
+func TestIntegerProofTampered(t *testing.T) {
+	right := big.NewInt(1000)
+	m := newTestMMR(100, 1000)
+
+	proof := m.CreateIntegerProof(right)
+	proof.Checked = proof.Checked[1:]
+	_, err := VerifyRequiredBlocksInteger(proof, right)
+	assert.NotNil(t, err)
+
+	// sampled weights do not match blocks other than the sampled ones
+	proof = m.CreateIntegerProof(right)
+	blocks, err := VerifyRequiredBlocksInteger(proof, right)
+	assert.Nil(t, err)
+	for _, b := range blocks {
+		b.Weight = big.NewInt(0)
+	}
+	assert.False(t, proof.VerifyProof(blocks))
+}
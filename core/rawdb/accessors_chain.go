@@ -1396,3 +1396,33 @@ func uptimeKey(epoch uint64) []byte {
 	// abuse encodeBlockNumber for epochs
 	return append([]byte("uptime"), encodeBlockNumber(epoch)...)
 }
+
+// ReadSignerRecord retrieves the signers of the specified block as seen by the uptime monitor
+func ReadSignerRecord(db ethdb.Reader, number uint64) *uptime.SignerRecord {
+	data, _ := db.Get(signerRecordKey(number))
+	if len(data) == 0 {
+		return nil
+	}
+	record := new(uptime.SignerRecord)
+	if err := rlp.Decode(bytes.NewReader(data), record); err != nil {
+		log.Error("Invalid signer record RLP", "number", number, "err", err)
+		return nil
+	}
+	return record
+}
+
+// WriteSignerRecord stores the signers of the specified block
+func WriteSignerRecord(db ethdb.KeyValueWriter, number uint64, record *uptime.SignerRecord) {
+	data, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		log.Crit("Failed to RLP encode signer record", "err", err)
+	}
+	if err := db.Put(signerRecordKey(number), data); err != nil {
+		log.Crit("Failed to store signer record", "err", err)
+	}
+}
+
+// signerRecordKey = signerRecordPrefix + block number
+func signerRecordKey(number uint64) []byte {
+	return append([]byte("uptime-signers"), encodeBlockNumber(number)...)
+}
@@ -0,0 +1,48 @@
+package rawdb
+
+import (
+	"encoding/binary"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ReadMMRLeafCount retrieves the number of blocks appended to the header MMR.
+func ReadMMRLeafCount(db ethdb.KeyValueReader) uint64 {
+	data, _ := db.Get(mmrLeafCountKey)
+	if len(data) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(data)
+}
+
+// WriteMMRLeafCount stores the number of blocks appended to the header MMR.
+func WriteMMRLeafCount(db ethdb.KeyValueWriter, count uint64) {
+	if err := db.Put(mmrLeafCountKey, encodeBlockNumber(count)); err != nil {
+		log.Crit("Failed to store MMR leaf count", "err", err)
+	}
+}
+
+// ReadMMRNode retrieves the hash of the header MMR node at the given position.
+func ReadMMRNode(db ethdb.KeyValueReader, pos uint64) common.Hash {
+	data, _ := db.Get(mmrNodeKey(pos))
+	if len(data) != common.HashLength {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// WriteMMRNode stores the hash of the header MMR node at the given position.
+func WriteMMRNode(db ethdb.KeyValueWriter, pos uint64, hash common.Hash) {
+	if err := db.Put(mmrNodeKey(pos), hash.Bytes()); err != nil {
+		log.Crit("Failed to store MMR node", "err", err)
+	}
+}
+
+// DeleteMMRNode removes the header MMR node at the given position.
+func DeleteMMRNode(db ethdb.KeyValueWriter, pos uint64) {
+	if err := db.Delete(mmrNodeKey(pos)); err != nil {
+		log.Crit("Failed to delete MMR node", "err", err)
+	}
+}
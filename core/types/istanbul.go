@@ -77,11 +77,17 @@ type IstanbulExtra struct {
 	AggregatedSeal IstanbulAggregatedSeal
 	// ParentAggregatedSeal contains and aggregated BLS signature for the previous block.
 	ParentAggregatedSeal IstanbulAggregatedSeal
+	// ValidatorWeights is the voting power of each validator of the next epoch, in validator
+	// set order. It is only set in the last block of an epoch after the weighted BFT fork.
+	ValidatorWeights []*big.Int
+	// VRFProof is the proposer's BLS signature over the VRF input derived from the parent
+	// header. It is only set when proposers are selected with the VRF policy.
+	VRFProof []byte
 }
 
 // EncodeRLP serializes ist into the Ethereum RLP format.
 func (ist *IstanbulExtra) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, []interface{}{
+	fields := []interface{}{
 		ist.AddedValidators,
 		ist.AddedValidatorsPublicKeys,
 		ist.AddedValidatorsG1PublicKeys,
@@ -89,7 +95,16 @@ func (ist *IstanbulExtra) EncodeRLP(w io.Writer) error {
 		ist.Seal,
 		&ist.AggregatedSeal,
 		&ist.ParentAggregatedSeal,
-	})
+	}
+	// the weights are left out, rather than encoded empty, to keep the encoding of
+	// unweighted headers unchanged
+	if len(ist.ValidatorWeights) > 0 || len(ist.VRFProof) > 0 {
+		fields = append(fields, ist.ValidatorWeights)
+	}
+	if len(ist.VRFProof) > 0 {
+		fields = append(fields, ist.VRFProof)
+	}
+	return rlp.Encode(w, fields)
 }
 
 // DecodeRLP implements rlp.Decoder, and load the istanbul fields from a RLP stream.
@@ -102,11 +117,21 @@ func (ist *IstanbulExtra) DecodeRLP(s *rlp.Stream) error {
 		Seal                        []byte
 		AggregatedSeal              IstanbulAggregatedSeal
 		ParentAggregatedSeal        IstanbulAggregatedSeal
+		ValidatorWeights            []*big.Int `rlp:"optional"`
+		VRFProof                    []byte     `rlp:"optional"`
 	}
 	if err := s.Decode(&istanbulExtra); err != nil {
 		return err
 	}
 	ist.AddedValidators, ist.AddedValidatorsPublicKeys, ist.AddedValidatorsG1PublicKeys, ist.RemovedValidators, ist.Seal, ist.AggregatedSeal, ist.ParentAggregatedSeal = istanbulExtra.AddedValidators, istanbulExtra.AddedValidatorsPublicKeys, istanbulExtra.AddedValidatorsG1PublicKeys, istanbulExtra.RemovedValidators, istanbulExtra.Seal, istanbulExtra.AggregatedSeal, istanbulExtra.ParentAggregatedSeal
+	// a header with a VRF proof but without weights carries an empty weights list,
+	// which means unweighted just like a missing one
+	if len(istanbulExtra.ValidatorWeights) > 0 {
+		ist.ValidatorWeights = istanbulExtra.ValidatorWeights
+	} else {
+		ist.ValidatorWeights = nil
+	}
+	ist.VRFProof = istanbulExtra.VRFProof
 	return nil
 }
 
@@ -85,6 +85,7 @@ var (
 	cip26Address             = atlasPrecompileAddress(30)
 
 	eth2VerifyUpdateAddress = atlasPrecompileAddress(31)
+	eth2HeaderStoreAddress  = atlasPrecompileAddress(32)
 )
 
 // PrecompiledContract is the basic interface for native Go contracts. The implementation
@@ -233,6 +234,24 @@ var PrecompiledContractsBLS = map[common.Address]PrecompiledContract{
 	eth2VerifyUpdateAddress: &eth2VerifyLightClient{},
 }
 
+// PrecompiledContractsEth2HeaderStore contains the pre-compiled contracts added by the
+// eth2 header store fork, on top of the set of the Ethereum fork.
+var PrecompiledContractsEth2HeaderStore = map[common.Address]PrecompiledContract{
+	eth2HeaderStoreAddress: &eth2Store{},
+}
+
+// PrecompiledContractsVerifyBatch contains the pre-compiled contracts replaced by the
+// verify batch fork.
+var PrecompiledContractsVerifyBatch = map[common.Address]PrecompiledContract{
+	params.TxVerifyAddress: &verify{batch: true},
+}
+
+// PrecompiledContractsRelayerSet contains the pre-compiled contracts replaced by the
+// relayer set fork.
+var PrecompiledContractsRelayerSet = map[common.Address]PrecompiledContract{
+	params.HeaderStoreAddress: &store{relayers: true},
+}
+
 var (
 	PrecompiledAddressesBerlin    []common.Address
 	PrecompiledAddressesIstanbul  []common.Address
@@ -257,16 +276,21 @@ func init() {
 
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
+	var addresses []common.Address
 	switch {
 	case rules.IsBerlin:
-		return PrecompiledAddressesBerlin
+		addresses = PrecompiledAddressesBerlin
 	case rules.IsIstanbul:
-		return PrecompiledAddressesIstanbul
+		addresses = PrecompiledAddressesIstanbul
 	case rules.IsByzantium:
-		return PrecompiledAddressesByzantium
+		addresses = PrecompiledAddressesByzantium
 	default:
-		return PrecompiledAddressesHomestead
+		addresses = PrecompiledAddressesHomestead
 	}
+	if rules.IsEth2HeaderStore {
+		addresses = append(append([]common.Address{}, addresses...), eth2HeaderStoreAddress)
+	}
+	return addresses
 }
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
@@ -1174,7 +1198,9 @@ func (c *bls12381MapG2) Run(evm *EVM, contract *Contract, input []byte) ([]byte,
 
 const gasPerByte = 68
 
-type store struct{}
+type store struct {
+	relayers bool // the relayer set methods are enabled, since the relayer set fork
+}
 
 func (s *store) RequiredGas(input []byte) uint64 {
 	var (
@@ -1190,6 +1216,9 @@ func (s *store) RequiredGas(input []byte) uint64 {
 		return uint64(len(input) * gasPerByte)
 	}
 
+	if gas, ok := RelayerGas[method.Name]; ok && s.relayers {
+		return gas
+	}
 	if gas, ok := SyncGas[method.Name]; ok {
 		return gas
 	}
@@ -1200,7 +1229,9 @@ func (s *store) Run(evm *EVM, contract *Contract, input []byte) (ret []byte, err
 	return RunHeaderStore(evm, contract, input)
 }
 
-type verify struct{}
+type verify struct {
+	batch bool // verifyProofDataBatch is enabled, since the verify batch fork
+}
 
 func (tv *verify) RequiredGas(input []byte) uint64 {
 	var (
@@ -1212,6 +1243,10 @@ func (tv *verify) RequiredGas(input []byte) uint64 {
 		return baseGas
 	}
 
+	if method.Name == VerifyProofBatch && tv.batch {
+		return TxVerifyGas[VerifyProof] + uint64(len(input))*VerifyProofBatchGasPerByte
+	}
+
 	if gas, ok := TxVerifyGas[method.Name]; ok {
 		return gas
 	}
@@ -1742,3 +1777,29 @@ func (c *eth2VerifyLightClient) RequiredGas(input []byte) uint64 {
 func (c *eth2VerifyLightClient) Run(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
 	return nil, eth2.VerifyLightClientUpdate(input)
 }
+
+type eth2Store struct{}
+
+func (s *eth2Store) RequiredGas(input []byte) uint64 {
+	var (
+		baseGas uint64 = 21000
+	)
+
+	method, err := abiEth2HeaderStore.MethodById(input)
+	if err != nil {
+		return baseGas
+	}
+
+	if method.Name == Eth2UpdateLightClient {
+		return params2.VerifyEth2UpdateGas + uint64(len(input)*gasPerByte)
+	}
+
+	if gas, ok := Eth2HeaderStoreGas[method.Name]; ok {
+		return gas
+	}
+	return baseGas
+}
+
+func (s *eth2Store) Run(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	return RunEth2HeaderStore(evm, contract, input)
+}
@@ -508,3 +508,50 @@ func BenchmarkPrecompiledBLS12381G2MultiExpWorstCase(b *testing.B) {
 	}
 	benchmarkPrecompiled("0f", testcase, b)
 }
+
+func TestEth2HeaderStoreFork(t *testing.T) {
+	config := *params.TestChainConfig
+	config.Eth2HeaderStoreBlock = big.NewInt(10)
+	for _, tt := range []struct {
+		number int64
+		active bool
+	}{{9, false}, {10, true}, {11, true}} {
+		evm := NewEVM(BlockContext{BlockNumber: big.NewInt(tt.number)}, TxContext{}, nil, &config, Config{})
+		if _, ok := evm.precompile(eth2HeaderStoreAddress); ok != tt.active {
+			t.Errorf("block %d: precompile active %v, want %v", tt.number, ok, tt.active)
+		}
+		active := false
+		for _, addr := range ActivePrecompiles(config.Rules(big.NewInt(tt.number))) {
+			active = active || addr == eth2HeaderStoreAddress
+		}
+		if active != tt.active {
+			t.Errorf("block %d: precompile listed %v, want %v", tt.number, active, tt.active)
+		}
+	}
+}
+
+func TestVerifyBatchFork(t *testing.T) {
+	config := *params.TestChainConfig
+	config.VerifyBatchBlock = big.NewInt(10)
+	input, err := abiTxVerify.Pack(VerifyProofBatch, [][]byte{{0x01}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		number int64
+		gas    uint64
+		err    error
+	}{
+		{9, 21000, ErrMethodNotActive},
+		{10, TxVerifyGas[VerifyProof] + uint64(len(input))*VerifyProofBatchGasPerByte, nil},
+	} {
+		evm := NewEVM(BlockContext{BlockNumber: big.NewInt(tt.number)}, TxContext{}, nil, &config, Config{})
+		p, _ := evm.precompile(params.TxVerifyAddress)
+		if gas := p.RequiredGas(input); gas != tt.gas {
+			t.Errorf("block %d: gas %d, want %d", tt.number, gas, tt.gas)
+		}
+		if _, err := p.Run(evm, &Contract{}, input); err != tt.err {
+			t.Errorf("block %d: error %v, want %v", tt.number, err, tt.err)
+		}
+	}
+}
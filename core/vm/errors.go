@@ -35,6 +35,7 @@ var (
 	ErrReturnDataOutOfBounds    = errors.New("return data out of bounds")
 	ErrGasUintOverflow          = errors.New("gas uint64 overflow")
 	ErrNotSupportChain          = errors.New("not supported chain")
+	ErrMethodNotActive          = errors.New("method is not active at this block")
 	ErrInvalidCode              = errors.New("invalid code: must not begin with 0xef")
 
 	// ErrInvalidSubroutineEntry means that a BEGINSUB was reached via iteration,
@@ -0,0 +1,220 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/chains/eth2"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const (
+	Eth2Reset             = "reset"
+	Eth2UpdateLightClient = "updateLightClient"
+	Eth2CurNbrAndHash     = "currentNumberAndHash"
+	Eth2FinalizedSlot     = "finalizedSlot"
+	Eth2GetBlockHash      = "getBlockHash"
+	Eth2GetReceiptsRoot   = "getReceiptsRoot"
+	EventOfUpdateEth2     = "UpdateLightClient"
+)
+
+// Eth2HeaderStore contract ABI
+var (
+	abiEth2HeaderStore, _ = abi.JSON(strings.NewReader(params.Eth2HeaderStoreABIJSON))
+)
+
+// Eth2HeaderStoreGas defines the gas of the query methods
+var Eth2HeaderStoreGas = map[string]uint64{
+	Eth2CurNbrAndHash:   2100,
+	Eth2FinalizedSlot:   2100,
+	Eth2GetBlockHash:    2100,
+	Eth2GetReceiptsRoot: 2100,
+}
+
+// RunEth2HeaderStore execute atlas eth2 header store contract
+func RunEth2HeaderStore(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	method, err := abiEth2HeaderStore.MethodById(input)
+	if err != nil {
+		log.Error("get eth2 header store ABI method failed", "error", err)
+		return nil, err
+	}
+
+	data := input[4:]
+	switch method.Name {
+	case Eth2Reset:
+		ret, err = eth2Reset(evm, contract, data)
+	case Eth2UpdateLightClient:
+		ret, err = eth2UpdateLightClient(evm, contract, data)
+	case Eth2CurNbrAndHash:
+		ret, err = eth2CurrentNumberAndHash(evm, data)
+	case Eth2FinalizedSlot:
+		ret, err = eth2FinalizedSlot(evm, data)
+	case Eth2GetBlockHash, Eth2GetReceiptsRoot:
+		ret, err = eth2GetExecutionHeader(evm, method, data)
+	default:
+		log.Warn("run eth2 header store contract failed, invalid method name", "method.name", method.Name)
+		return ret, errors.New("invalid method name")
+	}
+
+	if err != nil {
+		log.Error("run eth2 header store contract failed", "method.name", method.Name, "error", err)
+	} else if _, ok := Eth2HeaderStoreGas[method.Name]; ok {
+		log.Debug("run eth2 header store contract succeed", "method.name", method.Name)
+	} else {
+		log.Info("run eth2 header store contract succeed", "method.name", method.Name)
+	}
+
+	return ret, err
+}
+
+func eth2Reset(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	args := struct {
+		State        []byte
+		Number       *big.Int
+		BlockHash    [32]byte
+		ReceiptsRoot [32]byte
+	}{}
+
+	adminHash := evm.StateDB.GetState(params.RegistryProxyAddress, params.ProxyOwnerStorageLocation)
+	if !bytes.Equal(contract.CallerAddress.Bytes(), adminHash[12:]) {
+		return nil, errors.New("forbidden")
+	}
+
+	method := abiEth2HeaderStore.Methods[Eth2Reset]
+	unpack, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := method.Inputs.Copy(&args, unpack); err != nil {
+		return nil, err
+	}
+
+	exe := &eth2.ExecutionHeader{
+		Number:       args.Number.Uint64(),
+		BlockHash:    args.BlockHash,
+		ReceiptsRoot: args.ReceiptsRoot,
+	}
+	if err := new(eth2.HeaderStore).ResetHeaderStore(evm.StateDB, args.State, exe); err != nil {
+		log.Error("failed to reset eth2 header store", "error", err)
+		return nil, err
+	}
+	return nil, nil
+}
+
+func eth2UpdateLightClient(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	args := struct {
+		ChainID *big.Int
+		Update  []byte
+	}{}
+
+	method := abiEth2HeaderStore.Methods[Eth2UpdateLightClient]
+	unpack, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := method.Inputs.Copy(&args, unpack); err != nil {
+		return nil, err
+	}
+	chain := chains.ChainType(args.ChainID.Uint64())
+	if err := validateRelayer(evm, contract.CallerAddress, chain); err != nil {
+		return nil, err
+	}
+
+	hs := eth2.NewHeaderStore(args.ChainID.Uint64())
+	exe, err := hs.UpdateLightClient(evm.StateDB, args.Update)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordSubmission(evm, chain, contract.CallerAddress, 1); err != nil {
+		return nil, err
+	}
+
+	event := abiEth2HeaderStore.Events[EventOfUpdateEth2]
+	logData, err := event.Inputs.NonIndexed().Pack()
+	if err != nil {
+		return nil, err
+	}
+	topics := []common.Hash{
+		event.ID,
+		contract.CallerAddress.Hash(),
+		common.BigToHash(args.ChainID),
+		common.BigToHash(new(big.Int).SetUint64(exe.Number)),
+	}
+	addLog(evm, contract, topics, logData)
+	return nil, nil
+}
+
+func eth2CurrentNumberAndHash(evm *EVM, input []byte) (ret []byte, err error) {
+	args := struct {
+		ChainID *big.Int
+	}{}
+	method := abiEth2HeaderStore.Methods[Eth2CurNbrAndHash]
+	unpack, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := method.Inputs.Copy(&args, unpack); err != nil {
+		return nil, err
+	}
+
+	number, hash, err := eth2.NewHeaderStore(args.ChainID.Uint64()).GetCurrentNumberAndHash(evm.StateDB)
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(new(big.Int).SetUint64(number), hash)
+}
+
+func eth2FinalizedSlot(evm *EVM, input []byte) (ret []byte, err error) {
+	args := struct {
+		ChainID *big.Int
+	}{}
+	method := abiEth2HeaderStore.Methods[Eth2FinalizedSlot]
+	unpack, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := method.Inputs.Copy(&args, unpack); err != nil {
+		return nil, err
+	}
+
+	hs := eth2.NewHeaderStore(args.ChainID.Uint64())
+	if err := hs.Load(evm.StateDB); err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(hs.FinalizedHeader.Slot)
+}
+
+func eth2GetExecutionHeader(evm *EVM, method *abi.Method, input []byte) (ret []byte, err error) {
+	args := struct {
+		ChainID *big.Int
+		Number  *big.Int
+	}{}
+	unpack, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := method.Inputs.Copy(&args, unpack); err != nil {
+		return nil, err
+	}
+
+	// the execution headers are stored under their own keys, the store itself with its
+	// sync committees is not loaded
+	hs := eth2.NewHeaderStore(args.ChainID.Uint64())
+	var hash common.Hash
+	if method.Name == Eth2GetBlockHash {
+		hash, err = hs.GetHashByNumber(evm.StateDB, args.Number.Uint64())
+	} else {
+		hash, err = hs.GetReceiptsRootByNumber(evm.StateDB, args.Number.Uint64())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(hash)
+}
@@ -71,6 +71,15 @@ func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 		precompiles = PrecompiledContractsHomestead
 	}
 	p, ok := precompiles[addr]
+	if !ok && evm.chainRules.IsEth2HeaderStore {
+		p, ok = PrecompiledContractsEth2HeaderStore[addr]
+	}
+	if batch, found := PrecompiledContractsVerifyBatch[addr]; found && evm.chainRules.IsVerifyBatch {
+		p, ok = batch, true
+	}
+	if relayers, found := PrecompiledContractsRelayerSet[addr]; found && evm.chainRules.IsRelayerSet {
+		p, ok = relayers, true
+	}
 	return p, ok
 }
 
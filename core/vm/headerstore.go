@@ -22,7 +22,9 @@ const (
 	CurNbrAndHash = "currentNumberAndHash"
 	SetRelayer    = "setRelayer"
 	GetRelayer    = "getRelayer"
+	IsConfirmed   = "isConfirmed"
 	EventOfUpdate = "UpdateBlockHeader"
+	EventOfReorg  = "ReorgBlockHeader"
 )
 
 // HeaderStore contract ABI
@@ -33,6 +35,7 @@ var (
 // SyncGas defines all method gas
 var SyncGas = map[string]uint64{
 	CurNbrAndHash: 42000,
+	IsConfirmed:   42000,
 	SetRelayer:    2100,
 	GetRelayer:    0,
 }
@@ -55,10 +58,14 @@ func RunHeaderStore(evm *EVM, contract *Contract, input []byte) (ret []byte, err
 		ret, err = reset(evm, contract, data)
 	case CurNbrAndHash:
 		ret, err = currentNumberAndHash(evm, contract, data)
+	case IsConfirmed:
+		ret, err = isConfirmed(evm, contract, data)
 	case SetRelayer:
 		ret, err = setRelayer(evm, contract, data)
 	case GetRelayer:
 		ret, err = getRelayer(evm)
+	case AddRelayer, RemoveRelayer, RotateRelayer, GetRelayers, SetRelayerRewardShare, GetRelayerRewardShare:
+		ret, err = runRelayerMethod(evm, contract, method.Name, data)
 	default:
 		log.Warn("run header store contract failed, invalid method name", "method.name", method.Name)
 		return ret, errors.New("invalid method name")
@@ -82,10 +89,6 @@ func updateBlockHeader(evm *EVM, contract *Contract, input []byte) (ret []byte,
 		Headers []byte
 	}{}
 
-	if err := validateRelayer(evm, contract.CallerAddress); err != nil {
-		return nil, err
-	}
-
 	method := abiHeaderStore.Methods[Save]
 	unpack, err := method.Inputs.Unpack(input)
 	if err != nil {
@@ -108,16 +111,18 @@ func updateBlockHeader(evm *EVM, contract *Contract, input []byte) (ret []byte,
 	// check if it is a supported chain
 	fromChain := chains.ChainType(args.From.Uint64())
 	toChain := chains.ChainType(args.To.Uint64())
-	if !(chains.IsSupportedChain(fromChain) && chains.IsSupportedChain(toChain)) {
+	info, err := chains.LookupAt(evm.chainConfig, evm.Context.BlockNumber, fromChain)
+	if err != nil {
 		return nil, ErrNotSupportChain
 	}
-
-	group, err := chains.ChainType2ChainGroup(fromChain)
-	if err != nil {
+	if _, err := chains.LookupAt(evm.chainConfig, evm.Context.BlockNumber, toChain); err != nil {
+		return nil, ErrNotSupportChain
+	}
+	if err := validateRelayer(evm, contract.CallerAddress, fromChain); err != nil {
 		return nil, err
 	}
 
-	chain, err := interfaces.ChainFactory(group)
+	chain, err := interfaces.ChainFactory(info)
 	if err != nil {
 		return nil, err
 	}
@@ -126,11 +131,20 @@ func updateBlockHeader(evm *EVM, contract *Contract, input []byte) (ret []byte,
 		return nil, err
 	}
 
-	nums, err := chain.InsertHeaders(evm.StateDB, args.Headers)
+	nums, reorg, err := chain.InsertHeaders(evm.StateDB, args.Headers)
 	if err != nil {
 		log.Error("failed to write headers", "error", err)
 		return nil, err
 	}
+	// the reorg log is part of the receipts since the relay finality fork
+	if reorg != nil && evm.chainConfig.IsRelayFinality(evm.Context.BlockNumber) {
+		if err := addReorgLog(evm, contract, fromChain, reorg); err != nil {
+			return nil, err
+		}
+	}
+	if err := recordSubmission(evm, fromChain, contract.CallerAddress, uint64(len(nums))); err != nil {
+		return nil, err
+	}
 
 	// make event
 	event := abiHeaderStore.Events[EventOfUpdate]
@@ -170,24 +184,28 @@ func reset(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
 	}
 
 	from := chains.ChainType(args.From.Uint64())
-	chainID, err := chains.ChainType2ChainID(from)
-	if err != nil {
-		return nil, err
+	info, err := chains.LookupAt(evm.chainConfig, evm.Context.BlockNumber, from)
+	if err != nil || info.ChainID == 0 {
+		return nil, ErrNotSupportChain
 	}
-	if evm.chainConfig.ChainID.Cmp(new(big.Int).SetUint64(chainID)) != 0 {
-		log.Info("reset ----------- ", "cfgId", evm.chainConfig.ChainID, "chainID", chainID)
+	if evm.chainConfig.ChainID.Cmp(new(big.Int).SetUint64(info.ChainID)) != 0 {
+		log.Info("reset ----------- ", "cfgId", evm.chainConfig.ChainID, "chainID", info.ChainID)
 		return nil, errors.New("current chainID does not match the from parameter")
 	}
 
-	group, err := chains.ChainType2ChainGroup(from)
+	hs, err := interfaces.HeaderStoreFactory(info.Group, info.Type)
 	if err != nil {
 		return nil, err
 	}
-	hs, err := interfaces.HeaderStoreFactory(group)
-	if err != nil {
-		return nil, err
+	// fall back to the registered checkpoint if no header is given
+	header, td := args.Header, args.Td
+	if len(header) == 0 {
+		if len(info.GenesisHeader) == 0 || info.GenesisTD == nil {
+			return nil, errors.New("header cannot be empty")
+		}
+		header, td = info.GenesisHeader, info.GenesisTD
 	}
-	if err := hs.ResetHeaderStore(evm.StateDB, args.Header, args.Td); err != nil {
+	if err := hs.ResetHeaderStore(evm.StateDB, header, td); err != nil {
 		log.Error("failed to reset header store", "error", err)
 		return nil, err
 	}
@@ -207,11 +225,11 @@ func currentNumberAndHash(evm *EVM, contract *Contract, input []byte) (ret []byt
 		return nil, err
 	}
 
-	group, err := chains.ChainType2ChainGroup(chains.ChainType(args.ChainID.Uint64()))
+	info, err := chains.LookupAt(evm.chainConfig, evm.Context.BlockNumber, chains.ChainType(args.ChainID.Uint64()))
 	if err != nil {
 		return nil, err
 	}
-	hs, err := interfaces.HeaderStoreFactory(group)
+	hs, err := interfaces.HeaderStoreFactory(info.Group, info.Type)
 	if err != nil {
 		return nil, err
 	}
@@ -222,6 +240,65 @@ func currentNumberAndHash(evm *EVM, contract *Contract, input []byte) (ret []byt
 	return method.Outputs.Pack(new(big.Int).SetUint64(number), hash.Bytes())
 }
 
+// addReorgLog records that the canonical chain of the relayed chain has been replaced
+// above the common ancestor.
+func addReorgLog(evm *EVM, contract *Contract, chain chains.ChainType, reorg *params.Reorg) error {
+	event := abiHeaderStore.Events[EventOfReorg]
+	logData, err := event.Inputs.NonIndexed().Pack(
+		reorg.Ancestor.Hash,
+		new(big.Int).SetUint64(reorg.OldHead.Number),
+		reorg.OldHead.Hash,
+		new(big.Int).SetUint64(reorg.NewHead.Number),
+		reorg.NewHead.Hash,
+		new(big.Int).SetUint64(reorg.Depth),
+	)
+	if err != nil {
+		return err
+	}
+	topics := []common.Hash{
+		event.ID,
+		common.BigToHash(new(big.Int).SetUint64(uint64(chain))),
+		common.BigToHash(new(big.Int).SetUint64(reorg.Ancestor.Number)),
+	}
+	addLog(evm, contract, topics, logData)
+	log.Info("relayed chain reorganized", "chain", chain, "ancestor", reorg.Ancestor.Number,
+		"oldHead", reorg.OldHead.Number, "newHead", reorg.NewHead.Number, "depth", reorg.Depth)
+	return nil
+}
+
+func isConfirmed(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	if !evm.chainConfig.IsRelayFinality(evm.Context.BlockNumber) {
+		return nil, ErrMethodNotActive
+	}
+	args := struct {
+		ChainID       *big.Int
+		BlockNumber   *big.Int
+		Confirmations *big.Int
+	}{}
+	method, _ := abiHeaderStore.Methods[IsConfirmed]
+	unpack, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+	if err := method.Inputs.Copy(&args, unpack); err != nil {
+		return nil, err
+	}
+
+	info, err := chains.LookupAt(evm.chainConfig, evm.Context.BlockNumber, chains.ChainType(args.ChainID.Uint64()))
+	if err != nil {
+		return nil, err
+	}
+	hs, err := interfaces.HeaderStoreFactory(info.Group, info.Type)
+	if err != nil {
+		return nil, err
+	}
+	confirmed, err := interfaces.IsConfirmed(evm.StateDB, hs, args.BlockNumber.Uint64(), args.Confirmations.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	return method.Outputs.Pack(confirmed)
+}
+
 func setRelayer(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
 	adminHash := evm.StateDB.GetState(params.RegistryProxyAddress, params.ProxyOwnerStorageLocation)
 	if !bytes.Equal(contract.CallerAddress.Bytes(), adminHash[12:]) {
@@ -249,14 +326,6 @@ func getRelayer(evm *EVM) (ret []byte, err error) {
 	return method.Outputs.Pack(common.BytesToAddress(relayerBytes))
 }
 
-func validateRelayer(evm *EVM, caller common.Address) error {
-	adminAddrBytes := evm.StateDB.GetPOWState(params.NewRelayerAddress, common.BytesToHash(params.NewRelayerAddress[:]))
-	if !bytes.Equal(caller.Bytes(), adminAddrBytes) {
-		return errors.New("invalid relayer")
-	}
-	return nil
-}
-
 func addLog(evm *EVM, contract *Contract, topics []common.Hash, data []byte) {
 	evm.StateDB.AddLog(&types.Log{
 		Address:     contract.Address(),
@@ -2,8 +2,16 @@ package vm
 
 import (
 	"fmt"
+	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/core/rawdb"
+	"github.com/mapprotocol/atlas/core/state"
+	"github.com/mapprotocol/atlas/params"
 )
 
 func headerStorePack(method string, args ...interface{}) []byte {
@@ -77,3 +85,92 @@ func TestCurrentNumberAndHash(t *testing.T) {
 		})
 	}
 }
+
+func TestRelayFinalityFork(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RelayFinalityBlock = big.NewInt(10)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	input := headerStorePack(IsConfirmed, new(big.Int).SetUint64(uint64(chains.ChainTypeETH)), big.NewInt(1), big.NewInt(1))
+
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(9)}, TxContext{}, db, &config, Config{})
+	if _, err := isConfirmed(evm, &Contract{}, input); err != ErrMethodNotActive {
+		t.Errorf("block 9: have %v, want %v", err, ErrMethodNotActive)
+	}
+	// the store has not been reset, but the method is active
+	evm = NewEVM(BlockContext{BlockNumber: big.NewInt(10)}, TxContext{}, db, &config, Config{})
+	if _, err := isConfirmed(evm, &Contract{}, input); err == nil || err == ErrMethodNotActive {
+		t.Errorf("block 10: have %v, want an uninitialized store", err)
+	}
+}
+
+func TestRelayerSet(t *testing.T) {
+	config := *params.TestChainConfig
+	config.RelayerSetBlock = big.NewInt(10)
+	config.Istanbul = &params.IstanbulConfig{Epoch: 10}
+	db, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	admin, a, b := common.HexToAddress("0xad"), common.HexToAddress("0x0a"), common.HexToAddress("0x0b")
+	db.SetState(params.RegistryProxyAddress, params.ProxyOwnerStorageLocation, admin.Hash())
+	eth, ethTest := new(big.Int).SetUint64(uint64(chains.ChainTypeETH)), new(big.Int).SetUint64(uint64(chains.ChainTypeETHTest))
+	call := func(number int64, caller common.Address, method string, args ...interface{}) error {
+		evm := NewEVM(BlockContext{BlockNumber: big.NewInt(number)}, TxContext{}, db, &config, Config{})
+		contract := NewContract(AccountRef(caller), AccountRef(params.HeaderStoreAddress), new(big.Int), 0)
+		_, err := runRelayerMethod(evm, contract, method, headerStorePack(method, args...))
+		return err
+	}
+
+	if err := call(9, admin, AddRelayer, eth, a); err != ErrMethodNotActive {
+		t.Fatalf("block 9: have %v, want %v", err, ErrMethodNotActive)
+	}
+	input := append(abiHeaderStore.Methods[AddRelayer].ID, headerStorePack(AddRelayer, eth, a)...)
+	if gas := (&store{}).RequiredGas(input); gas != 21000 {
+		t.Errorf("gas before the fork: have %d, want 21000", gas)
+	}
+	if gas := (&store{relayers: true}).RequiredGas(input); gas != RelayerGas[AddRelayer] {
+		t.Errorf("gas after the fork: have %d, want %d", gas, RelayerGas[AddRelayer])
+	}
+
+	for _, chain := range []*big.Int{eth, ethTest} {
+		if err := call(10, admin, AddRelayer, chain, a); err != nil {
+			t.Fatalf("add relayer: %v", err)
+		}
+	}
+	if err := call(10, admin, RemoveRelayer, big.NewInt(12345), a); err != ErrNotSupportChain {
+		t.Errorf("remove relayer of an unknown chain: have %v, want %v", err, ErrNotSupportChain)
+	}
+	if err := call(10, admin, SetRelayerRewardShare, big.NewInt(101)); err != ErrInvalidRewardShare {
+		t.Errorf("reward share: have %v, want %v", err, ErrInvalidRewardShare)
+	}
+	if err := call(10, admin, SetRelayerRewardShare, big.NewInt(60)); err != nil || RelayerRewardShare(db) != 60 {
+		t.Errorf("reward share: have %d, %v, want 60", RelayerRewardShare(db), err)
+	}
+
+	// the counters of the current epoch follow a rotated relayer, on its chain only
+	evm := NewEVM(BlockContext{BlockNumber: big.NewInt(11)}, TxContext{}, db, &config, Config{})
+	if err := recordSubmission(evm, chains.ChainTypeETH, a, 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := recordSubmission(evm, chains.ChainTypeETHTest, a, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := call(12, a, RotateRelayer, eth, common.Address{}); err != ErrInvalidRelayer {
+		t.Errorf("rotate to the zero address: have %v, want %v", err, ErrInvalidRelayer)
+	}
+	if err := call(12, a, RotateRelayer, big.NewInt(12345), b); err != ErrNotSupportChain {
+		t.Errorf("rotate relayer of an unknown chain: have %v, want %v", err, ErrNotSupportChain)
+	}
+	if err := call(10, admin, AddRelayer, eth, common.Address{}); err != ErrInvalidRelayer {
+		t.Errorf("add the zero address: have %v, want %v", err, ErrInvalidRelayer)
+	}
+	if err := call(12, a, RotateRelayer, eth, b); err != nil {
+		t.Fatalf("rotate relayer: %v", err)
+	}
+	submissions, _ := EpochSubmissions(db, 2)
+	want := []*Relayer{{Address: b, Submissions: 3}, {Address: a, Submissions: 2}}
+	if !reflect.DeepEqual(submissions, want) {
+		t.Errorf("epoch submissions: have %v, want %v", submissions, want)
+	}
+	relayers, _ := ChainRelayers(db, chains.ChainTypeETH)
+	if want := []*Relayer{{Address: b, Submissions: 3}}; !reflect.DeepEqual(relayers, want) {
+		t.Errorf("relayers: have %v, want %v", relayers, want)
+	}
+}
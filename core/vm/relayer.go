@@ -0,0 +1,468 @@
+package vm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/mapprotocol/atlas/chains"
+	"github.com/mapprotocol/atlas/consensus/istanbul"
+	"github.com/mapprotocol/atlas/core/types"
+	"github.com/mapprotocol/atlas/params"
+)
+
+const (
+	AddRelayer            = "addRelayer"
+	RemoveRelayer         = "removeRelayer"
+	RotateRelayer         = "rotateRelayer"
+	GetRelayers           = "getRelayers"
+	SetRelayerRewardShare = "setRelayerRewardShare"
+	GetRelayerRewardShare = "getRelayerRewardShare"
+	EventOfRelayerSet     = "RelayerUpdated"
+
+	// MaxRelayersPerChain bounds the relayers of a chain, the set is loaded on every submission
+	MaxRelayersPerChain = 32
+)
+
+var (
+	ErrRelayerExists      = errors.New("relayer already exists")
+	ErrRelayerNotFound    = errors.New("relayer not found")
+	ErrTooManyRelayers    = fmt.Errorf("a chain has at most %d relayers", MaxRelayersPerChain)
+	ErrInvalidRewardShare = errors.New("relayer reward share is a percentage")
+	ErrInvalidRelayer     = errors.New("relayer is the zero address")
+)
+
+// RelayerGas defines the gas of the relayer set methods, charged since the relayer set fork
+var RelayerGas = map[string]uint64{
+	AddRelayer:            42000,
+	RemoveRelayer:         42000,
+	RotateRelayer:         42000,
+	GetRelayers:           42000,
+	SetRelayerRewardShare: 2100,
+	GetRelayerRewardShare: 0,
+}
+
+// Relayer is an account authorized to submit the headers of a chain.
+type Relayer struct {
+	Address     common.Address
+	Submissions uint64 // headers accepted from the relayer
+}
+
+func relayersKey(chain chains.ChainType) common.Hash {
+	return common.BytesToHash([]byte(fmt.Sprintf("relayers-%d", chain)))
+}
+
+func epochSubmissionsKey(epoch uint64) common.Hash {
+	return common.BytesToHash([]byte(fmt.Sprintf("relayer-epoch-%d", epoch)))
+}
+
+var relayerRewardShareKey = common.BytesToHash([]byte("relayer-reward-share"))
+
+// epochSubmission counts the headers of a chain a relayer submitted during an epoch.
+type epochSubmission struct {
+	Chain       chains.ChainType
+	Address     common.Address
+	Submissions uint64
+}
+
+func loadRelayers(db types.StateDB, key common.Hash) ([]*Relayer, error) {
+	data := db.GetPOWState(params.NewRelayerAddress, key)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var relayers []*Relayer
+	if err := rlp.DecodeBytes(data, &relayers); err != nil {
+		return nil, err
+	}
+	return relayers, nil
+}
+
+func storeRelayers(db types.StateDB, key common.Hash, relayers []*Relayer) error {
+	if len(relayers) == 0 {
+		db.SetPOWState(params.NewRelayerAddress, key, nil)
+		return nil
+	}
+	data, err := rlp.EncodeToBytes(relayers)
+	if err != nil {
+		return err
+	}
+	db.SetPOWState(params.NewRelayerAddress, key, data)
+	return nil
+}
+
+func findRelayer(relayers []*Relayer, addr common.Address) int {
+	for i, r := range relayers {
+		if r.Address == addr {
+			return i
+		}
+	}
+	return -1
+}
+
+// ChainRelayers returns the relayers of a chain with their submission counters.
+func ChainRelayers(db types.StateDB, chain chains.ChainType) ([]*Relayer, error) {
+	return loadRelayers(db, relayersKey(chain))
+}
+
+func loadEpochSubmissions(db types.StateDB, key common.Hash) ([]*epochSubmission, error) {
+	data := db.GetPOWState(params.NewRelayerAddress, key)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var submissions []*epochSubmission
+	if err := rlp.DecodeBytes(data, &submissions); err != nil {
+		return nil, err
+	}
+	return submissions, nil
+}
+
+func storeEpochSubmissions(db types.StateDB, key common.Hash, submissions []*epochSubmission) error {
+	if len(submissions) == 0 {
+		db.SetPOWState(params.NewRelayerAddress, key, nil)
+		return nil
+	}
+	data, err := rlp.EncodeToBytes(submissions)
+	if err != nil {
+		return err
+	}
+	db.SetPOWState(params.NewRelayerAddress, key, data)
+	return nil
+}
+
+func findEpochSubmission(submissions []*epochSubmission, chain chains.ChainType, addr common.Address) int {
+	for i, s := range submissions {
+		if s.Chain == chain && s.Address == addr {
+			return i
+		}
+	}
+	return -1
+}
+
+// currentEpochSubmissionsKey returns the key of the counters of the epoch being built.
+func currentEpochSubmissionsKey(evm *EVM) (common.Hash, bool) {
+	if evm.chainConfig.Istanbul == nil || evm.chainConfig.Istanbul.Epoch == 0 {
+		return common.Hash{}, false
+	}
+	epoch := istanbul.GetEpochNumber(evm.Context.BlockNumber.Uint64(), evm.chainConfig.Istanbul.Epoch)
+	return epochSubmissionsKey(epoch), true
+}
+
+// EpochSubmissions returns the headers every relayer submitted during an epoch,
+// summed over all chains in the order the relayers first submitted, the relayer
+// reward of the epoch is split by them.
+func EpochSubmissions(db types.StateDB, epoch uint64) ([]*Relayer, error) {
+	submissions, err := loadEpochSubmissions(db, epochSubmissionsKey(epoch))
+	if err != nil {
+		return nil, err
+	}
+	var relayers []*Relayer
+	for _, s := range submissions {
+		if i := findRelayer(relayers, s.Address); i >= 0 {
+			relayers[i].Submissions += s.Submissions
+		} else {
+			relayers = append(relayers, &Relayer{Address: s.Address, Submissions: s.Submissions})
+		}
+	}
+	return relayers, nil
+}
+
+// ClearEpochSubmissions drops the counters of an epoch once it is over, whether or not
+// its relayers were rewarded.
+func ClearEpochSubmissions(db types.StateDB, epoch uint64) {
+	db.SetPOWState(params.NewRelayerAddress, epochSubmissionsKey(epoch), nil)
+}
+
+// RelayerRewardShare returns the percentage of the maintainer reward paid to the
+// relayers, the rest goes to the maintainer.
+func RelayerRewardShare(db types.StateDB) uint64 {
+	data := db.GetPOWState(params.NewRelayerAddress, relayerRewardShareKey)
+	return new(big.Int).SetBytes(data).Uint64()
+}
+
+// recordSubmission counts the headers a relayer got accepted, both on its chain
+// and in the epoch totals the relayer rewards are split by.
+func recordSubmission(evm *EVM, chain chains.ChainType, relayer common.Address, headers uint64) error {
+	key := relayersKey(chain)
+	relayers, err := loadRelayers(evm.StateDB, key)
+	if err != nil {
+		return err
+	}
+	i := findRelayer(relayers, relayer)
+	if i < 0 {
+		// the legacy relayer configured by setRelayer is not counted
+		return nil
+	}
+	relayers[i].Submissions += headers
+	if err := storeRelayers(evm.StateDB, key, relayers); err != nil {
+		return err
+	}
+
+	key, ok := currentEpochSubmissionsKey(evm)
+	if !ok {
+		return nil
+	}
+	submissions, err := loadEpochSubmissions(evm.StateDB, key)
+	if err != nil {
+		return err
+	}
+	if i := findEpochSubmission(submissions, chain, relayer); i >= 0 {
+		submissions[i].Submissions += headers
+	} else {
+		submissions = append(submissions, &epochSubmission{Chain: chain, Address: relayer, Submissions: headers})
+	}
+	return storeEpochSubmissions(evm.StateDB, key, submissions)
+}
+
+// moveEpochSubmissions credits the headers of chain submitted by a rotated relayer
+// during the current epoch to its new key.
+func moveEpochSubmissions(evm *EVM, chain chains.ChainType, from, to common.Address) error {
+	key, ok := currentEpochSubmissionsKey(evm)
+	if !ok {
+		return nil
+	}
+	submissions, err := loadEpochSubmissions(evm.StateDB, key)
+	if err != nil {
+		return err
+	}
+	i := findEpochSubmission(submissions, chain, from)
+	if i < 0 {
+		return nil
+	}
+	if j := findEpochSubmission(submissions, chain, to); j >= 0 {
+		// the new key relayed the chain earlier in the epoch, before it was removed
+		submissions[j].Submissions += submissions[i].Submissions
+		submissions = append(submissions[:i], submissions[i+1:]...)
+	} else {
+		submissions[i].Address = to
+	}
+	return storeEpochSubmissions(evm.StateDB, key, submissions)
+}
+
+// validateRelayer checks that caller may submit the headers of chain. Chains without
+// a relayer set fall back to the single relayer configured by setRelayer.
+func validateRelayer(evm *EVM, caller common.Address, chain chains.ChainType) error {
+	relayers, err := ChainRelayers(evm.StateDB, chain)
+	if err != nil {
+		return err
+	}
+	if len(relayers) != 0 {
+		if findRelayer(relayers, caller) < 0 {
+			return errors.New("invalid relayer")
+		}
+		return nil
+	}
+	adminAddrBytes := evm.StateDB.GetPOWState(params.NewRelayerAddress, common.BytesToHash(params.NewRelayerAddress[:]))
+	if !bytes.Equal(caller.Bytes(), adminAddrBytes) {
+		return errors.New("invalid relayer")
+	}
+	return nil
+}
+
+func isAdmin(evm *EVM, caller common.Address) bool {
+	adminHash := evm.StateDB.GetState(params.RegistryProxyAddress, params.ProxyOwnerStorageLocation)
+	return bytes.Equal(caller.Bytes(), adminHash[12:])
+}
+
+func addRelayerLog(evm *EVM, contract *Contract, chain chains.ChainType, oldRelayer, newRelayer common.Address) {
+	event := abiHeaderStore.Events[EventOfRelayerSet]
+	topics := []common.Hash{
+		event.ID,
+		common.BigToHash(new(big.Int).SetUint64(uint64(chain))),
+		oldRelayer.Hash(),
+		newRelayer.Hash(),
+	}
+	addLog(evm, contract, topics, nil)
+	log.Info("relayer updated", "chain", chain, "old", oldRelayer, "new", newRelayer)
+}
+
+func unpackRelayerArgs(method string, input []byte, args interface{}) error {
+	m := abiHeaderStore.Methods[method]
+	unpack, err := m.Inputs.Unpack(input)
+	if err != nil {
+		return err
+	}
+	return m.Inputs.Copy(args, unpack)
+}
+
+// runRelayerMethod runs the relayer set methods, they are enabled by the relayer set fork.
+func runRelayerMethod(evm *EVM, contract *Contract, name string, input []byte) (ret []byte, err error) {
+	if !evm.chainConfig.IsRelayerSet(evm.Context.BlockNumber) {
+		return nil, ErrMethodNotActive
+	}
+	switch name {
+	case AddRelayer:
+		return addRelayer(evm, contract, input)
+	case RemoveRelayer:
+		return removeRelayer(evm, contract, input)
+	case RotateRelayer:
+		return rotateRelayer(evm, contract, input)
+	case GetRelayers:
+		return getRelayers(evm, input)
+	case SetRelayerRewardShare:
+		return setRelayerRewardShare(evm, contract, input)
+	default:
+		return getRelayerRewardShare(evm)
+	}
+}
+
+// addRelayer authorizes one more relayer for a chain, only the admin may call it.
+func addRelayer(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	if !isAdmin(evm, contract.CallerAddress) {
+		return nil, errors.New("forbidden")
+	}
+	args := struct {
+		ChainID *big.Int
+		Relayer common.Address
+	}{}
+	if err := unpackRelayerArgs(AddRelayer, input, &args); err != nil {
+		return nil, err
+	}
+	if args.Relayer == (common.Address{}) {
+		return nil, ErrInvalidRelayer
+	}
+	chain := chains.ChainType(args.ChainID.Uint64())
+	if _, err := chains.LookupAt(evm.chainConfig, evm.Context.BlockNumber, chain); err != nil {
+		return nil, ErrNotSupportChain
+	}
+
+	key := relayersKey(chain)
+	relayers, err := loadRelayers(evm.StateDB, key)
+	if err != nil {
+		return nil, err
+	}
+	if findRelayer(relayers, args.Relayer) >= 0 {
+		return nil, ErrRelayerExists
+	}
+	if len(relayers) >= MaxRelayersPerChain {
+		return nil, ErrTooManyRelayers
+	}
+	relayers = append(relayers, &Relayer{Address: args.Relayer})
+	if err := storeRelayers(evm.StateDB, key, relayers); err != nil {
+		return nil, err
+	}
+	addRelayerLog(evm, contract, chain, common.Address{}, args.Relayer)
+	return nil, nil
+}
+
+// removeRelayer revokes a relayer of a chain, only the admin may call it.
+func removeRelayer(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	if !isAdmin(evm, contract.CallerAddress) {
+		return nil, errors.New("forbidden")
+	}
+	args := struct {
+		ChainID *big.Int
+		Relayer common.Address
+	}{}
+	if err := unpackRelayerArgs(RemoveRelayer, input, &args); err != nil {
+		return nil, err
+	}
+	chain := chains.ChainType(args.ChainID.Uint64())
+	if _, err := chains.LookupAt(evm.chainConfig, evm.Context.BlockNumber, chain); err != nil {
+		return nil, ErrNotSupportChain
+	}
+
+	key := relayersKey(chain)
+	relayers, err := loadRelayers(evm.StateDB, key)
+	if err != nil {
+		return nil, err
+	}
+	i := findRelayer(relayers, args.Relayer)
+	if i < 0 {
+		return nil, ErrRelayerNotFound
+	}
+	relayers = append(relayers[:i], relayers[i+1:]...)
+	if err := storeRelayers(evm.StateDB, key, relayers); err != nil {
+		return nil, err
+	}
+	addRelayerLog(evm, contract, chain, args.Relayer, common.Address{})
+	return nil, nil
+}
+
+// rotateRelayer lets a relayer move its authorization and counters to a new key.
+func rotateRelayer(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	args := struct {
+		ChainID    *big.Int
+		NewRelayer common.Address
+	}{}
+	if err := unpackRelayerArgs(RotateRelayer, input, &args); err != nil {
+		return nil, err
+	}
+	if args.NewRelayer == (common.Address{}) {
+		return nil, ErrInvalidRelayer
+	}
+	chain := chains.ChainType(args.ChainID.Uint64())
+	if _, err := chains.LookupAt(evm.chainConfig, evm.Context.BlockNumber, chain); err != nil {
+		return nil, ErrNotSupportChain
+	}
+
+	key := relayersKey(chain)
+	relayers, err := loadRelayers(evm.StateDB, key)
+	if err != nil {
+		return nil, err
+	}
+	i := findRelayer(relayers, contract.CallerAddress)
+	if i < 0 {
+		return nil, ErrRelayerNotFound
+	}
+	if findRelayer(relayers, args.NewRelayer) >= 0 {
+		return nil, ErrRelayerExists
+	}
+	relayers[i].Address = args.NewRelayer
+	if err := storeRelayers(evm.StateDB, key, relayers); err != nil {
+		return nil, err
+	}
+	if err := moveEpochSubmissions(evm, chain, contract.CallerAddress, args.NewRelayer); err != nil {
+		return nil, err
+	}
+	addRelayerLog(evm, contract, chain, contract.CallerAddress, args.NewRelayer)
+	return nil, nil
+}
+
+func getRelayers(evm *EVM, input []byte) (ret []byte, err error) {
+	args := struct {
+		ChainID *big.Int
+	}{}
+	if err := unpackRelayerArgs(GetRelayers, input, &args); err != nil {
+		return nil, err
+	}
+	relayers, err := ChainRelayers(evm.StateDB, chains.ChainType(args.ChainID.Uint64()))
+	if err != nil {
+		return nil, err
+	}
+	addrs := make([]common.Address, 0, len(relayers))
+	submissions := make([]*big.Int, 0, len(relayers))
+	for _, r := range relayers {
+		addrs = append(addrs, r.Address)
+		submissions = append(submissions, new(big.Int).SetUint64(r.Submissions))
+	}
+	return abiHeaderStore.Methods[GetRelayers].Outputs.Pack(addrs, submissions)
+}
+
+// setRelayerRewardShare sets the percentage of the maintainer reward paid to the
+// relayers, only the admin may call it.
+func setRelayerRewardShare(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	if !isAdmin(evm, contract.CallerAddress) {
+		return nil, errors.New("forbidden")
+	}
+	args := struct {
+		Share *big.Int
+	}{}
+	if err := unpackRelayerArgs(SetRelayerRewardShare, input, &args); err != nil {
+		return nil, err
+	}
+	if args.Share.Cmp(big.NewInt(100)) > 0 {
+		return nil, ErrInvalidRewardShare
+	}
+	evm.StateDB.SetPOWState(params.NewRelayerAddress, relayerRewardShareKey, args.Share.Bytes())
+	return nil, nil
+}
+
+func getRelayerRewardShare(evm *EVM) (ret []byte, err error) {
+	share := new(big.Int).SetUint64(RelayerRewardShare(evm.StateDB))
+	return abiHeaderStore.Methods[GetRelayerRewardShare].Outputs.Pack(share)
+}
@@ -3,6 +3,7 @@ package vm
 import (
 	"bytes"
 	"errors"
+	"fmt"
 	"math/big"
 	"strings"
 
@@ -17,7 +18,14 @@ import (
 )
 
 const (
-	VerifyProof = "verifyProofData"
+	VerifyProof      = "verifyProofData"
+	VerifyProofBatch = "verifyProofDataBatch"
+
+	// MaxBatchProofs is the maximum number of proofs verified in one batch call
+	MaxBatchProofs = 256
+	// VerifyProofBatchGasPerByte is charged for every byte of a batch on top of the
+	// gas of a single verification
+	VerifyProofBatchGasPerByte = 16
 )
 
 // TxVerify contract ABI
@@ -41,6 +49,11 @@ func RunTxVerify(evm *EVM, contract *Contract, input []byte) (ret []byte, err er
 	switch method.Name {
 	case VerifyProof:
 		ret, err = verifyProofData(evm, contract, data)
+	case VerifyProofBatch:
+		if !evm.chainRules.IsVerifyBatch {
+			return nil, ErrMethodNotActive
+		}
+		ret, err = verifyProofDataBatch(evm, contract, data)
 	default:
 		log.Warn("run tx verify contract failed, invalid method", "method", method.Name)
 		return ret, errors.New("invalid method name")
@@ -54,6 +67,15 @@ func RunTxVerify(evm *EVM, contract *Contract, input []byte) (ret []byte, err er
 	return ret, err
 }
 
+// receiptProofArgs is the RLP encoded receipt proof passed to verifyProofData.
+type receiptProofArgs struct {
+	Router   common.Address
+	Coin     common.Address
+	SrcChain *big.Int
+	DstChain *big.Int
+	TxProve  []byte
+}
+
 func verifyProofData(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
 	var (
 		success      = true
@@ -61,13 +83,7 @@ func verifyProofData(evm *EVM, contract *Contract, input []byte) (ret []byte, er
 		logs         []byte
 		receiptProof []byte
 	)
-	args := struct {
-		Router   common.Address
-		Coin     common.Address
-		SrcChain *big.Int
-		DstChain *big.Int
-		TxProve  []byte
-	}{}
+	var args receiptProofArgs
 
 	verifyProof := abiTxVerify.Methods[VerifyProof]
 	defer func() {
@@ -121,15 +137,12 @@ func verifyProofData(evm *EVM, contract *Contract, input []byte) (ret []byte, er
 	//if bytes.Equal(args.Coin.Bytes(), common.Address{}.Bytes()) {
 	//	return nil, errors.New("coin address is empty")
 	//}
-	if !chains.IsSupportedChain(chains.ChainType(args.SrcChain.Uint64())) {
-		return nil, ErrNotSupportChain
-	}
-	group, err := chains.ChainType2ChainGroup(chains.ChainType(args.SrcChain.Uint64()))
+	info, err := chains.LookupAt(evm.chainConfig, evm.Context.BlockNumber, chains.ChainType(args.SrcChain.Uint64()))
 	if err != nil {
-		return nil, err
+		return nil, ErrNotSupportChain
 	}
 
-	v, err := interfaces.VerifyFactory(group)
+	v, err := interfaces.VerifyFactory(info)
 	if err != nil {
 		return nil, err
 	}
@@ -140,3 +153,84 @@ func verifyProofData(evm *EVM, contract *Contract, input []byte) (ret []byte, er
 	}
 	return nil, nil
 }
+
+// verifyProofDataBatch verifies many receipt proofs in one call. Proofs of the same chain
+// share their header lookups, and every proof gets its own result.
+func verifyProofDataBatch(evm *EVM, contract *Contract, input []byte) (ret []byte, err error) {
+	method := abiTxVerify.Methods[VerifyProofBatch]
+	unpack, err := method.Inputs.Unpack(input)
+	if err != nil {
+		return nil, err
+	}
+	receiptProofs, ok := unpack[0].([][]byte)
+	if !ok {
+		return nil, errors.New("invalid receipt proofs")
+	}
+	if len(receiptProofs) == 0 || len(receiptProofs) > MaxBatchProofs {
+		return nil, fmt.Errorf("number of receipt proofs should be between 1 and %d, but got %d", MaxBatchProofs, len(receiptProofs))
+	}
+
+	var (
+		logs   = make([][]byte, len(receiptProofs))
+		errs   = make([]error, len(receiptProofs))
+		args   = make([]*receiptProofArgs, len(receiptProofs))
+		groups = make(map[chains.ChainType][]int)
+		order  []chains.ChainType
+	)
+	for i, receiptProof := range receiptProofs {
+		args[i] = new(receiptProofArgs)
+		if err := rlp.DecodeBytes(receiptProof, args[i]); err != nil {
+			errs[i] = err
+			continue
+		}
+		if bytes.Equal(args[i].Router.Bytes(), common.Address{}.Bytes()) {
+			errs[i] = errors.New("router address is empty")
+			continue
+		}
+		chain := chains.ChainType(args[i].SrcChain.Uint64())
+		if _, ok := groups[chain]; !ok {
+			order = append(order, chain)
+		}
+		groups[chain] = append(groups[chain], i)
+	}
+
+	for _, chain := range order {
+		indexes := groups[chain]
+		v, err := batchVerifier(evm, chain)
+		if err != nil {
+			for _, i := range indexes {
+				errs[i] = err
+			}
+			continue
+		}
+
+		routers := make([]common.Address, len(indexes))
+		txProves := make([][]byte, len(indexes))
+		for j, i := range indexes {
+			routers[j], txProves[j] = args[i].Router, args[i].TxProve
+		}
+		chainLogs, chainErrs := interfaces.VerifyBatch(evm.StateDB, v, routers, txProves)
+		for j, i := range indexes {
+			logs[i], errs[i] = chainLogs[j], chainErrs[j]
+		}
+	}
+
+	success := make([]bool, len(receiptProofs))
+	messages := make([]string, len(receiptProofs))
+	for i, err := range errs {
+		success[i] = err == nil
+		if err != nil {
+			messages[i], logs[i] = err.Error(), []byte{}
+			log.Debug("verify proof in batch failed", "index", i, "err", err)
+		}
+	}
+	return method.Outputs.Pack(success, messages, logs)
+}
+
+func batchVerifier(evm *EVM, chain chains.ChainType) (interfaces.IVerify, error) {
+	info, err := chains.LookupAt(evm.chainConfig, evm.Context.BlockNumber, chain)
+	if err != nil {
+		return nil, ErrNotSupportChain
+	}
+	return interfaces.VerifyFactory(info)
+}
@@ -203,7 +203,7 @@ func TestReceiptsRootAndProof(t *testing.T) {
 		router = common.HexToAddress("0xd6199276959b95a68c1ee30e8569f5fe060903a6")
 	)
 
-	group, err := chains.ChainType2ChainGroup(chains.ChainType(srcChain.Uint64()))
+	info, err := chains.Lookup(chains.ChainType(srcChain.Uint64()))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -211,7 +211,7 @@ func TestReceiptsRootAndProof(t *testing.T) {
 	//set := flag.NewFlagSet("test", 0)
 	//chainsdb.NewStoreDb(cli.NewContext(nil, set, nil), 10, 2)
 
-	v, err := interfaces.VerifyFactory(group)
+	v, err := interfaces.VerifyFactory(info)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -16,12 +16,16 @@ type Cluster struct {
 	env    *env.Environment
 	config Config
 
-	nodes []*Node
+	nodes   []*Node
+	network *Network
 }
 
 type Config struct {
 	GethPath   string
 	ExtraFlags string
+	// ProxyPort is the first port of the proxies the nodes connect to each other through, so that
+	// faults can be injected on their links. The nodes connect directly if zero.
+	ProxyPort int64
 }
 
 // New creates a new cluster instance
@@ -56,8 +60,18 @@ func (cl *Cluster) Init() error {
 	// Connect each validator to each other
 	for i, node := range nodes {
 		var urls []string
-		urls = append(urls, enodeUrls[:i]...)
-		urls = append(urls, enodeUrls[i+1:]...)
+		for j, peer := range nodes {
+			if i == j {
+				continue
+			}
+			url := enodeUrls[j]
+			if cl.config.ProxyPort != 0 {
+				if url, err = peer.enodeURL(cl.proxyPort(i, j)); err != nil {
+					return err
+				}
+			}
+			urls = append(urls, url)
+		}
 		err = node.SetStaticNodes(urls...)
 		if err != nil {
 			return err
@@ -67,6 +81,62 @@ func (cl *Cluster) Init() error {
 	return nil
 }
 
+// proxyPort is the port of the proxy node i connects to node j through
+func (cl *Cluster) proxyPort(i, j int) int64 {
+	return cl.config.ProxyPort + int64(i*len(cl.nodes)+j)
+}
+
+// Nodes returns the nodes of the cluster
+func (cl *Cluster) Nodes() []*Node { return cl.ensureNodes() }
+
+// Network returns the proxies between the nodes, nil unless the cluster links are proxied and
+// the cluster started
+func (cl *Cluster) Network() *Network { return cl.network }
+
+// startNetwork starts the proxies between the nodes if the cluster links are proxied
+func (cl *Cluster) startNetwork() error {
+	nodes := cl.ensureNodes()
+	if cl.config.ProxyPort == 0 || cl.network != nil {
+		return nil
+	}
+	network, err := NewNetwork(len(nodes), cl.proxyPort, func(j int) int64 { return nodes[j].NodePort() })
+	if err != nil {
+		return err
+	}
+	cl.network = network
+	return nil
+}
+
+// Start starts all the cluster nodes, and the proxies between them
+func (cl *Cluster) Start() error {
+	if err := cl.startNetwork(); err != nil {
+		return err
+	}
+	for i, node := range cl.ensureNodes() {
+		log.Printf("Starting validator%02d...", i)
+		if err := node.Start(); err != nil {
+			cl.Stop()
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop stops the running cluster nodes and the proxies between them
+func (cl *Cluster) Stop() {
+	for _, node := range cl.ensureNodes() {
+		if node.Running() {
+			if err := node.Stop(); err != nil {
+				log.Printf("Failed to stop validator-%d: %v", node.Number, err)
+			}
+		}
+	}
+	if cl.network != nil {
+		cl.network.Close()
+		cl.network = nil
+	}
+}
+
 func (cl *Cluster) ensureNodes() []*Node {
 
 	if cl.nodes == nil {
@@ -103,6 +173,9 @@ func (cl *Cluster) PrintNodeInfo() error {
 
 // Run will run all the cluster nodes
 func (cl *Cluster) Run(ctx context.Context) error {
+	if err := cl.startNetwork(); err != nil {
+		return err
+	}
 	group, ctx := errgroup.WithContext(ctx)
 	log.Printf("Starting cluster")
 	for i, node := range cl.ensureNodes() {
@@ -111,5 +184,10 @@ func (cl *Cluster) Run(ctx context.Context) error {
 		log.Printf("Starting validator%02d...", i)
 		group.Go(func() error { return node.Run(ctx) })
 	}
-	return group.Wait()
+	err := group.Wait()
+	if cl.network != nil {
+		cl.network.Close()
+		cl.network = nil
+	}
+	return err
 }
@@ -0,0 +1,248 @@
+package cluster
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+)
+
+// lossDelay is the delay of the data lost on a link, as TCP would retransmit it
+const lossDelay = 200 * time.Millisecond
+
+// LinkFaults are the faults injected on the link between two nodes
+type LinkFaults struct {
+	Down    bool          // The link is partitioned, its connections are closed and refused
+	Latency time.Duration // Delay added to the data crossing the link
+	Loss    float64       // Probability of the data read from a connection to be lost, and so delayed by lossDelay
+}
+
+// link is the link between two nodes, shared by the proxies of both directions
+type link struct {
+	mu     sync.Mutex
+	faults LinkFaults
+	conns  map[net.Conn]struct{}
+}
+
+func (l *link) getFaults() LinkFaults {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.faults
+}
+
+func (l *link) setFaults(faults LinkFaults) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.faults = faults
+	if faults.Down {
+		for conn := range l.conns {
+			conn.Close()
+		}
+	}
+}
+
+// track adds the connection to the link, failing if the link is down
+func (l *link) track(conn net.Conn) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.faults.Down {
+		return false
+	}
+	l.conns[conn] = struct{}{}
+	return true
+}
+
+func (l *link) untrack(conn net.Conn) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.conns, conn)
+}
+
+// Network is a layer of local TCP proxies between the nodes of a cluster. Each node dials each of
+// its peers through a proxy of their link, injecting the faults of the link.
+type Network struct {
+	size      int
+	links     map[[2]int]*link
+	listeners []net.Listener
+}
+
+// NewNetwork creates the network of size nodes, the proxy from node i to node j listening on
+// proxyPort(i, j) and forwarding to nodePort(j)
+func NewNetwork(size int, proxyPort func(i, j int) int64, nodePort func(j int) int64) (*Network, error) {
+	nw := &Network{
+		size:  size,
+		links: make(map[[2]int]*link),
+	}
+	for i := 0; i < size; i++ {
+		for j := i + 1; j < size; j++ {
+			nw.links[[2]int{i, j}] = &link{conns: make(map[net.Conn]struct{})}
+		}
+	}
+	for i := 0; i < size; i++ {
+		for j := 0; j < size; j++ {
+			if i == j {
+				continue
+			}
+			listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", proxyPort(i, j)))
+			if err != nil {
+				nw.Close()
+				return nil, err
+			}
+			nw.listeners = append(nw.listeners, listener)
+			go nw.serve(listener, fmt.Sprintf("127.0.0.1:%d", nodePort(j)), nw.link(i, j))
+		}
+	}
+	return nw, nil
+}
+
+// Close closes the proxies of the network and their connections
+func (nw *Network) Close() {
+	for _, listener := range nw.listeners {
+		listener.Close()
+	}
+	for _, l := range nw.links {
+		l.setFaults(LinkFaults{Down: true})
+	}
+}
+
+func (nw *Network) link(i, j int) *link {
+	if i > j {
+		i, j = j, i
+	}
+	return nw.links[[2]int{i, j}]
+}
+
+// SetFaults sets the faults of the link between nodes i and j
+func (nw *Network) SetFaults(i, j int, faults LinkFaults) error {
+	l := nw.link(i, j)
+	if l == nil {
+		return fmt.Errorf("no link between validator-%d and validator-%d", i, j)
+	}
+	l.setFaults(faults)
+	return nil
+}
+
+// UpdateFaults updates the faults of the link between nodes i and j
+func (nw *Network) UpdateFaults(i, j int, update func(*LinkFaults)) error {
+	l := nw.link(i, j)
+	if l == nil {
+		return fmt.Errorf("no link between validator-%d and validator-%d", i, j)
+	}
+	faults := l.getFaults()
+	update(&faults)
+	l.setFaults(faults)
+	return nil
+}
+
+// Partition brings down the links between the nodes of different groups. The links of the nodes
+// of no group are left as they are.
+func (nw *Network) Partition(groups [][]int) error {
+	group := make(map[int]int)
+	for g, nodes := range groups {
+		for _, node := range nodes {
+			if _, ok := group[node]; ok {
+				return fmt.Errorf("validator-%d is in several partition groups", node)
+			}
+			group[node] = g
+		}
+	}
+	for i := 0; i < nw.size; i++ {
+		for j := i + 1; j < nw.size; j++ {
+			gi, iok := group[i]
+			gj, jok := group[j]
+			if iok && jok && gi != gj {
+				if err := nw.UpdateFaults(i, j, func(f *LinkFaults) { f.Down = true }); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Heal removes the faults of all the links
+func (nw *Network) Heal() {
+	for _, l := range nw.links {
+		l.setFaults(LinkFaults{})
+	}
+}
+
+func (nw *Network) serve(listener net.Listener, target string, l *link) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go proxyConn(conn, target, l)
+	}
+}
+
+// proxyConn forwards the connection to the target through the link
+func proxyConn(conn net.Conn, target string, l *link) {
+	if !l.track(conn) {
+		conn.Close()
+		return
+	}
+	defer l.untrack(conn)
+	upstream, err := net.Dial("tcp", target)
+	if err != nil {
+		conn.Close()
+		return
+	}
+	if !l.track(upstream) {
+		conn.Close()
+		upstream.Close()
+		return
+	}
+	defer l.untrack(upstream)
+
+	done := make(chan struct{}, 2)
+	go func() { forward(upstream, conn, l); done <- struct{}{} }()
+	go func() { forward(conn, upstream, l); done <- struct{}{} }()
+	// Both ends are closed once either direction ends
+	<-done
+	conn.Close()
+	upstream.Close()
+	<-done
+}
+
+// chunk is data read from a connection, to be written to the other end at a given time
+type chunk struct {
+	data []byte
+	at   time.Time
+}
+
+// forward copies the data from src to dst, delaying it by the latency and losses of the link.
+// The data is written in order, so that a loss delays the data after it as well.
+func forward(dst, src net.Conn, l *link) {
+	chunks := make(chan chunk, 1024)
+	defer close(chunks)
+	go func() {
+		for c := range chunks {
+			time.Sleep(time.Until(c.at))
+			if _, err := dst.Write(c.data); err != nil {
+				src.Close()
+				for range chunks {
+				}
+				return
+			}
+		}
+	}()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			faults := l.getFaults()
+			delay := faults.Latency
+			if faults.Loss > 0 && rand.Float64() < faults.Loss {
+				delay += lossDelay
+			}
+			chunks <- chunk{data: append([]byte{}, buf[:n]...), at: time.Now().Add(delay)}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package cluster
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// freePort returns a port free to listen on
+func freePort(t *testing.T) int64 {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return int64(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// echo sends the message over the connection and reads it back
+func echo(conn net.Conn, msg string) (string, error) {
+	if _, err := conn.Write([]byte(msg)); err != nil {
+		return "", err
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, len(msg))
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func TestNetworkFaults(t *testing.T) {
+	// Node 1 is an echo server
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(conn, conn)
+		}
+	}()
+	ports := map[[2]int]int64{{0, 1}: freePort(t), {1, 0}: freePort(t)}
+	nw, err := NewNetwork(2,
+		func(i, j int) int64 { return ports[[2]int{i, j}] },
+		func(j int) int64 { return int64(server.Addr().(*net.TCPAddr).Port) },
+	)
+	if err != nil {
+		t.Fatalf("NewNetwork: %v", err)
+	}
+	defer nw.Close()
+	dial := func() net.Conn {
+		conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", ports[[2]int{0, 1}]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return conn
+	}
+
+	conn := dial()
+	defer conn.Close()
+	if reply, err := echo(conn, "ping"); err != nil || reply != "ping" {
+		t.Fatalf("echo through the proxy: have %q, %v", reply, err)
+	}
+
+	// The latency is added in both directions
+	if err := nw.SetFaults(1, 0, LinkFaults{Latency: 100 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if _, err := echo(conn, "ping"); err != nil {
+		t.Fatalf("echo with latency: %v", err)
+	}
+	if rtt := time.Since(start); rtt < 200*time.Millisecond {
+		t.Errorf("round trip too short with latency: %v", rtt)
+	}
+
+	// A partition closes the connections of the link and refuses the new ones
+	if err := nw.Partition([][]int{{0}, {1}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := echo(conn, "ping"); err == nil {
+		t.Error("expected the connection to be closed by the partition")
+	}
+	partitioned := dial()
+	defer partitioned.Close()
+	if _, err := echo(partitioned, "ping"); err == nil {
+		t.Error("expected the connection to be refused by the partition")
+	}
+
+	nw.Heal()
+	healed := dial()
+	defer healed.Close()
+	if reply, err := echo(healed, "ping"); err != nil || reply != "ping" {
+		t.Fatalf("echo after heal: have %q, %v", reply, err)
+	}
+
+	if err := nw.Partition([][]int{{0, 1}, {1}}); err == nil {
+		t.Error("expected error for a node in several groups")
+	}
+}
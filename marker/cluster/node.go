@@ -12,9 +12,12 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/mapprotocol/atlas/marker/env"
 	"strconv"
 	"strings"
@@ -45,9 +48,18 @@ func (nc *NodeConfig) NodePort() int64 {
 	return int64(30303 + nc.Number)
 }
 
+// stopTimeout is the time a node is given to shut down once interrupted
+const stopTimeout = 30 * time.Second
+
 // Node represents a Node runner
 type Node struct {
 	*NodeConfig
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd     // Process of the node, nil unless running
+	done   chan struct{} // Closed when the process exits
+	err    error         // Exit error of the process
+	client *rpc.Client
 }
 
 // NewNode creates a node runner
@@ -75,12 +87,18 @@ func (n *Node) SetStaticNodes(enodeUrls ...string) error {
 
 // EnodeURL returns the enode url used by the node
 func (n *Node) EnodeURL() (string, error) {
+	return n.enodeURL(n.NodePort())
+}
+
+// enodeURL returns the enode url of the node at the given local port, which is that of a proxy
+// to the node when the cluster links are proxied
+func (n *Node) enodeURL(port int64) (string, error) {
 	nodekey, err := crypto.LoadECDSA(n.keyFile())
 	if err != nil {
 		return "", err
 	}
 	ip := net.IP{127, 0, 0, 1}
-	en := enode.NewV4(&nodekey.PublicKey, ip, int(n.NodePort()), int(n.NodePort()))
+	en := enode.NewV4(&nodekey.PublicKey, ip, int(port), int(port))
 	return en.URLv4(), nil
 }
 
@@ -143,51 +161,33 @@ func (n *Node) generateNodeKey() error {
 	return nil
 }
 
-// Run will run the node
+// Run will run the node until the context is done
 func (n *Node) Run(ctx context.Context) error {
-
-	var addressToUnlock string
-	for _, addr := range n.AccountAddresses() {
-		addressToUnlock += "," + addr.Hex()
+	if err := n.Start(); err != nil {
+		return err
 	}
-
-	args := []string{
-		"--datadir", n.Datadir,
-		"--verbosity", "4",
-		"--networkid", n.ChainID.String(),
-		"--syncmode", "full",
-		"--mine",
-		"--allow-insecure-unlock",
-		"--nodiscover",
-		"--nat", "extip:127.0.0.1",
-		"--port", strconv.FormatInt(n.NodePort(), 10),
-		"--rpc",
-		"--rpcaddr", "127.0.0.1",
-		"--rpcport", strconv.FormatInt(n.RPCPort(), 10),
-		"--rpcapi", "eth,net,web3,debug,admin,personal,istanbul,txpool",
-		// "--nodiscover", "--nousb ",
-		"--unlock", addressToUnlock,
-		"--password", n.pwdFile(),
+	n.mu.Lock()
+	done := n.done
+	n.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return n.Stop()
+	case <-done:
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		return n.err
 	}
+}
 
-	// Once we're sure we won't run v1.2.x and older, can get rid of this check
-	// and just use the new options
-	helpBytes, _ := exec.Command(n.GethPath, "--help").Output() // #nosec G204
-	useTxFeeRecipient := strings.Contains(string(helpBytes), "miner.validator")
-	if useTxFeeRecipient {
-		args = append(args,
-			"--miner.validator", n.Account.Address.Hex(),
-			"--tx-fee-recipient", n.TxFeeRecipientAccount.Address.Hex(),
-		)
-	} else {
-		args = append(args,
-			"--etherbase", n.Account.Address.Hex(),
-		)
+// Start starts the node process
+func (n *Node) Start() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.cmd != nil {
+		return fmt.Errorf("validator-%d is already running", n.Number)
 	}
 
-	if n.ExtraFlags != "" {
-		args = append(args, strings.Fields(n.ExtraFlags)...)
-	}
+	args := n.args()
 	cmd := exec.Command(n.GethPath, args...) // #nosec G204
 
 	log.Println(n.GethPath, strings.Join(args, " "))
@@ -196,29 +196,115 @@ func (n *Node) Run(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	defer logfile.Close()
 	cmd.Stderr = logfile
 	cmd.Stdout = os.Stdout
-	// cmd.Stderr = os.Stderr
 
 	if err := cmd.Start(); err != nil {
+		logfile.Close()
 		return err
 	}
+	n.cmd, n.done = cmd, make(chan struct{})
+	go func(done chan struct{}) {
+		err := cmd.Wait()
+		logfile.Close()
+		n.mu.Lock()
+		n.cmd, n.err = nil, err
+		n.mu.Unlock()
+		close(done)
+	}(n.done)
+	return nil
+}
 
-	// rpc, err := rpc.Dial(fmt.Sprintf("http://localhost:%d", n.RPCPort()))
-	// if err != nil {
-	// 	return err
-	// }
-	// rpc.CallContext(ctx, nil, "personal_unlock", )
+// Stop interrupts the node process and waits for it to exit, killing it if it takes longer
+// than stopTimeout
+func (n *Node) Stop() error {
+	done, err := n.signal(os.Interrupt)
+	if err != nil {
+		return err
+	}
+	select {
+	case <-done:
+		return nil
+	case <-time.After(stopTimeout):
+		log.Printf("validator-%d did not stop in %v, killing it", n.Number, stopTimeout)
+		return n.Kill()
+	}
+}
 
-	go func() {
-		<-ctx.Done()
-		if err := cmd.Process.Signal(os.Interrupt); err != nil {
-			log.Fatal("Failed to send interrupt signal to geth cmd")
+// Kill kills the node process, without letting it shut down, and waits for it to exit
+func (n *Node) Kill() error {
+	done, err := n.signal(os.Kill)
+	if err != nil {
+		return err
+	}
+	<-done
+	return nil
+}
+
+// Running reports whether the node process is running
+func (n *Node) Running() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.cmd != nil
+}
+
+// signal signals the node process, returning the channel closed when it exits
+func (n *Node) signal(sig os.Signal) (chan struct{}, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.cmd == nil {
+		return nil, fmt.Errorf("validator-%d is not running", n.Number)
+	}
+	return n.done, n.cmd.Process.Signal(sig)
+}
+
+// RPCURL is the url of the node http rpc server
+func (n *Node) RPCURL() string {
+	return fmt.Sprintf("http://127.0.0.1:%d", n.RPCPort())
+}
+
+// Client returns an rpc client of the node
+func (n *Node) Client() (*rpc.Client, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.client == nil {
+		client, err := rpc.Dial(n.RPCURL())
+		if err != nil {
+			return nil, err
 		}
-	}()
+		n.client = client
+	}
+	return n.client, nil
+}
+
+func (n *Node) args() []string {
+	var addressToUnlock string
+	for _, addr := range n.AccountAddresses() {
+		addressToUnlock += "," + addr.Hex()
+	}
 
-	return cmd.Wait()
+	args := []string{
+		"--datadir", n.Datadir,
+		"--verbosity", "4",
+		"--networkid", n.ChainID.String(),
+		"--syncmode", "full",
+		"--mine",
+		"--miner.validator", n.Account.Address.Hex(),
+		"--allow-insecure-unlock",
+		"--nodiscover",
+		"--nat", "extip:127.0.0.1",
+		"--port", strconv.FormatInt(n.NodePort(), 10),
+		"--http",
+		"--http.addr", "127.0.0.1",
+		"--http.port", strconv.FormatInt(n.RPCPort(), 10),
+		"--http.api", "eth,net,web3,debug,admin,personal,istanbul,txpool",
+		"--unlock", addressToUnlock,
+		"--password", n.pwdFile(),
+	}
+	if n.ExtraFlags != "" {
+		args = append(args, strings.Fields(n.ExtraFlags)...)
+	}
+	return args
 }
 
 func (n *Node) pwdFile() string         { return path.Join(n.Datadir, "password") }
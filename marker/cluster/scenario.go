@@ -0,0 +1,462 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/mapprotocol/atlas/marker/internal/console"
+	"gopkg.in/yaml.v2"
+)
+
+// Scenario step actions
+const (
+	actionStart      = "start"      // Start the stopped nodes
+	actionStop       = "stop"       // Stop the nodes, letting them shut down
+	actionKill       = "kill"       // Kill the nodes, once in the consensus state of the step if any
+	actionPartition  = "partition"  // Bring down the links between the groups of nodes
+	actionHeal       = "heal"       // Remove the faults of all the links
+	actionLatency    = "latency"    // Add latency to the links between the nodes
+	actionLoss       = "loss"       // Lose data on the links between the nodes
+	actionSleep      = "sleep"      // Wait for a duration
+	actionWaitBlocks = "waitBlocks" // Wait for blocks to be produced
+)
+
+const (
+	defaultLivenessBlocks = 3
+	defaultTimeout        = time.Minute
+	startTimeout          = 2 * time.Minute // Timeout of the first block of a started cluster
+	pollInterval          = 500 * time.Millisecond
+	statePollInterval     = 10 * time.Millisecond // Interval between two polls of the consensus state of a node to kill
+)
+
+// consensusStates are the consensus states a node can be killed in
+var consensusStates = []string{"Accept request", "Preprepared", "Prepared", "Committed", "Waiting for new round"}
+
+// Duration is a duration written as a string such as "1.5s" in scenario files
+type Duration time.Duration
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err != nil {
+		return fmt.Errorf("invalid duration, want a string such as \"2s\": %v", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Scenario is a list of fault injection steps applied to a running cluster. The liveness and
+// safety of the chain are checked at its end.
+type Scenario struct {
+	Name     string   `yaml:"name"`
+	Steps    []Step   `yaml:"steps"`
+	Liveness Liveness `yaml:"liveness"`
+}
+
+// Step is a step of a scenario. Nodes are given by their index in the cluster.
+type Step struct {
+	Action   string   `yaml:"action"`
+	Nodes    []int    `yaml:"nodes,omitempty"`    // Nodes of the action, the links between them for latency and loss
+	Groups   [][]int  `yaml:"groups,omitempty"`   // Groups of nodes of a partition
+	Duration Duration `yaml:"duration,omitempty"` // Duration of a sleep, or latency added to the links
+	Loss     float64  `yaml:"loss,omitempty"`     // Probability of losing the data crossing the links
+	Blocks   uint64   `yaml:"blocks,omitempty"`   // Blocks to wait for
+	State    string   `yaml:"state,omitempty"`    // Consensus state to kill the nodes in, such as "Prepared"
+	Timeout  Duration `yaml:"timeout,omitempty"`  // Timeout of waitBlocks, or of waiting for the state of kill
+}
+
+// Liveness is the liveness requirement at the end of a scenario: the chain produces blocks
+// within the timeout.
+type Liveness struct {
+	Blocks  uint64   `yaml:"blocks,omitempty"`
+	Timeout Duration `yaml:"timeout,omitempty"`
+}
+
+// ParseScenarios parses a YAML or JSON scenario file, holding a list of scenarios
+func ParseScenarios(data []byte) ([]*Scenario, error) {
+	var file struct {
+		Scenarios []*Scenario `yaml:"scenarios"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid scenario file: %v", err)
+	}
+	if len(file.Scenarios) == 0 {
+		return nil, fmt.Errorf("no scenario in the scenario file")
+	}
+	for i, s := range file.Scenarios {
+		if s.Name == "" {
+			s.Name = fmt.Sprintf("scenario-%d", i)
+		}
+		if s.Liveness.Blocks == 0 {
+			s.Liveness.Blocks = defaultLivenessBlocks
+		}
+		if s.Liveness.Timeout == 0 {
+			s.Liveness.Timeout = Duration(defaultTimeout)
+		}
+		for j := range s.Steps {
+			step := &s.Steps[j]
+			if step.Timeout == 0 {
+				step.Timeout = Duration(defaultTimeout)
+			}
+			if err := step.validate(); err != nil {
+				return nil, fmt.Errorf("scenario %s step %d (%s): %v", s.Name, j, step.Action, err)
+			}
+		}
+	}
+	return file.Scenarios, nil
+}
+
+func (step *Step) validate() error {
+	switch step.Action {
+	case actionStart, actionStop, actionKill:
+		if len(step.Nodes) == 0 {
+			return fmt.Errorf("nodes are required")
+		}
+		if step.State != "" && !contains(consensusStates, step.State) {
+			return fmt.Errorf("unknown consensus state %q, want one of %s", step.State, strings.Join(consensusStates, ", "))
+		}
+	case actionPartition:
+		if len(step.Groups) < 2 {
+			return fmt.Errorf("at least two groups are required")
+		}
+	case actionHeal:
+	case actionLatency:
+		if len(step.Nodes) < 2 || step.Duration <= 0 {
+			return fmt.Errorf("two nodes or more and a duration are required")
+		}
+	case actionLoss:
+		if len(step.Nodes) < 2 || step.Loss <= 0 || step.Loss > 1 {
+			return fmt.Errorf("two nodes or more and a loss between 0 and 1 are required")
+		}
+	case actionSleep:
+		if step.Duration <= 0 {
+			return fmt.Errorf("a duration is required")
+		}
+	case actionWaitBlocks:
+		if step.Blocks == 0 {
+			return fmt.Errorf("blocks are required")
+		}
+	default:
+		return fmt.Errorf("unknown action")
+	}
+	return nil
+}
+
+// faultsLinks reports whether the step injects link faults
+func (step *Step) faultsLinks() bool {
+	switch step.Action {
+	case actionPartition, actionHeal, actionLatency, actionLoss:
+		return true
+	}
+	return false
+}
+
+// check checks the nodes of the scenario exist in a cluster of size nodes
+func (s *Scenario) check(size int) error {
+	for i, step := range s.Steps {
+		nodes := append([]int{}, step.Nodes...)
+		for _, group := range step.Groups {
+			nodes = append(nodes, group...)
+		}
+		for _, node := range nodes {
+			if node < 0 || node >= size {
+				return fmt.Errorf("scenario %s step %d: no validator-%d in a cluster of %d validators", s.Name, i, node, size)
+			}
+		}
+	}
+	return nil
+}
+
+// RunScenarios runs the scenarios one after the other, each on a freshly initialized cluster, and
+// returns an error if any of them failed
+func (cl *Cluster) RunScenarios(ctx context.Context, scenarios []*Scenario) error {
+	var failed []string
+	for _, s := range scenarios {
+		console.Infof("Running scenario %s", s.Name)
+		if err := cl.RunScenario(ctx, s); err != nil {
+			log.Printf("Scenario %s failed: %v", s.Name, err)
+			failed = append(failed, s.Name)
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		log.Printf("Scenario %s passed", s.Name)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d scenarios failed: %s", len(failed), len(scenarios), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// RunScenario initializes and starts the cluster, applies the steps of the scenario, and checks
+// the liveness and safety of the chain. The cluster is stopped at the end.
+func (cl *Cluster) RunScenario(ctx context.Context, s *Scenario) error {
+	if err := s.check(len(cl.ensureNodes())); err != nil {
+		return err
+	}
+	for _, step := range s.Steps {
+		if step.faultsLinks() && cl.config.ProxyPort == 0 {
+			return fmt.Errorf("scenario %s injects link faults, which requires proxied links", s.Name)
+		}
+	}
+	if err := cl.Init(); err != nil {
+		return err
+	}
+	if err := cl.Start(); err != nil {
+		return err
+	}
+	defer cl.Stop()
+	if err := cl.waitBlocks(ctx, 1, startTimeout); err != nil {
+		return fmt.Errorf("cluster start: %v", err)
+	}
+
+	for i, step := range s.Steps {
+		log.Printf("Scenario %s step %d: %s %v", s.Name, i, step.Action, step.Nodes)
+		if err := cl.applyStep(ctx, &step); err != nil {
+			return fmt.Errorf("step %d (%s): %v", i, step.Action, err)
+		}
+	}
+	if err := cl.waitBlocks(ctx, s.Liveness.Blocks, time.Duration(s.Liveness.Timeout)); err != nil {
+		return fmt.Errorf("liveness: %v", err)
+	}
+	if err := cl.checkSafety(ctx); err != nil {
+		return fmt.Errorf("safety: %v", err)
+	}
+	return nil
+}
+
+func (cl *Cluster) applyStep(ctx context.Context, step *Step) error {
+	nodes := cl.ensureNodes()
+	switch step.Action {
+	case actionStart:
+		for _, i := range step.Nodes {
+			if err := nodes[i].Start(); err != nil {
+				return err
+			}
+		}
+	case actionStop:
+		for _, i := range step.Nodes {
+			if err := nodes[i].Stop(); err != nil {
+				return err
+			}
+		}
+	case actionKill:
+		for _, i := range step.Nodes {
+			if step.State != "" {
+				if err := waitState(ctx, nodes[i], step.State, time.Duration(step.Timeout)); err != nil {
+					return err
+				}
+			}
+			if err := nodes[i].Kill(); err != nil {
+				return err
+			}
+		}
+	case actionPartition:
+		return cl.network.Partition(step.Groups)
+	case actionHeal:
+		cl.network.Heal()
+	case actionLatency, actionLoss:
+		for a, i := range step.Nodes {
+			for _, j := range step.Nodes[a+1:] {
+				err := cl.network.UpdateFaults(i, j, func(f *LinkFaults) {
+					if step.Action == actionLatency {
+						f.Latency = time.Duration(step.Duration)
+					} else {
+						f.Loss = step.Loss
+					}
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+	case actionSleep:
+		select {
+		case <-time.After(time.Duration(step.Duration)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	case actionWaitBlocks:
+		return cl.waitBlocks(ctx, step.Blocks, time.Duration(step.Timeout))
+	}
+	return nil
+}
+
+// waitState waits for the node to be in the consensus state
+func waitState(ctx context.Context, node *Node, state string, timeout time.Duration) error {
+	client, err := node.Client()
+	if err != nil {
+		return err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		var roundState struct {
+			State string `json:"state"`
+		}
+		if err := client.CallContext(ctx, &roundState, "istanbul_getCurrentRoundState"); err == nil && roundState.State == state {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("validator-%d not in the %q consensus state within %v", node.Number, state, timeout)
+		}
+		select {
+		case <-time.After(statePollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// head returns the highest block number of the running nodes
+func (cl *Cluster) head(ctx context.Context) (uint64, error) {
+	var (
+		head    uint64
+		running bool
+		err     error
+	)
+	for _, node := range cl.ensureNodes() {
+		if !node.Running() {
+			continue
+		}
+		running = true
+		number, nodeErr := blockNumber(ctx, node)
+		if nodeErr != nil {
+			err = nodeErr
+			continue
+		}
+		if number > head {
+			head = number
+		}
+	}
+	if !running {
+		return 0, fmt.Errorf("no validator running")
+	}
+	if head == 0 && err != nil {
+		return 0, err
+	}
+	return head, nil
+}
+
+// waitBlocks waits for the running nodes to produce blocks
+func (cl *Cluster) waitBlocks(ctx context.Context, blocks uint64, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	start, err := cl.head(ctx)
+	for err != nil && time.Now().Before(deadline) {
+		// The nodes just started may not serve rpc yet
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		start, err = cl.head(ctx)
+	}
+	if err != nil {
+		return err
+	}
+	head := start
+	for {
+		if number, err := cl.head(ctx); err == nil && number > head {
+			head = number
+		}
+		if head >= start+blocks {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("chain stalled at block %d, %d blocks produced in %v, want %d", head, head-start, timeout, blocks)
+		}
+		select {
+		case <-time.After(pollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// checkSafety checks that the running nodes have no conflicting blocks. As each block commits to
+// its parent, it is enough for each pair of nodes to agree on the block at the lowest of their
+// heads.
+func (cl *Cluster) checkSafety(ctx context.Context) error {
+	var (
+		nodes []*Node
+		heads []uint64
+	)
+	for _, node := range cl.ensureNodes() {
+		if !node.Running() {
+			continue
+		}
+		head, err := blockNumber(ctx, node)
+		if err != nil {
+			return err
+		}
+		nodes, heads = append(nodes, node), append(heads, head)
+	}
+	for a := range nodes {
+		for b := a + 1; b < len(nodes); b++ {
+			number := heads[a]
+			if heads[b] < number {
+				number = heads[b]
+			}
+			hashA, err := blockHash(ctx, nodes[a], number)
+			if err != nil {
+				return err
+			}
+			hashB, err := blockHash(ctx, nodes[b], number)
+			if err != nil {
+				return err
+			}
+			if hashA != hashB {
+				return fmt.Errorf("conflicting blocks at height %d: validator-%d has %s, validator-%d %s",
+					number, nodes[a].Number, hashA.Hex(), nodes[b].Number, hashB.Hex())
+			}
+		}
+	}
+	log.Printf("No conflicting blocks between %d validators", len(nodes))
+	return nil
+}
+
+func blockNumber(ctx context.Context, node *Node) (uint64, error) {
+	client, err := node.Client()
+	if err != nil {
+		return 0, err
+	}
+	var number hexutil.Uint64
+	if err := client.CallContext(ctx, &number, "eth_blockNumber"); err != nil {
+		return 0, fmt.Errorf("validator-%d: %v", node.Number, err)
+	}
+	return uint64(number), nil
+}
+
+func blockHash(ctx context.Context, node *Node, number uint64) (common.Hash, error) {
+	client, err := node.Client()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var block *struct {
+		Hash common.Hash `json:"hash"`
+	}
+	if err := client.CallContext(ctx, &block, "eth_getBlockByNumber", hexutil.EncodeUint64(number), false); err != nil {
+		return common.Hash{}, fmt.Errorf("validator-%d: %v", node.Number, err)
+	}
+	if block == nil {
+		return common.Hash{}, fmt.Errorf("validator-%d: no block %d", node.Number, number)
+	}
+	return block.Hash, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
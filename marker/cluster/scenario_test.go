@@ -0,0 +1,62 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseScenarios(t *testing.T) {
+	scenarios, err := ParseScenarios([]byte(`
+scenarios:
+  - name: minority-partition
+    steps:
+      - {action: partition, groups: [[0, 1, 2], [3]]}
+      - {action: waitBlocks, blocks: 5}
+      - action: heal
+    liveness: {blocks: 10, timeout: 2m}
+  - steps:
+      - {action: latency, nodes: [0, 1], duration: 300ms}
+      - {action: loss, nodes: [0, 1, 2], loss: 0.1}
+      - {action: kill, nodes: [1], state: Prepared}
+      - {action: sleep, duration: 10s}
+      - {action: start, nodes: [1]}
+`))
+	if err != nil {
+		t.Fatalf("ParseScenarios: %v", err)
+	}
+	if len(scenarios) != 2 {
+		t.Fatalf("scenarios mismatch: have %d, want 2", len(scenarios))
+	}
+	first, second := scenarios[0], scenarios[1]
+	if first.Liveness.Blocks != 10 || time.Duration(first.Liveness.Timeout) != 2*time.Minute {
+		t.Errorf("liveness mismatch: %+v", first.Liveness)
+	}
+	if second.Name != "scenario-1" || second.Liveness.Blocks != defaultLivenessBlocks || time.Duration(second.Liveness.Timeout) != defaultTimeout {
+		t.Errorf("defaults mismatch: name %s, liveness %+v", second.Name, second.Liveness)
+	}
+	if step := second.Steps[0]; time.Duration(step.Duration) != 300*time.Millisecond || time.Duration(step.Timeout) != defaultTimeout {
+		t.Errorf("latency step mismatch: %+v", step)
+	}
+	if err := first.check(4); err != nil {
+		t.Errorf("check in a cluster of 4: %v", err)
+	}
+	if err := first.check(3); err == nil {
+		t.Error("expected error for validator-3 in a cluster of 3")
+	}
+
+	for _, invalid := range []string{
+		`{}`,
+		`{"scenarios": [{"steps": [{"action": "reboot"}]}]}`,
+		`{"scenarios": [{"steps": [{"action": "stop"}]}]}`,
+		`{"scenarios": [{"steps": [{"action": "kill", "nodes": [0], "state": "Voting"}]}]}`,
+		`{"scenarios": [{"steps": [{"action": "partition", "groups": [[0, 1]]}]}]}`,
+		`{"scenarios": [{"steps": [{"action": "latency", "nodes": [0], "duration": "1s"}]}]}`,
+		`{"scenarios": [{"steps": [{"action": "loss", "nodes": [0, 1], "loss": 2}]}]}`,
+		`{"scenarios": [{"steps": [{"action": "sleep", "duration": 10}]}]}`,
+		`{"scenarios": [{"steps": [{"action": "waitBlocks"}]}]}`,
+	} {
+		if _, err := ParseScenarios([]byte(invalid)); err == nil {
+			t.Errorf("expected error for scenarios %s", invalid)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common/mclock"
@@ -77,6 +78,9 @@ type Peer struct {
 	purposesMu sync.Mutex
 	purposes   PurposeFlag
 
+	pingSent int64 // Unix time in nanoseconds of the last unanswered ping, zero if none (atomic)
+	rtt      int64 // Round trip time in nanoseconds of the last answered ping (atomic)
+
 	Server *Server
 }
 
@@ -165,6 +169,12 @@ func (p *Peer) Inbound() bool {
 	return p.rw.is(inboundConn)
 }
 
+// RTT returns the round trip time of the last ping answered by the peer, or zero
+// if no ping has been answered yet.
+func (p *Peer) RTT() time.Duration {
+	return time.Duration(atomic.LoadInt64(&p.rtt))
+}
+
 func (p *Peer) Log() log.Logger {
 	return p.log
 }
@@ -244,6 +254,7 @@ func (p *Peer) pingLoop() {
 	for {
 		select {
 		case <-ping.C:
+			atomic.StoreInt64(&p.pingSent, time.Now().UnixNano())
 			if err := SendItems(p.rw, pingMsg); err != nil {
 				p.protoErr <- err
 				return
@@ -278,6 +289,11 @@ func (p *Peer) handle(msg Msg) error {
 	case msg.Code == pingMsg:
 		msg.Discard()
 		go SendItems(p.rw, pongMsg)
+	case msg.Code == pongMsg:
+		msg.Discard()
+		if sent := atomic.SwapInt64(&p.pingSent, 0); sent != 0 {
+			atomic.StoreInt64(&p.rtt, msg.ReceivedAt.UnixNano()-sent)
+		}
 	case msg.Code == discMsg:
 		var reason [1]DiscReason
 		// This is the last message. We don't need to discard or
@@ -11,10 +11,19 @@ type BlockHeader struct {
 
 contract HeaderStore {
     event UpdateBlockHeader(address indexed account, uint256 indexed blockHeight);
+    event ReorgBlockHeader(uint256 indexed chainID, uint256 indexed ancestor, bytes32 ancestorHash, uint256 oldHead, bytes32 oldHash, uint256 newHead, bytes32 newHash, uint256 depth);
+    event RelayerUpdated(uint256 indexed chainID, address indexed oldRelayer, address indexed newRelayer);
     function updateBlockHeader(bytes memory blockHeader) public {}
     function currentNumberAndHash(uint256 chainID) public returns (uint256 number, bytes memory hash) {}
+    function isConfirmed(uint256 chainID, uint256 blockNumber, uint256 confirmations) public returns (bool confirmed) {}
     function setRelayer(address relayer) public {}
     function getRelayer() public returns (address relayer) {}
+    function addRelayer(uint256 chainID, address relayer) public {}
+    function removeRelayer(uint256 chainID, address relayer) public {}
+    function rotateRelayer(uint256 chainID, address newRelayer) public {}
+    function getRelayers(uint256 chainID) public returns (address[] memory relayers, uint256[] memory submissions) {}
+    function setRelayerRewardShare(uint256 share) public {}
+    function getRelayerRewardShare() public returns (uint256 share) {}
     function reset(uint256 from, uint256 td, bytes memory header) public {}
     function verifyProofData(bytes memory receiptProof) public returns(bool success, string memory message, bytes memory logs) {}
 }
@@ -39,6 +48,104 @@ const HeaderStoreABIJSON = `[
 	   "name": "UpdateBlockHeader",
 	   "type": "event"
 	},
+	{
+	   "anonymous": false,
+	   "inputs": [
+		  {
+			 "indexed": true,
+			 "internalType": "uint256",
+			 "name": "chainID",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": true,
+			 "internalType": "uint256",
+			 "name": "ancestor",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "bytes32",
+			 "name": "ancestorHash",
+			 "type": "bytes32"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "uint256",
+			 "name": "oldHead",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "bytes32",
+			 "name": "oldHash",
+			 "type": "bytes32"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "uint256",
+			 "name": "newHead",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "bytes32",
+			 "name": "newHash",
+			 "type": "bytes32"
+		  },
+		  {
+			 "indexed": false,
+			 "internalType": "uint256",
+			 "name": "depth",
+			 "type": "uint256"
+		  }
+	   ],
+	   "name": "ReorgBlockHeader",
+	   "type": "event"
+	},
+	{
+	   "anonymous": false,
+	   "inputs": [
+		  {
+			 "indexed": true,
+			 "internalType": "uint256",
+			 "name": "chainID",
+			 "type": "uint256"
+		  },
+		  {
+			 "indexed": true,
+			 "internalType": "address",
+			 "name": "oldRelayer",
+			 "type": "address"
+		  },
+		  {
+			 "indexed": true,
+			 "internalType": "address",
+			 "name": "newRelayer",
+			 "type": "address"
+		  }
+	   ],
+	   "name": "RelayerUpdated",
+	   "type": "event"
+	},
+	{
+	   "inputs": [
+		  {
+			 "internalType": "uint256",
+			 "name": "chainID",
+			 "type": "uint256"
+		  },
+		  {
+			 "internalType": "address",
+			 "name": "relayer",
+			 "type": "address"
+		  }
+	   ],
+	   "name": "addRelayer",
+	   "outputs": [],
+	   "stateMutability": "nonpayable",
+	   "type": "function"
+	},
 	{
 	   "inputs": [
 		  {
@@ -63,6 +170,35 @@ const HeaderStoreABIJSON = `[
 	   "stateMutability": "nonpayable",
 	   "type": "function"
 	},
+	{
+	   "inputs": [
+		  {
+			 "internalType": "uint256",
+			 "name": "chainID",
+			 "type": "uint256"
+		  },
+		  {
+			 "internalType": "uint256",
+			 "name": "blockNumber",
+			 "type": "uint256"
+		  },
+		  {
+			 "internalType": "uint256",
+			 "name": "confirmations",
+			 "type": "uint256"
+		  }
+	   ],
+	   "name": "isConfirmed",
+	   "outputs": [
+		  {
+			 "internalType": "bool",
+			 "name": "confirmed",
+			 "type": "bool"
+		  }
+	   ],
+	   "stateMutability": "nonpayable",
+	   "type": "function"
+	},
 	{
 	   "inputs": [],
 	   "name": "getRelayer",
@@ -76,6 +212,61 @@ const HeaderStoreABIJSON = `[
 	   "stateMutability": "nonpayable",
 	   "type": "function"
 	},
+	{
+	   "inputs": [],
+	   "name": "getRelayerRewardShare",
+	   "outputs": [
+		  {
+			 "internalType": "uint256",
+			 "name": "share",
+			 "type": "uint256"
+		  }
+	   ],
+	   "stateMutability": "nonpayable",
+	   "type": "function"
+	},
+	{
+	   "inputs": [
+		  {
+			 "internalType": "uint256",
+			 "name": "chainID",
+			 "type": "uint256"
+		  }
+	   ],
+	   "name": "getRelayers",
+	   "outputs": [
+		  {
+			 "internalType": "address[]",
+			 "name": "relayers",
+			 "type": "address[]"
+		  },
+		  {
+			 "internalType": "uint256[]",
+			 "name": "submissions",
+			 "type": "uint256[]"
+		  }
+	   ],
+	   "stateMutability": "nonpayable",
+	   "type": "function"
+	},
+	{
+	   "inputs": [
+		  {
+			 "internalType": "uint256",
+			 "name": "chainID",
+			 "type": "uint256"
+		  },
+		  {
+			 "internalType": "address",
+			 "name": "relayer",
+			 "type": "address"
+		  }
+	   ],
+	   "name": "removeRelayer",
+	   "outputs": [],
+	   "stateMutability": "nonpayable",
+	   "type": "function"
+	},
 	{
 	   "inputs": [
 		  {
@@ -99,6 +290,24 @@ const HeaderStoreABIJSON = `[
 	   "stateMutability": "nonpayable",
 	   "type": "function"
 	},
+	{
+	   "inputs": [
+		  {
+			 "internalType": "uint256",
+			 "name": "chainID",
+			 "type": "uint256"
+		  },
+		  {
+			 "internalType": "address",
+			 "name": "newRelayer",
+			 "type": "address"
+		  }
+	   ],
+	   "name": "rotateRelayer",
+	   "outputs": [],
+	   "stateMutability": "nonpayable",
+	   "type": "function"
+	},
 	{
 	   "inputs": [
 		  {
@@ -112,6 +321,19 @@ const HeaderStoreABIJSON = `[
 	   "stateMutability": "nonpayable",
 	   "type": "function"
 	},
+	{
+	   "inputs": [
+		  {
+			 "internalType": "uint256",
+			 "name": "share",
+			 "type": "uint256"
+		  }
+	   ],
+	   "name": "setRelayerRewardShare",
+	   "outputs": [],
+	   "stateMutability": "nonpayable",
+	   "type": "function"
+	},
 	{
 	   "inputs": [
 		  {
@@ -174,11 +396,51 @@ type TxProve struct {
 	TxIndex     uint
 }
 
+// post-merge ethereum blocks finalized through the eth2 header store
+type TxProve struct {
+	Receipt         *ethtypes.Receipt
+	Prove           light.NodeList
+	TxIndex         uint
+	Header          *eth2.ExecutionBlockHeader
+	BeaconHeader    *eth2.BeaconBlockHeader
+	ExecutionBranch [][]byte
+}
+
 contract TxVerify {
     function verifyProofData(bytes memory receiptProof) public returns(bool success, string memory message, bytes memory logs) {}
+    function verifyProofDataBatch(bytes[] memory receiptProofs) public returns(bool[] memory success, string[] memory messages, bytes[] memory logs) {}
 }
 */
 const TxVerifyABIJSON = `[
+	{
+		"inputs": [
+			{
+				"internalType": "bytes[]",
+				"name": "receiptProofs",
+				"type": "bytes[]"
+			}
+		],
+		"name": "verifyProofDataBatch",
+		"outputs": [
+			{
+				"internalType": "bool[]",
+				"name": "success",
+				"type": "bool[]"
+			},
+			{
+				"internalType": "string[]",
+				"name": "messages",
+				"type": "string[]"
+			},
+			{
+				"internalType": "bytes[]",
+				"name": "logs",
+				"type": "bytes[]"
+			}
+		],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
 	{
 		"inputs": [
 			{
@@ -209,3 +471,181 @@ const TxVerifyABIJSON = `[
 		"type": "function"
 	}
 ]`
+
+// Eth2HeaderStoreABIJSON  eth2 header store abi json
+/*
+
+contract Eth2HeaderStore {
+    event UpdateLightClient(address indexed account, uint256 indexed chainID, uint256 indexed blockNumber);
+    function reset(bytes memory state, uint256 number, bytes32 blockHash, bytes32 receiptsRoot) public {}
+    function updateLightClient(uint256 chainID, bytes memory update) public {}
+    function currentNumberAndHash(uint256 chainID) public returns (uint256 number, bytes32 hash) {}
+    function finalizedSlot(uint256 chainID) public returns (uint64 slot) {}
+    function getBlockHash(uint256 chainID, uint256 number) public returns (bytes32 hash) {}
+    function getReceiptsRoot(uint256 chainID, uint256 number) public returns (bytes32 receiptsRoot) {}
+}
+*/
+const Eth2HeaderStoreABIJSON = `[
+	{
+		"anonymous": false,
+		"inputs": [
+			{
+				"indexed": true,
+				"internalType": "address",
+				"name": "account",
+				"type": "address"
+			},
+			{
+				"indexed": true,
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			},
+			{
+				"indexed": true,
+				"internalType": "uint256",
+				"name": "blockNumber",
+				"type": "uint256"
+			}
+		],
+		"name": "UpdateLightClient",
+		"type": "event"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			}
+		],
+		"name": "currentNumberAndHash",
+		"outputs": [
+			{
+				"internalType": "uint256",
+				"name": "number",
+				"type": "uint256"
+			},
+			{
+				"internalType": "bytes32",
+				"name": "hash",
+				"type": "bytes32"
+			}
+		],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			}
+		],
+		"name": "finalizedSlot",
+		"outputs": [
+			{
+				"internalType": "uint64",
+				"name": "slot",
+				"type": "uint64"
+			}
+		],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			},
+			{
+				"internalType": "uint256",
+				"name": "number",
+				"type": "uint256"
+			}
+		],
+		"name": "getBlockHash",
+		"outputs": [
+			{
+				"internalType": "bytes32",
+				"name": "hash",
+				"type": "bytes32"
+			}
+		],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			},
+			{
+				"internalType": "uint256",
+				"name": "number",
+				"type": "uint256"
+			}
+		],
+		"name": "getReceiptsRoot",
+		"outputs": [
+			{
+				"internalType": "bytes32",
+				"name": "receiptsRoot",
+				"type": "bytes32"
+			}
+		],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "bytes",
+				"name": "state",
+				"type": "bytes"
+			},
+			{
+				"internalType": "uint256",
+				"name": "number",
+				"type": "uint256"
+			},
+			{
+				"internalType": "bytes32",
+				"name": "blockHash",
+				"type": "bytes32"
+			},
+			{
+				"internalType": "bytes32",
+				"name": "receiptsRoot",
+				"type": "bytes32"
+			}
+		],
+		"name": "reset",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	},
+	{
+		"inputs": [
+			{
+				"internalType": "uint256",
+				"name": "chainID",
+				"type": "uint256"
+			},
+			{
+				"internalType": "bytes",
+				"name": "update",
+				"type": "bytes"
+			}
+		],
+		"name": "updateLightClient",
+		"outputs": [],
+		"stateMutability": "nonpayable",
+		"type": "function"
+	}
+]`
@@ -72,6 +72,8 @@ var (
 	ValidatorsRegistryId = makeRegistryId("Validators")
 	AccountsId           = makeRegistryId("Accounts")
 
+	DoubleSigningSlasherRegistryId = makeRegistryId("DoubleSigningSlasher")
+
 	// Function is "getOrComputeTobinTax()"
 	// selector is first 4 bytes of keccak256 of "getOrComputeTobinTax()"
 	// Source:
@@ -171,6 +171,12 @@ var (
 		DonutBlock:          nil,
 		EWASMBlock:          nil,
 		CatalystBlock:       nil,
+
+		Eth2HeaderStoreBlock: big.NewInt(0),
+		VerifyBatchBlock:     big.NewInt(0),
+		RelayFinalityBlock:   big.NewInt(0),
+		RelayerSetBlock:      big.NewInt(0),
+		VRFBlock:             big.NewInt(0),
 		Istanbul: &IstanbulConfig{
 			Epoch:          17280,
 			ProposerPolicy: 2,
@@ -205,6 +211,12 @@ var (
 		DonutBlock:          nil,
 		EWASMBlock:          nil,
 		CatalystBlock:       nil,
+
+		Eth2HeaderStoreBlock: big.NewInt(0),
+		VerifyBatchBlock:     big.NewInt(0),
+		RelayFinalityBlock:   big.NewInt(0),
+		RelayerSetBlock:      big.NewInt(0),
+		VRFBlock:             big.NewInt(0),
 		Istanbul: &IstanbulConfig{
 			Epoch:          4000,
 			ProposerPolicy: 2,
@@ -239,6 +251,12 @@ var (
 		DonutBlock:          nil,
 		EWASMBlock:          big.NewInt(0),
 		CatalystBlock:       nil,
+
+		Eth2HeaderStoreBlock: big.NewInt(0),
+		VerifyBatchBlock:     big.NewInt(0),
+		RelayFinalityBlock:   big.NewInt(0),
+		RelayerSetBlock:      big.NewInt(0),
+		VRFBlock:             big.NewInt(0),
 		Istanbul: &IstanbulConfig{
 			Epoch:          300,
 			ProposerPolicy: 0,
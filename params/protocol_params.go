@@ -12,6 +12,7 @@ const (
 
 	// Contract communication gas limits
 	MaxGasForCalculateTargetEpochPaymentAndRewards uint64 = 2000 * million
+	MaxGasForCheckForDoubleSigning                 uint64 = 2 * million
 	MaxGasForCommitments                           uint64 = 2000 * million
 	MaxGasForComputeCommitment                     uint64 = 2000 * million
 	MaxGasForBlockRandomness                       uint64 = 2000 * million
@@ -40,6 +41,7 @@ const (
 	MaxGasForMedianRate                            uint64 = 100 * thousand
 	MaxGasForReadBlockchainParameter               uint64 = 40 * thousand // ad-hoc measurement is ~26k
 	MaxGasForRevealAndCommit                       uint64 = 20 * million
+	MaxGasForSlashDoubleSigning                    uint64 = 2 * million
 	MaxGasForUpdateGasPriceMinimum                 uint64 = 20 * million
 	MaxGasForUpdateTargetVotingYield               uint64 = 20 * million
 	MaxGasForUpdateValidatorScore                  uint64 = 10 * million
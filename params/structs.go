@@ -102,6 +102,33 @@ type ChainConfig struct {
 	EnableRewardBlock *big.Int `json:"rewardblock,omitempty"`
 	DeregisterBlock   *big.Int `json:"deregisterblock,omitempty"`
 	CalcBaseBlock     *big.Int `json:"calcbaseblock,omitempty"`
+
+	// WeightedBFTBlock switches the Istanbul quorum from validator count to the voting power
+	// elected at the epoch boundaries (nil = no fork, 0 = already activated)
+	WeightedBFTBlock *big.Int `json:"weightedBftBlock,omitempty"`
+
+	// Eth2HeaderStoreBlock enables the stateful eth2 light client header store precompile
+	// (nil = no fork, 0 = already activated)
+	Eth2HeaderStoreBlock *big.Int `json:"eth2HeaderStoreBlock,omitempty"`
+
+	// RelayFinalityBlock enables the reorg log and the confirmation query of the header store
+	// precompile (nil = no fork, 0 = already activated)
+	RelayFinalityBlock *big.Int `json:"relayFinalityBlock,omitempty"`
+
+	// VerifyBatchBlock enables the batch receipt proof verification of the tx verify precompile
+	// (nil = no fork, 0 = already activated)
+	VerifyBatchBlock *big.Int `json:"verifyBatchBlock,omitempty"`
+
+	// RelayerSetBlock enables the relayer sets of the header store precompile and pays their
+	// share of the maintainer reward (nil = no fork, 0 = already activated)
+	RelayerSetBlock *big.Int `json:"relayerSetBlock,omitempty"`
+
+	// VRFBlock enables the VRF proof in the header extra-data and the VRF proposer order
+	// (nil = no fork, 0 = already activated)
+	VRFBlock *big.Int `json:"vrfBlock,omitempty"`
+
+	// Chains whose headers can be relayed in addition to the builtin ones
+	RelayChains []*RelayChainConfig `json:"relayChains,omitempty"`
 	// This does not belong here but passing it to every function is not possible since that breaks
 	// some implemented interfaces and introduces churn across the geth codebase.
 	FullHeaderChainAvailable bool // False for lightest Sync mode, true otherwise
@@ -110,6 +137,20 @@ type ChainConfig struct {
 	Faker bool `json:"faker,omitempty"`
 }
 
+// RelayChainConfig registers a chain of an existing chain group for relaying, it is activated
+// from genesis or from ActivationBlock on.
+type RelayChainConfig struct {
+	ChainType        uint64        `json:"chainType"`
+	Group            uint64        `json:"group"`
+	ChainID          uint64        `json:"chainId"`                    // Atlas chain id on which the header store can be reset
+	LondonBlock      *big.Int      `json:"londonBlock,omitempty"`      // EIP-1559 fork block of the relayed chain (nil = no fork)
+	ExecutionChainID uint64        `json:"executionChainId,omitempty"` // Chain id keying the eth2 light client store (0 = none)
+	Confirmations    uint64        `json:"confirmations,omitempty"`    // Confirmations required before proofs are accepted
+	ActivationBlock  *big.Int      `json:"activationBlock,omitempty"`  // Atlas block the chain is supported from (nil = genesis)
+	GenesisHeader    hexutil.Bytes `json:"genesisHeader,omitempty"`    // Trusted checkpoint header the store starts from
+	GenesisTD        *big.Int      `json:"genesisTD,omitempty"`        // Total difficulty of the checkpoint header
+}
+
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
 type EthashConfig struct{}
 
@@ -259,6 +300,36 @@ func (c *ChainConfig) IsCalc(num *big.Int) bool {
 	return isForked(c.CalcBaseBlock, num)
 }
 
+// IsWeightedBFT returns whether num is either equal to the weighted BFT fork block or greater.
+func (c *ChainConfig) IsWeightedBFT(num *big.Int) bool {
+	return isForked(c.WeightedBFTBlock, num)
+}
+
+// IsEth2HeaderStore returns whether num is either equal to the eth2 header store fork block or greater.
+func (c *ChainConfig) IsEth2HeaderStore(num *big.Int) bool {
+	return isForked(c.Eth2HeaderStoreBlock, num)
+}
+
+// IsRelayFinality returns whether num is either equal to the relay finality fork block or greater.
+func (c *ChainConfig) IsRelayFinality(num *big.Int) bool {
+	return isForked(c.RelayFinalityBlock, num)
+}
+
+// IsVerifyBatch returns whether num is either equal to the verify batch fork block or greater.
+func (c *ChainConfig) IsVerifyBatch(num *big.Int) bool {
+	return isForked(c.VerifyBatchBlock, num)
+}
+
+// IsRelayerSet returns whether num is either equal to the relayer set fork block or greater.
+func (c *ChainConfig) IsRelayerSet(num *big.Int) bool {
+	return isForked(c.RelayerSetBlock, num)
+}
+
+// IsVRF returns whether num is either equal to the VRF fork block or greater.
+func (c *ChainConfig) IsVRF(num *big.Int) bool {
+	return isForked(c.VRFBlock, num)
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -371,9 +442,66 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if isForkIncompatible(c.WeightedBFTBlock, newcfg.WeightedBFTBlock, head) {
+		return newCompatError("weighted BFT fork block", c.WeightedBFTBlock, newcfg.WeightedBFTBlock)
+	}
+	if isForkIncompatible(c.Eth2HeaderStoreBlock, newcfg.Eth2HeaderStoreBlock, head) {
+		return newCompatError("eth2 header store fork block", c.Eth2HeaderStoreBlock, newcfg.Eth2HeaderStoreBlock)
+	}
+	if isForkIncompatible(c.RelayFinalityBlock, newcfg.RelayFinalityBlock, head) {
+		return newCompatError("relay finality fork block", c.RelayFinalityBlock, newcfg.RelayFinalityBlock)
+	}
+	if isForkIncompatible(c.VerifyBatchBlock, newcfg.VerifyBatchBlock, head) {
+		return newCompatError("verify batch fork block", c.VerifyBatchBlock, newcfg.VerifyBatchBlock)
+	}
+	if isForkIncompatible(c.RelayerSetBlock, newcfg.RelayerSetBlock, head) {
+		return newCompatError("relayer set fork block", c.RelayerSetBlock, newcfg.RelayerSetBlock)
+	}
+	if isForkIncompatible(c.VRFBlock, newcfg.VRFBlock, head) {
+		return newCompatError("VRF fork block", c.VRFBlock, newcfg.VRFBlock)
+	}
+	for _, chain := range append(c.RelayChains, newcfg.RelayChains...) {
+		stored, next := c.relayChain(chain.ChainType), newcfg.relayChain(chain.ChainType)
+		what := fmt.Sprintf("relay chain %d activation block", chain.ChainType)
+		if isForkIncompatible(stored.activation(), next.activation(), head) {
+			return newCompatError(what, stored.activation(), next.activation())
+		}
+		// the parameters of an active chain can not change either
+		if isForked(stored.activation(), head) && !stored.equal(next) {
+			return newCompatError(what, stored.activation(), next.activation())
+		}
+	}
+	return nil
+}
+
+// relayChain returns the relay chain of the given type, nil if it is not listed.
+func (c *ChainConfig) relayChain(chainType uint64) *RelayChainConfig {
+	for _, chain := range c.RelayChains {
+		if chain.ChainType == chainType {
+			return chain
+		}
+	}
 	return nil
 }
 
+func (c *RelayChainConfig) equal(other *RelayChainConfig) bool {
+	a, _ := json.Marshal(c)
+	b, _ := json.Marshal(other)
+	return bytes.Equal(a, b)
+}
+
+// activation returns the Atlas block the relay chain is supported from, nil if it is
+// not listed.
+func (c *RelayChainConfig) activation() *big.Int {
+	if c == nil {
+		return nil
+	}
+	if c.ActivationBlock == nil {
+		return common.Big0
+	}
+	return c.ActivationBlock
+}
+
 // isForkIncompatible returns true if a fork scheduled at s1 cannot be rescheduled to
 // block s2 because head is already past the fork.
 func isForkIncompatible(s1, s2, head *big.Int) bool {
@@ -439,6 +567,7 @@ type Rules struct {
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158               bool
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
 	IsBerlin, IsLondon, IsCatalyst                          bool
+	IsEth2HeaderStore, IsVerifyBatch, IsRelayerSet          bool
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -460,6 +589,10 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 		IsBerlin:         c.IsBerlin(num),
 		IsLondon:         c.IsLondon(num),
 		IsCatalyst:       c.IsCatalyst(num),
+
+		IsEth2HeaderStore: c.IsEth2HeaderStore(num),
+		IsVerifyBatch:     c.IsVerifyBatch(num),
+		IsRelayerSet:      c.IsRelayerSet(num),
 	}
 }
 
@@ -468,3 +601,11 @@ type NumberHash struct {
 	Number uint64
 	Hash   common.Hash
 }
+
+// Reorg describes the replacement of the canonical head of a relayed chain.
+type Reorg struct {
+	OldHead  NumberHash
+	NewHead  NumberHash
+	Ancestor NumberHash // latest header shared by the old and the new canonical chain
+	Depth    uint64     // number of headers removed from the old canonical chain
+}
@@ -0,0 +1,35 @@
+package tools
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ParallelFor calls fn for every index in [0, n) on as many workers as allowed threads
+// and returns once all calls have finished.
+func ParallelFor(n int, fn func(i int)) {
+	workers := runtime.GOMAXPROCS(0)
+	if n < workers {
+		workers = n
+	}
+
+	var (
+		wg    sync.WaitGroup
+		tasks = make(chan int, n)
+	)
+	for i := 0; i < n; i++ {
+		tasks <- i
+	}
+	close(tasks)
+
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}